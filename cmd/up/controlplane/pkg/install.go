@@ -17,11 +17,14 @@ package pkg
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/pterm/pterm"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -29,14 +32,19 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 
+	"github.com/upbound/up/internal/credhelper"
 	"github.com/upbound/up/internal/kube"
 	"github.com/upbound/up/internal/resources"
 	"github.com/upbound/up/internal/upbound"
 	"github.com/upbound/up/internal/upterm"
 	"github.com/upbound/up/internal/xpkg"
+	"github.com/upbound/up/internal/xpkg/signature"
 )
 
-const errUnknownPkgType = "provided package type is unknown"
+const (
+	errUnknownPkgType      = "provided package type is unknown"
+	errReadVerificationKey = "failed to read verification key"
+)
 
 // Supported package kinds.
 const (
@@ -102,6 +110,7 @@ type installCmd struct {
 	Name               string        `help:"Name of ${package_type}."`
 	PackagePullSecrets []string      `help:"List of secrets used to pull ${package_type}."`
 	Wait               time.Duration `short:"w" help:"Wait duration for successful ${package_type} installation."`
+	RequireSignature   string        `type:"existingfile" placeholder:"PUBLIC-KEY" help:"Require the ${package_type} to be signed with the private key belonging to the supplied public key. The verified digest is installed rather than the tag."`
 }
 
 // Run executes the install command.
@@ -113,6 +122,22 @@ func (c *installCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upboun
 	if c.Name == "" {
 		c.Name = xpkg.ToDNSLabel(ref.Context().RepositoryStr())
 	}
+	// NOTE: the control plane cannot fall back to other mirrors, so the
+	// package is installed from the first mirror of its registry, if any.
+	ref, err = upCtx.Profile.Mirrors.Rewrite(ref)
+	if err != nil {
+		return err
+	}
+	pkgRef := ref.Name()
+	if c.RequireSignature != "" {
+		d, err := c.verify(ctx, upCtx, ref)
+		if err != nil {
+			return err
+		}
+		// NOTE: install the verified digest so that the tag cannot be moved
+		// to an unsigned package before Crossplane pulls it.
+		pkgRef = d.Name()
+	}
 	packagePullSecrets := make([]corev1.LocalObjectReference, len(c.PackagePullSecrets))
 	for i, s := range c.PackagePullSecrets {
 		packagePullSecrets[i] = corev1.LocalObjectReference{
@@ -126,7 +151,7 @@ func (c *installCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upboun
 			"name": c.Name,
 		},
 		"spec": map[string]interface{}{
-			"package":            ref.Name(),
+			"package":            pkgRef,
 			"packagePullSecrets": packagePullSecrets,
 		},
 	}}, v1.CreateOptions{}); err != nil {
@@ -161,3 +186,32 @@ func (c *installCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upboun
 	s.Success(fmt.Sprintf("%s installed and healthy", c.Name))
 	return nil
 }
+
+// verify resolves the supplied reference to a digest and verifies that it has
+// been signed with the private key belonging to the required public key.
+func (c *installCmd) verify(ctx context.Context, upCtx *upbound.Context, ref name.Reference) (name.Digest, error) {
+	b, err := os.ReadFile(c.RequireSignature)
+	if err != nil {
+		return name.Digest{}, errors.Wrap(err, errReadVerificationKey)
+	}
+	pub, err := signature.LoadPublicKey(b)
+	if err != nil {
+		return name.Digest{}, errors.Wrap(err, errReadVerificationKey)
+	}
+
+	kc := authn.NewMultiKeychain(
+		authn.NewKeychainFromHelper(
+			credhelper.New(
+				credhelper.WithDomain(upCtx.Domain.Hostname()),
+				credhelper.WithProfile(upCtx.ProfileName),
+			),
+		),
+		authn.DefaultKeychain,
+	)
+	opts := []remote.Option{remote.WithAuthFromKeychain(kc)}
+	d, err := signature.ResolveDigest(ctx, ref, opts...)
+	if err != nil {
+		return name.Digest{}, err
+	}
+	return d, signature.NewVerifier(pub, opts...).Verify(ctx, d)
+}
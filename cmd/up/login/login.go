@@ -180,6 +180,7 @@ func setSession(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context
 		Session: session,
 		// Carry over existing config.
 		BaseConfig: upCtx.Profile.BaseConfig,
+		Mirrors:    upCtx.Profile.Mirrors,
 	}
 	upCtx.Profile = profile
 
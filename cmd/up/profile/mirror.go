@@ -0,0 +1,128 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/upbound"
+)
+
+const (
+	errNoProfileSelected = "no profile selected, select one with --profile or up profile use"
+	errMirrorNotFoundFmt = "no mirror configured for %s"
+)
+
+// mirrorCmd manages the registry mirrors of a profile.
+type mirrorCmd struct {
+	Set   mirrorSetCmd   `cmd:"" help:"Pull the images of a registry or repository from mirrors."`
+	Unset mirrorUnsetCmd `cmd:"" help:"Remove the mirrors of a registry or repository."`
+	List  mirrorListCmd  `cmd:"" help:"List the registry mirrors of the profile."`
+}
+
+func (c *mirrorCmd) Help() string {
+	return `
+The mirror command manages the registry mirrors of the current profile, or of
+the profile selected with --profile. Images below a mirrored registry or
+repository prefix are pulled from the endpoints of the mirror instead, e.g.
+from a pull-through cache in a network without direct registry access:
+
+  up profile mirror set xpkg.upbound.io harbor.example.com/xpkg-proxy
+
+The mirror with the longest prefix matching an image applies. Its endpoints are
+tried in order by up xpkg dep, and by up xpkg push --from. Package references
+handed to control planes, by up ctp provider install and up space init, are
+rewritten to the first endpoint. The original registry is not tried unless it
+is one of the endpoints.`
+}
+
+// mirrorSetCmd sets the mirrors of a registry or repository prefix.
+type mirrorSetCmd struct {
+	Prefix    string   `arg:"" help:"Registry or repository prefix of the mirrored images, e.g. xpkg.upbound.io."`
+	Endpoints []string `arg:"" help:"Registries or repositories replacing the prefix, in the order in which they are tried."`
+}
+
+// Run executes the mirror set command.
+func (c *mirrorSetCmd) Run(p pterm.TextPrinter, upCtx *upbound.Context) error {
+	if upCtx.ProfileName == "" {
+		return errors.New(errNoProfileSelected)
+	}
+	mirrors := upCtx.Profile.Mirrors.Set(c.Prefix, c.Endpoints...)
+	if err := mirrors.Validate(); err != nil {
+		return err
+	}
+	upCtx.Profile.Mirrors = mirrors
+	if err := updateProfile(upCtx); err != nil {
+		return err
+	}
+	p.Printfln("Images below %s are pulled from %s", c.Prefix, strings.Join(c.Endpoints, ", "))
+	return nil
+}
+
+// mirrorUnsetCmd removes the mirrors of a registry or repository prefix.
+type mirrorUnsetCmd struct {
+	Prefix string `arg:"" help:"Registry or repository prefix of the mirrored images."`
+}
+
+// Run executes the mirror unset command.
+func (c *mirrorUnsetCmd) Run(p pterm.TextPrinter, upCtx *upbound.Context) error {
+	if upCtx.ProfileName == "" {
+		return errors.New(errNoProfileSelected)
+	}
+	mirrors, ok := upCtx.Profile.Mirrors.Unset(c.Prefix)
+	if !ok {
+		return errors.Errorf(errMirrorNotFoundFmt, c.Prefix)
+	}
+	upCtx.Profile.Mirrors = mirrors
+	if err := updateProfile(upCtx); err != nil {
+		return err
+	}
+	p.Printfln("Images below %s are pulled from their registry", c.Prefix)
+	return nil
+}
+
+// AfterApply sets default values in command after assignment and validation.
+func (c *mirrorListCmd) AfterApply(kongCtx *kong.Context) error {
+	kongCtx.Bind(pterm.DefaultTable.WithWriter(kongCtx.Stdout).WithSeparator("   "))
+	return nil
+}
+
+// mirrorListCmd lists the registry mirrors of a profile.
+type mirrorListCmd struct{}
+
+// Run executes the mirror list command.
+func (c *mirrorListCmd) Run(p pterm.TextPrinter, pt *pterm.TablePrinter, upCtx *upbound.Context) error {
+	if len(upCtx.Profile.Mirrors) == 0 {
+		p.Println("No mirrors configured")
+		return nil
+	}
+	data := make([][]string, len(upCtx.Profile.Mirrors)+1)
+	data[0] = []string{"PREFIX", "ENDPOINTS"}
+	for i, m := range upCtx.Profile.Mirrors {
+		data[i+1] = []string{m.Prefix, strings.Join(m.Endpoints, ", ")}
+	}
+	return pt.WithHasHeader().WithData(data).Render()
+}
+
+func updateProfile(upCtx *upbound.Context) error {
+	if err := upCtx.Cfg.AddOrUpdateUpboundProfile(upCtx.ProfileName, upCtx.Profile); err != nil {
+		return errors.Wrap(err, errUpdateProfile)
+	}
+	return errors.Wrap(upCtx.CfgSrc.UpdateConfig(upCtx.Cfg), errUpdateConfig)
+}
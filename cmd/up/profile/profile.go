@@ -30,6 +30,7 @@ type Cmd struct {
 	View    viewCmd    `cmd:"" help:"View the Upbound Profile settings across profiles."`
 	Config  config.Cmd `cmd:"" help:"Interact with the current Upbound Profile's config."`
 	Set     setCmd     `cmd:"" help:"Set an Upbound Profile for use with a Space."`
+	Mirror  mirrorCmd  `cmd:"" help:"Manage the registry mirrors of the current Upbound Profile."`
 
 	Flags upbound.Flags `embed:""`
 }
@@ -37,7 +38,7 @@ type Cmd struct {
 // AfterApply constructs and binds Upbound-specific context to any subcommands
 // that have Run() methods that receive it.
 func (c *Cmd) AfterApply(kongCtx *kong.Context) error {
-	upCtx, err := upbound.NewFromFlags(c.Flags, upbound.AllowMissingProfile())
+	upCtx, err := upbound.NewFromFlags(c.Flags, upbound.AllowMissingProfile(), upbound.AllowInvalidMirrors())
 	if err != nil {
 		return err
 	}
@@ -77,6 +77,7 @@ func (c *spaceCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.
 		KubeContext: c.Kube.GetContext(),
 		// Carry over existing config.
 		BaseConfig: upCtx.Profile.BaseConfig,
+		Mirrors:    upCtx.Profile.Mirrors,
 	}
 
 	installed, err := c.checkForSpaces(ctx)
@@ -9,8 +9,10 @@ import (
 	"os"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/name"
 
 	"github.com/upbound/up/internal/input"
+	"github.com/upbound/up/internal/mirror"
 )
 
 type registryFlags struct {
@@ -18,6 +20,29 @@ type registryFlags struct {
 	Endpoint   *url.URL `hidden:"" name:"registry-endpoint" env:"UPBOUND_REGISTRY_ENDPOINT" default:"https://us-west1-docker.pkg.dev" help:"Set registry endpoint, including scheme, for authentication."`
 }
 
+// applyMirrors rewrites the repository to the first of the supplied mirrors of
+// it, if any, and the endpoint to the registry of that mirror.
+func (p *registryFlags) applyMirrors(m mirror.Mirrors) error {
+	repo, err := name.NewRepository(p.Repository.String())
+	if err != nil {
+		return err
+	}
+	repos, err := m.Repositories(repo)
+	if err != nil {
+		return err
+	}
+	if repos[0].Name() == repo.Name() {
+		return nil
+	}
+	u, err := url.Parse(repos[0].Name())
+	if err != nil {
+		return err
+	}
+	p.Repository = u
+	p.Endpoint = &url.URL{Scheme: p.Endpoint.Scheme, Host: repos[0].RegistryStr()}
+	return nil
+}
+
 type authorizedRegistryFlags struct {
 	registryFlags
 
@@ -123,9 +123,6 @@ func (c *initCmd) AfterApply(kongCtx *kong.Context, quiet config.QuietFlag) erro
 	if err := c.Kube.AfterApply(); err != nil {
 		return err
 	}
-	if err := c.Registry.AfterApply(); err != nil {
-		return err
-	}
 
 	// NOTE(tnthornton) we currently only have support for stylized output.
 	pterm.EnableStyling()
@@ -135,6 +132,15 @@ func (c *initCmd) AfterApply(kongCtx *kong.Context, quiet config.QuietFlag) erro
 	if err != nil {
 		return err
 	}
+	// NOTE: mirrors are applied before the registry credentials are read, as
+	// the credentials are those of the mirror.
+	if err := c.Registry.applyMirrors(upCtx.Profile.Mirrors); err != nil {
+		return err
+	}
+	if err := c.Registry.AfterApply(); err != nil {
+		return err
+	}
+
 	kongCtx.Bind(upCtx)
 
 	kubeconfig := c.Kube.GetConfig()
@@ -160,7 +166,7 @@ func (c *initCmd) AfterApply(kongCtx *kong.Context, quiet config.QuietFlag) erro
 		pterm.Info.Println("Public ingress will be exposed")
 	}
 
-	prereqs, err := prerequisites.New(kubeconfig, defs)
+	prereqs, err := prerequisites.New(kubeconfig, defs, prerequisites.WithMirrors(upCtx.Profile.Mirrors))
 	if err != nil {
 		return err
 	}
@@ -280,6 +286,7 @@ func (c *initCmd) createOrUpdateProfile(acct string, upCtx *upbound.Context) err
 		KubeContext: c.Kube.GetContext(),
 		// Carry over existing config.
 		BaseConfig: upCtx.Profile.BaseConfig,
+		Mirrors:    upCtx.Profile.Mirrors,
 	}
 	upCtx.Profile = profile
 
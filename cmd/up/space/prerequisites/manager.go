@@ -24,6 +24,7 @@ import (
 	"github.com/upbound/up/cmd/up/space/prerequisites/providers/helm"
 	"github.com/upbound/up/cmd/up/space/prerequisites/providers/kubernetes"
 	"github.com/upbound/up/cmd/up/space/prerequisites/uxp"
+	"github.com/upbound/up/internal/mirror"
 )
 
 var (
@@ -51,8 +52,28 @@ type Status struct {
 	NotInstalled []Prerequisite
 }
 
+// Option modifies the Manager.
+type Option func(*options)
+
+type options struct {
+	mirrors mirror.Mirrors
+}
+
+// WithMirrors installs the provider packages of the Prerequisites from the
+// first of the supplied mirrors of their registries, if any.
+func WithMirrors(m mirror.Mirrors) Option {
+	return func(o *options) {
+		o.mirrors = m
+	}
+}
+
 // New constructs a new Manager for working with installation Prerequisites.
-func New(config *rest.Config, defs *defaults.CloudConfig) (*Manager, error) {
+func New(config *rest.Config, defs *defaults.CloudConfig, opts ...Option) (*Manager, error) {
+	o := &options{}
+	for _, fn := range opts {
+		fn(o)
+	}
+
 	prereqs := []Prerequisite{}
 	certmanager, err := certmanager.New(config)
 	if err != nil {
@@ -77,13 +98,13 @@ func New(config *rest.Config, defs *defaults.CloudConfig) (*Manager, error) {
 	}
 	prereqs = append(prereqs, ingress)
 
-	pk8s, err := kubernetes.New(config)
+	pk8s, err := kubernetes.New(config, kubernetes.WithMirrors(o.mirrors))
 	if err != nil {
 		return nil, errors.Wrap(err, errCreatePrerequisite)
 	}
 	prereqs = append(prereqs, pk8s)
 
-	phelm, err := helm.New(config)
+	phelm, err := helm.New(config, helm.WithMirrors(o.mirrors))
 	if err != nil {
 		return nil, errors.Wrap(err, errCreatePrerequisite)
 	}
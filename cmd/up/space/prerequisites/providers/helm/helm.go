@@ -34,6 +34,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
+	"github.com/upbound/up/internal/mirror"
 	"github.com/upbound/up/internal/resources"
 )
 
@@ -66,6 +67,19 @@ type Helm struct {
 	crdclient *apixv1client.ApiextensionsV1Client
 	dClient   dynamic.Interface
 	kclient   kubernetes.Interface
+	mirrors   mirror.Mirrors
+	pkg       string
+}
+
+// Option modifies the Helm prerequisite.
+type Option func(*Helm)
+
+// WithMirrors installs the provider package from the first of the supplied
+// mirrors of its registry, if any.
+func WithMirrors(m mirror.Mirrors) Option {
+	return func(h *Helm) {
+		h.mirrors = m
+	}
 }
 
 func init() {
@@ -79,7 +93,7 @@ func init() {
 
 // New constructs a new CertManager instance that can used to install the
 // cert-manager chart.
-func New(config *rest.Config) (*Helm, error) {
+func New(config *rest.Config, opts ...Option) (*Helm, error) {
 	crdclient, err := apixv1client.NewForConfig(config)
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf(errFmtCreateK8sClient, providerName))
@@ -93,11 +107,20 @@ func New(config *rest.Config) (*Helm, error) {
 		return nil, errors.Wrap(err, fmt.Sprintf(errFmtCreateK8sClient, providerName))
 	}
 
-	return &Helm{
+	h := &Helm{
 		crdclient: crdclient,
 		dClient:   dclient,
 		kclient:   kclient,
-	}, nil
+	}
+	for _, o := range opts {
+		o(h)
+	}
+	ref, err := h.mirrors.Rewrite(pkgRef)
+	if err != nil {
+		return nil, err
+	}
+	h.pkg = ref.String()
+	return h, nil
 }
 
 // GetName returns the name of the provider-helm provider.
@@ -134,7 +157,7 @@ func (h *Helm) Install() error { //nolint:gocyclo
 
 	p := &resources.Package{}
 	p.SetName(pkgName)
-	p.SetPackage(pkgRef.String())
+	p.SetPackage(h.pkg)
 	p.SetGroupVersionKind(xppkgv1.ProviderGroupVersionKind)
 	p.SetControllerConfigRef(xppkgv1.ControllerConfigReference{
 		Name: ccName,
@@ -34,6 +34,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
+	"github.com/upbound/up/internal/mirror"
 	"github.com/upbound/up/internal/resources"
 )
 
@@ -65,6 +66,19 @@ type Kubernetes struct {
 	crdclient *apixv1client.ApiextensionsV1Client
 	dClient   dynamic.Interface
 	kclient   kubernetes.Interface
+	mirrors   mirror.Mirrors
+	pkg       string
+}
+
+// Option modifies the Kubernetes prerequisite.
+type Option func(*Kubernetes)
+
+// WithMirrors installs the provider package from the first of the supplied
+// mirrors of its registry, if any.
+func WithMirrors(m mirror.Mirrors) Option {
+	return func(k *Kubernetes) {
+		k.mirrors = m
+	}
 }
 
 func init() {
@@ -78,7 +92,7 @@ func init() {
 
 // New constructs a new CertManager instance that can used to install the
 // cert-manager chart.
-func New(config *rest.Config) (*Kubernetes, error) {
+func New(config *rest.Config, opts ...Option) (*Kubernetes, error) {
 	crdclient, err := apixv1client.NewForConfig(config)
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf(errFmtCreateK8sClient, providerName))
@@ -92,11 +106,20 @@ func New(config *rest.Config) (*Kubernetes, error) {
 		return nil, errors.Wrap(err, fmt.Sprintf(errFmtCreateK8sClient, providerName))
 	}
 
-	return &Kubernetes{
+	k := &Kubernetes{
 		crdclient: crdclient,
 		dClient:   dclient,
 		kclient:   kclient,
-	}, nil
+	}
+	for _, o := range opts {
+		o(k)
+	}
+	ref, err := k.mirrors.Rewrite(pkgRef)
+	if err != nil {
+		return nil, err
+	}
+	k.pkg = ref.String()
+	return k, nil
 }
 
 // GetName returns the name of the cert-manager chart.
@@ -133,7 +156,7 @@ func (k *Kubernetes) Install() error { //nolint:gocyclo
 
 	p := &resources.Package{}
 	p.SetName(pkgName)
-	p.SetPackage(pkgRef.String())
+	p.SetPackage(k.pkg)
 	p.SetGroupVersionKind(xppkgv1.ProviderGroupVersionKind)
 	p.SetControllerConfigRef(xppkgv1.ControllerConfigReference{
 		Name: ccName,
@@ -79,9 +79,6 @@ func (c *upgradeCmd) AfterApply(quiet config.QuietFlag) error { //nolint:gocyclo
 	if err := c.Kube.AfterApply(); err != nil {
 		return err
 	}
-	if err := c.Registry.AfterApply(); err != nil {
-		return err
-	}
 
 	// NOTE(tnthornton) we currently only have support for stylized output.
 	pterm.EnableStyling()
@@ -91,6 +88,14 @@ func (c *upgradeCmd) AfterApply(quiet config.QuietFlag) error { //nolint:gocyclo
 	if err != nil {
 		return err
 	}
+	// NOTE: mirrors are applied before the registry credentials are read, as
+	// the credentials are those of the mirror.
+	if err := c.Registry.applyMirrors(upCtx.Profile.Mirrors); err != nil {
+		return err
+	}
+	if err := c.Registry.AfterApply(); err != nil {
+		return err
+	}
 
 	kubeconfig, err := c.getKubeconfig(upCtx)
 	if err != nil {
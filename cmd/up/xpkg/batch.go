@@ -21,8 +21,10 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
@@ -52,6 +54,7 @@ const (
 	errGetLayersFmt       = "failed to get layers from %s image for service %q"
 	errGetBaseLayersFmt   = "failed to get base layers from %s image for service %q"
 	errGetDigestFmt       = "failed to get layer's digest from %s image for service %q"
+	errGetImageDigestFmt  = "failed to get the digest of the %s image for service %q"
 	errAppendLayersFmt    = "failed to append layers to %s image for service %q"
 	errReadProviderBinFmt = "failed to read %q provider binary for %s platform from path: %s"
 	errNewLayerFmt        = "failed to initialize a new image layer for %s platform for service %q"
@@ -63,12 +66,21 @@ const (
 	errOutputAbsFmt       = "failed to get the absolute path for the package archive to store: %s/%s/%s"
 	errOpenPackageFmt     = "failed to open package file for writing: %s"
 	errWritePackageFmt    = "failed to store package archive in: %s"
-	errBatch              = "processing of at least one smaller provider has failed"
+	errBatchFmt           = "processing of at least one smaller provider of %s has failed"
 )
 
 const (
 	wildcard  = "*"
 	tagLatest = "latest"
+
+	familyGroup = "Family"
+
+	// batchInputsLabel is the label of the images of a batch package that
+	// holds the digest of the inputs the package was built from.
+	batchInputsLabel = "io.upbound.xpkg.batch.inputs"
+
+	errSpecFlagFmt     = "--%s cannot be used with --spec, set it in the batch spec instead"
+	errMissingFlagsFmt = "missing flags: %s, or a batch spec supplied with --spec"
 )
 
 // AfterApply constructs and binds Upbound-specific context to any subcommands
@@ -85,6 +97,35 @@ func (c *batchCmd) AfterApply(kongCtx *kong.Context) error {
 	}
 	kongCtx.Bind(upCtx)
 
+	if c.Spec != "" {
+		for _, p := range kongCtx.Path {
+			if p.Flag != nil && p.Flag.Group != nil && p.Flag.Group.Key == familyGroup {
+				return errors.Errorf(errSpecFlagFmt, p.Flag.Name)
+			}
+		}
+		spec, err := readBatchSpec(c.fs, c.Spec)
+		if err != nil {
+			return err
+		}
+		c.spec = spec
+		return nil
+	}
+
+	var missing []string
+	for f, v := range map[string]string{
+		"--family-base-image":         c.FamilyBaseImage,
+		"--provider-name":             c.ProviderName,
+		"--family-package-url-format": c.FamilyPackageURLFormat,
+	} {
+		if v == "" {
+			missing = append(missing, f)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return errors.Errorf(errMissingFlagsFmt, strings.Join(missing, ", "))
+	}
+	c.targets = []string{c.FamilyPackageURLFormat}
 	return nil
 }
 
@@ -92,31 +133,44 @@ func (c *batchCmd) AfterApply(kongCtx *kong.Context) error {
 type batchCmd struct {
 	fs    afero.Fs
 	fetch fetchFn
-
-	FamilyBaseImage        string   `help:"Family image used as the base for the smaller provider packages." required:""`
-	ProviderName           string   `help:"Provider name, such as provider-aws to be used while formatting smaller provider package repositories." required:""`
-	FamilyPackageURLFormat string   `help:"Family package URL format to be used for the smaller provider packages. Must be a valid OCI image URL with the format specifier \"%s\", which will be substituted with <provider name>-<service name>." required:""`
-	SmallerProviders       []string `help:"Smaller provider names to build and push, such as ec2, eks or config." default:"monolith"`
+	spec  *batchSpec
+	// targets are the package URL formats to push to.
+	targets []string
+	report  *reporter
+	// resume are the services to process, keyed by their family, if the
+	// failed services of a previous report are resumed.
+	resume map[string]map[string]bool
+
+	Spec            string `type:"existingfile" placeholder:"PATH" help:"Path to a batch spec declaring the provider families, services, platforms and push targets to build. Replaces the flags of the Family group."`
+	Incremental     bool   `help:"Skip services whose inputs are unchanged since their package was last pushed to the target."`
+	IncrementalFrom string `placeholder:"TAG" help:"With --incremental, copy the package of a service with unchanged inputs from this tag of its repository to the target instead of rebuilding it, e.g. the previous release."`
+	Report          string `type:"path" placeholder:"PATH" help:"Write a JSON report of the built and pushed packages, their images, digests, tags, retries, durations and errors to this file."`
+	ResumeFrom      string `type:"existingfile" placeholder:"PATH" help:"Only process the services that failed according to this report, written by a previous run with --report."`
+
+	FamilyBaseImage        string   `group:"Family" help:"Family image used as the base for the smaller provider packages. Required without --spec."`
+	ProviderName           string   `group:"Family" help:"Provider name, such as provider-aws to be used while formatting smaller provider package repositories. Required without --spec."`
+	FamilyPackageURLFormat string   `group:"Family" help:"Family package URL format to be used for the smaller provider packages. Must be a valid OCI image URL with the format specifier \"%s\", which will be substituted with <provider name>-<service name>. Required without --spec."`
+	SmallerProviders       []string `group:"Family" help:"Smaller provider names to build and push, such as ec2, eks or config." default:"monolith"`
 	Concurrency            uint     `help:"Maximum number of packages to process concurrently. Setting it to 0 puts no limit on the concurrency, i.e., all packages are processed in parallel." default:"0"`
 	PushRetry              uint     `help:"Number of retries when pushing a provider package fails." default:"3"`
 
-	Platform        []string `help:"Platforms to build the packages for. Each platform should use the <OS>_<arch> syntax. An example is: linux_arm64." default:"linux_amd64,linux_arm64"`
-	ProviderBinRoot string   `short:"p" help:"Provider binary paths root. Smaller provider binaries should reside under the platform directories in this folder." type:"existingdir"`
+	Platform        []string `group:"Family" help:"Platforms to build the packages for. Each platform should use the <OS>_<arch> syntax. An example is: linux_arm64." default:"linux_amd64,linux_arm64"`
+	ProviderBinRoot string   `group:"Family" short:"p" help:"Provider binary paths root. Smaller provider binaries should reside under the platform directories in this folder." type:"existingdir"`
 	OutputDir       string   `short:"o" help:"Path of the package output directory." optional:""`
-	StorePackages   []string `help:"Smaller provider names whose provider package should be stored under the package output directory specified with the --output-dir option." optional:""`
+	StorePackages   []string `group:"Family" help:"Smaller provider names whose provider package should be stored under the package output directory specified with the --output-dir option." optional:""`
 
-	PackageMetadataTemplate string            `help:"Smaller provider metadata template. The template variables {{ .Service }} and {{ .Name }} will be substituted when the template is executed among with the supplied template variable substitutions." default:"./package/crossplane.yaml.tmpl" type:"path"`
-	TemplateVar             map[string]string `help:"Smaller provider metadata template variables to be used for the specified template."`
+	PackageMetadataTemplate string            `group:"Family" help:"Smaller provider metadata template. The template variables {{ .Service }} and {{ .Name }} will be substituted when the template is executed among with the supplied template variable substitutions." default:"./package/crossplane.yaml.tmpl" type:"path"`
+	TemplateVar             map[string]string `group:"Family" help:"Smaller provider metadata template variables to be used for the specified template."`
 
-	ExamplesGroupOverride map[string]string `help:"Overrides for the location of the example manifests folder of a smaller provider." optional:""`
-	CRDGroupOverride      map[string]string `help:"Overrides for the locations of the CRD folders of the smaller providers." optional:""`
-	PackageRepoOverride   map[string]string `help:"Overrides for the package repository names of the smaller providers." optional:""`
-	ProvidersWithAuthExt  []string          `help:"Smaller provider names for which we need to configure the authentication extension." default:"monolith,config"`
+	ExamplesGroupOverride map[string]string `group:"Family" help:"Overrides for the location of the example manifests folder of a smaller provider." optional:""`
+	CRDGroupOverride      map[string]string `group:"Family" help:"Overrides for the locations of the CRD folders of the smaller providers." optional:""`
+	PackageRepoOverride   map[string]string `group:"Family" help:"Overrides for the package repository names of the smaller providers." optional:""`
+	ProvidersWithAuthExt  []string          `group:"Family" help:"Smaller provider names for which we need to configure the authentication extension." default:"monolith,config"`
 
-	ExamplesRoot string   `short:"e" help:"Path to package examples directory." default:"./examples" type:"path"`
-	CRDRoot      string   `help:"Path to package CRDs directory." default:"./package/crds" type:"path"`
-	AuthExt      string   `help:"Path to an authentication extension file." default:"./package/auth.yaml" type:"path"`
-	Ignore       []string `help:"Paths to exclude from the smaller provider packages."`
+	ExamplesRoot string   `group:"Family" short:"e" help:"Path to package examples directory." default:"./examples" type:"path"`
+	CRDRoot      string   `group:"Family" help:"Path to package CRDs directory." default:"./package/crds" type:"path"`
+	AuthExt      string   `group:"Family" help:"Path to an authentication extension file." default:"./package/auth.yaml" type:"path"`
+	Ignore       []string `group:"Family" help:"Paths to exclude from the smaller provider packages."`
 	Create       bool     `help:"Create repository on push if it does not exist."`
 	BuildOnly    bool     `help:"Only build the smaller provider packages and do not attempt to push them to a package repository." default:"false"`
 
@@ -124,23 +178,112 @@ type batchCmd struct {
 	Flags upbound.Flags `embed:""`
 }
 
+func (c *batchCmd) Help() string {
+	return `
+The batch command builds the service-scoped provider packages of a provider
+family on top of the family base image, and pushes them. The family is either
+configured with the flags of the Family group, or declared in a batch spec
+supplied with --spec, which may list several families and push targets:
+
+  apiVersion: xpkg.upbound.io/v1alpha1
+  kind: BatchSpec
+  platforms: [linux_amd64, linux_arm64]
+  families:
+  - name: provider-aws
+    baseImage: build/provider-aws
+    targets:
+    - xpkg.upbound.io/upbound/%s:v1.2.0
+    - registry.example.com/upbound/%s:v1.2.0
+    providerBinRoot: _output/bin
+    services:
+    - name: config
+      crdGroup: aws
+      authExt: true
+    - name: ec2
+      store: true
+
+Relative paths are relative to the directory of the spec, and unset paths keep
+the defaults of their flags.
+
+Every package is labeled with a digest of its inputs: its metadata, CRDs,
+examples and authentication extension, the base images and the provider
+binaries. With --incremental, services whose package was published to a
+target from the same inputs are not pushed to it again, and services with no
+stale target are not built at all. --incremental-from copies unchanged
+packages from another tag of their repository, e.g. the previous release,
+instead of rebuilding them.
+
+  up alpha xpkg batch --spec batch.yaml --incremental --incremental-from v1.1.0
+
+With --report, the result of every service is written to a JSON file: the
+digests of its package and platform images, the tags it was pushed to, the
+number of retried pushes, its duration and its error, if any. A failed run
+can be resumed with --resume-from, which only processes the services that
+failed according to the report. The report of the resumed run includes the
+services that did not fail before.
+
+  up alpha xpkg batch --spec batch.yaml --report report.json
+  up alpha xpkg batch --spec batch.yaml --resume-from report.json --report report.json`
+}
+
 // Run executes the batch command.
-func (c *batchCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context) error { //nolint:gocyclo
-	baseImgMap := make(map[string]v1.Image, len(c.Platform))
-	for _, p := range c.Platform {
-		tokens := strings.Split(p, "_")
-		if len(tokens) != 2 {
-			return errors.Errorf(errInvalidPlatformFmt, p)
-		}
-		ref, err := name.ParseReference(fmt.Sprintf("%s-%s", c.FamilyBaseImage, tokens[1]))
+func (c *batchCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context) error {
+	c.report = &reporter{}
+	if c.ResumeFrom != "" {
+		r, err := readReport(c.fs, c.ResumeFrom)
 		if err != nil {
 			return err
 		}
-		img, err := c.fetch(ctx, ref)
-		if err != nil {
-			return err
+		c.resume = r.failed()
+		for _, pr := range r.Packages {
+			if pr.Status != statusFailed {
+				c.report.add(pr)
+			}
 		}
-		baseImgMap[p] = img // assumes correct OS
+	}
+	err := c.run(ctx, p, upCtx)
+	if c.Report == "" {
+		return err
+	}
+	return c.report.flush(p, c.fs, c.Report, err)
+}
+
+func (c *batchCmd) run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context) error {
+	if c.spec == nil {
+		return c.runFamily(ctx, p, upCtx)
+	}
+	var result error
+	for _, f := range c.spec.Families {
+		p.Printfln("Processing provider family %q", f.Name)
+		err := c.forFamily(c.spec, f).runFamily(ctx, p, upCtx)
+		switch {
+		case result == nil:
+			result = err
+		case err != nil:
+			result = errors.Wrap(result, err.Error())
+		}
+	}
+	return result
+}
+
+// runFamily builds and pushes the smaller provider packages of the configured
+// provider family.
+func (c *batchCmd) runFamily(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context) error { //nolint:gocyclo
+	if c.resume != nil {
+		c.SmallerProviders = c.resumedServices()
+		if len(c.SmallerProviders) == 0 {
+			p.Printfln("No failed services of %s to resume", c.ProviderName)
+			return nil
+		}
+	}
+	baseImgMap, err := c.getBaseImages(ctx)
+	if err != nil {
+		// NOTE: the services of the family are reported as failed, so that
+		// they are processed when the run is resumed.
+		for _, s := range c.SmallerProviders {
+			c.report.add(packageReport{Family: c.ProviderName, Service: s, Status: statusFailed, Error: err.Error()})
+		}
+		return err
 	}
 
 	chErr := make(chan error, len(c.SmallerProviders))
@@ -160,7 +303,11 @@ func (c *batchCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.
 					concurrency <- struct{}{}
 				}()
 			}
-			err := c.processService(p, upCtx, baseImgMap, s)
+			start := time.Now()
+			pr := packageReport{Family: c.ProviderName, Service: s}
+			err := c.processService(ctx, p, upCtx, baseImgMap, s, &pr)
+			pr.finish(start, err)
+			c.report.add(pr)
 			p.PrintOnErrorf(fmt.Sprintf("Publishing of smaller provider package has failed for service %q: %%v", s), err)
 			chErr <- errors.WithMessagef(err, errProcessFmt, s)
 		}()
@@ -175,7 +322,40 @@ func (c *batchCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.
 			result = errors.Wrap(result, err.Error())
 		}
 	}
-	return errors.WithMessage(result, errBatch)
+	return errors.WithMessagef(result, errBatchFmt, c.ProviderName)
+}
+
+// getBaseImages fetches the family base image of each platform, keyed by the
+// platform.
+func (c *batchCmd) getBaseImages(ctx context.Context) (map[string]v1.Image, error) {
+	baseImgMap := make(map[string]v1.Image, len(c.Platform))
+	for _, p := range c.Platform {
+		tokens := strings.Split(p, "_")
+		if len(tokens) != 2 {
+			return nil, errors.Errorf(errInvalidPlatformFmt, p)
+		}
+		ref, err := name.ParseReference(fmt.Sprintf("%s-%s", c.FamilyBaseImage, tokens[1]))
+		if err != nil {
+			return nil, err
+		}
+		img, err := c.fetch(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		baseImgMap[p] = img // assumes correct OS
+	}
+	return baseImgMap, nil
+}
+
+// resumedServices returns the services of the family that are resumed.
+func (c *batchCmd) resumedServices() []string {
+	var services []string
+	for _, s := range c.SmallerProviders {
+		if c.resume[c.ProviderName][s] {
+			services = append(services, s)
+		}
+	}
+	return services
 }
 
 // processService builds and pushes the smaller provider package
@@ -187,8 +367,25 @@ func (c *batchCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.
 // and thus is computed only once. `processService` also adds
 // the smaller provider controller binary (which is platform specific) on top
 // of the addendum layers and then pushes the built multi-arch package
-// (if `len(c.Platforms) > 1`) to the specified package repository.
-func (c *batchCmd) processService(p pterm.TextPrinter, upCtx *upbound.Context, baseImgMap map[string]v1.Image, s string) error { //nolint:gocyclo
+// (if `len(c.Platforms) > 1`) to the specified package repositories.
+// The packages are labeled with the digest of their inputs, and in incremental
+// mode the targets whose package was built from the same inputs are skipped.
+// The result is recorded in the supplied report.
+func (c *batchCmd) processService(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context, baseImgMap map[string]v1.Image, s string, pr *packageReport) error { //nolint:gocyclo
+	inputs, err := c.inputsDigest(baseImgMap, s)
+	if err != nil {
+		return err
+	}
+	targets := c.getPackageURLs(s)
+	if c.Incremental {
+		targets, pr.UpToDate = c.staleTargets(ctx, p, upCtx, targets, inputs, s)
+		if len(targets) == 0 && !contains(c.StorePackages, s) {
+			p.Printfln("Skipping service %q, its inputs are unchanged", s)
+			pr.Status = statusSkipped
+			return nil
+		}
+	}
+
 	imgs := make([]v1.Image, 0, len(c.Platform))
 	// image layers added on top of the base image by xpkg push to be reused
 	// across the platforms so that they are computed only once.
@@ -246,29 +443,40 @@ func (c *batchCmd) processService(p pterm.TextPrinter, upCtx *upbound.Context, b
 				return err
 			}
 		}
+		img, err = labelInputs(img, inputs)
+		if err != nil {
+			return errors.Wrapf(err, errMutateConfigFmt, p, s)
+		}
 		imgs = append(imgs, img)
 	}
 	if err := c.storePackage(p, s, imgs); err != nil {
 		return err
 	}
-	if c.BuildOnly {
+	pr.Status = statusBuilt
+	if c.BuildOnly || len(targets) == 0 {
+		pr.Images = make([]imageReport, len(imgs))
+		for i, img := range imgs {
+			d, err := img.Digest()
+			if err != nil {
+				return errors.Wrapf(err, errGetImageDigestFmt, c.Platform[i], s)
+			}
+			pr.Images[i] = imageReport{Platform: c.Platform[i], Digest: d.String()}
+		}
 		return nil
 	}
 	// now try to push the package with the specified retry configuration.
-	return c.pushWithRetry(p, upCtx, imgs, s)
+	for _, t := range targets {
+		if err := c.pushWithRetry(p, upCtx, imgs, s, t, pr); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Optionally stores the provider package under the configured directory,
 // if the service name exists in the c.StorePackage slice.
 func (c *batchCmd) storePackage(tp pterm.TextPrinter, s string, imgs []v1.Image) error {
-	found := false
-	for _, pkg := range c.StorePackages {
-		if pkg == s {
-			found = true
-			break
-		}
-	}
-	if !found {
+	if !contains(c.StorePackages, s) {
 		return nil
 	}
 	for i, p := range c.Platform {
@@ -305,14 +513,18 @@ func (c *batchCmd) getPackageVersion() string {
 	return tokens[len(tokens)-1]
 }
 
-func (c *batchCmd) pushWithRetry(p pterm.TextPrinter, upCtx *upbound.Context, imgs []v1.Image, s string) error {
-	t := c.getPackageURL(s)
+func (c *batchCmd) pushWithRetry(p pterm.TextPrinter, upCtx *upbound.Context, imgs []v1.Image, s, t string, pr *packageReport) error {
 	tries := c.PushRetry + 1
 	retryMsg := ""
 	for i := uint(0); i < tries; i++ {
+		if i > 0 {
+			pr.Retries++
+		}
 		p.Printfln("Pushing xpkg to %s.%s", t, retryMsg)
-		err := PushImages(p, upCtx, imgs, t, c.Create, c.Flags.Profile)
+		d, descs, err := pushImages(p, upCtx, imgs, t, c.Create, c.Flags.Profile)
 		if err == nil {
+			pr.pushed(t, d)
+			pr.setImages(descs)
 			break
 		}
 		if i == tries-1 { // no more retries
@@ -333,8 +545,12 @@ func (c *batchCmd) getPackageRepo(s string) string {
 	return repo
 }
 
-func (c *batchCmd) getPackageURL(s string) string {
-	return fmt.Sprintf(c.FamilyPackageURLFormat, c.getPackageRepo(s))
+func (c *batchCmd) getPackageURLs(s string) []string {
+	urls := make([]string, len(c.targets))
+	for i, t := range c.targets {
+		urls[i] = fmt.Sprintf(t, c.getPackageRepo(s))
+	}
+	return urls
 }
 
 // getAddendumLayers returns the diff layers between the specified
@@ -442,15 +658,11 @@ func (c *batchCmd) getBuilder(service string) (*xpkg.Builder, error) {
 	if err != nil {
 		return nil, errors.Wrapf(err, errAbsAuthExtFmt, c.AuthExt)
 	}
-	for _, s := range c.ProvidersWithAuthExt {
-		if service != s {
-			continue
-		}
+	if contains(c.ProvidersWithAuthExt, service) {
 		authBE, err = c.getAuthBackend(ax)
 		if err != nil {
 			return nil, err
 		}
-		break
 	}
 
 	pp, err := yaml.New()
@@ -471,13 +683,7 @@ func (c *batchCmd) getBuilder(service string) (*xpkg.Builder, error) {
 			fs:              c.fs,
 			options: []parser.BackendOption{
 				parser.FsDir(c.CRDRoot),
-				parser.FsFilters(
-					append(
-						buildFilters(c.CRDRoot, c.Ignore),
-						xpkg.SkipContains(c.ExamplesRoot), xpkg.SkipContains(c.AuthExt),
-						func(_ string, info os.FileInfo) (bool, error) {
-							return !strings.HasPrefix(info.Name(), c.getCRDPrefix(service)), nil
-						})...),
+				parser.FsFilters(c.getCRDFilters(service)...),
 			},
 		},
 		authBE,
@@ -492,6 +698,15 @@ func (c *batchCmd) getBuilder(service string) (*xpkg.Builder, error) {
 	), nil
 }
 
+func (c *batchCmd) getCRDFilters(service string) []parser.FilterFn {
+	return append(
+		buildFilters(c.CRDRoot, c.Ignore),
+		xpkg.SkipContains(c.ExamplesRoot), xpkg.SkipContains(c.AuthExt),
+		func(_ string, info os.FileInfo) (bool, error) {
+			return !strings.HasPrefix(info.Name(), c.getCRDPrefix(service)), nil
+		})
+}
+
 func (c *batchCmd) getCRDPrefix(service string) string {
 	o := c.CRDGroupOverride[service]
 	if o == wildcard {
@@ -0,0 +1,217 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"path/filepath"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/parser"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/upbound"
+	"github.com/upbound/up/internal/version"
+)
+
+const (
+	errHashInputsFmt     = "failed to compute the digest of the inputs of service %q"
+	errGetBaseImageIDFmt = "failed to get the ID of the %s base image for service %q"
+	errNoManifests       = "package index has no manifests"
+)
+
+// inputsDigest returns the digest of the inputs the package of the supplied
+// service is built from: its metadata, CRDs, examples and authentication
+// extension, and the base images and provider binaries of all platforms.
+func (c *batchCmd) inputsDigest(baseImgMap map[string]v1.Image, s string) (string, error) {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "up %s\n", version.GetVersion())
+
+	meta, err := c.getPackageMetadata(s)
+	if err != nil {
+		return "", err
+	}
+	_, _ = io.WriteString(h, meta)
+
+	if err := hashDir(h, c.fs, c.CRDRoot, c.getCRDFilters(s)); err != nil {
+		return "", errors.Wrapf(err, errHashInputsFmt, s)
+	}
+	ex, err := filepath.Abs(c.getExamplesGroup(s))
+	if err != nil {
+		return "", err
+	}
+	// NOTE: packages are built without examples if there are none for
+	// the service.
+	if ok, _ := afero.DirExists(c.fs, ex); ok {
+		if err := hashDir(h, c.fs, ex, buildFilters(ex, c.Ignore)); err != nil {
+			return "", errors.Wrapf(err, errHashInputsFmt, s)
+		}
+	}
+	if contains(c.ProvidersWithAuthExt, s) {
+		// NOTE: a missing authentication extension is skipped by the
+		// builder as well.
+		if b, err := afero.ReadFile(c.fs, c.AuthExt); err == nil {
+			_, _ = h.Write(b)
+		}
+	}
+
+	for _, p := range c.Platform {
+		id, err := baseImgMap[p].ConfigName()
+		if err != nil {
+			return "", errors.Wrapf(err, errGetBaseImageIDFmt, p, s)
+		}
+		_, _ = fmt.Fprintf(h, "%s %s\n", p, id)
+		binPath := filepath.Join(c.ProviderBinRoot, p, s)
+		f, err := c.fs.Open(filepath.Clean(binPath))
+		if err != nil {
+			return "", errors.Wrapf(err, errReadProviderBinFmt, s, p, binPath)
+		}
+		_, err = io.Copy(h, f)
+		_ = f.Close()
+		if err != nil {
+			return "", errors.Wrapf(err, errReadProviderBinFmt, s, p, binPath)
+		}
+	}
+	return fmt.Sprintf("sha256:%x", h.Sum(nil)), nil
+}
+
+// hashDir writes the files below dir that are not filtered out to h, in the
+// same order in which the package builder reads them.
+func hashDir(h hash.Hash, fs afero.Fs, dir string, filters []parser.FilterFn) error {
+	rc, err := parser.NewFsReadCloser(fs, dir, filters...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rc.Close() }()
+	_, err = io.Copy(h, rc)
+	return err
+}
+
+// labelInputs labels the supplied image with the supplied inputs digest.
+func labelInputs(img v1.Image, inputs string) (v1.Image, error) {
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Config.Labels == nil {
+		cfg.Config.Labels = map[string]string{}
+	}
+	cfg.Config.Labels[batchInputsLabel] = inputs
+	return mutate.Config(img, cfg.Config)
+}
+
+// staleTargets returns the targets whose published package was not built from
+// the supplied inputs, and the targets that are up to date. If a package built
+// from the same inputs is published with the incremental source tag, it is
+// copied to the targets instead. Targets whose package cannot be inspected are
+// considered stale.
+func (c *batchCmd) staleTargets(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context, targets []string, inputs, s string) (stale, upToDate []string) {
+	for _, t := range targets {
+		published, err := c.publishedInputs(ctx, upCtx, t)
+		var terr *transport.Error
+		if err != nil && !(errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound) {
+			p.Printfln("Cannot get the inputs of %s, rebuilding service %q: %v", t, s, err)
+		}
+		if published == inputs {
+			p.Printfln("Package %s is up to date", t)
+			upToDate = append(upToDate, t)
+			continue
+		}
+		if c.IncrementalFrom != "" && !c.BuildOnly && c.copyUnchanged(ctx, p, upCtx, t, inputs) {
+			upToDate = append(upToDate, t)
+			continue
+		}
+		stale = append(stale, t)
+	}
+	return stale, upToDate
+}
+
+// copyUnchanged copies the package published with the incremental source tag
+// of the repository of the supplied target to the target if it was built from
+// the supplied inputs. It reports whether the package was copied.
+func (c *batchCmd) copyUnchanged(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context, t, inputs string) bool {
+	tag, err := name.NewTag(t, name.WithDefaultRegistry(upCtx.RegistryEndpoint.Hostname()))
+	if err != nil {
+		return false
+	}
+	src := tag.Context().Tag(c.IncrementalFrom).String()
+	if published, err := c.publishedInputs(ctx, upCtx, src); err != nil || published != inputs {
+		return false
+	}
+	if err := CopyPackage(p, upCtx, src, t, c.Create, c.Flags.Profile); err != nil {
+		p.Printfln("Failed to copy xpkg from %s to %s, rebuilding: %v", src, t, err)
+		return false
+	}
+	return true
+}
+
+// publishedInputs returns the inputs digest of the package published with the
+// supplied tag. It returns an empty digest if the package has no inputs label.
+func (c *batchCmd) publishedInputs(ctx context.Context, upCtx *upbound.Context, t string) (string, error) {
+	tag, err := name.NewTag(t, name.WithDefaultRegistry(upCtx.RegistryEndpoint.Hostname()))
+	if err != nil {
+		return "", err
+	}
+	desc, err := remote.Get(tag, remote.WithAuthFromKeychain(keychain(upCtx, c.Flags.Profile)), remote.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	var img v1.Image
+	if desc.MediaType.IsIndex() {
+		ii, err := desc.ImageIndex()
+		if err != nil {
+			return "", err
+		}
+		im, err := ii.IndexManifest()
+		if err != nil {
+			return "", err
+		}
+		// NOTE: all images of a package are labeled with the same
+		// inputs digest.
+		if len(im.Manifests) == 0 {
+			return "", errors.New(errNoManifests)
+		}
+		if img, err = ii.Image(im.Manifests[0].Digest); err != nil {
+			return "", err
+		}
+	} else if img, err = desc.Image(); err != nil {
+		return "", err
+	}
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return "", err
+	}
+	return cfg.Config.Labels[batchInputsLabel], nil
+}
+
+func contains(l []string, s string) bool {
+	for _, e := range l {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
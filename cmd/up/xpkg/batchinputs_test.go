@@ -0,0 +1,106 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/spf13/afero"
+)
+
+func TestInputsDigest(t *testing.T) {
+	files := map[string]string{
+		"crossplane.yaml.tmpl":         "name: {{ .Name }}",
+		"crds/ec2.aws.io_vpcs.yaml":    "kind: CustomResourceDefinition",
+		"crds/rds.aws.io_dbs.yaml":     "kind: CustomResourceDefinition",
+		"examples/ec2/vpc.yaml":        "kind: VPC",
+		"bin/linux_amd64/ec2":          "binary",
+		"bin/linux_amd64/rds":          "binary",
+		"examples/rds/dbinstance.yaml": "kind: DBInstance",
+	}
+	cases := map[string]struct {
+		reason  string
+		changes map[string]string
+		want    bool
+	}{
+		"Unchanged": {
+			reason: "The digest should not change if the inputs of the service do not.",
+			want:   true,
+		},
+		"OtherService": {
+			reason:  "The digest should not change if only the inputs of another service do.",
+			changes: map[string]string{"crds/rds.aws.io_dbs.yaml": "kind: Other", "bin/linux_amd64/rds": "other"},
+			want:    true,
+		},
+		"CRD": {
+			reason:  "The digest should change if a CRD of the service does.",
+			changes: map[string]string{"crds/ec2.aws.io_vpcs.yaml": "kind: Other"},
+		},
+		"Example": {
+			reason:  "The digest should change if an example of the service does.",
+			changes: map[string]string{"examples/ec2/vpc.yaml": "kind: Other"},
+		},
+		"Binary": {
+			reason:  "The digest should change if the provider binary of the service does.",
+			changes: map[string]string{"bin/linux_amd64/ec2": "other"},
+		},
+		"Metadata": {
+			reason:  "The digest should change if the package metadata does.",
+			changes: map[string]string{"crossplane.yaml.tmpl": "name: other-{{ .Name }}"},
+		},
+	}
+
+	for n, tc := range cases {
+		t.Run(n, func(t *testing.T) {
+			dir := t.TempDir()
+			fs := afero.NewOsFs()
+			digest := func(files map[string]string) string {
+				for f, content := range files {
+					p := filepath.Join(dir, f)
+					if err := fs.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+						t.Fatal(err)
+					}
+					if err := afero.WriteFile(fs, p, []byte(content), 0o600); err != nil {
+						t.Fatal(err)
+					}
+				}
+				c := &batchCmd{
+					fs:                      fs,
+					ProviderName:            "provider-aws",
+					Platform:                []string{"linux_amd64"},
+					PackageMetadataTemplate: filepath.Join(dir, "crossplane.yaml.tmpl"),
+					CRDRoot:                 filepath.Join(dir, "crds"),
+					ExamplesRoot:            filepath.Join(dir, "examples"),
+					ProviderBinRoot:         filepath.Join(dir, "bin"),
+					AuthExt:                 filepath.Join(dir, "auth.yaml"),
+				}
+				d, err := c.inputsDigest(map[string]v1.Image{"linux_amd64": empty.Image}, "ec2")
+				if err != nil {
+					t.Fatal(err)
+				}
+				return d
+			}
+			before := digest(files)
+			after := digest(tc.changes)
+			if diff := cmp.Diff(tc.want, before == after); diff != "" {
+				t.Errorf("\n%s\ninputsDigest(...): -want unchanged, +got unchanged:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	batchSpecAPIVersion = "xpkg.upbound.io/v1alpha1"
+	batchSpecKind       = "BatchSpec"
+
+	errReadBatchSpecFmt    = "failed to read batch spec %s"
+	errInvalidBatchSpecFmt = "invalid batch spec %s"
+)
+
+// batchSpec is a declarative configuration of up xpkg batch. It lists the
+// provider families to build, their services and where to push them.
+type batchSpec struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+
+	// Platforms to build the packages of all families for, unless a family
+	// overrides them.
+	Platforms []string `json:"platforms,omitempty"`
+
+	Families []batchFamily `json:"families"`
+}
+
+// batchFamily is a provider family. Relative paths are relative to the
+// directory of the spec.
+type batchFamily struct {
+	// Name of the family provider, such as provider-aws.
+	Name string `json:"name"`
+
+	// BaseImage is the family image used as the base for the service
+	// packages. It is suffixed with the architecture of each platform.
+	BaseImage string `json:"baseImage"`
+
+	// Targets are the package URL formats to push the service packages to.
+	// Each must contain a single %s, which is substituted with the package
+	// repository of a service.
+	Targets []string `json:"targets"`
+
+	Platforms               []string          `json:"platforms,omitempty"`
+	ProviderBinRoot         string            `json:"providerBinRoot,omitempty"`
+	PackageMetadataTemplate string            `json:"packageMetadataTemplate,omitempty"`
+	TemplateVars            map[string]string `json:"templateVars,omitempty"`
+	ExamplesRoot            string            `json:"examplesRoot,omitempty"`
+	CRDRoot                 string            `json:"crdRoot,omitempty"`
+	AuthExt                 string            `json:"authExt,omitempty"`
+	Ignore                  []string          `json:"ignore,omitempty"`
+
+	Services []batchService `json:"services"`
+}
+
+// batchService is a service of a provider family.
+type batchService struct {
+	// Name of the service, such as ec2.
+	Name string `json:"name"`
+
+	// ExamplesGroup overrides the examples folder of the service below the
+	// examples root. * refers to the examples root itself.
+	ExamplesGroup string `json:"examplesGroup,omitempty"`
+
+	// CRDGroup overrides the file name prefix of the CRDs of the service. *
+	// includes all CRDs.
+	CRDGroup string `json:"crdGroup,omitempty"`
+
+	// PackageRepo overrides the package repository of the service, which
+	// defaults to <family name>-<service name>.
+	PackageRepo string `json:"packageRepo,omitempty"`
+
+	// AuthExt includes the authentication extension in the package.
+	AuthExt bool `json:"authExt,omitempty"`
+
+	// Store writes the package to the output directory.
+	Store bool `json:"store,omitempty"`
+}
+
+// readBatchSpec reads and validates the batch spec at the supplied path.
+func readBatchSpec(fs afero.Fs, path string) (*batchSpec, error) {
+	b, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, errors.Wrapf(err, errReadBatchSpecFmt, path)
+	}
+	s := &batchSpec{}
+	if err := yaml.UnmarshalStrict(b, s); err != nil {
+		return nil, errors.Wrapf(err, errReadBatchSpecFmt, path)
+	}
+	if errs := s.validate(); len(errs) > 0 {
+		return nil, errors.Wrapf(errs.ToAggregate(), errInvalidBatchSpecFmt, path)
+	}
+	s.resolvePaths(filepath.Dir(path))
+	return s, nil
+}
+
+func (s *batchSpec) validate() field.ErrorList { //nolint:gocyclo
+	var errs field.ErrorList
+	if s.APIVersion != batchSpecAPIVersion {
+		errs = append(errs, field.NotSupported(field.NewPath("apiVersion"), s.APIVersion, []string{batchSpecAPIVersion}))
+	}
+	if s.Kind != batchSpecKind {
+		errs = append(errs, field.NotSupported(field.NewPath("kind"), s.Kind, []string{batchSpecKind}))
+	}
+	errs = append(errs, validatePlatforms(field.NewPath("platforms"), s.Platforms)...)
+	if len(s.Families) == 0 {
+		errs = append(errs, field.Required(field.NewPath("families"), "at least one family must be specified"))
+	}
+
+	families := map[string]bool{}
+	for i, f := range s.Families {
+		fp := field.NewPath("families").Index(i)
+		switch {
+		case f.Name == "":
+			errs = append(errs, field.Required(fp.Child("name"), "must be specified"))
+		case families[f.Name]:
+			errs = append(errs, field.Duplicate(fp.Child("name"), f.Name))
+		}
+		families[f.Name] = true
+		if f.BaseImage == "" {
+			errs = append(errs, field.Required(fp.Child("baseImage"), "must be specified"))
+		}
+		if len(f.Platforms) == 0 && len(s.Platforms) == 0 {
+			errs = append(errs, field.Required(fp.Child("platforms"), "must be specified for the family or the spec"))
+		}
+		errs = append(errs, validatePlatforms(fp.Child("platforms"), f.Platforms)...)
+		if len(f.Targets) == 0 {
+			errs = append(errs, field.Required(fp.Child("targets"), "at least one target must be specified"))
+		}
+		for j, t := range f.Targets {
+			if strings.Count(t, "%s") != 1 {
+				errs = append(errs, field.Invalid(fp.Child("targets").Index(j), t, "must contain a single %s, substituted with the package repository of a service"))
+				continue
+			}
+			if _, err := name.NewTag(fmt.Sprintf(t, f.Name)); err != nil {
+				errs = append(errs, field.Invalid(fp.Child("targets").Index(j), t, err.Error()))
+			}
+		}
+		if len(f.Services) == 0 {
+			errs = append(errs, field.Required(fp.Child("services"), "at least one service must be specified"))
+		}
+		services := map[string]bool{}
+		for j, svc := range f.Services {
+			sp := fp.Child("services").Index(j)
+			switch {
+			case svc.Name == "":
+				errs = append(errs, field.Required(sp.Child("name"), "must be specified"))
+			case services[svc.Name]:
+				errs = append(errs, field.Duplicate(sp.Child("name"), svc.Name))
+			}
+			services[svc.Name] = true
+		}
+	}
+	return errs
+}
+
+func validatePlatforms(p *field.Path, platforms []string) field.ErrorList {
+	var errs field.ErrorList
+	for i, pl := range platforms {
+		if len(strings.Split(pl, "_")) != 2 {
+			errs = append(errs, field.Invalid(p.Index(i), pl, "must use the <OS>_<arch> syntax, such as linux_arm64"))
+		}
+	}
+	return errs
+}
+
+// resolvePaths makes the relative paths of the families relative to the
+// supplied directory.
+func (s *batchSpec) resolvePaths(dir string) {
+	for i := range s.Families {
+		f := &s.Families[i]
+		for _, p := range []*string{&f.ProviderBinRoot, &f.PackageMetadataTemplate, &f.ExamplesRoot, &f.CRDRoot, &f.AuthExt} {
+			if *p != "" && !filepath.IsAbs(*p) {
+				*p = filepath.Join(dir, *p)
+			}
+		}
+	}
+}
+
+// forFamily returns a copy of the batch command configured to build the
+// supplied family of the spec. Settings the family does not specify keep the
+// defaults of their flags.
+func (c *batchCmd) forFamily(s *batchSpec, f batchFamily) *batchCmd {
+	fc := *c
+	fc.ProviderName = f.Name
+	fc.FamilyBaseImage = f.BaseImage
+	fc.FamilyPackageURLFormat = f.Targets[0]
+	fc.targets = f.Targets
+	fc.Platform = s.Platforms
+	if len(f.Platforms) > 0 {
+		fc.Platform = f.Platforms
+	}
+	for _, v := range []struct {
+		dst *string
+		src string
+	}{
+		{&fc.ProviderBinRoot, f.ProviderBinRoot},
+		{&fc.PackageMetadataTemplate, f.PackageMetadataTemplate},
+		{&fc.ExamplesRoot, f.ExamplesRoot},
+		{&fc.CRDRoot, f.CRDRoot},
+		{&fc.AuthExt, f.AuthExt},
+	} {
+		if v.src != "" {
+			*v.dst = v.src
+		}
+	}
+	fc.TemplateVar = f.TemplateVars
+	fc.Ignore = f.Ignore
+
+	fc.SmallerProviders = make([]string, len(f.Services))
+	fc.ExamplesGroupOverride = map[string]string{}
+	fc.CRDGroupOverride = map[string]string{}
+	fc.PackageRepoOverride = map[string]string{}
+	fc.ProvidersWithAuthExt = nil
+	fc.StorePackages = nil
+	for i, svc := range f.Services {
+		fc.SmallerProviders[i] = svc.Name
+		if svc.ExamplesGroup != "" {
+			fc.ExamplesGroupOverride[svc.Name] = svc.ExamplesGroup
+		}
+		if svc.CRDGroup != "" {
+			fc.CRDGroupOverride[svc.Name] = svc.CRDGroup
+		}
+		if svc.PackageRepo != "" {
+			fc.PackageRepoOverride[svc.Name] = svc.PackageRepo
+		}
+		if svc.AuthExt {
+			fc.ProvidersWithAuthExt = append(fc.ProvidersWithAuthExt, svc.Name)
+		}
+		if svc.Store {
+			fc.StorePackages = append(fc.StorePackages, svc.Name)
+		}
+	}
+	return &fc
+}
@@ -0,0 +1,181 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestReadBatchSpec(t *testing.T) {
+	type want struct {
+		spec *batchSpec
+		err  error
+	}
+	cases := map[string]struct {
+		reason string
+		spec   string
+		want   want
+	}{
+		"Valid": {
+			reason: "A valid spec should be read with its paths made relative to its directory.",
+			spec: `
+apiVersion: xpkg.upbound.io/v1alpha1
+kind: BatchSpec
+platforms: [linux_amd64]
+families:
+- name: provider-aws
+  baseImage: build/provider-aws
+  targets: [xpkg.upbound.io/upbound/%s:v1.0.0]
+  providerBinRoot: _output/bin
+  authExt: /abs/auth.yaml
+  services:
+  - name: ec2
+`,
+			want: want{
+				spec: &batchSpec{
+					APIVersion: batchSpecAPIVersion,
+					Kind:       batchSpecKind,
+					Platforms:  []string{"linux_amd64"},
+					Families: []batchFamily{{
+						Name:            "provider-aws",
+						BaseImage:       "build/provider-aws",
+						Targets:         []string{"xpkg.upbound.io/upbound/%s:v1.0.0"},
+						ProviderBinRoot: "/spec/_output/bin",
+						AuthExt:         "/abs/auth.yaml",
+						Services:        []batchService{{Name: "ec2"}},
+					}},
+				},
+			},
+		},
+		"UnknownField": {
+			reason: "Unknown fields should be rejected.",
+			spec: `
+apiVersion: xpkg.upbound.io/v1alpha1
+kind: BatchSpec
+family: []
+`,
+			want: want{
+				err: errors.Wrapf(errors.New(`error unmarshaling JSON: while decoding JSON: json: unknown field "family"`), errReadBatchSpecFmt, "/spec/batch.yaml"),
+			},
+		},
+		"Invalid": {
+			reason: "All validation errors of a spec should be returned.",
+			spec: `
+apiVersion: xpkg.upbound.io/v1beta1
+kind: BatchSpec
+families:
+- name: provider-aws
+  baseImage: build/provider-aws
+  targets: [xpkg.upbound.io/upbound/provider-aws:v1.0.0]
+  platforms: [linux]
+  services:
+  - name: ec2
+  - name: ec2
+`,
+			want: want{
+				err: errors.Wrapf(field.ErrorList{
+					field.NotSupported(field.NewPath("apiVersion"), "xpkg.upbound.io/v1beta1", []string{batchSpecAPIVersion}),
+					field.Invalid(field.NewPath("families").Index(0).Child("platforms").Index(0), "linux", "must use the <OS>_<arch> syntax, such as linux_arm64"),
+					field.Invalid(field.NewPath("families").Index(0).Child("targets").Index(0), "xpkg.upbound.io/upbound/provider-aws:v1.0.0", "must contain a single %s, substituted with the package repository of a service"),
+					field.Duplicate(field.NewPath("families").Index(0).Child("services").Index(1).Child("name"), "ec2"),
+				}.ToAggregate(), errInvalidBatchSpecFmt, "/spec/batch.yaml"),
+			},
+		},
+		"NoPlatforms": {
+			reason: "Families should be rejected if neither they nor the spec specify platforms.",
+			spec: `
+apiVersion: xpkg.upbound.io/v1alpha1
+kind: BatchSpec
+families:
+- name: provider-aws
+  baseImage: build/provider-aws
+  targets: [xpkg.upbound.io/upbound/%s:v1.0.0]
+  services:
+  - name: ec2
+`,
+			want: want{
+				err: errors.Wrapf(field.ErrorList{
+					field.Required(field.NewPath("families").Index(0).Child("platforms"), "must be specified for the family or the spec"),
+				}.ToAggregate(), errInvalidBatchSpecFmt, "/spec/batch.yaml"),
+			},
+		},
+	}
+
+	for n, tc := range cases {
+		t.Run(n, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			if err := afero.WriteFile(fs, "/spec/batch.yaml", []byte(tc.spec), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			spec, err := readBatchSpec(fs, "/spec/batch.yaml")
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nreadBatchSpec(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.spec, spec); diff != "" {
+				t.Errorf("\n%s\nreadBatchSpec(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestForFamily(t *testing.T) {
+	c := &batchCmd{
+		Platform:                []string{"linux_amd64", "linux_arm64"},
+		PackageMetadataTemplate: "./package/crossplane.yaml.tmpl",
+		CRDRoot:                 "./package/crds",
+		SmallerProviders:        []string{"monolith"},
+		ProvidersWithAuthExt:    []string{"monolith", "config"},
+	}
+	s := &batchSpec{Platforms: []string{"linux_arm64"}}
+	f := batchFamily{
+		Name:      "provider-aws",
+		BaseImage: "build/provider-aws",
+		Targets:   []string{"a.example.com/%s:v1", "b.example.com/%s:v1"},
+		CRDRoot:   "/spec/crds",
+		Services: []batchService{
+			{Name: "config", CRDGroup: "aws", AuthExt: true},
+			{Name: "ec2", PackageRepo: "provider-aws-compute", Store: true},
+		},
+	}
+	want := &batchCmd{
+		ProviderName:            "provider-aws",
+		FamilyBaseImage:         "build/provider-aws",
+		FamilyPackageURLFormat:  "a.example.com/%s:v1",
+		targets:                 []string{"a.example.com/%s:v1", "b.example.com/%s:v1"},
+		Platform:                []string{"linux_arm64"},
+		PackageMetadataTemplate: "./package/crossplane.yaml.tmpl",
+		CRDRoot:                 "/spec/crds",
+		SmallerProviders:        []string{"config", "ec2"},
+		ExamplesGroupOverride:   map[string]string{},
+		CRDGroupOverride:        map[string]string{"config": "aws"},
+		PackageRepoOverride:     map[string]string{"ec2": "provider-aws-compute"},
+		ProvidersWithAuthExt:    []string{"config"},
+		StorePackages:           []string{"ec2"},
+	}
+	got := c.forFamily(s, f)
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(batchCmd{}), cmpopts.IgnoreFields(batchCmd{}, "fetch")); diff != "" {
+		t.Errorf("\nforFamily(...): -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"a.example.com/provider-aws-compute:v1", "b.example.com/provider-aws-compute:v1"}, got.getPackageURLs("ec2")); diff != "" {
+		t.Errorf("\ngetPackageURLs(...): -want, +got:\n%s", diff)
+	}
+}
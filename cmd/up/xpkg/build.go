@@ -17,19 +17,37 @@ package xpkg
 import (
 	"context"
 	"io"
+	"io/fs"
+	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/crossplane/crossplane-runtime/pkg/parser"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/daemon"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/pterm/pterm"
 	"github.com/spf13/afero"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/upbound/up/internal/xpkg"
+	"github.com/upbound/up/internal/xpkg/dep/cache"
+	"github.com/upbound/up/internal/xpkg/dep/lock"
+	"github.com/upbound/up/internal/xpkg/dep/manager"
+	mxpkg "github.com/upbound/up/internal/xpkg/dep/marshaler/xpkg"
 	"github.com/upbound/up/internal/xpkg/parser/examples"
 	"github.com/upbound/up/internal/xpkg/parser/yaml"
+	"github.com/upbound/up/internal/xpkg/sbom"
+	"github.com/upbound/up/internal/xpkg/snapshot"
+	pmeta "github.com/upbound/up/internal/xpkg/workspace/meta"
 )
 
 const (
@@ -37,6 +55,30 @@ const (
 	errBuildPackage    = "failed to build package"
 	errImageDigest     = "failed to get package digest"
 	errCreatePackage   = "failed to create package file"
+	errFrozen          = "dependencies do not match crossplane.lock"
+
+	errBuildPlatformFmt           = "failed to build package for platform %s"
+	errFetchControllerFmt         = "failed to fetch controller image %s"
+	errBuildIndex                 = "failed to build image index"
+	errWriteLayout                = "failed to write OCI image layout"
+	errAmbiguousLayoutFmt         = "OCI image layout %s contains %d images, use --platform to select one"
+	errIndexFormatWithoutPlatform = "--index-format=layout can only be used together with --platform"
+	errGenerateSBOM               = "failed to generate SBOM"
+	errWriteSBOM                  = "failed to write SBOM"
+	errValidateExamples           = "failed to validate examples"
+	errParseExampleFmt            = "failed to parse examples in %s"
+	errInvalidExamplesFmt         = "found %d invalid field(s) in examples, use --allow-invalid-examples to build anyway"
+)
+
+const (
+	controllerFromDaemon   = "daemon"
+	controllerFromRegistry = "registry"
+	controllerFromLayout   = "layout"
+
+	indexFormatTarball = "tarball"
+	indexFormatLayout  = "layout"
+
+	sbomNone = "none"
 )
 
 // AfterApply constructs and binds Upbound-specific context to any subcommands
@@ -54,6 +96,7 @@ func (c *buildCmd) AfterApply() error {
 	if err != nil {
 		return err
 	}
+	c.exRoot = ex
 
 	var authBE parser.Backend
 	if ax, err := filepath.Abs(c.AuthExt); err == nil {
@@ -92,9 +135,14 @@ func (c *buildCmd) AfterApply() error {
 		examples.New(),
 	)
 
-	// NOTE(hasheddan): we currently only support fetching controller image from
-	// daemon, but may opt to support additional sources in the future.
-	c.fetch = daemonFetch
+	switch c.ControllerFrom {
+	case controllerFromRegistry:
+		c.fetch = registryControllerFetch
+	case controllerFromLayout:
+		c.fetch = layoutControllerFetch
+	default:
+		c.fetch = daemonControllerFetch
+	}
 
 	return nil
 }
@@ -104,15 +152,24 @@ type buildCmd struct {
 	fs      afero.Fs
 	builder *xpkg.Builder
 	root    string
-	fetch   fetchFn
+	exRoot  string
+	fetch   controllerFetchFn
+
+	Name           string   `optional:"" xor:"xpkg-build-out" help:"[DEPRECATED: use --output] Name of the package to be built. Uses name in crossplane.yaml if not specified. Does not correspond to package tag."`
+	Output         string   `optional:"" short:"o" xor:"xpkg-build-out" help:"Path for package output."`
+	Controller     string   `help:"Controller image used as base for package. For function packages, this is the function runtime image."`
+	ControllerFrom string   `enum:"daemon,registry,layout" default:"daemon" help:"Where to fetch the controller image from. One of: daemon, registry, layout. For layout, --controller is the path to an OCI image layout directory."`
+	Platform       []string `help:"Platforms to build the package for, in os/arch[/variant] form, e.g. linux/amd64,linux/arm64. If specified, one package image is built per platform and written as an image index."`
+	IndexFormat    string   `enum:"tarball,layout" default:"tarball" help:"Format of the output for multi-platform packages. One of: tarball (a multi-manifest xpkg file), layout (an OCI image layout directory)."`
+	PackageRoot    string   `short:"f" help:"Path to package directory." default:"."`
+	ExamplesRoot   string   `short:"e" help:"Path to package examples directory." default:"./examples"`
+	AuthExt        string   `short:"a" help:"Path to an authentication extension file." default:"auth.yaml"`
+	Ignore         []string `help:"Paths, specified relative to --package-root, to exclude from the package."`
+	Frozen         bool     `help:"Fail if any dependency is not pinned in crossplane.lock or does not match the cached digest."`
+	CacheDir       string   `short:"d" help:"Directory used for caching package images." default:"~/.up/cache/" env:"CACHE_DIR" type:"path"`
+	SBOM           string   `name:"sbom" enum:"none,spdx,cyclonedx" default:"none" help:"Generate a software bill of materials for the package. One of: none, spdx, cyclonedx. The SBOM is written next to the package and attached to it on push."`
 
-	Name         string   `optional:"" xor:"xpkg-build-out" help:"[DEPRECATED: use --output] Name of the package to be built. Uses name in crossplane.yaml if not specified. Does not correspond to package tag."`
-	Output       string   `optional:"" short:"o" xor:"xpkg-build-out" help:"Path for package output."`
-	Controller   string   `help:"Controller image used as base for package."`
-	PackageRoot  string   `short:"f" help:"Path to package directory." default:"."`
-	ExamplesRoot string   `short:"e" help:"Path to package examples directory." default:"./examples"`
-	AuthExt      string   `short:"a" help:"Path to an authentication extension file." default:"auth.yaml"`
-	Ignore       []string `help:"Paths, specified relative to --package-root, to exclude from the package."`
+	AllowInvalidExamples bool `help:"Print a warning instead of failing if examples do not match the schemas of the package or its dependencies."`
 }
 
 func (c *buildCmd) Help() string {
@@ -122,9 +179,36 @@ from the local file system. It packages the found YAML files containing Kubernet
 object manifests into the meta data layer of the OCI image. The package manager
 will use this information to install the package into a Crossplane instance.
 
-Only configuration and provider packages are supported at this time. 
+Configuration, provider and function packages are supported. Provider and
+function packages are typically built on top of their controller or function
+runtime image, which is supplied via --controller. By default it is fetched
+from the local Docker daemon; use --controller-from to fetch it from a registry
+or an OCI image layout on disk instead.
+
+Multi-platform packages can be built by passing one or more platforms via
+--platform. For every platform, the controller image for that platform is
+fetched and a package image is built on top of it. The resulting images are
+written as an image index, either as a multi-manifest xpkg file (the default)
+or as an OCI image layout directory named like the package but without the
+.xpkg extension (--index-format=layout). Both can be pushed as a single
+multi-arch tag with the push command:
 
-Example claims can be specified in the examples directory.
+  up xpkg build --controller xpkg.upbound.io/acme/provider-foo-controller:v1.0.0 \
+    --controller-from registry --platform linux/amd64,linux/arm64
+
+A software bill of materials (SBOM) listing the CRDs, XRDs and Compositions
+of the package, its dependencies and the controller image it was built on can
+be generated with --sbom. Dependencies are resolved from crossplane.lock, if
+present. The SBOM is written next to the package, e.g. to
+provider-foo-abc123.xpkg.spdx.json, and is attached to the package as an OCI
+referrer when the package is pushed.
+
+Example claims can be specified in the examples directory. Every example is
+validated against the CRDs and XRDs of the package and of its dependencies in
+the cache, and the build fails if a field of an example does not match the
+schema of its kind. With --allow-invalid-examples, invalid fields are printed
+as warnings instead. Examples of kinds without a known schema, e.g. Secrets,
+are not validated.
 
 For more generic information, see the xpkg parent command help. Also see the
 Crossplane documentation for more information on building packages:
@@ -135,55 +219,393 @@ Even more details can be found in the xpkg reference document.`
 }
 
 // Run executes the build command.
-func (c *buildCmd) Run(ctx context.Context, p pterm.TextPrinter) error { //nolint:gocyclo
-	var buildOpts []xpkg.BuildOpt
+func (c *buildCmd) Run(ctx context.Context, p pterm.TextPrinter) error {
+	platforms, err := xpkg.ParsePlatforms(c.Platform)
+	if err != nil {
+		return err
+	}
+	if len(platforms) == 0 && c.IndexFormat != indexFormatTarball {
+		return errors.New(errIndexFormatWithoutPlatform)
+	}
+
+	var (
+		imgs  []v1.Image
+		bases []sbom.Image
+		meta  runtime.Object
+	)
+	if len(platforms) == 0 {
+		img, base, m, err := c.build(ctx, nil)
+		if err != nil {
+			return errors.Wrap(err, errBuildPackage)
+		}
+		imgs, bases, meta = append(imgs, img), append(bases, base...), m
+	}
+	for _, pl := range platforms {
+		pl := pl
+		img, base, m, err := c.build(ctx, &pl)
+		if err != nil {
+			return errors.Wrapf(err, errBuildPlatformFmt, pl.String())
+		}
+		imgs, bases, meta = append(imgs, img), append(bases, base...), m
+	}
+
+	if c.Frozen {
+		if err := c.checkLock(meta); err != nil {
+			return errors.Wrap(err, errFrozen)
+		}
+	}
+
+	if err := c.validateExamples(ctx, p, meta, imgs[0]); err != nil {
+		return err
+	}
+
+	var (
+		output string
+		hash   v1.Hash
+	)
+	if len(platforms) == 0 {
+		output, hash, err = c.writeImage(p, meta, imgs[0])
+	} else {
+		output, hash, err = c.writeIndex(p, meta, imgs)
+	}
+	if err != nil || c.SBOM == sbomNone {
+		return err
+	}
+	return c.writeSBOM(p, output, hash, meta, imgs[0], bases)
+}
+
+// build builds the package for the supplied platform. If the platform is nil,
+// the package is built for the platform of the controller image. The
+// controller image, if any, is returned as the base of the package.
+func (c *buildCmd) build(ctx context.Context, pl *v1.Platform) (v1.Image, []sbom.Image, runtime.Object, error) {
+	var (
+		buildOpts []xpkg.BuildOpt
+		bases     []sbom.Image
+	)
 	if c.Controller != "" {
-		ref, err := name.ParseReference(c.Controller)
+		base, err := c.fetch(ctx, c.Controller, pl)
 		if err != nil {
-			return err
+			return nil, nil, nil, errors.Wrapf(err, errFetchControllerFmt, c.Controller)
 		}
-		base, err := c.fetch(ctx, ref)
+		d, err := base.Digest()
 		if err != nil {
-			return err
+			return nil, nil, nil, errors.Wrapf(err, errFetchControllerFmt, c.Controller)
+		}
+		b := sbom.Image{Ref: c.Controller, Digest: d.String()}
+		if pl != nil {
+			b.Platform = pl.String()
 		}
 		buildOpts = append(buildOpts, xpkg.WithController(base))
+		bases = append(bases, b)
 	}
-	img, meta, err := c.builder.Build(ctx, buildOpts...)
-	if err != nil {
-		return errors.Wrap(err, errBuildPackage)
+	if pl != nil {
+		buildOpts = append(buildOpts, xpkg.WithPlatform(*pl))
 	}
+	img, meta, err := c.builder.Build(ctx, buildOpts...)
+	return img, bases, meta, err
+}
 
+// writeImage writes a single package image as an xpkg file. It returns the
+// path and digest of the written package.
+func (c *buildCmd) writeImage(p pterm.TextPrinter, meta runtime.Object, img v1.Image) (string, v1.Hash, error) {
 	hash, err := img.Digest()
 	if err != nil {
-		return errors.Wrap(err, errImageDigest)
+		return "", v1.Hash{}, errors.Wrap(err, errImageDigest)
 	}
 
-	output := filepath.Clean(c.Output)
-	if c.Output == "" {
-		pkgName := c.Name
-		if pkgName == "" {
-			pkgMeta, ok := meta.(metav1.Object)
-			if !ok {
-				return errors.New(errGetNameFromMeta)
-			}
-			pkgName = xpkg.FriendlyID(pkgMeta.GetName(), hash.Hex)
-		}
-		output = xpkg.BuildPath(c.root, pkgName)
+	output, _, err := c.outputPath(meta, hash)
+	if err != nil {
+		return "", v1.Hash{}, err
 	}
 
 	f, err := c.fs.Create(output)
 	if err != nil {
-		return errors.Wrap(err, errCreatePackage)
+		return "", v1.Hash{}, errors.Wrap(err, errCreatePackage)
 	}
 
 	defer func() { _ = f.Close() }()
 	if err := tarball.Write(nil, img, f); err != nil {
-		return err
+		return "", v1.Hash{}, err
 	}
 	p.Printfln("xpkg saved to %s", output)
+	return output, hash, nil
+}
+
+// writeIndex writes the per-platform package images as an image index, either
+// as a multi-manifest xpkg file or as an OCI image layout directory. It
+// returns the path and digest of the written index.
+func (c *buildCmd) writeIndex(p pterm.TextPrinter, meta runtime.Object, imgs []v1.Image) (string, v1.Hash, error) {
+	ii, err := xpkg.Index(imgs...)
+	if err != nil {
+		return "", v1.Hash{}, errors.Wrap(err, errBuildIndex)
+	}
+	hash, err := ii.Digest()
+	if err != nil {
+		return "", v1.Hash{}, errors.Wrap(err, errImageDigest)
+	}
+
+	output, pkgName, err := c.outputPath(meta, hash)
+	if err != nil {
+		return "", v1.Hash{}, err
+	}
+
+	if c.IndexFormat == indexFormatLayout {
+		// NOTE: the layout is a directory, not an xpkg file.
+		output = strings.TrimSuffix(output, xpkg.XpkgExtension)
+		if err := xpkg.WriteLayout(c.fs, output, ii); err != nil {
+			return "", v1.Hash{}, errors.Wrap(err, errWriteLayout)
+		}
+		p.Printfln("xpkg index for %d platforms saved to %s", len(imgs), output)
+		return output, hash, nil
+	}
+
+	f, err := c.fs.Create(output)
+	if err != nil {
+		return "", v1.Hash{}, errors.Wrap(err, errCreatePackage)
+	}
+
+	defer func() { _ = f.Close() }()
+	if err := xpkg.WriteIndexTarball(xpkg.ToDNSLabel(pkgName), ii, f); err != nil {
+		return "", v1.Hash{}, err
+	}
+	p.Printfln("xpkg index for %d platforms saved to %s", len(imgs), output)
+	return output, hash, nil
+}
+
+// writeSBOM generates an SBOM for the package written to output and writes it
+// next to the package. The objects of the package are the same for every
+// platform, so they are read from the supplied image.
+func (c *buildCmd) writeSBOM(p pterm.TextPrinter, output string, hash v1.Hash, meta runtime.Object, img v1.Image, bases []sbom.Image) error {
+	pkg, err := c.sbomPackage(hash, meta, img, bases)
+	if err != nil {
+		return errors.Wrap(err, errGenerateSBOM)
+	}
+	b, err := sbom.Generate(c.SBOM, pkg)
+	if err != nil {
+		return errors.Wrap(err, errGenerateSBOM)
+	}
+	path := output + sbom.Extension(c.SBOM)
+	if err := afero.WriteFile(c.fs, path, b, os.ModePerm); err != nil {
+		return errors.Wrap(err, errWriteSBOM)
+	}
+	p.Printfln("SBOM saved to %s", path)
 	return nil
 }
 
+// sbomPackage collects the contents of the package for its SBOM. Dependencies
+// are resolved from the lock file, if one exists.
+func (c *buildCmd) sbomPackage(hash v1.Hash, meta runtime.Object, img v1.Image, bases []sbom.Image) (sbom.Package, error) {
+	pkgMeta, ok := meta.(metav1.Object)
+	if !ok {
+		return sbom.Package{}, errors.New(errGetNameFromMeta)
+	}
+
+	m, err := mxpkg.NewMarshaler()
+	if err != nil {
+		return sbom.Package{}, err
+	}
+	parsed, err := m.FromImage(xpkg.Image{Image: img})
+	if err != nil {
+		return sbom.Package{}, err
+	}
+
+	l, err := lock.Read(c.fs, filepath.Join(c.root, xpkg.LockFile))
+	if err != nil && !os.IsNotExist(err) {
+		return sbom.Package{}, err
+	}
+
+	deps := make([]sbom.Dependency, 0, len(parsed.Dependencies()))
+	direct := make(map[string]bool, len(parsed.Dependencies()))
+	for _, d := range parsed.Dependencies() {
+		dep := sbom.Dependency{Package: d.Package, Type: string(d.Type), Constraint: d.Constraints}
+		if l != nil {
+			if lp, ok := l.Get(d.Package); ok {
+				dep.Version, dep.Digest = lp.Version, lp.Digest
+			}
+		}
+		deps = append(deps, dep)
+		direct[lock.Key(d.Package)] = true
+	}
+	// NOTE: transitive dependencies are only known if they have been resolved
+	// into the lock file.
+	if l != nil {
+		for _, lp := range l.Packages {
+			if direct[lp.Name] {
+				continue
+			}
+			deps = append(deps, sbom.Dependency{Package: lp.Name, Type: string(lp.Type), Version: lp.Version, Digest: lp.Digest})
+		}
+	}
+
+	return sbom.Package{
+		Name:         pkgMeta.GetName(),
+		Kind:         meta.GetObjectKind().GroupVersionKind().Kind,
+		Digest:       hash.String(),
+		Created:      time.Now(),
+		Objects:      sbom.Objects(parsed.Objects()),
+		Dependencies: deps,
+		Bases:        bases,
+	}, nil
+}
+
+// validateExamples validates the examples of the package against the CRDs and
+// XRDs of the package and of its dependencies in the cache. The objects of the
+// package are the same for every platform, so they are read from the supplied
+// image.
+func (c *buildCmd) validateExamples(ctx context.Context, p pterm.TextPrinter, meta runtime.Object, img v1.Image) error {
+	if _, err := c.fs.Stat(c.exRoot); os.IsNotExist(err) {
+		return nil
+	}
+	objs, err := c.schemaObjects(ctx, meta, img)
+	if err != nil {
+		return errors.Wrap(err, errValidateExamples)
+	}
+	v := snapshot.NewExampleValidator(ctx, objs...)
+
+	prefix := ""
+	if c.AllowInvalidExamples {
+		prefix = "Warning: "
+	}
+	filters := buildFilters(c.exRoot, c.Ignore)
+	invalid := 0
+	err = afero.Walk(c.fs, c.exRoot, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		for _, fn := range filters {
+			if skip, err := fn(path, info); err != nil || skip {
+				return err
+			}
+		}
+		f, err := c.fs.Open(path)
+		if err != nil {
+			return err
+		}
+		ex, err := examples.New().Parse(ctx, f)
+		_ = f.Close()
+		if err != nil {
+			return errors.Wrapf(err, errParseExampleFmt, path)
+		}
+		if rel, err := filepath.Rel(c.root, path); err == nil {
+			path = rel
+		}
+		objs := ex.Objects()
+		for i := range objs {
+			for _, e := range v.Validate(ctx, &objs[i]) {
+				invalid++
+				p.Printfln("%s%s: %s/%s: %s", prefix, path, objs[i].GetKind(), objs[i].GetName(), e.Error())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, errValidateExamples)
+	}
+	if invalid > 0 && !c.AllowInvalidExamples {
+		return errors.Errorf(errInvalidExamplesFmt, invalid)
+	}
+	return nil
+}
+
+// schemaObjects returns the objects of the package and of its dependencies in
+// the cache. Dependencies that are not in the cache are skipped.
+func (c *buildCmd) schemaObjects(ctx context.Context, meta runtime.Object, img v1.Image) ([]runtime.Object, error) {
+	m, err := mxpkg.NewMarshaler()
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := m.FromImage(xpkg.Image{Image: img})
+	if err != nil {
+		return nil, err
+	}
+	objs := parsed.Objects()
+
+	deps, err := pmeta.New(meta).DependsOn()
+	if err != nil {
+		return nil, err
+	}
+	depObjs, err := dependencyObjects(ctx, c.fs, c.root, c.CacheDir, deps)
+	if err != nil {
+		return nil, err
+	}
+	return append(objs, depObjs...), nil
+}
+
+// dependencyObjects returns the objects of the supplied dependencies of the
+// package at root, resolved through its lock file and the package cache.
+// Dependencies that are not in the cache are skipped.
+func dependencyObjects(ctx context.Context, fs afero.Fs, root, cacheDir string, deps []v1beta1.Dependency) ([]runtime.Object, error) {
+	if len(deps) == 0 {
+		return nil, nil
+	}
+	ch, err := cache.NewLocal(cacheDir, cache.WithFS(fs))
+	if err != nil {
+		return nil, err
+	}
+	l, err := lock.Read(fs, filepath.Join(root, xpkg.LockFile))
+	if os.IsNotExist(err) {
+		l, err = lock.New(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	dm, err := manager.New(manager.WithCache(ch), manager.WithLock(l))
+	if err != nil {
+		return nil, err
+	}
+	view, err := dm.View(ctx, deps)
+	if err != nil {
+		return nil, err
+	}
+	objs := []runtime.Object{}
+	for _, pkg := range view.Packages() {
+		objs = append(objs, pkg.Objects()...)
+	}
+	return objs, nil
+}
+
+// outputPath returns the path the package should be written to and the name
+// of the package.
+func (c *buildCmd) outputPath(meta runtime.Object, hash v1.Hash) (string, string, error) {
+	pkgMeta, ok := meta.(metav1.Object)
+	if !ok {
+		return "", "", errors.New(errGetNameFromMeta)
+	}
+	if c.Output != "" {
+		return filepath.Clean(c.Output), pkgMeta.GetName(), nil
+	}
+	pkgName := c.Name
+	if pkgName == "" {
+		pkgName = xpkg.FriendlyID(pkgMeta.GetName(), hash.Hex)
+	}
+	return xpkg.BuildPath(c.root, pkgName), pkgMeta.GetName(), nil
+}
+
+// checkLock ensures that every dependency of the package is pinned in the lock
+// file and that the pinned digests match those in the cache.
+func (c *buildCmd) checkLock(meta runtime.Object) error {
+	l, err := lock.Read(c.fs, filepath.Join(c.root, xpkg.LockFile))
+	if err != nil {
+		return err
+	}
+
+	deps, err := pmeta.New(meta).DependsOn()
+	if err != nil {
+		return err
+	}
+
+	if err := l.Check(deps); err != nil {
+		return err
+	}
+
+	ch, err := cache.NewLocal(c.CacheDir, cache.WithFS(c.fs))
+	if err != nil {
+		return err
+	}
+
+	return l.Verify(ch)
+}
+
 // default build filters skip directories, empty files, and files without YAML
 // extension in addition to any paths specified.
 func buildFilters(root string, skips []string) []parser.FilterFn {
@@ -199,3 +621,70 @@ func buildFilters(root string, skips []string) []parser.FilterFn {
 	}
 	return opts
 }
+
+// controllerFetchFn fetches a controller image. If a platform is supplied, the
+// image for that platform is returned.
+type controllerFetchFn func(ctx context.Context, ref string, p *v1.Platform) (v1.Image, error)
+
+// daemonControllerFetch fetches a controller image from the Docker daemon.
+func daemonControllerFetch(ctx context.Context, ref string, p *v1.Platform) (v1.Image, error) {
+	r, err := name.ParseReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	img, err := daemon.Image(r, daemon.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if p != nil {
+		if err := xpkg.CheckPlatform(img, *p); err != nil {
+			return nil, err
+		}
+	}
+	return img, nil
+}
+
+// registryControllerFetch fetches a controller image from a registry.
+func registryControllerFetch(ctx context.Context, ref string, p *v1.Platform) (v1.Image, error) {
+	r, err := name.ParseReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	opts := []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+	}
+	if p == nil {
+		return remote.Image(r, opts...)
+	}
+	img, err := remote.Image(r, append(opts, remote.WithPlatform(*p))...)
+	if err != nil {
+		return nil, err
+	}
+	// NOTE: the platform is only used to select an image if the reference
+	// points to an index, so single-platform images have to be checked.
+	if err := xpkg.CheckPlatform(img, *p); err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// layoutControllerFetch fetches a controller image from an OCI image layout
+// directory.
+func layoutControllerFetch(_ context.Context, path string, p *v1.Platform) (v1.Image, error) {
+	ii, err := layout.ImageIndexFromPath(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	if p != nil {
+		return xpkg.ImageForPlatform(ii, *p)
+	}
+	imgs, err := xpkg.ImagesFromIndex(ii)
+	if err != nil {
+		return nil, err
+	}
+	if len(imgs) != 1 {
+		return nil, errors.Errorf(errAmbiguousLayoutFmt, path, len(imgs))
+	}
+	return imgs[0], nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+
+	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
+	"github.com/google/go-cmp/cmp"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func platformImage(t *testing.T, os, arch string) v1.Image {
+	t.Helper()
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg = cfg.DeepCopy()
+	cfg.OS, cfg.Architecture = os, arch
+	img, err = mutate.ConfigFile(img, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestWriteIndex(t *testing.T) {
+	meta := &pkgmetav1.Provider{ObjectMeta: metav1.ObjectMeta{Name: "provider-foo"}}
+	imgs := []v1.Image{platformImage(t, "linux", "amd64"), platformImage(t, "linux", "arm64")}
+
+	type want struct {
+		output string
+		files  []string
+	}
+	cases := map[string]struct {
+		reason string
+		format string
+		want   want
+	}{
+		"Tarball": {
+			reason: "A multi-platform package should be written as a single xpkg file.",
+			format: indexFormatTarball,
+			want: want{
+				output: "/ws/provider-foo.xpkg",
+				files:  []string{"/ws/provider-foo.xpkg"},
+			},
+		},
+		"Layout": {
+			reason: "A multi-platform package should be written as an OCI image layout directory without the xpkg extension.",
+			format: indexFormatLayout,
+			want: want{
+				output: "/ws/provider-foo",
+				files:  []string{"/ws/provider-foo/index.json", "/ws/provider-foo/oci-layout"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			c := &buildCmd{fs: fs, root: "/ws", Name: "provider-foo", IndexFormat: tc.format}
+
+			output, _, err := c.writeIndex(pterm.DefaultBasicText.WithWriter(io.Discard), meta, imgs)
+			if err != nil {
+				t.Fatalf("\n%s\nwriteIndex(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.output, output); diff != "" {
+				t.Errorf("\n%s\nwriteIndex(...): -want output, +got output:\n%s", tc.reason, diff)
+			}
+			for _, f := range tc.want.files {
+				if ok, _ := afero.Exists(fs, filepath.FromSlash(f)); !ok {
+					t.Errorf("\n%s\nwriteIndex(...): %s was not written", tc.reason, f)
+				}
+			}
+		})
+	}
+}
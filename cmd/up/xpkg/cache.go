@@ -0,0 +1,339 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	"github.com/docker/go-units"
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/util/duration"
+
+	"github.com/upbound/up/internal/upterm"
+	"github.com/upbound/up/internal/xpkg"
+	"github.com/upbound/up/internal/xpkg/dep/cache"
+	"github.com/upbound/up/internal/xpkg/dep/lock"
+	"github.com/upbound/up/internal/xpkg/dep/manager"
+)
+
+const (
+	errInvalidSizeFmt    = "invalid size %q"
+	errPruneNoCriteria   = "at least one of --workspace, --older-than or --max-size must be set"
+	errReadWorkspaceLock = "failed to read crossplane.lock of workspace %s, run up xpkg dep to generate it"
+	errNotCachedFmt      = "%s is not in the cache"
+	errCorruptFmt        = "found %d corrupt package(s)"
+	errRefetchFailFmt    = "%d corrupt package(s) could not be re-fetched"
+)
+
+var cacheEntryFieldNames = []string{"PACKAGE", "VERSION", "DIGEST", "SIZE", "LAST USED"}
+
+// AfterApply constructs and binds the package cache to any subcommands that
+// have Run() methods that receive it.
+func (c *cacheCmd) AfterApply(kongCtx *kong.Context) error {
+	ch, err := cache.NewLocal(c.CacheDir)
+	if err != nil {
+		return err
+	}
+	c.c = ch
+	kongCtx.Bind(c)
+	return nil
+}
+
+// cacheCmd manages the package cache.
+type cacheCmd struct {
+	c *cache.Local
+
+	CacheDir string `short:"d" help:"Directory used for caching package images." default:"~/.up/cache/" env:"CACHE_DIR" type:"path"`
+
+	List   cacheListCmd   `cmd:"" help:"List the packages in the cache."`
+	Prune  cachePruneCmd  `cmd:"" help:"Remove unused, old or least recently used packages from the cache."`
+	Verify cacheVerifyCmd `cmd:"" help:"Verify the packages in the cache and re-fetch corrupt ones."`
+	Remove cacheRemoveCmd `cmd:"" help:"Remove packages from the cache."`
+}
+
+func (c *cacheCmd) Help() string {
+	return `
+The cache command manages the local package cache (by default in ~/.up/cache),
+which holds the schemas of package dependencies. It is populated by the dep
+command and used e.g. by the lint command and the Crossplane language server.
+
+The list subcommand prints the cached packages along with their digest, their
+size on disk and when they were last used. The prune subcommand removes
+packages that are not used by any of the given package workspaces, that have
+not been used for a number of days or, with --max-size, the least recently
+used packages until the cache fits in the given size:
+
+  up xpkg cache prune --workspace . --workspace ../other-package
+  up xpkg cache prune --older-than 30 --max-size 5GiB
+
+The verify subcommand checks that every cached package was fully written, that
+its contents match the checksum recorded when it was cached and that it can be
+parsed, and re-fetches corrupt packages from their registries. The checksum of
+packages cached by earlier versions is recorded on their first verification. The
+remove subcommand removes single packages or package versions.
+
+The size of the cache can also be limited when dependencies are added, via the
+--cache-max-size flag or the CACHE_MAX_SIZE environment variable of the dep
+command.`
+}
+
+// cacheListCmd lists the packages in the cache.
+type cacheListCmd struct{}
+
+// AfterApply sets default values in command after assignment and validation.
+func (c *cacheListCmd) AfterApply(kongCtx *kong.Context) error {
+	kongCtx.Bind(pterm.DefaultTable.WithWriter(kongCtx.Stdout).WithSeparator("   "))
+	return nil
+}
+
+// Run executes the cache list command.
+func (c *cacheListCmd) Run(printer upterm.ObjectPrinter, p pterm.TextPrinter, cc *cacheCmd) error {
+	entries, err := cc.c.List()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		p.Printfln("No packages found in %s", cc.CacheDir)
+		return nil
+	}
+	return printer.Print(entries, cacheEntryFieldNames, extractCacheEntryFields)
+}
+
+// cachePruneCmd removes packages from the cache.
+type cachePruneCmd struct {
+	maxSize int64
+
+	Workspace []string `short:"w" type:"existingdir" placeholder:"DIR" help:"Remove packages that are not locked in the crossplane.lock file of any of the given package workspaces."`
+	OlderThan int      `placeholder:"DAYS" help:"Remove packages that have not been used for the given number of days."`
+	MaxSize   string   `placeholder:"SIZE" help:"Remove the least recently used packages until the cache is no larger than the given size, e.g. 10GiB."`
+	DryRun    bool     `help:"Print the packages that would be removed without removing them."`
+}
+
+// AfterApply sets default values in command after assignment and validation.
+func (c *cachePruneCmd) AfterApply() error {
+	if len(c.Workspace) == 0 && c.OlderThan <= 0 && c.MaxSize == "" {
+		return errors.New(errPruneNoCriteria)
+	}
+	if c.MaxSize != "" {
+		size, err := parseSize(c.MaxSize)
+		if err != nil {
+			return err
+		}
+		c.maxSize = size
+	}
+	return nil
+}
+
+// Run executes the cache prune command.
+func (c *cachePruneCmd) Run(p pterm.TextPrinter, cc *cacheCmd) error {
+	entries, err := cc.c.List()
+	if err != nil {
+		return err
+	}
+	locked, err := c.locked()
+	if err != nil {
+		return err
+	}
+
+	prune := []cache.Entry{}
+	keep := []cache.Entry{}
+	for _, e := range entries {
+		unused := locked != nil && !locked[cacheEntryKey(e)]
+		old := c.OlderThan > 0 && time.Since(e.LastUsed) > time.Duration(c.OlderThan)*24*time.Hour
+		if unused || old {
+			prune = append(prune, e)
+			continue
+		}
+		keep = append(keep, e)
+	}
+	if c.MaxSize != "" {
+		prune = append(prune, cache.LeastRecentlyUsed(keep, c.maxSize)...)
+	}
+
+	verb := "Removed"
+	if c.DryRun {
+		verb = "Would remove"
+	}
+	var freed int64
+	for _, e := range prune {
+		if !c.DryRun {
+			if err := cc.c.Remove(e); err != nil {
+				return err
+			}
+		}
+		freed += e.Size
+		p.Printfln("%s %s (%s)", verb, cacheEntryKey(e), units.BytesSize(float64(e.Size)))
+	}
+	p.Printfln("%s %d package(s), %s", verb, len(prune), units.BytesSize(float64(freed)))
+	return nil
+}
+
+// locked returns the packages locked by the workspaces to keep, keyed by
+// their name and version. It returns nil if no workspace was supplied.
+func (c *cachePruneCmd) locked() (map[string]bool, error) {
+	if len(c.Workspace) == 0 {
+		return nil, nil
+	}
+	fs := afero.NewOsFs()
+	locked := map[string]bool{}
+	for _, ws := range c.Workspace {
+		l, err := lock.Read(fs, filepath.Join(ws, xpkg.LockFile))
+		if err != nil {
+			return nil, errors.Wrapf(err, errReadWorkspaceLock, ws)
+		}
+		for _, p := range l.Packages {
+			locked[cacheEntryKey(cache.Entry{Package: p.Name, Version: p.Version})] = true
+		}
+	}
+	return locked, nil
+}
+
+// cacheVerifyCmd verifies the packages in the cache.
+type cacheVerifyCmd struct {
+	Refetch bool   `default:"true" negatable:"" help:"Re-fetch corrupt packages from their registries."`
+	Profile string `env:"UP_PROFILE" predictor:"profiles" help:"Profile whose registry mirrors are used to re-fetch packages. Defaults to the default profile."`
+}
+
+// Run executes the cache verify command.
+func (c *cacheVerifyCmd) Run(ctx context.Context, p pterm.TextPrinter, cc *cacheCmd) error {
+	entries, err := cc.c.List()
+	if err != nil {
+		return err
+	}
+	upCtx, err := profileContext(c.Profile)
+	if err != nil {
+		return err
+	}
+	m, err := manager.New(
+		manager.WithCache(cc.c),
+		manager.WithResolver(imageResolver(false, cc.CacheDir, cc.c, upCtx.Profile.Mirrors)),
+	)
+	if err != nil {
+		return err
+	}
+
+	corrupt, failed := 0, 0
+	for _, e := range entries {
+		verr := cc.c.Verify(e)
+		if verr == nil {
+			continue
+		}
+		corrupt++
+		name := cacheEntryKey(e)
+		p.Printfln("%s is corrupt: %s", name, verr)
+		if !c.Refetch {
+			continue
+		}
+		pkg, err := m.Fetch(ctx, v1beta1.Dependency{Package: e.Package, Constraints: e.Version})
+		if err != nil {
+			failed++
+			p.Printfln("Failed to re-fetch %s: %s", name, err)
+			continue
+		}
+		if e.Digest != "" && pkg.Digest() != e.Digest {
+			p.Printfln("Re-fetched %s, its digest changed from %s to %s", name, e.Digest, pkg.Digest())
+			continue
+		}
+		p.Printfln("Re-fetched %s", name)
+	}
+
+	switch {
+	case failed > 0:
+		return errors.Errorf(errRefetchFailFmt, failed)
+	case corrupt > 0 && !c.Refetch:
+		return errors.Errorf(errCorruptFmt, corrupt)
+	}
+	p.Printfln("Verified %d package(s)", len(entries))
+	return nil
+}
+
+// cacheRemoveCmd removes packages from the cache.
+type cacheRemoveCmd struct {
+	Packages []string `arg:"" help:"Packages to remove, e.g. xpkg.upbound.io/upbound/provider-aws-ec2 for all versions or xpkg.upbound.io/upbound/provider-aws-ec2@v1.0.0 for a single version."`
+}
+
+// Run executes the cache remove command.
+func (c *cacheRemoveCmd) Run(p pterm.TextPrinter, cc *cacheCmd) error {
+	entries, err := cc.c.List()
+	if err != nil {
+		return err
+	}
+	for _, pkg := range c.Packages {
+		// NOTE: unlike for dependencies, a package without a version
+		// refers to all of its cached versions.
+		name, version, _ := strings.Cut(pkg, "@")
+		removed := 0
+		for _, e := range entries {
+			if lock.Key(e.Package) != lock.Key(name) || (version != "" && e.Version != version) {
+				continue
+			}
+			if err := cc.c.Remove(e); err != nil {
+				return err
+			}
+			removed++
+			p.Printfln("Removed %s", cacheEntryKey(e))
+		}
+		if removed == 0 {
+			return errors.Errorf(errNotCachedFmt, pkg)
+		}
+	}
+	return nil
+}
+
+// parseSize parses a size in bytes, e.g. 512MiB or 10GB. Units are binary,
+// i.e. 1GB equals 1GiB.
+func parseSize(s string) (int64, error) {
+	size, err := units.RAMInBytes(s)
+	if err != nil || size < 0 {
+		return 0, errors.Errorf(errInvalidSizeFmt, s)
+	}
+	return size, nil
+}
+
+// cacheSizeOption returns the cache option limiting the cache to the supplied
+// size. It returns nil if the size is empty.
+func cacheSizeOption(size string) (cache.Option, error) {
+	if size == "" {
+		return nil, nil
+	}
+	n, err := parseSize(size)
+	if err != nil {
+		return nil, err
+	}
+	return cache.WithMaxSize(n), nil
+}
+
+// cacheEntryKey returns the name and version of the package of an entry, as
+// used in crossplane.lock.
+func cacheEntryKey(e cache.Entry) string {
+	return lock.Key(e.Package) + "@" + e.Version
+}
+
+func extractCacheEntryFields(obj any) []string {
+	e := obj.(cache.Entry)
+	digest := e.Digest
+	if len(digest) > 19 {
+		// sha256: followed by the first 12 characters of the hash.
+		digest = digest[:19]
+	}
+	return []string{lock.Key(e.Package), e.Version, digest, units.BytesSize(float64(e.Size)), duration.HumanDuration(time.Since(e.LastUsed)) + " ago"}
+}
@@ -18,23 +18,35 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/alecthomas/kong"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/pterm/pterm"
 	"github.com/spf13/afero"
 
+	"github.com/upbound/up/internal/mirror"
+	"github.com/upbound/up/internal/upbound"
 	"github.com/upbound/up/internal/xpkg"
 	"github.com/upbound/up/internal/xpkg/dep"
+	"github.com/upbound/up/internal/xpkg/dep/bundle"
 	"github.com/upbound/up/internal/xpkg/dep/cache"
+	"github.com/upbound/up/internal/xpkg/dep/lock"
 	"github.com/upbound/up/internal/xpkg/dep/manager"
+	mxpkg "github.com/upbound/up/internal/xpkg/dep/marshaler/xpkg"
 	"github.com/upbound/up/internal/xpkg/dep/resolver/image"
+	"github.com/upbound/up/internal/xpkg/signature"
 	"github.com/upbound/up/internal/xpkg/workspace"
 )
 
 const (
 	errMetaFileNotFound = "crossplane.yaml file not found in current directory"
+	errLockFileNotFound = "crossplane.lock file not found, run without --frozen to generate it"
+	errVerifyLock       = "failed to verify crossplane.lock against cache"
+	errOfflineSignature = "--require-signature cannot be used with --offline, signatures are verified against the registry"
 )
 
 // AfterApply constructs and binds Upbound-specific context to any subcommands
@@ -44,45 +56,87 @@ func (c *depCmd) AfterApply(kongCtx *kong.Context, p pterm.TextPrinter) error {
 	ctx := context.Background()
 	fs := afero.NewOsFs()
 
-	cache, err := cache.NewLocal(c.CacheDir)
+	var cacheOpts []cache.Option
+	sizeOpt, err := cacheSizeOption(c.CacheMaxSize)
+	if err != nil {
+		return err
+	}
+	if sizeOpt != nil {
+		cacheOpts = append(cacheOpts, sizeOpt)
+	}
+	cache, err := cache.NewLocal(c.CacheDir, cacheOpts...)
 	if err != nil {
 		return err
 	}
 
 	c.c = cache
+	c.fs = fs
 
 	// only parse the workspace if we aren't attempting to clean the cache
 	if !c.CleanCache {
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
 
-		r := image.NewResolver()
-
-		m, err := manager.New(
-			manager.WithCache(cache),
-			manager.WithResolver(r),
-		)
-
+		ws, err := workspace.New(wd, workspace.WithFS(fs), workspace.WithPrinter(p))
 		if err != nil {
 			return err
 		}
+		c.ws = ws
 
-		c.m = m
+		if err := ws.Parse(ctx); err != nil {
+			return err
+		}
 
-		wd, err := os.Getwd()
+		l, err := readLock(fs, ws, c.Frozen)
 		if err != nil {
 			return err
 		}
+		if err := l.Verify(cache); err != nil {
+			if c.Frozen {
+				return errors.Wrap(err, errVerifyLock)
+			}
+			p.Printfln("Warning: %s", err)
+		}
+		c.l = l
 
-		ws, err := workspace.New(wd, workspace.WithFS(fs), workspace.WithPrinter(p))
+		if c.Offline && c.RequireSignature != "" {
+			return errors.New(errOfflineSignature)
+		}
+		upCtx, err := profileContext(c.Profile)
 		if err != nil {
 			return err
 		}
-		c.ws = ws
+		mirrors := upCtx.Profile.Mirrors
+		c.r = imageResolver(c.Offline, c.CacheDir, cache, mirrors)
 
-		if err := ws.Parse(ctx); err != nil {
+		opts := []manager.Option{
+			manager.WithCache(cache),
+			manager.WithResolver(c.r),
+			manager.WithLock(l),
+		}
+		if c.Frozen {
+			opts = append(opts, manager.WithFrozen())
+		}
+		if c.RequireSignature != "" {
+			pub, err := loadVerificationKey(fs, c.RequireSignature)
+			if err != nil {
+				return err
+			}
+			v := signature.NewVerifier(pub, remote.WithAuthFromKeychain(keychain(upCtx, c.Profile)))
+			opts = append(opts, manager.WithVerifier(&mirrorVerifier{v: v, m: mirrors}))
+		}
+
+		m, err := manager.New(opts...)
+		if err != nil {
 			return err
 		}
+
+		c.m = m
 	}
 
+	kongCtx.Bind(c)
 	// workaround interfaces not being bindable ref: https://github.com/alecthomas/kong/issues/48
 	kongCtx.BindTo(ctx, (*context.Context)(nil))
 	return nil
@@ -91,16 +145,33 @@ func (c *depCmd) AfterApply(kongCtx *kong.Context, p pterm.TextPrinter) error {
 // depCmd manages crossplane dependencies.
 type depCmd struct {
 	c  *cache.Local
+	fs afero.Fs
+	l  *lock.Lock
 	m  *manager.Manager
+	r  *image.Resolver
 	ws *workspace.Workspace
 
 	// TODO(@tnthornton) remove cacheDir flag. Having a user supplied flag
 	// can result in broken behavior between xpls and dep. CacheDir should
 	// only be supplied by the Config.
-	CacheDir   string `short:"d" help:"Directory used for caching package images." default:"~/.up/cache/" env:"CACHE_DIR" type:"path"`
-	CleanCache bool   `short:"c" help:"Clean dep cache."`
-
-	Package string `arg:"" optional:"" help:"Package to be added."`
+	CacheDir     string `short:"d" help:"Directory used for caching package images." default:"~/.up/cache/" env:"CACHE_DIR" type:"path"`
+	CleanCache   bool   `short:"c" help:"Clean dep cache."`
+	CacheMaxSize string `placeholder:"SIZE" env:"CACHE_MAX_SIZE" help:"Evict the least recently used packages from the cache once it grows beyond the given size, e.g. 10GiB."`
+	Frozen       bool   `help:"Fail instead of resolving dependencies that are not pinned in crossplane.lock."`
+	Offline      bool   `help:"Resolve dependencies only from imported bundles and the cache, without accessing any registry."`
+	Profile      string `env:"UP_PROFILE" predictor:"profiles" help:"Profile whose registry mirrors are used to resolve dependencies. Defaults to the default profile."`
+
+	RequireSignature string `type:"existingfile" placeholder:"PUBLIC-KEY" help:"Fail if any dependency is not signed with the private key belonging to the supplied public key."`
+
+	Add      depAddCmd      `cmd:"" default:"withargs" help:"Add a dependency to crossplane.yaml and populate the cache. Used when no subcommand is given."`
+	Tree     depTreeCmd     `cmd:"" help:"Print the resolved dependency graph of the package in the current directory."`
+	Why      depWhyCmd      `cmd:"" help:"Print every path through which a package is depended on."`
+	Update   depUpdateCmd   `cmd:"" help:"Update dependencies to the latest versions matching their constraints."`
+	Remove   depRemoveCmd   `cmd:"" help:"Remove a dependency from crossplane.yaml and prune unused packages."`
+	Outdated depOutdatedCmd `cmd:"" help:"Report dependencies that have newer versions available."`
+
+	Bundle       depBundleCmd       `cmd:"" help:"Export the resolved dependencies of the package in the current directory to a bundle."`
+	ImportBundle depImportBundleCmd `cmd:"" help:"Import a bundle of dependencies for use without registry access."`
 }
 
 func (c *depCmd) Help() string {
@@ -112,11 +183,52 @@ server.
 
 If a package (e.g. provider-foo@v0.42.0 or provider-foo for latest) is specified,
 it will be added to the crossplane.yaml file in the current directory as dependency. 
+
+The tree subcommand prints the resolved dependency graph, and the why
+subcommand prints every path through which a given package is pulled in.
+
+The update subcommand re-resolves dependencies to the latest matching versions,
+optionally bumping their constraints, and the remove subcommand drops a
+dependency and prunes packages that are no longer used. Both preserve comments
+and formatting in crossplane.yaml. The outdated subcommand reports dependencies
+that have newer versions available in their registries.
+
+The exact version and digest of every direct and transitive dependency is
+recorded in a crossplane.lock file next to crossplane.yaml. Subsequent runs
+resolve to the pinned versions as long as they satisfy the constraints in
+crossplane.yaml. With --frozen, resolution fails for any dependency that is not
+pinned in crossplane.lock or whose digest does not match.
+
+With --require-signature, every dependency must have been signed with the
+private key belonging to the supplied public key, e.g. by xpkg push --sign.
+Unsigned dependencies are rejected before they are added to the cache.
+
+With --cache-max-size, the least recently used packages are evicted from the
+cache once it grows beyond the given size. Use the cache command to list,
+prune and verify the cached packages.
+
+For environments without registry access, the bundle subcommand exports the
+images and cache entries of all direct and transitive dependencies to a single
+archive, which the import-bundle subcommand imports on another machine. With
+--offline, dependencies are then resolved only from imported bundles and the
+cache. The build and lint commands and the language server read dependencies
+from the cache and need no registry access.
+
+Dependencies are pulled from the registry mirrors configured in the selected
+profile, see up profile mirror. The mirrors of a registry are tried in order,
+while crossplane.yaml, crossplane.lock and the cache keep referring to the
+original registry.
 `
 }
 
-// Run executes the dep command.
-func (c *depCmd) Run(ctx context.Context, p pterm.TextPrinter, pb *pterm.BulletListPrinter) error {
+// depAddCmd adds a dependency to the package in the current directory, or
+// populates the cache with all existing dependencies if none is specified.
+type depAddCmd struct {
+	Package string `arg:"" optional:"" help:"Package to be added."`
+}
+
+// Run executes the dep add command.
+func (a *depAddCmd) Run(ctx context.Context, p pterm.TextPrinter, pb *pterm.BulletListPrinter, c *depCmd) error {
 	// no need to do anything else if clean cache was called.
 
 	// TODO (@tnthornton) this feels a little out of place here. We should
@@ -129,11 +241,11 @@ func (c *depCmd) Run(ctx context.Context, p pterm.TextPrinter, pb *pterm.BulletL
 		return nil
 	}
 
-	if c.Package != "" {
-		if err := c.userSuppliedDep(ctx); err != nil {
+	if a.Package != "" {
+		if err := c.userSuppliedDep(ctx, a.Package); err != nil {
 			return err
 		}
-		p.Printfln("%s added to xpkg cache", c.Package)
+		p.Printfln("%s added to xpkg cache", a.Package)
 		return nil
 	}
 
@@ -159,21 +271,38 @@ func (c *depCmd) Run(ctx context.Context, p pterm.TextPrinter, pb *pterm.BulletL
 	return pb.WithItems(li).Render()
 }
 
-func (c *depCmd) userSuppliedDep(ctx context.Context) error {
+func (c *depCmd) userSuppliedDep(ctx context.Context, pkg string) error {
 	// exit early check if we were supplied an invalid package string
-	_, err := xpkg.ValidDep(c.Package)
+	_, err := xpkg.ValidDep(pkg)
 	if err != nil {
 		return err
 	}
 
-	d := dep.New(c.Package)
+	d := dep.New(pkg)
+	meta := c.ws.View().Meta()
 
-	ud, _, err := c.m.AddAll(ctx, d)
-	if err != nil {
-		return errors.Wrapf(err, "in %s", c.Package)
+	// solve the graph including the existing dependencies so that the new
+	// dependency does not conflict with them.
+	roots := []v1beta1.Dependency{d}
+	if meta != nil {
+		deps, err := meta.DependsOn()
+		if err != nil {
+			return err
+		}
+		for _, ed := range deps {
+			if lock.Key(ed.Package) != lock.Key(d.Package) {
+				roots = append(roots, ed)
+			}
+		}
+	}
+	if _, err := c.m.Solve(ctx, roots); err != nil {
+		return errors.Wrapf(err, "in %s", pkg)
 	}
 
-	meta := c.ws.View().Meta()
+	ud, acc, err := c.m.AddAll(ctx, d)
+	if err != nil {
+		return errors.Wrapf(err, "in %s", pkg)
+	}
 
 	if meta != nil {
 		// crossplane.yaml file exists in the workspace, upsert the new dependency
@@ -184,6 +313,13 @@ func (c *depCmd) userSuppliedDep(ctx context.Context) error {
 		if err := c.ws.Write(meta); err != nil {
 			return err
 		}
+
+		deps, err := meta.DependsOn()
+		if err != nil {
+			return err
+		}
+
+		return c.writeLock(deps, acc)
 	}
 
 	return nil
@@ -201,14 +337,97 @@ func (c *depCmd) metaSuppliedDeps(ctx context.Context) ([]v1beta1.Dependency, er
 		return nil, err
 	}
 
+	if _, err := c.m.Solve(ctx, deps); err != nil {
+		return nil, err
+	}
+
 	resolvedDeps := make([]v1beta1.Dependency, len(deps))
+	var acc []*mxpkg.ParsedPackage
 	for i, d := range deps {
-		ud, _, err := c.m.AddAll(ctx, d)
+		ud, a, err := c.m.AddAll(ctx, d)
 		if err != nil {
 			return nil, err
 		}
 		resolvedDeps[i] = ud
+		acc = a
+	}
+
+	return resolvedDeps, c.writeLock(deps, acc)
+}
+
+// writeLock records the supplied resolved packages in the lock file, pruning
+// any entries that are no longer reachable from the supplied dependencies. The
+// lock file is left untouched when running frozen.
+func (c *depCmd) writeLock(deps []v1beta1.Dependency, acc []*mxpkg.ParsedPackage) error {
+	if c.Frozen {
+		return nil
 	}
+	c.l.Add(acc...)
+	c.l.Prune(deps)
+	return c.l.Write(c.fs, filepath.Join(c.ws.View().MetaLocation(), xpkg.LockFile))
+}
 
-	return resolvedDeps, nil
+// imageResolver returns the resolver of package images, which pulls images
+// from the supplied mirrors. Offline, images, tags and digests are served only
+// from the image store of imported bundles and the package cache at cacheDir.
+func imageResolver(offline bool, cacheDir string, c bundle.Cache, m mirror.Mirrors) *image.Resolver {
+	if !offline {
+		return image.NewResolver(image.WithFetcher(image.NewMirrorFetcher(image.NewLocalFetcher(), m)))
+	}
+	s := bundle.NewStore(filepath.Join(cacheDir, bundle.StoreDir))
+	return image.NewResolver(image.WithFetcher(bundle.NewOfflineFetcher(s, c)))
+}
+
+// profileContext returns the Upbound context of the supplied profile, or of the
+// default profile if none is supplied.
+// NOTE: commands with a --cache-dir flag cannot embed upbound.Flags, because
+// its short flag clashes with --debug. The flags are parsed from their
+// environment variables and defaults instead.
+func profileContext(profile string) (*upbound.Context, error) {
+	f := upbound.Flags{}
+	parser, err := kong.New(&f)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := parser.Parse([]string{}); err != nil {
+		return nil, err
+	}
+	if profile != "" {
+		f.Profile = profile
+	}
+	return upbound.NewFromFlags(f)
+}
+
+// mirrorVerifier verifies package signatures against the mirrors of their
+// registries. The endpoints of a mirror are tried in order until the
+// signatures of a package are verified with one of them.
+type mirrorVerifier struct {
+	v manager.Verifier
+	m mirror.Mirrors
+}
+
+func (v *mirrorVerifier) Verify(ctx context.Context, d name.Digest) error {
+	refs, err := v.m.References(d)
+	if err != nil {
+		return err
+	}
+	for _, r := range refs {
+		if err = v.v.Verify(ctx, r.(name.Digest)); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// readLock reads the lock file that lives alongside the workspace's meta
+// file. A missing lock file results in an empty lock, unless frozen is set.
+func readLock(fs afero.Fs, ws *workspace.Workspace, frozen bool) (*lock.Lock, error) {
+	l, err := lock.Read(fs, filepath.Join(ws.View().MetaLocation(), xpkg.LockFile))
+	switch {
+	case os.IsNotExist(err) && frozen:
+		return nil, errors.New(errLockFileNotFound)
+	case os.IsNotExist(err):
+		return lock.New(), nil
+	}
+	return l, err
 }
@@ -0,0 +1,206 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/xpkg/dep/bundle"
+	"github.com/upbound/up/internal/xpkg/dep/cache"
+	mxpkg "github.com/upbound/up/internal/xpkg/dep/marshaler/xpkg"
+)
+
+const (
+	errBundleDigestFmt  = "digest %s of %s does not match cached digest %s, run up xpkg dep to update the cache"
+	errImportCorruptFmt = "imported cache entry %s is corrupt"
+)
+
+// depBundleCmd exports the resolved dependencies of a package to a bundle.
+type depBundleCmd struct {
+	Output string `short:"o" default:"dependencies.tar" type:"path" help:"Path to write the bundle to."`
+}
+
+func (c *depBundleCmd) Help() string {
+	return `
+The bundle command resolves the direct and transitive dependencies of the
+package in the current directory and writes their images and cache entries to
+a single tar archive. The images are stored as an OCI image layout, so that
+they can also be copied to a private registry with standard tools.
+
+  up xpkg dep bundle -o dependencies.tar
+
+Import the bundle on a machine without registry access with up xpkg dep
+import-bundle.`
+}
+
+// Run executes the dep bundle command.
+func (c *depBundleCmd) Run(ctx context.Context, p pterm.TextPrinter, d *depCmd) error { // nolint:gocyclo
+	pkgs, err := d.resolveAll(ctx)
+	if err != nil {
+		return err
+	}
+	entries, err := d.c.List()
+	if err != nil {
+		return err
+	}
+	cached := make(map[string]cache.Entry, len(entries))
+	for _, e := range entries {
+		cached[cacheEntryKey(e)] = e
+	}
+
+	f, err := d.fs.Create(c.Output)
+	if err != nil {
+		return err
+	}
+	w := bundle.NewWriter(f)
+	err = func() error {
+		for _, pkg := range pkgs {
+			key := packageKey(pkg)
+			e, ok := cached[key]
+			if !ok {
+				return errors.Errorf(errNotCachedFmt, key)
+			}
+			ref, err := name.NewTag(pkg.Name() + ":" + pkg.Version())
+			if err != nil {
+				return err
+			}
+			_, img, err := d.r.ResolveImage(ctx, v1beta1.Dependency{Package: pkg.Name(), Constraints: pkg.Version()})
+			if err != nil {
+				return err
+			}
+			digest, err := img.Digest()
+			if err != nil {
+				return err
+			}
+			if digest.String() != pkg.Digest() {
+				return errors.Errorf(errBundleDigestFmt, digest, key, pkg.Digest())
+			}
+			if err := w.AddImage(ref, img); err != nil {
+				return err
+			}
+			if err := w.AddCacheEntry(d.fs, d.CacheDir, e.Path()); err != nil {
+				return err
+			}
+			p.Printfln("Added %s", key)
+		}
+		return w.Close()
+	}()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = d.fs.Remove(c.Output)
+		return err
+	}
+	p.Printfln("Wrote %d package(s) to %s", len(pkgs), c.Output)
+	return nil
+}
+
+// resolveAll resolves the dependencies of the package in the current
+// directory and adds them to the cache. It returns the resolved direct and
+// transitive dependencies, sorted by name.
+func (c *depCmd) resolveAll(ctx context.Context) ([]*mxpkg.ParsedPackage, error) {
+	meta := c.ws.View().Meta()
+	if meta == nil {
+		return nil, errors.New(errMetaFileNotFound)
+	}
+	deps, err := meta.DependsOn()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.m.Solve(ctx, deps); err != nil {
+		return nil, err
+	}
+	resolved := map[string]*mxpkg.ParsedPackage{}
+	for _, d := range deps {
+		_, acc, err := c.m.AddAll(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range acc {
+			resolved[packageKey(p)] = p
+		}
+	}
+	pkgs := make([]*mxpkg.ParsedPackage, 0, len(resolved))
+	for _, p := range resolved {
+		pkgs = append(pkgs, p)
+	}
+	sort.Slice(pkgs, func(i, j int) bool {
+		return packageKey(pkgs[i]) < packageKey(pkgs[j])
+	})
+	return pkgs, nil
+}
+
+// depImportBundleCmd imports a bundle of dependencies.
+type depImportBundleCmd struct {
+	Bundle string `arg:"" type:"existingfile" help:"Path to the bundle to import."`
+}
+
+func (c *depImportBundleCmd) Help() string {
+	return `
+The import-bundle command imports a bundle written by up xpkg dep bundle into
+the cache, replacing cached packages of the same versions. Afterwards, the
+dependencies in the bundle can be resolved without registry access by passing
+--offline to up xpkg dep.
+
+  up xpkg dep import-bundle dependencies.tar
+  up xpkg dep --offline --frozen`
+}
+
+// Run executes the dep import-bundle command.
+func (c *depImportBundleCmd) Run(p pterm.TextPrinter, d *depCmd) error {
+	f, err := d.fs.Open(c.Bundle)
+	if err != nil {
+		return err
+	}
+	defer f.Close() // nolint:errcheck
+
+	s := bundle.NewStore(filepath.Join(d.CacheDir, bundle.StoreDir))
+	imported, err := bundle.Import(f, s, d.fs, d.CacheDir)
+	if err != nil {
+		return err
+	}
+	entries, err := d.c.List()
+	if err != nil {
+		return err
+	}
+	paths := make(map[string]bool, len(imported))
+	for _, i := range imported {
+		paths[i] = true
+	}
+	for _, e := range entries {
+		if !paths[e.Path()] {
+			continue
+		}
+		if err := d.c.Verify(e); err != nil {
+			return errors.Wrapf(err, errImportCorruptFmt, cacheEntryKey(e))
+		}
+		p.Printfln("Imported %s", cacheEntryKey(e))
+	}
+	return nil
+}
+
+// packageKey returns the name and version of the supplied package, as used in
+// crossplane.lock.
+func packageKey(p *mxpkg.ParsedPackage) string {
+	return cacheEntryKey(cache.Entry{Package: p.Name(), Version: p.Version()})
+}
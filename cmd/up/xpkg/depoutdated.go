@@ -0,0 +1,115 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/upterm"
+	"github.com/upbound/up/internal/xpkg/dep/manager"
+)
+
+const (
+	failOnNone  = "none"
+	failOnMajor = "major"
+	failOnMinor = "minor"
+	failOnPatch = "patch"
+
+	errOutdatedFmt = "%d dependencies are behind the latest available version by at least one %s version"
+)
+
+var outdatedFieldNames = []string{"PACKAGE", "DIRECT", "CONSTRAINT", "CURRENT", "WANTED", "LATEST"}
+
+// depOutdatedCmd reports dependencies that have newer versions available.
+type depOutdatedCmd struct {
+	All    bool   `help:"Include dependencies that are up to date."`
+	FailOn string `enum:"none,major,minor,patch" default:"major" help:"Exit with a non-zero code if a dependency is behind the latest version by at least the given increment. One of: none, major, minor, patch."`
+}
+
+func (c *depOutdatedCmd) Help() string {
+	return `
+The outdated command compares the dependencies of the package in the current
+directory, including transitive dependencies, with the versions available in
+their registries. For every package it shows the constraint imposed on it, the
+currently resolved version, the latest version satisfying the constraint
+(wanted) and the latest version available overall.
+
+The output format is controlled by the global --format flag. By default, the
+command exits with a non-zero code if any dependency is behind the latest
+version by a major version, which makes it suitable for use in CI. Use
+--fail-on to change or disable this behavior.`
+}
+
+// Run executes the dep outdated command.
+func (c *depOutdatedCmd) Run(ctx context.Context, printer upterm.ObjectPrinter, p pterm.TextPrinter, d *depCmd) error {
+	nodes, err := d.graph(ctx)
+	if err != nil {
+		return err
+	}
+
+	pkgs, err := d.m.Outdated(ctx, nodes)
+	if err != nil {
+		return err
+	}
+
+	report := make([]manager.OutdatedPackage, 0, len(pkgs))
+	failing := 0
+	for _, o := range pkgs {
+		b := o.Behind()
+		if b == manager.BumpNone && o.Wanted == o.Current && !c.All {
+			continue
+		}
+		report = append(report, o)
+		if c.fails(b) {
+			failing++
+		}
+	}
+
+	if len(report) == 0 {
+		p.Printfln("All dependencies are up to date")
+		return nil
+	}
+	if err := printer.Print(report, outdatedFieldNames, extractOutdatedFields); err != nil {
+		return err
+	}
+	if failing > 0 {
+		return errors.Errorf(errOutdatedFmt, failing, c.FailOn)
+	}
+	return nil
+}
+
+// fails returns true if a dependency that is behind by the supplied increment
+// should result in a non-zero exit code.
+func (c *depOutdatedCmd) fails(b manager.BumpPolicy) bool {
+	switch c.FailOn {
+	case failOnMajor:
+		return b == manager.BumpMajor
+	case failOnMinor:
+		return b == manager.BumpMajor || b == manager.BumpMinor
+	case failOnPatch:
+		return b != manager.BumpNone
+	case failOnNone:
+	}
+	return false
+}
+
+func extractOutdatedFields(obj any) []string {
+	o := obj.(manager.OutdatedPackage)
+	return []string{o.Package, strconv.FormatBool(o.Direct), o.Constraint, o.Current, o.Wanted, o.Latest}
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/xpkg"
+	"github.com/upbound/up/internal/xpkg/dep/lock"
+	"github.com/upbound/up/internal/xpkg/workspace/meta"
+)
+
+const (
+	errRemoveFrozen       = "dependencies cannot be removed with --frozen"
+	errRemoveDependency   = "failed to remove dependency from crossplane.yaml"
+	errPruneCacheEntryFmt = "failed to remove %s@%s from cache"
+)
+
+// depRemoveCmd removes a dependency from the package in the current
+// directory.
+type depRemoveCmd struct {
+	Package string `arg:"" help:"Package to remove, e.g. xpkg.upbound.io/upbound/provider-aws-ec2."`
+}
+
+func (c *depRemoveCmd) Help() string {
+	return `
+The remove command removes a dependency from the crossplane.yaml file in the
+current directory while preserving its comments and formatting. Packages that
+are no longer reachable from the remaining dependencies are removed from
+crossplane.lock and from the local package cache.
+
+Note that the cache is shared between packages, so a pruned package is
+fetched again the next time another package depends on it.`
+}
+
+// Run executes the dep remove command.
+func (c *depRemoveCmd) Run(ctx context.Context, p pterm.TextPrinter, d *depCmd) error {
+	if d.Frozen {
+		return errors.New(errRemoveFrozen)
+	}
+	m := d.ws.View().Meta()
+	if m == nil {
+		return errors.New(errMetaFileNotFound)
+	}
+	deps, err := m.DependsOn()
+	if err != nil {
+		return err
+	}
+
+	remaining := make([]v1beta1.Dependency, 0, len(deps))
+	for _, dep := range deps {
+		if lock.Key(dep.Package) != lock.Key(c.Package) {
+			remaining = append(remaining, dep)
+		}
+	}
+	if len(remaining) == len(deps) {
+		return errors.Errorf(errDependencyNotFound, c.Package)
+	}
+
+	b, err := d.ws.ReadMetaFile()
+	if err != nil {
+		return errors.Wrap(err, errReadMetaFile)
+	}
+	b, err = meta.RemoveDependency(b, c.Package)
+	if err != nil {
+		return errors.Wrap(err, errRemoveDependency)
+	}
+	if err := d.ws.WriteMetaFile(b); err != nil {
+		return errors.Wrap(err, errWriteMetaFile)
+	}
+	p.Printfln("%s removed from %s", c.Package, xpkg.MetaFile)
+
+	pruned := d.l.Prune(remaining)
+	if err := d.l.Write(d.fs, filepath.Join(d.ws.View().MetaLocation(), xpkg.LockFile)); err != nil {
+		return err
+	}
+
+	for _, lp := range pruned {
+		err := d.c.Delete(v1beta1.Dependency{
+			Package:     lp.Name,
+			Type:        lp.Type,
+			Constraints: lp.Version,
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, errPruneCacheEntryFmt, lp.Name, lp.Version)
+		}
+		p.Printfln("%s@%s removed from xpkg cache", lp.Name, lp.Version)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/xpkg/dep/manager"
+)
+
+const (
+	outputTree = "tree"
+	outputJSON = "json"
+	outputDOT  = "dot"
+
+	shortDigestLen = 19 // sha256: + 12 characters
+
+	errBuildGraph = "failed to build dependency graph, try running up xpkg dep first"
+)
+
+// depTreeCmd prints the resolved dependency graph.
+type depTreeCmd struct {
+	w io.Writer
+
+	Output string `short:"o" enum:"tree,json,dot" default:"tree" help:"Output format. One of: tree, json, dot."`
+}
+
+func (c *depTreeCmd) Help() string {
+	return `
+The tree command prints the resolved dependency graph of the package in the
+current directory. For every dependency it shows the package, the version
+constraint it was required with, the version that constraint resolved to, its
+digest and its package type.
+
+Dependencies are resolved from the local cache, so up xpkg dep should be run
+first to populate it. The graph can be printed as a tree, as JSON, or in the
+Graphviz DOT format, e.g.:
+
+  up xpkg dep tree -o dot | dot -Tsvg > deps.svg`
+}
+
+// AfterApply sets up the output writer for the tree command.
+func (c *depTreeCmd) AfterApply(kongCtx *kong.Context) error {
+	c.w = kongCtx.Stdout
+	return nil
+}
+
+// Run executes the dep tree command.
+func (c *depTreeCmd) Run(ctx context.Context, d *depCmd) error {
+	nodes, err := d.graph(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch c.Output {
+	case outputJSON:
+		b, err := json.MarshalIndent(nodes, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(c.w, string(b))
+		return err
+	case outputDOT:
+		_, err := io.WriteString(c.w, renderDOT(nodes))
+		return err
+	default:
+		return pterm.DefaultTree.WithWriter(c.w).WithRoot(pterm.TreeNode{
+			Text:     ".",
+			Children: treeNodes(nodes),
+		}).Render()
+	}
+}
+
+// graph builds the dependency graph of the package in the workspace.
+func (c *depCmd) graph(ctx context.Context) ([]*manager.Node, error) {
+	meta := c.ws.View().Meta()
+	if meta == nil {
+		return nil, errors.New(errMetaFileNotFound)
+	}
+
+	deps, err := meta.DependsOn()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := c.m.Graph(ctx, deps)
+	return nodes, errors.Wrap(err, errBuildGraph)
+}
+
+func treeNodes(nodes []*manager.Node) []pterm.TreeNode {
+	tn := make([]pterm.TreeNode, len(nodes))
+	for i, n := range nodes {
+		tn[i] = pterm.TreeNode{
+			Text:     nodeText(n),
+			Children: treeNodes(n.Dependencies),
+		}
+	}
+	return tn
+}
+
+func nodeText(n *manager.Node) string {
+	return fmt.Sprintf("%s@%s (%s) %s %s", n.Package, n.Version, n.Constraint, n.Type, shortDigest(n.Digest))
+}
+
+func shortDigest(d string) string {
+	if len(d) > shortDigestLen {
+		return d[:shortDigestLen]
+	}
+	return d
+}
+
+// renderDOT renders the supplied graph in the Graphviz DOT format. Each
+// resolved package version is a single vertex, and edges are labeled with the
+// constraint the dependant declared.
+func renderDOT(nodes []*manager.Node) string {
+	b := &strings.Builder{}
+	b.WriteString("digraph dependencies {\n")
+	b.WriteString("  node [shape=box];\n")
+
+	seen := map[string]bool{}
+	edges := map[string]bool{}
+
+	var walk func(parent string, n *manager.Node)
+	walk = func(parent string, n *manager.Node) {
+		id := fmt.Sprintf("%s@%s", n.Package, n.Version)
+		if !seen[id] {
+			seen[id] = true
+			fmt.Fprintf(b, "  %q [label=%q];\n", id, fmt.Sprintf("%s\n%s\n%s\n%s", n.Package, n.Version, n.Type, shortDigest(n.Digest)))
+		}
+		if parent != "" {
+			e := fmt.Sprintf("  %q -> %q [label=%q];\n", parent, id, n.Constraint)
+			if !edges[e] {
+				edges[e] = true
+				b.WriteString(e)
+			}
+		}
+		for _, d := range n.Dependencies {
+			walk(id, d)
+		}
+	}
+	for _, n := range nodes {
+		walk("", n)
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
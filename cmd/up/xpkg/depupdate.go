@@ -0,0 +1,222 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/diff"
+	"github.com/upbound/up/internal/xpkg"
+	"github.com/upbound/up/internal/xpkg/dep/lock"
+	"github.com/upbound/up/internal/xpkg/dep/manager"
+	mxpkg "github.com/upbound/up/internal/xpkg/dep/marshaler/xpkg"
+	"github.com/upbound/up/internal/xpkg/workspace/meta"
+)
+
+const (
+	errUpdateFrozen        = "dependencies cannot be updated with --frozen"
+	errDependencyNotFound  = "%s is not a dependency in crossplane.yaml"
+	errReadMetaFile        = "failed to read crossplane.yaml"
+	errWriteMetaFile       = "failed to write crossplane.yaml"
+	errResolveVersionsFmt  = "failed to resolve versions of %s"
+	errUpdateDependencyFmt = "failed to update %s"
+)
+
+// depUpdateCmd updates dependencies of the package in the current directory.
+type depUpdateCmd struct {
+	Package string `arg:"" optional:"" help:"Package to update. All dependencies are updated if omitted."`
+
+	Major  bool `xor:"bump" help:"Allow updating to a newer major version, rewriting the constraint in crossplane.yaml."`
+	Minor  bool `xor:"bump" help:"Allow updating to a newer minor version, rewriting the constraint in crossplane.yaml."`
+	Patch  bool `xor:"bump" help:"Allow updating to a newer patch version, rewriting the constraint in crossplane.yaml."`
+	DryRun bool `help:"Print the changes to crossplane.yaml and crossplane.lock instead of writing them."`
+}
+
+func (c *depUpdateCmd) Help() string {
+	return `
+The update command re-resolves the dependencies of the package in the current
+directory to the latest versions that satisfy their constraints and records
+them in crossplane.lock. If a package is given, only that dependency is
+updated; other pinned versions are kept where possible.
+
+With --major, --minor or --patch the constraint in crossplane.yaml is rewritten
+to the latest version allowed by the respective bump policy. Comments and
+formatting in crossplane.yaml are preserved.
+
+With --dry-run a diff of crossplane.yaml and crossplane.lock is printed and
+nothing is written, nor are any packages added to the cache.`
+}
+
+func (c *depUpdateCmd) policy() manager.BumpPolicy {
+	switch {
+	case c.Major:
+		return manager.BumpMajor
+	case c.Minor:
+		return manager.BumpMinor
+	case c.Patch:
+		return manager.BumpPatch
+	}
+	return manager.BumpNone
+}
+
+// Run executes the dep update command.
+func (c *depUpdateCmd) Run(ctx context.Context, p pterm.TextPrinter, d *depCmd) error { //nolint:gocyclo
+	if d.Frozen {
+		return errors.New(errUpdateFrozen)
+	}
+	m := d.ws.View().Meta()
+	if m == nil {
+		return errors.New(errMetaFileNotFound)
+	}
+	deps, err := m.DependsOn()
+	if err != nil {
+		return err
+	}
+
+	targets := make([]int, 0, len(deps))
+	for i, dep := range deps {
+		if c.Package == "" || lock.Key(dep.Package) == lock.Key(c.Package) {
+			targets = append(targets, i)
+		}
+	}
+	if c.Package != "" && len(targets) == 0 {
+		return errors.Errorf(errDependencyNotFound, c.Package)
+	}
+
+	rawMeta, err := d.ws.ReadMetaFile()
+	if err != nil {
+		return errors.Wrap(err, errReadMetaFile)
+	}
+	oldMeta := rawMeta
+	oldLock, err := d.l.Bytes()
+	if err != nil {
+		return err
+	}
+	before := lockedVersions(d.l)
+
+	if pol := c.policy(); pol != manager.BumpNone {
+		for _, i := range targets {
+			nc, changed, err := c.bump(ctx, d, deps[i], pol)
+			if err != nil {
+				return err
+			}
+			if !changed {
+				continue
+			}
+			rawMeta, err = meta.SetDependencyVersion(rawMeta, deps[i].Package, nc)
+			if err != nil {
+				return errors.Wrapf(err, errUpdateDependencyFmt, deps[i].Package)
+			}
+			deps[i].Constraints = nc
+		}
+	}
+
+	// drop the targets from the lock so that they are re-resolved to the
+	// latest matching version.
+	if c.Package == "" {
+		d.l.Packages = nil
+	} else {
+		d.l.Remove(c.Package)
+	}
+
+	// a dry run resolves versions without adding packages to the cache.
+	solve := d.m.Solve
+	if c.DryRun {
+		solve = d.m.DrySolve
+	}
+	solved, err := solve(ctx, deps)
+	if err != nil {
+		return err
+	}
+	pkgs := make([]*mxpkg.ParsedPackage, 0, len(solved))
+	for _, sp := range solved {
+		pkgs = append(pkgs, sp)
+	}
+	d.l.Add(pkgs...)
+	d.l.Prune(deps)
+
+	newLock, err := d.l.Bytes()
+	if err != nil {
+		return err
+	}
+
+	if c.DryRun {
+		p.Print(diff.Unified("a/"+xpkg.MetaFile, "b/"+xpkg.MetaFile, oldMeta, rawMeta))
+		p.Print(diff.Unified("a/"+xpkg.LockFile, "b/"+xpkg.LockFile, oldLock, newLock))
+		return nil
+	}
+
+	if err := d.ws.WriteMetaFile(rawMeta); err != nil {
+		return errors.Wrap(err, errWriteMetaFile)
+	}
+	if err := d.l.Write(d.fs, filepath.Join(d.ws.View().MetaLocation(), xpkg.LockFile)); err != nil {
+		return err
+	}
+
+	updated := 0
+	for _, lp := range d.l.Packages {
+		if old, ok := before[lp.Name]; ok && old != lp.Version {
+			p.Printfln("%s: %s -> %s", lp.Name, old, lp.Version)
+			updated++
+		}
+	}
+	if updated == 0 {
+		p.Printfln("Dependencies are up to date")
+	}
+	return nil
+}
+
+// bump returns the constraint for the supplied dependency after applying the
+// supplied bump policy, and whether it differs from the current one.
+func (c *depUpdateCmd) bump(ctx context.Context, d *depCmd, dep v1beta1.Dependency, pol manager.BumpPolicy) (string, bool, error) {
+	vers, err := d.m.RemoteVersions(ctx, dep)
+	if err != nil {
+		return "", false, errors.Wrapf(err, errResolveVersionsFmt, dep.Package)
+	}
+
+	// bump relative to the version we are on today.
+	cur, ok := "", false
+	if lp, found := d.l.Get(dep.Package); found {
+		cur, ok = lp.Version, true
+	}
+	if !ok {
+		cur, ok = manager.HighestMatching(vers, []manager.Constraint{{Constraint: dep.Constraints}})
+	}
+	if !ok {
+		return "", false, nil
+	}
+
+	v, ok := manager.Latest(vers, cur, pol)
+	if !ok || v == cur {
+		return "", false, nil
+	}
+	nc := manager.BumpConstraint(dep.Constraints, v)
+	return nc, nc != dep.Constraints, nil
+}
+
+// lockedVersions returns the versions pinned in the supplied lock, keyed by
+// package name.
+func lockedVersions(l *lock.Lock) map[string]string {
+	vers := make(map[string]string, len(l.Packages))
+	for _, p := range l.Packages {
+		vers[p.Name] = p.Version
+	}
+	return vers
+}
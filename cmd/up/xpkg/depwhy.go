@@ -0,0 +1,64 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/xpkg/dep/manager"
+)
+
+// depWhyCmd prints every path through which a package is depended on.
+type depWhyCmd struct {
+	Package string `arg:"" help:"Package to explain, e.g. xpkg.upbound.io/upbound/provider-aws-ec2 or provider-aws-ec2."`
+}
+
+func (c *depWhyCmd) Help() string {
+	return `
+The why command prints every path from the dependencies declared in the
+crossplane.yaml file in the current directory to the given package. Use it to
+understand why a package is pulled in as a transitive dependency.`
+}
+
+// Run executes the dep why command.
+func (c *depWhyCmd) Run(ctx context.Context, p pterm.TextPrinter, d *depCmd) error {
+	nodes, err := d.graph(ctx)
+	if err != nil {
+		return err
+	}
+
+	paths := manager.Paths(nodes, c.Package)
+	if len(paths) == 0 {
+		p.Printfln("%s is not a dependency of this package", c.Package)
+		return nil
+	}
+
+	for _, path := range paths {
+		p.Println(pathText(path))
+	}
+	return nil
+}
+
+func pathText(path []*manager.Node) string {
+	parts := make([]string, len(path))
+	for i, n := range path {
+		parts[i] = fmt.Sprintf("%s@%s (%s)", n.Package, n.Version, n.Constraint)
+	}
+	return strings.Join(parts, " -> ")
+}
@@ -0,0 +1,161 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"context"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/upbound"
+	"github.com/upbound/up/internal/upterm"
+	"github.com/upbound/up/internal/xpkg"
+	mxpkg "github.com/upbound/up/internal/xpkg/dep/marshaler/xpkg"
+	"github.com/upbound/up/internal/xpkg/schemadiff"
+)
+
+const (
+	errFetchPackageFmt = "failed to fetch package %s"
+	errParsePackageFmt = "failed to parse package %s"
+	errCompareSchemas  = "failed to compare package APIs"
+	errBreakingFmt     = "found %d breaking changes"
+)
+
+// AfterApply constructs and binds Upbound-specific context to any subcommands
+// that have Run() methods that receive it.
+func (c *diffCmd) AfterApply() error {
+	c.fs = afero.NewOsFs()
+	return nil
+}
+
+// diffCmd compares the APIs of two versions of a package.
+type diffCmd struct {
+	fs afero.Fs
+
+	Old              string `arg:"" help:"Reference of the old package, or path to an xpkg file."`
+	New              string `arg:"" help:"Reference of the new package, or path to an xpkg file."`
+	FromDaemon       bool   `help:"Indicates that the images should be fetched from the Docker daemon."`
+	FailOnBreaking   bool   `help:"Exit with a non-zero code if any breaking change is found."`
+	IncludeAdditions bool   `help:"Include non-breaking field additions in the human readable output."`
+	Platform         string `help:"Platform of the images to compare if the packages were built for multiple platforms, in os/arch[/variant] form." default:"linux/amd64"`
+
+	// Common Upbound API configuration
+	Flags upbound.Flags `embed:""`
+}
+
+func (c *diffCmd) Help() string {
+	return `
+The diff command compares the APIs defined by two versions of a package, i.e.
+the schemas of the CRDs and XRDs they contain. APIs are matched by group and
+kind, and the command reports added and removed kinds, added, removed and no
+longer served versions, and changes to the OpenAPI schema of every version.
+
+Changes that may break existing users of an API are flagged as breaking:
+removed kinds, removed served versions, removed fields, type changes, new
+required fields and narrowed enums. Use --fail-on-breaking to gate upgrades in
+CI:
+
+  up xpkg diff xpkg.upbound.io/upbound/provider-aws-s3:v0.47.0 \
+    xpkg.upbound.io/upbound/provider-aws-s3:v1.0.0 --fail-on-breaking
+
+Packages are fetched from a registry by default, or from the Docker daemon
+with --from-daemon. Paths of xpkg files are read from the filesystem. The
+output format is controlled by the global --format flag; JSON and YAML output
+include every change.`
+}
+
+// Run executes the diff command.
+func (c *diffCmd) Run(ctx context.Context, printer upterm.ObjectPrinter, p pterm.TextPrinter) error {
+	m, err := mxpkg.NewMarshaler()
+	if err != nil {
+		return err
+	}
+	oldPkg, err := c.load(ctx, m, c.Old)
+	if err != nil {
+		return err
+	}
+	newPkg, err := c.load(ctx, m, c.New)
+	if err != nil {
+		return err
+	}
+
+	changes, err := schemadiff.Compare(oldPkg.Objects(), newPkg.Objects())
+	if err != nil {
+		return errors.Wrap(err, errCompareSchemas)
+	}
+	r := schemadiff.NewReport(c.Old, c.New, changes)
+
+	if printer.Format != config.Default {
+		if err := printer.Print(r, nil, nil); err != nil {
+			return err
+		}
+	} else {
+		c.printReport(p, r)
+	}
+	if c.FailOnBreaking && r.Breaking > 0 {
+		return errors.Errorf(errBreakingFmt, r.Breaking)
+	}
+	return nil
+}
+
+// load fetches and parses the supplied package.
+func (c *diffCmd) load(ctx context.Context, m *mxpkg.Marshaler, pkg string) (*mxpkg.ParsedPackage, error) {
+	fetch, ref, err := packageFetch(c.fs, pkg, c.FromDaemon, c.Platform, c.Flags)
+	if err != nil {
+		return nil, err
+	}
+	img, err := fetch(ctx, ref)
+	if err != nil {
+		return nil, errors.Wrapf(err, errFetchPackageFmt, pkg)
+	}
+	parsed, err := m.FromImage(xpkg.Image{Image: img})
+	return parsed, errors.Wrapf(err, errParsePackageFmt, pkg)
+}
+
+// printReport prints the report as human readable text. Breaking changes are
+// prefixed with "!", other changes with "~" or "+".
+func (c *diffCmd) printReport(p pterm.TextPrinter, r *schemadiff.Report) {
+	shown := 0
+	for _, ch := range r.Changes {
+		if ch.Type == schemadiff.FieldAdded && !ch.Breaking && !c.IncludeAdditions {
+			continue
+		}
+		prefix := "~"
+		switch {
+		case ch.Breaking:
+			prefix = "!"
+		case ch.Type == schemadiff.KindAdded, ch.Type == schemadiff.VersionAdded, ch.Type == schemadiff.FieldAdded:
+			prefix = "+"
+		}
+		loc := []string{ch.Kind}
+		if ch.Version != "" {
+			loc = append(loc, ch.Version)
+		}
+		if ch.Field != "" {
+			loc = append(loc, ch.Field)
+		}
+		p.Printfln("%s %s: %s", prefix, strings.Join(loc, " "), ch.Message)
+		shown++
+	}
+	if shown == 0 {
+		p.Printfln("No API changes between %s and %s", r.Old, r.New)
+		return
+	}
+	p.Printfln("%d changes, %d breaking", len(r.Changes), r.Breaking)
+}
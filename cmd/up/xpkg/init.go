@@ -17,12 +17,14 @@ package xpkg
 import (
 	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	v1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
 	"github.com/pterm/pterm"
 	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/upbound/up/internal/input"
 	"github.com/upbound/up/internal/xpkg"
@@ -31,7 +33,13 @@ import (
 
 const (
 	errAlreadyExistsFmt   = "directory contains pre-existing meta file: %s"
-	errInvalidPackageType = "the provided package type %q is invalid; valid types: configuration,provider"
+	errInvalidPackageType = "the provided package type %q is invalid; valid types: configuration,provider,function"
+
+	errInvalidFunctionNameFmt = "the provided function name %q is invalid: %s"
+
+	functionInputDir   = "input"
+	functionExampleDir = "examples"
+	functionExample    = "composition.yaml"
 )
 
 // BeforeApply sets default values in init before assignment and validation.
@@ -74,6 +82,10 @@ func (c *initCmd) AfterApply() error {
 		if err := c.initProviderPkg(); err != nil {
 			return err
 		}
+	case string(xpkg.Function):
+		if err := c.initFunctionPkg(); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -87,7 +99,18 @@ type initCmd struct {
 	root     string
 
 	PackageRoot string `optional:"" short:"p" help:"Path to directory to write new package." default:"."`
-	Type        string `optional:"" short:"t" help:"Type of package to be initialized." default:"configuration" enum:"configuration,provider"`
+	Type        string `optional:"" short:"t" help:"Type of package to be initialized." default:"configuration" enum:"configuration,provider,function"`
+}
+
+func (c *initCmd) Help() string {
+	return `
+The init command initializes a new package by writing a crossplane.yaml file
+to the package root after prompting for the package details.
+
+For function packages, a CustomResourceDefinition for the function's input is
+written to the input directory and an example Composition that runs the
+function is written to the examples directory. The function's runtime image is
+embedded when building the package with up xpkg build --controller.`
 }
 
 // Run executes the init command.
@@ -106,6 +129,11 @@ func (c *initCmd) Run(p pterm.TextPrinter) error {
 		if err != nil {
 			return err
 		}
+	case string(xpkg.Function):
+		fileBody, err = meta.NewFunctionXPkg(c.ctx)
+		if err != nil {
+			return err
+		}
 	}
 
 	writer := xpkg.NewFileWriter(
@@ -119,10 +147,35 @@ func (c *initCmd) Run(p pterm.TextPrinter) error {
 		return err
 	}
 
+	if c.Type == string(xpkg.Function) {
+		if err := c.writeFunctionFiles(writer); err != nil {
+			return err
+		}
+	}
+
 	p.Printfln("xpkg initialized at %s", path.Join(c.root, xpkg.MetaFile))
 	return nil
 }
 
+// writeFunctionFiles scaffolds the input CustomResourceDefinition and an
+// example Composition for a function package.
+func (c *initCmd) writeFunctionFiles(writer *xpkg.Writer) error {
+	crd, err := meta.NewFunctionInputCRD(c.ctx)
+	if err != nil {
+		return err
+	}
+	group := meta.FunctionInputGroup(c.ctx.Name)
+	if err := writer.NewFile(filepath.Join(functionInputDir, group+"_inputs.yaml"), crd); err != nil {
+		return err
+	}
+
+	ex, err := meta.NewFunctionExample(c.ctx)
+	if err != nil {
+		return err
+	}
+	return writer.NewFile(filepath.Join(functionExampleDir, functionExample), ex)
+}
+
 func (c *initCmd) initCommon() error {
 	name, err := c.prompter.Prompt("Package name", false)
 	if err != nil {
@@ -196,6 +249,15 @@ func (c *initCmd) initProviderPkg() error {
 	return nil
 }
 
+func (c *initCmd) initFunctionPkg() error {
+	// function names are used to derive the input API group, make sure they
+	// are valid DNS labels.
+	if errs := validation.IsDNS1123Label(c.ctx.Name); len(errs) > 0 {
+		return errors.Errorf(errInvalidFunctionNameFmt, c.ctx.Name, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
 func (c *initCmd) metaFileInRoot() error {
 	// validate if current directory does not contain crossplane.yaml
 	exists, err := afero.Exists(c.fs, filepath.Join(c.root, xpkg.MetaFile))
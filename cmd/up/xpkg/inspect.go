@@ -0,0 +1,165 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/upbound"
+	"github.com/upbound/up/internal/upterm"
+	"github.com/upbound/up/internal/xpkg/inspect"
+)
+
+const (
+	errInspectPackage = "failed to inspect package"
+)
+
+var (
+	inspectMetaFieldNames   = []string{"KIND", "NAME", "VERSION", "CROSSPLANE", "EXAMPLES", "DIGEST"}
+	inspectDepFieldNames    = []string{"PACKAGE", "TYPE", "CONSTRAINTS"}
+	inspectObjectFieldNames = []string{"API VERSION", "KIND", "COUNT"}
+	inspectLayerFieldNames  = []string{"DIGEST", "MEDIA TYPE", "SIZE", "ANNOTATION"}
+	inspectConfigFieldNames = []string{"PLATFORM", "USER", "ENTRYPOINT", "CMD", "PORTS"}
+)
+
+// AfterApply constructs and binds Upbound-specific context to any subcommands
+// that have Run() methods that receive it.
+func (c *inspectCmd) AfterApply() error {
+	c.fs = afero.NewOsFs()
+	fetch, ref, err := packageFetch(c.fs, c.Package, c.FromDaemon, c.Platform, c.Flags)
+	if err != nil {
+		return err
+	}
+	c.fetch, c.name = fetch, ref
+	return nil
+}
+
+// inspectCmd summarizes the contents of a package.
+type inspectCmd struct {
+	fs    afero.Fs
+	name  name.Reference
+	fetch fetchFn
+
+	Package    string `arg:"" help:"Reference of the package to inspect, or path to an xpkg file."`
+	FromDaemon bool   `help:"Indicates that the image should be fetched from the Docker daemon."`
+	Platform   string `help:"Platform of the image to inspect if the package was built for multiple platforms, in os/arch[/variant] form." default:"linux/amd64"`
+
+	// Common Upbound API configuration
+	Flags upbound.Flags `embed:""`
+}
+
+func (c *inspectCmd) Help() string {
+	return `
+The inspect command prints a summary of the contents of a package: the kind,
+name and version of the package, its Crossplane version constraint and
+dependencies, the CRDs, XRDs and Compositions it contains, whether it contains
+examples, the digests and annotations of its layers, and the runtime
+configuration of the controller image it was built on.
+
+The package is fetched from a registry by default, or from the Docker daemon
+with --from-daemon. If the argument is the path of an xpkg file, the package is
+read from that file:
+
+  up xpkg inspect xpkg.upbound.io/upbound/provider-aws-s3:v1.0.0
+  up xpkg inspect ./provider-foo-abc123.xpkg
+
+If the package was built for multiple platforms, the image for --platform is
+inspected.
+
+The output format is controlled by the global --format flag. JSON and YAML
+output include the names of all objects in the package.`
+}
+
+// Run executes the inspect command.
+func (c *inspectCmd) Run(ctx context.Context, printer upterm.ObjectPrinter, p pterm.TextPrinter) error {
+	img, err := c.fetch(ctx, c.name)
+	if err != nil {
+		return errors.Wrap(err, errFetchPackage)
+	}
+	i, err := inspect.NewInspector()
+	if err != nil {
+		return err
+	}
+	s, err := i.Inspect(img)
+	if err != nil {
+		return errors.Wrap(err, errInspectPackage)
+	}
+	if t, ok := c.name.(name.Tag); ok {
+		s.Meta.Version = t.TagStr()
+	}
+
+	if printer.Format != config.Default {
+		return printer.Print(s, nil, nil)
+	}
+	return printSummary(printer, p, s)
+}
+
+// printSummary prints the sections of the summary as tables.
+func printSummary(printer upterm.ObjectPrinter, p pterm.TextPrinter, s *inspect.Summary) error {
+	if err := printer.Print(*s, inspectMetaFieldNames, extractInspectMetaFields); err != nil {
+		return err
+	}
+	if len(s.Dependencies) > 0 {
+		p.Println()
+		if err := printer.Print(s.Dependencies, inspectDepFieldNames, extractInspectDepFields); err != nil {
+			return err
+		}
+	}
+	if len(s.Objects) > 0 {
+		p.Println()
+		if err := printer.Print(s.Objects, inspectObjectFieldNames, extractInspectObjectFields); err != nil {
+			return err
+		}
+	}
+	p.Println()
+	if err := printer.Print(s.Layers, inspectLayerFieldNames, extractInspectLayerFields); err != nil {
+		return err
+	}
+	p.Println()
+	return printer.Print(s.Config, inspectConfigFieldNames, extractInspectConfigFields)
+}
+
+func extractInspectMetaFields(obj any) []string {
+	s := obj.(inspect.Summary)
+	return []string{s.Meta.Kind, s.Meta.Name, s.Meta.Version, s.Crossplane, strconv.FormatBool(s.Examples), s.Digest}
+}
+
+func extractInspectDepFields(obj any) []string {
+	d := obj.(inspect.Dependency)
+	return []string{d.Package, d.Type, d.Constraints}
+}
+
+func extractInspectObjectFields(obj any) []string {
+	o := obj.(inspect.ObjectGroup)
+	return []string{o.APIVersion, o.Kind, strconv.Itoa(o.Count)}
+}
+
+func extractInspectLayerFields(obj any) []string {
+	l := obj.(inspect.Layer)
+	return []string{l.Digest, l.MediaType, strconv.FormatInt(l.Size, 10), l.Annotation}
+}
+
+func extractInspectConfigFields(obj any) []string {
+	c := obj.(inspect.Config)
+	return []string{c.Platform, c.User, strings.Join(c.Entrypoint, " "), strings.Join(c.Cmd, " "), strings.Join(c.ExposedPorts, ",")}
+}
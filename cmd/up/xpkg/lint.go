@@ -0,0 +1,169 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/alecthomas/kong"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/upterm"
+	"github.com/upbound/up/internal/version"
+	"github.com/upbound/up/internal/xpkg"
+	"github.com/upbound/up/internal/xpkg/dep/cache"
+	"github.com/upbound/up/internal/xpkg/dep/lock"
+	"github.com/upbound/up/internal/xpkg/dep/manager"
+	"github.com/upbound/up/internal/xpkg/dep/resolver/image"
+	"github.com/upbound/up/internal/xpkg/lint"
+	"github.com/upbound/up/internal/xpkg/snapshot"
+	"github.com/upbound/up/internal/xpkg/snapshot/validator"
+)
+
+const (
+	lintOutputText  = "text"
+	lintOutputJSON  = "json"
+	lintOutputSARIF = "sarif"
+
+	errLintSnapshot = "failed to load package workspace"
+	errLintValidate = "failed to validate package workspace"
+	errLintFailFmt  = "found %d error(s)"
+)
+
+var lintRuleFieldNames = []string{"RULE", "SEVERITY", "DESCRIPTION"}
+
+// lintCmd runs the package validations over a workspace.
+type lintCmd struct {
+	Dir       string   `arg:"" optional:"" default:"." type:"existingdir" help:"Root directory of the package workspace."`
+	Output    string   `short:"o" enum:"text,json,sarif" default:"text" help:"Output format of the findings. Can be: text, json, sarif."`
+	Enable    []string `placeholder:"RULE" help:"Only report findings of the given rules."`
+	Disable   []string `placeholder:"RULE" help:"Do not report findings of the given rules."`
+	ListRules bool     `help:"Print the rule catalog and exit."`
+	CacheDir  string   `short:"d" help:"Directory used for caching package images." default:"~/.up/cache/" env:"CACHE_DIR" type:"path"`
+
+	AllowInvalidExamples bool `help:"Report examples that do not match the schemas of the package as warnings instead of errors."`
+}
+
+func (c *lintCmd) Help() string {
+	return `
+The lint command validates the files of a package workspace, by default the
+current directory, without building the package. It reports the same findings
+as the Crossplane language server: objects that do not match the schema of
+their CRD or XRD, invalid XRD schemas, composition patches producing invalid
+resources, examples that do not match the schema of their CRD or XRD and
+dependencies that cannot be resolved. Dependencies are resolved through the
+package cache, the same way as by the dep command.
+
+Every finding is reported with the file, line and column it applies to. Use
+--list-rules to print the catalog of rules, and --enable or --disable to select
+the rules to report:
+
+  up xpkg lint --disable definition-not-found
+  up xpkg lint --enable object-schema,xrd-schema
+
+Findings are printed as text by default. With --output json they are printed
+as a JSON array, and with --output sarif as a SARIF 2.1.0 log that code review
+tools can display inline.
+
+The command fails if any finding has error severity. With
+--allow-invalid-examples, invalid examples are reported as warnings.`
+}
+
+// Run executes the lint command.
+func (c *lintCmd) Run(ctx context.Context, kongCtx *kong.Context, printer upterm.ObjectPrinter, p pterm.TextPrinter) error {
+	if c.ListRules {
+		return printer.Print(lint.Rules(), lintRuleFieldNames, extractLintRuleFields)
+	}
+
+	root, err := filepath.Abs(c.Dir)
+	if err != nil {
+		return err
+	}
+	s, err := c.snapshot(ctx, root)
+	if err != nil {
+		return errors.Wrap(err, errLintSnapshot)
+	}
+	diags, err := s.ValidateAllFiles(ctx)
+	if err != nil {
+		return errors.Wrap(err, errLintValidate)
+	}
+	findings, err := lint.Filter(lint.Findings(root, diags), c.Enable, c.Disable)
+	if err != nil {
+		return err
+	}
+	if c.AllowInvalidExamples {
+		findings = lint.Warn(findings, validator.RuleExampleSchema)
+	}
+
+	if err := c.write(kongCtx.Stdout, findings); err != nil {
+		return err
+	}
+	if n := lint.Errors(findings); n > 0 {
+		return errors.Errorf(errLintFailFmt, n)
+	}
+	if c.Output == lintOutputText && len(findings) == 0 {
+		p.Printfln("No findings")
+	}
+	return nil
+}
+
+func (c *lintCmd) write(w io.Writer, findings []lint.Finding) error {
+	switch c.Output {
+	case lintOutputJSON:
+		return lint.WriteJSON(w, findings)
+	case lintOutputSARIF:
+		return lint.WriteSARIF(w, findings, version.GetVersion())
+	}
+	return lint.WriteText(w, findings)
+}
+
+// snapshot loads a snapshot of the workspace at root, resolving dependencies
+// through the package cache and the lock file of the workspace.
+func (c *lintCmd) snapshot(ctx context.Context, root string) (*snapshot.Snapshot, error) {
+	ch, err := cache.NewLocal(c.CacheDir)
+	if err != nil {
+		return nil, err
+	}
+	l, err := lock.Read(afero.NewOsFs(), filepath.Join(root, xpkg.LockFile))
+	if os.IsNotExist(err) {
+		l, err = lock.New(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m, err := manager.New(
+		manager.WithCache(ch),
+		manager.WithResolver(image.NewResolver()),
+		manager.WithLock(l),
+	)
+	if err != nil {
+		return nil, err
+	}
+	f, err := snapshot.NewFactory(root, snapshot.WithDepManager(m))
+	if err != nil {
+		return nil, err
+	}
+	return f.New(ctx)
+}
+
+func extractLintRuleFields(obj any) []string {
+	r := obj.(lint.Rule)
+	return []string{r.ID, r.Severity, r.Description}
+}
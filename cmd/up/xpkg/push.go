@@ -16,10 +16,11 @@ package xpkg
 
 import (
 	"context"
+	"crypto"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
@@ -29,7 +30,6 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
-	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/pterm/pterm"
 	"github.com/spf13/afero"
 	"golang.org/x/sync/errgroup"
@@ -38,6 +38,8 @@ import (
 	"github.com/upbound/up/internal/credhelper"
 	"github.com/upbound/up/internal/upbound"
 	"github.com/upbound/up/internal/xpkg"
+	"github.com/upbound/up/internal/xpkg/sbom"
+	"github.com/upbound/up/internal/xpkg/signature"
 )
 
 const (
@@ -47,8 +49,21 @@ const (
 	errGetwd             = "failed to get working directory while searching for package"
 	errFindPackageinWd   = "failed to find a package in current working directory"
 	errBuildImage        = "failed to build image from layers"
+	errParseSource       = "source is not a valid package reference"
+	errGetSource         = "failed to get source package from registry"
+	errCopyPackage       = "failed to copy package"
+	errSignWithoutKey    = "--sign requires a private key to be supplied via --key"
+	errReadSigningKey    = "failed to read signing key"
+	errSignPackage       = "failed to sign package"
+	errReadSBOM          = "failed to read SBOM"
+	errAttachSBOM        = "failed to attach SBOM"
+	errUpdateSBOM        = "failed to update SBOM digest"
 )
 
+// cosignPasswordEnv is the environment variable holding the password of
+// encrypted signing keys. It is the same variable cosign uses.
+const cosignPasswordEnv = "COSIGN_PASSWORD"
+
 // AfterApply constructs and binds Upbound-specific context to any subcommands
 // that have Run() methods that receive it.
 func (c *pushCmd) AfterApply(kongCtx *kong.Context) error {
@@ -66,15 +81,93 @@ type pushCmd struct {
 	fs afero.Fs
 
 	Tag     string   `arg:"" help:"Tag of the package to be pushed. Must be a valid OCI image tag."`
-	Package []string `short:"f" help:"Path to packages. If not specified and only one package exists in current directory it will be used."`
+	Package []string `short:"f" xor:"push-source" help:"Path to packages. Either xpkg files, which may contain an image per platform, or OCI image layout directories. If not specified and only one package exists in current directory it will be used."`
+	From    string   `xor:"push-source" help:"Reference of a package in a registry to copy to the tag. The image or image index is copied as is, preserving its digest."`
 	Create  bool     `help:"Create repository on push if it does not exist."`
+	Sign    bool     `help:"Sign the pushed package with the private key supplied via --key."`
+	Key     string   `type:"path" help:"Path to the PEM encoded private key used to sign the package. Keys generated with cosign are decrypted with the password in the COSIGN_PASSWORD environment variable."`
+	Report  string   `type:"path" placeholder:"PATH" help:"Write a JSON report of the pushed package, its images and digest to this file."`
 
 	// Common Upbound API configuration
 	Flags upbound.Flags `embed:""`
 }
 
+func (c *pushCmd) Help() string {
+	return `
+The push command pushes a package to a registry.
+
+Packages can be pushed from xpkg files, as produced by the build command, or
+from OCI image layout directories. If a package contains images for multiple
+platforms, or multiple packages are supplied, an image index referencing all
+of them is pushed to the tag.
+
+Packages that already exist in a registry can be copied to the tag with --from,
+e.g. to promote a tested package from a staging to a production repository:
+
+  up xpkg push xpkg.upbound.io/acme/provider-foo:v1.0.0 \
+    --from registry.example.com/staging/provider-foo:v1.0.0
+
+The package is copied without modification, so its digest is unchanged. Layers
+that already exist in the target repository are not uploaded again, and layers
+in another repository of the same registry are mounted rather than copied.
+
+Packages can be signed on push with --sign and a private key supplied via
+--key. The signature is stored next to the package in the registry, in the
+same format cosign uses, and can be checked with the verify command or with
+cosign verify.
+
+SBOMs generated by the build command with --sbom are found next to the
+supplied packages and attached to the pushed package as OCI referrers, so that
+they can be discovered through the registry's referrers API.
+
+Packages copied with --from are pulled from the registry mirrors configured in
+the profile, see up profile mirror. With --create, a tag in a mirror of the
+Upbound registry creates the Upbound repository it mirrors.
+
+With --report, the digests of the pushed package and its platform images, the
+duration of the push and its error, if any, are written to a JSON file.`
+}
+
 // Run runs the push cmd.
-func (c *pushCmd) Run(p pterm.TextPrinter, upCtx *upbound.Context) error { //nolint:gocyclo
+func (c *pushCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context) error {
+	start := time.Now()
+	pr := packageReport{}
+	err := c.push(ctx, p, upCtx, &pr)
+	if c.Report == "" {
+		return err
+	}
+	pr.finish(start, err)
+	r := &reporter{}
+	r.add(pr)
+	return r.flush(p, c.fs, c.Report, err)
+}
+
+// push pushes the package and records the result in the supplied report.
+func (c *pushCmd) push(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context, pr *packageReport) error { //nolint:gocyclo
+	var key crypto.Signer
+	if c.Sign {
+		if c.Key == "" {
+			return errors.New(errSignWithoutKey)
+		}
+		b, err := afero.ReadFile(c.fs, c.Key)
+		if err != nil {
+			return errors.Wrap(err, errReadSigningKey)
+		}
+		key, err = signature.LoadPrivateKey(b, []byte(os.Getenv(cosignPasswordEnv)))
+		if err != nil {
+			return errors.Wrap(err, errReadSigningKey)
+		}
+	}
+
+	if c.From != "" {
+		d, err := copyPackage(p, upCtx, c.From, c.Tag, c.Create, c.Flags.Profile)
+		if err != nil {
+			return err
+		}
+		pr.pushed(c.Tag, d)
+		return c.sign(ctx, p, upCtx, d, key)
+	}
+
 	// If package is not defined, attempt to find single package in current
 	// directory.
 	if len(c.Package) == 0 {
@@ -89,51 +182,93 @@ func (c *pushCmd) Run(p pterm.TextPrinter, upCtx *upbound.Context) error { //nol
 		c.Package = []string{path}
 	}
 
+	// NOTE: a package may contain more than one image if it was built for
+	// multiple platforms, in which case all of them are pushed as an index.
 	imgs := make([]v1.Image, 0, len(c.Package))
 	for _, p := range c.Package {
-		img, err := tarball.ImageFromPath(filepath.Clean(p), nil)
+		pimgs, err := xpkg.ImagesFromPath(p)
 		if err != nil {
 			return err
 		}
-		imgs = append(imgs, img)
+		imgs = append(imgs, pimgs...)
 	}
-	return PushImages(p, upCtx, imgs, c.Tag, c.Create, c.Flags.Profile)
+	d, descs, err := pushImages(p, upCtx, imgs, c.Tag, c.Create, c.Flags.Profile)
+	if err != nil {
+		return err
+	}
+	pr.pushed(c.Tag, d)
+	pr.setImages(descs)
+	if err := c.attachSBOMs(ctx, p, upCtx, d); err != nil {
+		return err
+	}
+	return c.sign(ctx, p, upCtx, d, key)
 }
 
-func PushImages(p pterm.TextPrinter, upCtx *upbound.Context, imgs []v1.Image, t string, create bool, profile string) error { //nolint:gocyclo
+// attachSBOMs attaches the SBOMs written next to the pushed packages by the
+// build command to the pushed package. Push annotates the layers of the
+// package images, so the SBOMs are updated with the digest of the pushed
+// package first.
+func (c *pushCmd) attachSBOMs(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context, d name.Digest) error {
+	for _, path := range c.Package {
+		for _, f := range sbom.Formats() {
+			sp := path + sbom.Extension(f)
+			b, err := afero.ReadFile(c.fs, sp)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return errors.Wrap(err, errReadSBOM)
+			}
+			b, err = sbom.SetDigest(f, b, d.DigestStr())
+			if err != nil {
+				return errors.Wrap(err, errUpdateSBOM)
+			}
+			if _, err := sbom.Attach(ctx, d, f, b, remote.WithAuthFromKeychain(keychain(upCtx, c.Flags.Profile))); err != nil {
+				return errors.Wrap(err, errAttachSBOM)
+			}
+			p.Printfln("SBOM %s attached to %s", sp, d.String())
+		}
+	}
+	return nil
+}
+
+// sign signs the pushed package with the supplied key, if any.
+func (c *pushCmd) sign(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context, d name.Digest, key crypto.Signer) error {
+	if key == nil {
+		return nil
+	}
+	if err := signature.Sign(ctx, d, key, remote.WithAuthFromKeychain(keychain(upCtx, c.Flags.Profile))); err != nil {
+		return errors.Wrap(err, errSignPackage)
+	}
+	p.Printfln("xpkg %s signed", d.String())
+	return nil
+}
+
+// PushImages pushes the supplied package images to the tag. If more than one
+// image is supplied, an image index referencing all of them is pushed.
+func PushImages(p pterm.TextPrinter, upCtx *upbound.Context, imgs []v1.Image, t string, create bool, profile string) error {
+	_, _, err := pushImages(p, upCtx, imgs, t, create, profile)
+	return err
+}
+
+// pushImages pushes the supplied package images to the tag and returns the
+// digest of the pushed package along with the descriptors of its images.
+func pushImages(p pterm.TextPrinter, upCtx *upbound.Context, imgs []v1.Image, t string, create bool, profile string) (name.Digest, []v1.Descriptor, error) { //nolint:gocyclo
 	tag, err := name.NewTag(t, name.WithDefaultRegistry(upCtx.RegistryEndpoint.Hostname()))
 	if err != nil {
-		return err
+		return name.Digest{}, nil, err
 	}
 
-	kc := authn.NewMultiKeychain(
-		authn.NewKeychainFromHelper(
-			credhelper.New(
-				credhelper.WithDomain(upCtx.Domain.Hostname()),
-				credhelper.WithProfile(profile),
-			),
-		),
-		authn.DefaultKeychain,
-	)
+	kc := keychain(upCtx, profile)
 
 	if create {
-		if !strings.Contains(tag.RegistryStr(), upCtx.RegistryEndpoint.Hostname()) {
-			return errors.New(errCreateNotUpbound)
-		}
-		parts := strings.Split(tag.RepositoryStr(), "/")
-		if len(parts) != 2 {
-			return errors.New(errCreateAccountRepo)
-		}
-		cfg, err := upCtx.BuildSDKConfig()
-		if err != nil {
-			return err
-		}
-		if err := repositories.NewClient(cfg).CreateOrUpdate(context.Background(), parts[0], parts[1]); err != nil {
-			return errors.Wrap(err, errCreateRepo)
+		if err := createRepository(upCtx, tag); err != nil {
+			return name.Digest{}, nil, err
 		}
 	}
 
 	adds := make([]mutate.IndexAddendum, len(imgs))
+	descs := make([]v1.Descriptor, len(imgs))
 
 	// NOTE(hasheddan): the errgroup context is passed to each image write,
 	// meaning that if one fails it will cancel others that are in progress.
@@ -148,36 +283,44 @@ func PushImages(p pterm.TextPrinter, upCtx *upbound.Context, imgs []v1.Image, t
 				return err
 			}
 
+			d, err := aimg.Digest()
+			if err != nil {
+				return err
+			}
+
+			mt, err := aimg.MediaType()
+			if err != nil {
+				return err
+			}
+
+			conf, err := aimg.ConfigFile()
+			if err != nil {
+				return err
+			}
+
+			descs[i] = v1.Descriptor{
+				MediaType: mt,
+				Digest:    d,
+				Platform: &v1.Platform{
+					Architecture: conf.Architecture,
+					OS:           conf.OS,
+					OSVersion:    conf.OSVersion,
+					Variant:      conf.Variant,
+				},
+			}
+
 			var t name.Reference = tag
 			if len(imgs) > 1 {
-				d, err := aimg.Digest()
-				if err != nil {
-					return err
-				}
 				t, err = name.NewDigest(fmt.Sprintf("%s@%s", tag.Repository.Name(), d.String()), name.WithDefaultRegistry(upCtx.RegistryEndpoint.Hostname()))
 				if err != nil {
 					return err
 				}
 
-				mt, err := aimg.MediaType()
-				if err != nil {
-					return err
-				}
-
-				conf, err := aimg.ConfigFile()
-				if err != nil {
-					return err
-				}
-
 				adds[i] = mutate.IndexAddendum{
 					Add: aimg,
 					Descriptor: v1.Descriptor{
 						MediaType: mt,
-						Platform: &v1.Platform{
-							Architecture: conf.Architecture,
-							OS:           conf.OS,
-							OSVersion:    conf.OSVersion,
-						},
+						Platform:  descs[i].Platform,
 					},
 				}
 			}
@@ -190,18 +333,126 @@ func PushImages(p pterm.TextPrinter, upCtx *upbound.Context, imgs []v1.Image, t
 
 	// Error if writing any images failed.
 	if err := g.Wait(); err != nil {
-		return err
+		return name.Digest{}, nil, err
 	}
 
 	// If we pushed more than one xpkg then we need to write index.
+	h := descs[0].Digest
 	if len(imgs) > 1 {
-		if err := remote.WriteIndex(tag, mutate.AppendManifests(empty.Index, adds...), remote.WithAuthFromKeychain(kc)); err != nil {
-			return err
+		ii := mutate.AppendManifests(empty.Index, adds...)
+		if err := remote.WriteIndex(tag, ii, remote.WithAuthFromKeychain(kc)); err != nil {
+			return name.Digest{}, nil, err
+		}
+		if h, err = ii.Digest(); err != nil {
+			return name.Digest{}, nil, err
 		}
 	}
 
 	p.Printfln("xpkg pushed to %s", tag.String())
-	return nil
+	return tag.Digest(h.String()), descs, nil
+}
+
+// CopyPackage copies the package image or image index at the source reference
+// to the supplied tag without modifying it. Blobs that already exist in the
+// target repository are not uploaded again.
+func CopyPackage(p pterm.TextPrinter, upCtx *upbound.Context, src, t string, create bool, profile string) error {
+	_, err := copyPackage(p, upCtx, src, t, create, profile)
+	return err
+}
+
+func copyPackage(p pterm.TextPrinter, upCtx *upbound.Context, src, t string, create bool, profile string) (name.Digest, error) { //nolint:gocyclo
+	tag, err := name.NewTag(t, name.WithDefaultRegistry(upCtx.RegistryEndpoint.Hostname()))
+	if err != nil {
+		return name.Digest{}, err
+	}
+	ref, err := name.ParseReference(src, name.WithDefaultRegistry(upCtx.RegistryEndpoint.Hostname()))
+	if err != nil {
+		return name.Digest{}, errors.Wrap(err, errParseSource)
+	}
+
+	if create {
+		if err := createRepository(upCtx, tag); err != nil {
+			return name.Digest{}, err
+		}
+	}
+
+	opts := []remote.Option{
+		remote.WithAuthFromKeychain(keychain(upCtx, profile)),
+		remote.WithContext(context.Background()),
+	}
+	// NOTE: the source is pulled from the mirrors of its registry, if any,
+	// trying each of them in order.
+	srcs, err := upCtx.Profile.Mirrors.References(ref)
+	if err != nil {
+		return name.Digest{}, errors.Wrap(err, errGetSource)
+	}
+	var desc *remote.Descriptor
+	for _, s := range srcs {
+		if desc, err = remote.Get(s, opts...); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return name.Digest{}, errors.Wrap(err, errGetSource)
+	}
+
+	if desc.MediaType.IsIndex() {
+		ii, err := desc.ImageIndex()
+		if err != nil {
+			return name.Digest{}, errors.Wrap(err, errGetSource)
+		}
+		if err := remote.WriteIndex(tag, ii, opts...); err != nil {
+			return name.Digest{}, errors.Wrap(err, errCopyPackage)
+		}
+	} else {
+		img, err := desc.Image()
+		if err != nil {
+			return name.Digest{}, errors.Wrap(err, errGetSource)
+		}
+		if err := remote.Write(tag, img, opts...); err != nil {
+			return name.Digest{}, errors.Wrap(err, errCopyPackage)
+		}
+	}
+
+	p.Printfln("xpkg copied from %s to %s@%s", ref.String(), tag.String(), desc.Digest.String())
+	return tag.Digest(desc.Digest.String()), nil
+}
+
+// keychain returns a keychain that resolves credentials for the Upbound
+// registry from the supplied profile, and for other registries from the
+// default keychain.
+func keychain(upCtx *upbound.Context, profile string) authn.Keychain {
+	return authn.NewMultiKeychain(
+		authn.NewKeychainFromHelper(
+			credhelper.New(
+				credhelper.WithDomain(upCtx.Domain.Hostname()),
+				credhelper.WithProfile(profile),
+			),
+		),
+		authn.DefaultKeychain,
+	)
+}
+
+// createRepository creates the Upbound repository for the supplied tag if it
+// does not exist. Tags in a mirror of the Upbound registry, as configured in
+// the profile, refer to the Upbound repository they mirror.
+func createRepository(upCtx *upbound.Context, tag name.Tag) error {
+	repo := tag.Repository
+	if o, ok := upCtx.Profile.Mirrors.Origin(repo); ok {
+		repo = o
+	}
+	if !strings.Contains(repo.RegistryStr(), upCtx.RegistryEndpoint.Hostname()) {
+		return errors.New(errCreateNotUpbound)
+	}
+	parts := strings.Split(repo.RepositoryStr(), "/")
+	if len(parts) != 2 {
+		return errors.New(errCreateAccountRepo)
+	}
+	cfg, err := upCtx.BuildSDKConfig()
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(repositories.NewClient(cfg).CreateOrUpdate(context.Background(), parts[0], parts[1]), errCreateRepo)
 }
 
 // annotate reads in the layers of the given v1.Image and annotates the xpkg
@@ -0,0 +1,98 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/upbound"
+)
+
+func TestCopyPackage(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, _ := url.Parse(s.URL)
+	upCtx := &upbound.Context{Domain: u, RegistryEndpoint: u}
+
+	img, _ := random.Image(256, 2)
+	ii, _ := random.Index(256, 1, 2)
+
+	type want struct {
+		digest func() (v1.Hash, error)
+	}
+	cases := map[string]struct {
+		reason string
+		write  func(ref name.Reference) error
+		want   want
+	}{
+		"Image": {
+			reason: "An image should be copied without modification.",
+			write: func(ref name.Reference) error {
+				return remote.Write(ref, img)
+			},
+			want: want{digest: img.Digest},
+		},
+		"Index": {
+			reason: "An image index should be copied without modification.",
+			write: func(ref name.Reference) error {
+				return remote.WriteIndex(ref, ii)
+			},
+			want: want{digest: ii.Digest},
+		},
+	}
+
+	for n, tc := range cases {
+		t.Run(n, func(t *testing.T) {
+			src := fmt.Sprintf("%s/staging/%s:v1", u.Host, strings.ToLower(n))
+			dst := fmt.Sprintf("%s/production/%s:v1", u.Host, strings.ToLower(n))
+			srcRef, err := name.ParseReference(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dstRef, err := name.ParseReference(dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tc.write(srcRef); err != nil {
+				t.Fatalf("\n%s\nwrite(...): unexpected error: %v", tc.reason, err)
+			}
+
+			if err := CopyPackage(&pterm.BasicTextPrinter{}, upCtx, src, dst, false, ""); err != nil {
+				t.Fatalf("\n%s\nCopyPackage(...): unexpected error: %v", tc.reason, err)
+			}
+
+			desc, err := remote.Head(dstRef)
+			if err != nil {
+				t.Fatalf("\n%s\nremote.Head(...): unexpected error: %v", tc.reason, err)
+			}
+			want, _ := tc.want.digest()
+			if diff := cmp.Diff(want, desc.Digest); diff != "" {
+				t.Errorf("\n%s\nCopyPackage(...): -want digest, +got digest:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/alecthomas/kong"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/upbound/up/internal/xpkg/parser/examples"
+	"github.com/upbound/up/internal/xpkg/render"
+	"github.com/upbound/up/internal/xpkg/scheme"
+	"github.com/upbound/up/internal/xpkg/workspace"
+)
+
+const (
+	errRenderWorkspace   = "failed to parse package workspace"
+	errRenderDeps        = "failed to load dependencies"
+	errRenderParseFmt    = "failed to parse %s"
+	errRenderFmt         = "failed to render %s"
+	errRenderNoResources = "no composite resource or claim found"
+	errRenderFailFmt     = "found %d error(s) in composed resources"
+)
+
+// renderCmd renders the resources composed by composite resources or claims.
+type renderCmd struct {
+	fs afero.Fs
+
+	Resource         string `arg:"" type:"existingfile" help:"Path to a file with the composite resources or claims to render."`
+	PackageRoot      string `short:"f" help:"Path to the package workspace with the compositions." default:"." type:"existingdir"`
+	Composition      string `short:"c" help:"Name of the composition to render with instead of the one selected for the resources. A composition enforced by the XRD of a resource takes precedence."`
+	IncludeComposite bool   `help:"Print the composite resources along with the resources they compose."`
+	CacheDir         string `short:"d" help:"Directory used for caching package images." default:"~/.up/cache/" env:"CACHE_DIR" type:"path"`
+}
+
+func (c *renderCmd) Help() string {
+	return `
+The render command renders the resources that composite resources or claims
+would compose, without a control plane. The compositions and XRDs are read from
+the package workspace, by default the current directory, and from the
+dependencies of the package in the package cache.
+
+The composition of every resource is selected the same way Crossplane does,
+unless one is supplied via --composition. Like in Crossplane, a composition
+enforced by the XRD of a resource is always used. The patches and transforms of the
+composition are applied and the composed resources are printed as YAML. Claims
+are rendered as the composite resource Crossplane would create for them, which
+can be printed via --include-composite.
+
+Composed resources are validated against the schemas of their CRDs. The command
+fails if a resource cannot be rendered or does not match its schema. Errors are
+printed as YAML comments, so that the output remains valid YAML. Values
+that are only known once resources exist, e.g. generated names or status
+fields, are not rendered.
+
+  up xpkg render examples/cluster.yaml
+  up xpkg render xr.yaml --composition cluster-aws --include-composite`
+}
+
+// AfterApply sets default values in command after assignment and validation.
+func (c *renderCmd) AfterApply() error {
+	c.fs = afero.NewOsFs()
+	return nil
+}
+
+// Run executes the render command.
+func (c *renderCmd) Run(ctx context.Context, kongCtx *kong.Context, p pterm.TextPrinter) error {
+	root, err := filepath.Abs(c.PackageRoot)
+	if err != nil {
+		return err
+	}
+	objs, err := c.objects(ctx, root)
+	if err != nil {
+		return err
+	}
+	ins, err := c.resources(ctx)
+	if err != nil {
+		return err
+	}
+	if len(ins) == 0 {
+		return errors.New(errRenderNoResources)
+	}
+
+	r := render.New(ctx, objs...)
+	failed := 0
+	for i := range ins {
+		in := &ins[i]
+		if c.Composition != "" {
+			if err := unstructured.SetNestedField(in.Object, c.Composition, "spec", "compositionRef", "name"); err != nil {
+				return errors.Wrapf(err, errRenderFmt, describeObject(in))
+			}
+		}
+		out, err := r.Render(ctx, in)
+		if err != nil {
+			return errors.Wrapf(err, errRenderFmt, describeObject(in))
+		}
+		if c.IncludeComposite {
+			if err := writeYAML(kongCtx.Stdout, out.Composite); err != nil {
+				return err
+			}
+		}
+		for _, res := range out.Resources {
+			if res.Err != nil {
+				failed++
+				p.Printfln("# %s: resource %q: %s", describeObject(in), res.Name, res.Err)
+				continue
+			}
+			for _, v := range res.Validations {
+				failed++
+				p.Printfln("# %s: resource %q: %s", describeObject(in), res.Name, v.Error())
+			}
+			if err := writeYAML(kongCtx.Stdout, res.Object); err != nil {
+				return err
+			}
+		}
+	}
+	if failed > 0 {
+		return errors.Errorf(errRenderFailFmt, failed)
+	}
+	return nil
+}
+
+// objects returns the objects of the package workspace at root and of its
+// dependencies in the cache.
+func (c *renderCmd) objects(ctx context.Context, root string) ([]runtime.Object, error) {
+	ws, err := workspace.New(root, workspace.WithFS(c.fs), workspace.WithPermissiveParser())
+	if err != nil {
+		return nil, err
+	}
+	if err := ws.Parse(ctx); err != nil {
+		return nil, errors.Wrap(err, errRenderWorkspace)
+	}
+	sch, err := scheme.BuildObjectScheme()
+	if err != nil {
+		return nil, err
+	}
+	objs := []runtime.Object{}
+	for _, n := range ws.View().Nodes() {
+		u, ok := n.GetObject().(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		// NOTE: only CRDs, XRDs and Compositions are known to the object
+		// scheme, all other objects are not needed for rendering.
+		o, err := sch.New(n.GetGVK())
+		if err != nil {
+			continue
+		}
+		b, err := u.MarshalJSON()
+		if err != nil {
+			continue
+		}
+		if err := json.Unmarshal(b, o); err != nil {
+			continue
+		}
+		objs = append(objs, o)
+	}
+
+	meta := ws.View().Meta()
+	if meta == nil {
+		return objs, nil
+	}
+	deps, err := meta.DependsOn()
+	if err != nil {
+		return nil, errors.Wrap(err, errRenderDeps)
+	}
+	depObjs, err := dependencyObjects(ctx, c.fs, root, c.CacheDir, deps)
+	if err != nil {
+		return nil, errors.Wrap(err, errRenderDeps)
+	}
+	return append(objs, depObjs...), nil
+}
+
+// resources returns the objects in the file of resources to render.
+func (c *renderCmd) resources(ctx context.Context) ([]unstructured.Unstructured, error) {
+	f, err := c.fs.Open(c.Resource)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() // nolint:errcheck
+	ex, err := examples.New().Parse(ctx, f)
+	if err != nil {
+		return nil, errors.Wrapf(err, errRenderParseFmt, c.Resource)
+	}
+	return ex.Objects(), nil
+}
+
+// writeYAML writes the supplied object as a YAML document.
+func writeYAML(w io.Writer, u *unstructured.Unstructured) error {
+	b, err := yaml.Marshal(u.Object)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "---\n%s", b); err != nil {
+		return err
+	}
+	return nil
+}
+
+func describeObject(u *unstructured.Unstructured) string {
+	return fmt.Sprintf("%s/%s", u.GetKind(), u.GetName())
+}
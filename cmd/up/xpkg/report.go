@@ -0,0 +1,187 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+)
+
+const (
+	errReadReportFmt  = "failed to read report %s"
+	errWriteReportFmt = "failed to write report %s"
+)
+
+// packageStatus is the outcome of processing a package.
+type packageStatus string
+
+const (
+	// statusBuilt packages were built but not pushed.
+	statusBuilt packageStatus = "built"
+	// statusPushed packages were pushed to all of their tags.
+	statusPushed packageStatus = "pushed"
+	// statusSkipped packages were already up to date in all of their tags.
+	statusSkipped packageStatus = "skipped"
+	// statusFailed packages could not be built or pushed.
+	statusFailed packageStatus = "failed"
+)
+
+// report is a machine-readable manifest of the packages processed by a
+// command.
+type report struct {
+	Packages []packageReport `json:"packages"`
+}
+
+// packageReport is the result of processing a package.
+type packageReport struct {
+	// Family and Service identify the packages of up xpkg batch.
+	Family  string `json:"family,omitempty"`
+	Service string `json:"service,omitempty"`
+
+	Status packageStatus `json:"status"`
+	Images []imageReport `json:"images,omitempty"`
+
+	// Digest of the pushed package, which is an image index if it has more
+	// than one image.
+	Digest string `json:"digest,omitempty"`
+	// Tags the package was pushed to.
+	Tags []string `json:"tags,omitempty"`
+	// UpToDate are the tags that already had a package built from the same
+	// inputs, or to which one was copied, in incremental mode.
+	UpToDate []string `json:"upToDate,omitempty"`
+
+	// Retries is the number of pushes that were retried.
+	Retries  uint    `json:"retries"`
+	Duration float64 `json:"durationSeconds"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// imageReport is a platform image of a package.
+type imageReport struct {
+	Platform string `json:"platform"`
+	Digest   string `json:"digest"`
+}
+
+// setImages sets the images of the package from the supplied descriptors.
+func (r *packageReport) setImages(descs []v1.Descriptor) {
+	r.Images = make([]imageReport, len(descs))
+	for i, d := range descs {
+		r.Images[i] = imageReport{Digest: d.Digest.String()}
+		if d.Platform != nil {
+			r.Images[i].Platform = d.Platform.OS + "_" + d.Platform.Architecture
+		}
+	}
+}
+
+// pushed records that the package with the supplied digest was pushed to the
+// supplied tag.
+func (r *packageReport) pushed(tag string, d name.Digest) {
+	r.Status = statusPushed
+	r.Digest = d.DigestStr()
+	r.Tags = append(r.Tags, tag)
+}
+
+// finish records the duration and the error, if any, of processing the
+// package, which started at the supplied time.
+func (r *packageReport) finish(start time.Time, err error) {
+	r.Duration = time.Since(start).Round(time.Millisecond).Seconds()
+	if err != nil {
+		r.Status = statusFailed
+		r.Error = err.Error()
+	}
+}
+
+// reporter collects the reports of packages processed concurrently.
+type reporter struct {
+	mu       sync.Mutex
+	packages []packageReport
+}
+
+func (r *reporter) add(p packageReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.packages = append(r.packages, p)
+}
+
+// write writes the collected reports to the supplied path, ordered by family
+// and service.
+func (r *reporter) write(fs afero.Fs, path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sort.SliceStable(r.packages, func(i, j int) bool {
+		if r.packages[i].Family != r.packages[j].Family {
+			return r.packages[i].Family < r.packages[j].Family
+		}
+		return r.packages[i].Service < r.packages[j].Service
+	})
+	b, err := json.MarshalIndent(report{Packages: r.packages}, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, errWriteReportFmt, path)
+	}
+	return errors.Wrapf(afero.WriteFile(fs, path, append(b, '\n'), 0o644), errWriteReportFmt, path)
+}
+
+// flush writes the collected reports to the supplied path. It returns the
+// supplied error of the command if there is one, and otherwise the error of
+// writing the reports.
+func (r *reporter) flush(p pterm.TextPrinter, fs afero.Fs, path string, err error) error {
+	werr := r.write(fs, path)
+	switch {
+	case werr == nil:
+		p.Printfln("Report written to %s", path)
+	case err == nil:
+		return werr
+	default:
+		p.PrintOnError(werr)
+	}
+	return err
+}
+
+// readReport reads the report at the supplied path.
+func readReport(fs afero.Fs, path string) (*report, error) {
+	b, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, errors.Wrapf(err, errReadReportFmt, path)
+	}
+	r := &report{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, errors.Wrapf(err, errReadReportFmt, path)
+	}
+	return r, nil
+}
+
+// failed returns the services of the failed packages of the report, keyed by
+// their family.
+func (r *report) failed() map[string]map[string]bool {
+	failed := map[string]map[string]bool{}
+	for _, p := range r.Packages {
+		if p.Status != statusFailed {
+			continue
+		}
+		if failed[p.Family] == nil {
+			failed[p.Family] = map[string]bool{}
+		}
+		failed[p.Family][p.Service] = true
+	}
+	return failed
+}
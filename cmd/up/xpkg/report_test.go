@@ -0,0 +1,85 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+)
+
+func TestReporter(t *testing.T) {
+	r := &reporter{}
+	r.add(packageReport{Family: "provider-gcp", Service: "compute", Status: statusPushed, Tags: []string{"xpkg.upbound.io/upbound/provider-gcp-compute:v1"}})
+	r.add(packageReport{Family: "provider-aws", Service: "rds", Status: statusFailed, Retries: 3, Error: "boom"})
+	r.add(packageReport{Family: "provider-aws", Service: "ec2", Status: statusSkipped})
+
+	fs := afero.NewMemMapFs()
+	if err := r.write(fs, "report.json"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readReport(fs, "report.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &report{Packages: []packageReport{
+		{Family: "provider-aws", Service: "ec2", Status: statusSkipped},
+		{Family: "provider-aws", Service: "rds", Status: statusFailed, Retries: 3, Error: "boom"},
+		{Family: "provider-gcp", Service: "compute", Status: statusPushed, Tags: []string{"xpkg.upbound.io/upbound/provider-gcp-compute:v1"}},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("\nreadReport(...): -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]map[string]bool{"provider-aws": {"rds": true}}, got.failed()); diff != "" {
+		t.Errorf("\nfailed(): -want, +got:\n%s", diff)
+	}
+}
+
+func TestResumedServices(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		resume map[string]map[string]bool
+		want   []string
+	}{
+		"Failed": {
+			reason: "Only the failed services of the family should be resumed.",
+			resume: map[string]map[string]bool{
+				"provider-aws": {"rds": true, "iam": true},
+				"provider-gcp": {"ec2": true},
+			},
+			want: []string{"rds"},
+		},
+		"NoneFailed": {
+			reason: "No services should be resumed if none of the family failed.",
+			resume: map[string]map[string]bool{
+				"provider-gcp": {"ec2": true},
+			},
+		},
+	}
+
+	for n, tc := range cases {
+		t.Run(n, func(t *testing.T) {
+			c := &batchCmd{
+				ProviderName:     "provider-aws",
+				SmallerProviders: []string{"ec2", "rds"},
+				resume:           tc.resume,
+			}
+			if diff := cmp.Diff(tc.want, c.resumedServices()); diff != "" {
+				t.Errorf("\n%s\nresumedServices(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
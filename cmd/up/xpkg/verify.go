@@ -0,0 +1,97 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"context"
+	"crypto"
+
+	"github.com/alecthomas/kong"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/upbound"
+	"github.com/upbound/up/internal/xpkg/signature"
+)
+
+const (
+	errReadVerificationKey = "failed to read verification key"
+)
+
+// AfterApply constructs and binds Upbound-specific context to any subcommands
+// that have Run() methods that receive it.
+func (c *verifyCmd) AfterApply(kongCtx *kong.Context) error {
+	c.fs = afero.NewOsFs()
+	upCtx, err := upbound.NewFromFlags(c.Flags)
+	if err != nil {
+		return err
+	}
+	kongCtx.Bind(upCtx)
+	return nil
+}
+
+// verifyCmd verifies the signature of a package.
+type verifyCmd struct {
+	fs afero.Fs
+
+	Package string `arg:"" help:"Reference of the package to verify. Must be a valid OCI image reference."`
+	Key     string `type:"existingfile" required:"" help:"Path to the PEM encoded public key the package must be signed with."`
+
+	// Common Upbound API configuration
+	Flags upbound.Flags `embed:""`
+}
+
+func (c *verifyCmd) Help() string {
+	return `
+The verify command checks that a package in a registry has been signed with the
+private key belonging to the supplied public key, e.g. by push --sign or by
+cosign sign. If the reference is a tag, the digest it currently points to is
+verified.`
+}
+
+// Run executes the verify command.
+func (c *verifyCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context) error {
+	pub, err := loadVerificationKey(c.fs, c.Key)
+	if err != nil {
+		return err
+	}
+	ref, err := name.ParseReference(c.Package, name.WithDefaultRegistry(upCtx.RegistryEndpoint.Hostname()))
+	if err != nil {
+		return err
+	}
+	opts := []remote.Option{remote.WithAuthFromKeychain(keychain(upCtx, c.Flags.Profile))}
+	d, err := signature.ResolveDigest(ctx, ref, opts...)
+	if err != nil {
+		return err
+	}
+	if err := signature.NewVerifier(pub, opts...).Verify(ctx, d); err != nil {
+		return err
+	}
+	p.Printfln("Signature of %s verified", d.String())
+	return nil
+}
+
+// loadVerificationKey reads the PEM encoded public key at the supplied path.
+func loadVerificationKey(fs afero.Fs, path string) (crypto.PublicKey, error) {
+	b, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, errors.Wrap(err, errReadVerificationKey)
+	}
+	pub, err := signature.LoadPublicKey(b)
+	return pub, errors.Wrap(err, errReadVerificationKey)
+}
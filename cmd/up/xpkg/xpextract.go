@@ -28,7 +28,6 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/daemon"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
-	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/pterm/pterm"
 	"github.com/spf13/afero"
 
@@ -59,9 +58,12 @@ const (
 // fetchFn fetches a package from a source.
 type fetchFn func(context.Context, name.Reference) (v1.Image, error)
 
-// registryFetch fetches a package from the registry.
-func registryFetch(ctx context.Context, r name.Reference) (v1.Image, error) {
-	return remote.Image(r, remote.WithContext(ctx))
+// registryFetch returns a fetchFn that fetches the image of a package for the
+// supplied platform from the registry.
+func registryFetch(p v1.Platform) fetchFn {
+	return func(ctx context.Context, r name.Reference) (v1.Image, error) {
+		return remote.Image(r, remote.WithContext(ctx), remote.WithPlatform(p))
+	}
 }
 
 // daemonFetch fetches a package from the Docker daemon.
@@ -69,17 +71,60 @@ func daemonFetch(ctx context.Context, r name.Reference) (v1.Image, error) {
 	return daemon.Image(r, daemon.WithContext(ctx))
 }
 
-func xpkgFetch(path string) fetchFn {
+// xpkgFetch returns a fetchFn that reads the image of a package for the
+// supplied platform from the xpkg file at path. Packages built for multiple
+// platforms contain an image per platform.
+func xpkgFetch(path string, p v1.Platform) fetchFn {
 	return func(ctx context.Context, r name.Reference) (v1.Image, error) {
-		return tarball.ImageFromPath(filepath.Clean(path), nil)
+		return xpkg.ImageFromPath(path, p)
+	}
+}
+
+// parsePlatform parses a platform of the form os/arch[/variant].
+func parsePlatform(s string) (v1.Platform, error) {
+	ps, err := xpkg.ParsePlatforms([]string{s})
+	if err != nil {
+		return v1.Platform{}, err
+	}
+	return ps[0], nil
+}
+
+// packageFetch returns the fetchFn for the supplied package, which is either
+// the path of an xpkg file or a reference. Files take precedence over
+// references with the same name unless fromDaemon is true. The returned
+// reference is nil for files. The image for the supplied platform is returned
+// if the package was built for multiple platforms.
+func packageFetch(fs afero.Fs, pkg string, fromDaemon bool, platform string, flags upbound.Flags) (fetchFn, name.Reference, error) {
+	p, err := parsePlatform(platform)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ok, _ := afero.Exists(fs, pkg); ok && !fromDaemon {
+		return xpkgFetch(pkg, p), nil, nil
 	}
+	upCtx, err := upbound.NewFromFlags(flags)
+	if err != nil {
+		return nil, nil, err
+	}
+	ref, err := name.ParseReference(pkg, name.WithDefaultRegistry(upCtx.RegistryEndpoint.Hostname()))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errInvalidTag)
+	}
+	if fromDaemon {
+		return daemonFetch, ref, nil
+	}
+	return registryFetch(p), ref, nil
 }
 
 // AfterApply constructs and binds Upbound-specific context to any subcommands
 // that have Run() methods that receive it.
 func (c *xpExtractCmd) AfterApply() error {
 	c.fs = afero.NewOsFs()
-	c.fetch = registryFetch
+	p, err := parsePlatform(c.Platform)
+	if err != nil {
+		return err
+	}
+	c.fetch = registryFetch(p)
 	if c.FromDaemon {
 		c.fetch = daemonFetch
 	}
@@ -97,7 +142,7 @@ func (c *xpExtractCmd) AfterApply() error {
 			}
 			c.Package = path
 		}
-		c.fetch = xpkgFetch(c.Package)
+		c.fetch = xpkgFetch(c.Package, p)
 	}
 	if !c.FromXpkg {
 		if c.Package == "" {
@@ -127,6 +172,7 @@ type xpExtractCmd struct {
 	FromDaemon bool   `xor:"xp-extract-from" help:"Indicates that the image should be fetched from the Docker daemon."`
 	FromXpkg   bool   `xor:"xp-extract-from" help:"Indicates that the image should be fetched from a local xpkg. If package is not specified and only one exists in current directory it will be used."`
 	Output     string `short:"o" help:"Package output file path. Extension must be .gz or will be replaced." default:"out.gz"`
+	Platform   string `help:"Platform of the image to extract if the package was built for multiple platforms, in os/arch[/variant] form." default:"linux/amd64"`
 
 	// Common Upbound API configuration
 	Flags upbound.Flags `embed:""`
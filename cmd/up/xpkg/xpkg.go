@@ -32,6 +32,12 @@ type Cmd struct {
 	Init      initCmd      `cmd:"" help:"Initialize a package, by default in the current directory."`
 	Dep       depCmd       `cmd:"" help:"Manage package dependencies in the filesystem and populate the cache, e.g. used by the Crossplane Language Server."`
 	Push      pushCmd      `cmd:"" help:"Push a package."`
+	Verify    verifyCmd    `cmd:"" help:"Verify the signature of a package."`
+	Inspect   inspectCmd   `cmd:"" help:"Show the contents, metadata, layers and dependencies of a package."`
+	Diff      diffCmd      `cmd:"" help:"Show the API changes between two versions of a package."`
+	Lint      lintCmd      `cmd:"" help:"Validate the files of a package, by default in the current directory."`
+	Render    renderCmd    `cmd:"" help:"Render the resources composed by composite resources or claims without a control plane."`
+	Cache     cacheCmd     `cmd:"" help:"Manage the local package cache."`
 	Batch     batchCmd     `cmd:"" maturity:"alpha" help:"Batch build and push a family of service-scoped provider packages."`
 }
 
@@ -55,6 +61,10 @@ currently supported:
   kind "Provider" manifest, and optionally CRD manifest.
 - **Configuration**: A Crossplane package that contains a Crossplane configuration,
   with a "meta.pkg.crossplane.io/v1" kind "Configuration" manifest in crossplane.yaml.
+- **Function**: A Crossplane package that contains a composition function. The layer
+  contains a crossplane.yaml file with a "meta.pkg.crossplane.io/v1beta1" kind
+  "Function" manifest, and optionally the CRD manifests of the function's input.
+  The function's runtime image is used as the base of the package.
 - in newer versions of Crossplane, more kinds will be supported.
 
 For more detailed information on Crossplane packages, see
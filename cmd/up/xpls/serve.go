@@ -33,6 +33,8 @@ type serveCmd struct {
 	// this to the config.
 	Cache   string `default:"~/.up/cache" help:"Directory path for dependency schema cache." type:"path"`
 	Verbose bool   `help:"Run server with verbose logging."`
+	Frozen  bool   `help:"Only resolve dependencies that are pinned in crossplane.lock."`
+	Offline bool   `help:"Do not access the network, e.g. to check for updates of up."`
 }
 
 // Run runs the language server.
@@ -44,6 +46,8 @@ func (c *serveCmd) Run(ctx context.Context) error {
 	zl := zap.New(zap.UseDevMode(c.Verbose))
 	h, err := handler.New(
 		handler.WithLogger(logging.NewLogrLogger(zl.WithName("xpls"))),
+		handler.WithFrozen(c.Frozen),
+		handler.WithOffline(c.Offline),
 	)
 	if err != nil {
 		return err
@@ -0,0 +1,170 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package diff computes line based diffs of text files.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines printed around every change.
+const contextLines = 3
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+type op struct {
+	kind opKind
+	line string
+	// a and b are the indexes of the line in the from and to text.
+	a, b int
+}
+
+// Unified returns a unified diff of the supplied from and to text, labelled
+// with the supplied names. It returns an empty string if the texts are equal.
+func Unified(fromName, toName string, from, to []byte) string {
+	ops := lines(split(string(from)), split(string(to)))
+
+	changed := false
+	for _, o := range ops {
+		if o.kind != opEqual {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "--- %s\n+++ %s\n", fromName, toName)
+	for _, h := range hunks(ops) {
+		writeHunk(b, ops[h[0]:h[1]])
+	}
+	return b.String()
+}
+
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.SplitAfter(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// lines computes the edit script between the supplied lines using their
+// longest common subsequence.
+func lines(a, b []string) []op {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case trim(a[i]) == trim(b[j]):
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && trim(a[i]) == trim(b[j]):
+			ops = append(ops, op{kind: opEqual, line: trim(a[i]), a: i, b: j})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{kind: opDelete, line: trim(a[i]), a: i, b: j})
+			i++
+		default:
+			ops = append(ops, op{kind: opInsert, line: trim(b[j]), a: i, b: j})
+			j++
+		}
+	}
+	return ops
+}
+
+func trim(l string) string {
+	return strings.TrimSuffix(l, "\n")
+}
+
+// hunks returns the [start, end) ranges of the supplied edit script that
+// should be printed, merging changes that are close to each other.
+func hunks(ops []op) [][2]int {
+	var hs [][2]int
+	for i, o := range ops {
+		if o.kind == opEqual {
+			continue
+		}
+		start := i - contextLines
+		if start < 0 {
+			start = 0
+		}
+		end := i + contextLines + 1
+		if end > len(ops) {
+			end = len(ops)
+		}
+		if n := len(hs); n > 0 && start <= hs[n-1][1] {
+			hs[n-1][1] = end
+			continue
+		}
+		hs = append(hs, [2]int{start, end})
+	}
+	return hs
+}
+
+func writeHunk(b *strings.Builder, ops []op) {
+	var aLen, bLen int
+	for _, o := range ops {
+		if o.kind != opInsert {
+			aLen++
+		}
+		if o.kind != opDelete {
+			bLen++
+		}
+	}
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(ops[0].a, aLen), hunkRange(ops[0].b, bLen))
+	for _, o := range ops {
+		switch o.kind {
+		case opEqual:
+			fmt.Fprintf(b, " %s\n", o.line)
+		case opDelete:
+			fmt.Fprintf(b, "-%s\n", o.line)
+		case opInsert:
+			fmt.Fprintf(b, "+%s\n", o.line)
+		}
+	}
+}
+
+func hunkRange(start, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, n)
+}
@@ -0,0 +1,74 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUnified(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		from   string
+		to     string
+		want   string
+	}{
+		"Equal": {
+			reason: "Should return an empty diff for equal texts.",
+			from:   "a\nb\n",
+			to:     "a\nb\n",
+		},
+		"Change": {
+			reason: "Should print changed lines with surrounding context.",
+			from:   "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			to:     "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			want: `--- a
++++ b
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`,
+		},
+		"Insert": {
+			reason: "Should print inserted lines.",
+			from:   "a\n",
+			to:     "a\nb\n",
+			want: `--- a
++++ b
+@@ -1,1 +1,2 @@
+ a
++b
+`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Unified("a", "b", []byte(tc.from), []byte(tc.to))
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nUnified(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mirror rewrites image references to the mirrors of their registries
+// and repositories.
+package mirror
+
+import (
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+const (
+	errInvalidPrefixFmt   = "invalid mirror prefix %q"
+	errInvalidEndpointFmt = "invalid endpoint %q of mirror %s"
+	errNoEndpointsFmt     = "mirror %s has no endpoints"
+	errDuplicatePrefixFmt = "duplicate mirror prefix %s"
+	errEmptyPrefix        = "prefix must not be empty"
+	errRegistryEndpoint   = "a mirror of a repository needs an endpoint repository, not a registry"
+)
+
+// A Mirror serves the images below a registry or repository prefix from one
+// or more endpoints, e.g. the images below xpkg.upbound.io from a pull-through
+// cache at harbor.example.com/xpkg-proxy.
+type Mirror struct {
+	// Prefix is the registry or repository prefix of the mirrored images,
+	// e.g. xpkg.upbound.io or xpkg.upbound.io/upbound.
+	Prefix string `json:"prefix"`
+
+	// Endpoints replace Prefix in the references of the mirrored images.
+	// They are tried in order. The original registry is not tried unless it
+	// is one of the endpoints.
+	Endpoints []string `json:"endpoints"`
+}
+
+// Mirrors is a set of mirrors. A reference is rewritten by the mirror with
+// the longest prefix matching its repository.
+type Mirrors []Mirror
+
+// Validate returns an error if any of the mirrors is invalid.
+func (m Mirrors) Validate() error {
+	seen := map[string]bool{}
+	for _, mi := range m {
+		p, err := normalize(mi.Prefix)
+		if err != nil {
+			return errors.Wrapf(err, errInvalidPrefixFmt, mi.Prefix)
+		}
+		if seen[p] {
+			return errors.Errorf(errDuplicatePrefixFmt, mi.Prefix)
+		}
+		seen[p] = true
+		if len(mi.Endpoints) == 0 {
+			return errors.Errorf(errNoEndpointsFmt, mi.Prefix)
+		}
+		for _, e := range mi.Endpoints {
+			if _, err := normalize(e); err != nil {
+				return errors.Wrapf(err, errInvalidEndpointFmt, e, mi.Prefix)
+			}
+		}
+	}
+	return nil
+}
+
+// Set returns the mirrors with the mirror of the supplied prefix replaced by,
+// or extended with, a mirror serving it from the supplied endpoints.
+func (m Mirrors) Set(prefix string, endpoints ...string) Mirrors {
+	out, _ := m.Unset(prefix)
+	return append(out, Mirror{Prefix: prefix, Endpoints: endpoints})
+}
+
+// Unset returns the mirrors without the mirror of the supplied prefix, and
+// whether such a mirror existed.
+func (m Mirrors) Unset(prefix string) (Mirrors, bool) {
+	p, err := normalize(prefix)
+	if err != nil {
+		return m, false
+	}
+	out := make(Mirrors, 0, len(m))
+	found := false
+	for _, mi := range m {
+		if mp, err := normalize(mi.Prefix); err == nil && mp == p {
+			found = true
+			continue
+		}
+		out = append(out, mi)
+	}
+	return out, found
+}
+
+// Repositories returns the repositories from which the images of the supplied
+// repository are served, in the order in which they should be tried. It
+// returns only the supplied repository if no mirror matches it, and an error
+// if the matching mirror has no endpoints.
+func (m Mirrors) Repositories(repo name.Repository) ([]name.Repository, error) {
+	mi, prefix, ok := m.match(repo)
+	if !ok {
+		return []name.Repository{repo}, nil
+	}
+	if len(mi.Endpoints) == 0 {
+		return nil, errors.Errorf(errNoEndpointsFmt, mi.Prefix)
+	}
+	rest := strings.TrimPrefix(repo.Name(), prefix)
+	repos := make([]name.Repository, len(mi.Endpoints))
+	for i, e := range mi.Endpoints {
+		ep, err := normalize(e)
+		if err != nil {
+			return nil, errors.Wrapf(err, errInvalidEndpointFmt, e, mi.Prefix)
+		}
+		if rest == "" && !strings.Contains(ep, "/") {
+			return nil, errors.Wrapf(errors.New(errRegistryEndpoint), errInvalidEndpointFmt, e, mi.Prefix)
+		}
+		r, err := name.NewRepository(ep + rest)
+		if err != nil {
+			return nil, errors.Wrapf(err, errInvalidEndpointFmt, e, mi.Prefix)
+		}
+		repos[i] = r
+	}
+	return repos, nil
+}
+
+// References returns the references from which the image with the supplied
+// reference is served, in the order in which they should be tried. Tags and
+// digests are preserved.
+func (m Mirrors) References(ref name.Reference) ([]name.Reference, error) {
+	repos, err := m.Repositories(ref.Context())
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]name.Reference, len(repos))
+	for i, r := range repos {
+		if d, ok := ref.(name.Digest); ok {
+			refs[i] = r.Digest(d.DigestStr())
+			continue
+		}
+		refs[i] = r.Tag(ref.Identifier())
+	}
+	return refs, nil
+}
+
+// Rewrite returns the supplied reference rewritten to the first endpoint of
+// the matching mirror, or unchanged if no mirror matches. It is meant for
+// references handed to clients that cannot fall back to other endpoints,
+// such as the package manager of a control plane.
+func (m Mirrors) Rewrite(ref name.Reference) (name.Reference, error) {
+	refs, err := m.References(ref)
+	if err != nil {
+		return nil, err
+	}
+	return refs[0], nil
+}
+
+// Origin returns the repository that the supplied repository mirrors, i.e.
+// the repository it has been rewritten from by the mirror with the longest
+// endpoint matching it. It returns false if no endpoint matches.
+func (m Mirrors) Origin(repo name.Repository) (name.Repository, bool) {
+	var (
+		origin   name.Repository
+		endpoint string
+	)
+	n := repo.Name()
+	for _, mi := range m {
+		p, err := normalize(mi.Prefix)
+		if err != nil {
+			continue
+		}
+		for _, e := range mi.Endpoints {
+			ep, err := normalize(e)
+			if err != nil || len(ep) <= len(endpoint) || (n != ep && !strings.HasPrefix(n, ep+"/")) {
+				continue
+			}
+			o, err := name.NewRepository(p + strings.TrimPrefix(n, ep))
+			if err != nil {
+				continue
+			}
+			origin, endpoint = o, ep
+		}
+	}
+	return origin, endpoint != ""
+}
+
+// match returns the mirror with the longest prefix matching the supplied
+// repository, along with its normalized prefix.
+func (m Mirrors) match(repo name.Repository) (Mirror, string, bool) {
+	var (
+		match  Mirror
+		prefix string
+	)
+	n := repo.Name()
+	for _, mi := range m {
+		p, err := normalize(mi.Prefix)
+		if err != nil {
+			continue
+		}
+		if (n == p || strings.HasPrefix(n, p+"/")) && len(p) > len(prefix) {
+			match, prefix = mi, p
+		}
+	}
+	return match, prefix, prefix != ""
+}
+
+// normalize returns the fully qualified name of the supplied registry or
+// repository prefix, e.g. index.docker.io for docker.io.
+func normalize(prefix string) (string, error) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		return "", errors.New(errEmptyPrefix)
+	}
+	if !strings.Contains(prefix, "/") {
+		r, err := name.NewRegistry(prefix)
+		if err != nil {
+			return "", err
+		}
+		return r.Name(), nil
+	}
+	r, err := name.NewRepository(prefix)
+	if err != nil {
+		return "", err
+	}
+	return r.Name(), nil
+}
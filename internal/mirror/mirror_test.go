@@ -0,0 +1,227 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+var mirrors = Mirrors{
+	{Prefix: "xpkg.upbound.io", Endpoints: []string{"harbor.example.com/xpkg-proxy", "xpkg.upbound.io"}},
+	{Prefix: "xpkg.upbound.io/internal", Endpoints: []string{"registry.example.com"}},
+	{Prefix: "docker.io", Endpoints: []string{"harbor.example.com/hub-proxy"}},
+}
+
+func TestReferences(t *testing.T) {
+	type want struct {
+		refs []string
+		err  error
+	}
+	cases := map[string]struct {
+		reason  string
+		mirrors Mirrors
+		ref     string
+		want    want
+	}{
+		"NoMatch": {
+			reason:  "A reference that no mirror matches should be returned as is.",
+			mirrors: mirrors,
+			ref:     "ghcr.io/crossplane/provider-aws:v1.0.0",
+			want: want{
+				refs: []string{"ghcr.io/crossplane/provider-aws:v1.0.0"},
+			},
+		},
+		"Registry": {
+			reason:  "A reference below a mirrored registry should be rewritten to every endpoint, in order.",
+			mirrors: mirrors,
+			ref:     "xpkg.upbound.io/upbound/provider-aws:v1.0.0",
+			want: want{
+				refs: []string{
+					"harbor.example.com/xpkg-proxy/upbound/provider-aws:v1.0.0",
+					"xpkg.upbound.io/upbound/provider-aws:v1.0.0",
+				},
+			},
+		},
+		"LongestPrefix": {
+			reason:  "The mirror with the longest matching prefix should apply.",
+			mirrors: mirrors,
+			ref:     "xpkg.upbound.io/internal/provider-secret:v1.0.0",
+			want: want{
+				refs: []string{"registry.example.com/provider-secret:v1.0.0"},
+			},
+		},
+		"PathSegments": {
+			reason:  "Prefixes should only match whole path segments.",
+			mirrors: Mirrors{{Prefix: "xpkg.upbound.io/up", Endpoints: []string{"registry.example.com"}}},
+			ref:     "xpkg.upbound.io/upbound/provider-aws:v1.0.0",
+			want: want{
+				refs: []string{"xpkg.upbound.io/upbound/provider-aws:v1.0.0"},
+			},
+		},
+		"DockerHub": {
+			reason:  "References to Docker Hub should match a mirror of docker.io.",
+			mirrors: mirrors,
+			ref:     "crossplane/provider-aws:v1.0.0",
+			want: want{
+				refs: []string{"harbor.example.com/hub-proxy/crossplane/provider-aws:v1.0.0"},
+			},
+		},
+		"Digest": {
+			reason:  "Digests should be preserved.",
+			mirrors: mirrors,
+			ref:     "xpkg.upbound.io/upbound/provider-aws@sha256:d507e508234732c6dc95d29c8a8c932fa8fa6a229231e309927641f99933892e",
+			want: want{
+				refs: []string{
+					"harbor.example.com/xpkg-proxy/upbound/provider-aws@sha256:d507e508234732c6dc95d29c8a8c932fa8fa6a229231e309927641f99933892e",
+					"xpkg.upbound.io/upbound/provider-aws@sha256:d507e508234732c6dc95d29c8a8c932fa8fa6a229231e309927641f99933892e",
+				},
+			},
+		},
+		"RegistryEndpointOfRepository": {
+			reason:  "A repository should not be rewritten to a bare registry.",
+			mirrors: Mirrors{{Prefix: "xpkg.upbound.io/upbound/provider-aws", Endpoints: []string{"registry.example.com"}}},
+			ref:     "xpkg.upbound.io/upbound/provider-aws:v1.0.0",
+			want: want{
+				err: errors.Wrapf(errors.New(errRegistryEndpoint), errInvalidEndpointFmt, "registry.example.com", "xpkg.upbound.io/upbound/provider-aws"),
+			},
+		},
+		"NoEndpoints": {
+			reason:  "A mirror without endpoints should be rejected.",
+			mirrors: Mirrors{{Prefix: "xpkg.upbound.io"}},
+			ref:     "xpkg.upbound.io/upbound/provider-aws:v1.0.0",
+			want: want{
+				err: errors.Errorf(errNoEndpointsFmt, "xpkg.upbound.io"),
+			},
+		},
+	}
+
+	for n, tc := range cases {
+		t.Run(n, func(t *testing.T) {
+			ref, err := name.ParseReference(tc.ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			refs, err := tc.mirrors.References(ref)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nReferences(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			var got []string
+			for _, r := range refs {
+				got = append(got, r.Name())
+			}
+			if diff := cmp.Diff(tc.want.refs, got); diff != "" {
+				t.Errorf("\n%s\nReferences(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestOrigin(t *testing.T) {
+	type want struct {
+		origin string
+		ok     bool
+	}
+	cases := map[string]struct {
+		reason string
+		repo   string
+		want   want
+	}{
+		"Mirrored": {
+			reason: "A repository below an endpoint should map back to the repository it mirrors.",
+			repo:   "harbor.example.com/xpkg-proxy/upbound/provider-aws",
+			want: want{
+				origin: "xpkg.upbound.io/upbound/provider-aws",
+				ok:     true,
+			},
+		},
+		"LongestEndpoint": {
+			reason: "The longest matching endpoint should apply.",
+			repo:   "registry.example.com/provider-secret",
+			want: want{
+				origin: "xpkg.upbound.io/internal/provider-secret",
+				ok:     true,
+			},
+		},
+		"NotMirrored": {
+			reason: "A repository below no endpoint should not map to any repository.",
+			repo:   "harbor.example.com/other/provider-aws",
+			want: want{
+				ok: false,
+			},
+		},
+	}
+
+	for n, tc := range cases {
+		t.Run(n, func(t *testing.T) {
+			repo, err := name.NewRepository(tc.repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			origin, ok := mirrors.Origin(repo)
+			if diff := cmp.Diff(tc.want.ok, ok); diff != "" {
+				t.Errorf("\n%s\nOrigin(...): -want ok, +got ok:\n%s", tc.reason, diff)
+			}
+			if !ok {
+				return
+			}
+			if diff := cmp.Diff(tc.want.origin, origin.Name()); diff != "" {
+				t.Errorf("\n%s\nOrigin(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		mirrors Mirrors
+		want    error
+	}{
+		"Valid": {
+			reason:  "Mirrors with valid prefixes and endpoints should be valid.",
+			mirrors: mirrors,
+		},
+		"Duplicate": {
+			reason:  "Prefixes referring to the same registry should be rejected.",
+			mirrors: Mirrors{{Prefix: "docker.io", Endpoints: []string{"a.example.com"}}, {Prefix: "index.docker.io", Endpoints: []string{"b.example.com"}}},
+			want:    errors.Errorf(errDuplicatePrefixFmt, "index.docker.io"),
+		},
+		"NoEndpoints": {
+			reason:  "Mirrors without endpoints should be rejected.",
+			mirrors: Mirrors{{Prefix: "xpkg.upbound.io"}},
+			want:    errors.Errorf(errNoEndpointsFmt, "xpkg.upbound.io"),
+		},
+		"EmptyPrefix": {
+			reason:  "Mirrors without a prefix should be rejected.",
+			mirrors: Mirrors{{Endpoints: []string{"a.example.com"}}},
+			want:    errors.Wrapf(errors.New(errEmptyPrefix), errInvalidPrefixFmt, ""),
+		},
+	}
+
+	for n, tc := range cases {
+		t.Run(n, func(t *testing.T) {
+			err := tc.mirrors.Validate()
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nValidate(): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -21,6 +21,8 @@ import (
 
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/upbound/up/internal/mirror"
 )
 
 // Type is a type of Upbound profile.
@@ -64,6 +66,10 @@ type Profile struct {
 	// * flags
 	// * environment variables
 	BaseConfig map[string]string `json:"base,omitempty"`
+
+	// Mirrors are the mirrors from which images are pulled instead of their
+	// registries when this profile is selected.
+	Mirrors mirror.Mirrors `json:"mirrors,omitempty"`
 }
 
 // Validate returns an error if the profile is invalid.
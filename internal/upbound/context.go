@@ -54,6 +54,7 @@ const (
 
 const (
 	errProfileNotFoundFmt = "profile not found with identifier: %s"
+	errInvalidMirrorsFmt  = "invalid registry mirrors in profile %s"
 )
 
 // Context includes common data that Upbound consumers may utilize.
@@ -76,6 +77,7 @@ type Context struct {
 	WrapTransport func(rt http.RoundTripper) http.RoundTripper
 
 	allowMissingProfile bool
+	allowInvalidMirrors bool
 	cfgPath             string
 	fs                  afero.Fs
 }
@@ -91,6 +93,15 @@ func AllowMissingProfile() Option {
 	}
 }
 
+// AllowInvalidMirrors indicates that Context should still be returned even if
+// the registry mirrors of the profile are invalid, e.g. so that they can be
+// fixed.
+func AllowInvalidMirrors() Option {
+	return func(ctx *Context) {
+		ctx.allowInvalidMirrors = true
+	}
+}
+
 // NewFromFlags constructs a new context from flags.
 func NewFromFlags(f Flags, opts ...Option) (*Context, error) { //nolint:gocyclo
 	p, err := config.GetDefaultPath()
@@ -138,6 +149,9 @@ func NewFromFlags(f Flags, opts ...Option) (*Context, error) { //nolint:gocyclo
 		c.Profile = p
 		c.ProfileName = f.Profile
 	}
+	if err := c.Profile.Mirrors.Validate(); err != nil && !c.allowInvalidMirrors {
+		return nil, errors.Wrapf(err, errInvalidMirrorsFmt, c.ProfileName)
+	}
 
 	of, err := c.applyOverrides(f, c.ProfileName)
 	if err != nil {
@@ -45,6 +45,20 @@ var (
 		}
 	  }
 	`
+	invalidMirrorsConfigJSON = `{
+		"upbound": {
+		  "default": "default",
+		  "profiles": {
+			"default": {
+			  "id": "someone@upbound.io",
+			  "type": "user",
+			  "session": "a token",
+			  "mirrors": [{"prefix": "xpkg.upbound.io", "endpoints": []}]
+			}
+		  }
+		}
+	  }
+	`
 	baseConfigJSON = `{
 		"upbound": {
 		  "default": "default",
@@ -176,6 +190,19 @@ func TestNewFromFlags(t *testing.T) {
 				},
 			},
 		},
+		"ErrorInvalidMirrors": {
+			reason: "We should return an error if the mirrors of the profile are invalid.",
+			args: args{
+				flags: []string{},
+				opts: []Option{
+					withConfig(invalidMirrorsConfigJSON),
+					withPath("/.up/config.json"),
+				},
+			},
+			want: want{
+				err: errors.Wrapf(errors.New("mirror xpkg.upbound.io has no endpoints"), errInvalidMirrorsFmt, "default"),
+			},
+		},
 		"PreExistingProfileNoBaseConfig": {
 			reason: "We should successfully return a Context if a pre-existing profile exists, but does not have a base config",
 			args: args{
@@ -120,7 +120,8 @@ func New(pkg, ab, ex parser.Backend, pp parser.Parser, ep *examples.Parser) *Bui
 }
 
 type buildOpts struct {
-	base v1.Image
+	base     v1.Image
+	platform *v1.Platform
 }
 
 // A BuildOpt modifies how a package is built.
@@ -134,6 +135,14 @@ func WithController(img v1.Image) BuildOpt {
 	}
 }
 
+// WithPlatform sets the platform recorded in the configuration of the package
+// image. If not set, the platform of the controller image is retained.
+func WithPlatform(p v1.Platform) BuildOpt {
+	return func(o *buildOpts) {
+		o.platform = &p
+	}
+}
+
 type AuthExtension struct {
 	Version      string `yaml:"version"`
 	Discriminant string `yaml:"discriminant"`
@@ -284,6 +293,13 @@ func (b *Builder) Build(ctx context.Context, opts ...BuildOpt) (v1.Image, runtim
 		return nil, nil, errors.Wrap(err, errMutateConfig)
 	}
 
+	if bOpts.platform != nil {
+		bOpts.base, err = setPlatform(bOpts.base, *bOpts.platform)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, errMutateConfig)
+		}
+	}
+
 	return bOpts.base, meta, nil
 }
 
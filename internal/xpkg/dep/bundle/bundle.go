@@ -0,0 +1,287 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bundle reads and writes dependency bundles, which allow to resolve
+// package dependencies without access to their registries.
+//
+// A bundle is a tar archive holding the images of a set of packages as an OCI
+// image layout, along with the cache entries of the packages below cache/.
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/spf13/afero"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	layoutFile = "oci-layout"
+	indexFile  = "index.json"
+	blobsDir   = "blobs"
+	cacheDir   = "cache"
+
+	errWriteImageFmt  = "failed to write image %s to bundle"
+	errWriteEntryFmt  = "failed to write cache entry %s to bundle"
+	errInvalidPathFmt = "invalid path %s in bundle"
+	errReadBundle     = "failed to read bundle"
+	errNoIndex        = "bundle does not contain an image index"
+)
+
+// A Writer writes a bundle.
+type Writer struct {
+	tw    *tar.Writer
+	blobs map[v1.Hash]bool
+	index v1.IndexManifest
+}
+
+// NewWriter returns a Writer writing a bundle to the supplied io.Writer. The
+// bundle is only complete once the Writer is closed.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		tw:    tar.NewWriter(w),
+		blobs: map[v1.Hash]bool{},
+		index: v1.IndexManifest{
+			SchemaVersion: 2,
+			MediaType:     types.OCIImageIndex,
+		},
+	}
+}
+
+// AddImage adds the image with the supplied reference to the bundle.
+func (w *Writer) AddImage(ref name.Reference, img v1.Image) error {
+	if err := w.addImage(ref, img); err != nil {
+		return errors.Wrapf(err, errWriteImageFmt, ref.Name())
+	}
+	return nil
+}
+
+func (w *Writer) addImage(ref name.Reference, img v1.Image) error {
+	layers, err := img.Layers()
+	if err != nil {
+		return err
+	}
+	for _, l := range layers {
+		h, err := l.Digest()
+		if err != nil {
+			return err
+		}
+		size, err := l.Size()
+		if err != nil {
+			return err
+		}
+		rc, err := l.Compressed()
+		if err != nil {
+			return err
+		}
+		err = w.writeBlob(h, size, rc)
+		rc.Close() // nolint:errcheck
+		if err != nil {
+			return err
+		}
+	}
+	for _, raw := range []func() ([]byte, error){img.RawConfigFile, img.RawManifest} {
+		b, err := raw()
+		if err != nil {
+			return err
+		}
+		h, size, err := v1.SHA256(bytes.NewReader(b))
+		if err != nil {
+			return err
+		}
+		if err := w.writeBlob(h, size, bytes.NewReader(b)); err != nil {
+			return err
+		}
+	}
+	desc, err := partial.Descriptor(img)
+	if err != nil {
+		return err
+	}
+	desc.Annotations = map[string]string{refNameAnnotation: ref.Name()}
+	w.index.Manifests = append(w.index.Manifests, *desc)
+	return nil
+}
+
+// AddCacheEntry adds the cache entry at the supplied path, relative to the
+// cache root, to the bundle.
+func (w *Writer) AddCacheEntry(cfs afero.Fs, root, entry string) error {
+	err := afero.Walk(cfs, filepath.Join(root, entry), func(p string, info fs.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		f, err := cfs.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close() // nolint:errcheck
+		return w.writeFile(path.Join(cacheDir, filepath.ToSlash(rel)), info.Size(), f)
+	})
+	return errors.Wrapf(err, errWriteEntryFmt, entry)
+}
+
+// Close writes the image index of the bundle and closes it. It does not close
+// the underlying io.Writer.
+func (w *Writer) Close() error {
+	b, err := json.Marshal(w.index)
+	if err != nil {
+		return err
+	}
+	if err := w.writeFile(indexFile, int64(len(b)), bytes.NewReader(b)); err != nil {
+		return err
+	}
+	l := []byte(`{"imageLayoutVersion": "1.0.0"}`)
+	if err := w.writeFile(layoutFile, int64(len(l)), bytes.NewReader(l)); err != nil {
+		return err
+	}
+	return w.tw.Close()
+}
+
+func (w *Writer) writeBlob(h v1.Hash, size int64, r io.Reader) error {
+	if w.blobs[h] {
+		return nil
+	}
+	w.blobs[h] = true
+	return w.writeFile(path.Join(blobsDir, h.Algorithm, h.Hex), size, r)
+}
+
+func (w *Writer) writeFile(name string, size int64, r io.Reader) error {
+	if err := w.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     0o644,
+	}); err != nil {
+		return err
+	}
+	_, err := io.Copy(w.tw, r)
+	return err
+}
+
+// Import reads the bundle from the supplied io.Reader. Its images are added to
+// the supplied Store, replacing images with the same reference, and its cache
+// entries are written to the cache at root, replacing existing entries. It
+// returns the paths of the imported cache entries, relative to the cache root.
+func Import(r io.Reader, s *Store, cfs afero.Fs, root string) ([]string, error) { // nolint:gocyclo
+	var index *v1.IndexManifest
+	entries := map[string]bool{}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, errReadBundle)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		p := path.Clean(hdr.Name)
+		if path.IsAbs(p) || p == ".." || strings.HasPrefix(p, "../") {
+			return nil, errors.Errorf(errInvalidPathFmt, hdr.Name)
+		}
+		switch {
+		case p == indexFile:
+			index = &v1.IndexManifest{}
+			if err := json.NewDecoder(tr).Decode(index); err != nil {
+				return nil, errors.Wrap(err, errReadBundle)
+			}
+		case path.Dir(path.Dir(p)) == blobsDir:
+			h, err := v1.NewHash(path.Base(path.Dir(p)) + ":" + path.Base(p))
+			if err != nil {
+				return nil, errors.Errorf(errInvalidPathFmt, hdr.Name)
+			}
+			if err := s.addBlob(h, tr); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(p, cacheDir+"/"):
+			rel := filepath.FromSlash(strings.TrimPrefix(p, cacheDir+"/"))
+			entry, err := entryOf(rel)
+			if err != nil {
+				return nil, errors.Errorf(errInvalidPathFmt, hdr.Name)
+			}
+			if !entries[entry] {
+				// replace the existing entry rather than merging the two
+				if err := cfs.RemoveAll(filepath.Join(root, entry)); err != nil {
+					return nil, err
+				}
+				entries[entry] = true
+			}
+			if err := writeFile(cfs, filepath.Join(root, rel), tr); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if index == nil {
+		return nil, errors.New(errNoIndex)
+	}
+	for _, desc := range index.Manifests {
+		if err := s.addDescriptor(desc); err != nil {
+			return nil, err
+		}
+	}
+
+	imported := make([]string, 0, len(entries))
+	for e := range entries {
+		imported = append(imported, e)
+	}
+	sort.Strings(imported)
+	return imported, nil
+}
+
+// entryOf returns the path of the cache entry holding the file at the
+// supplied path, i.e. the first directory containing the version of a
+// package.
+func entryOf(p string) (string, error) {
+	parts := strings.Split(p, string(filepath.Separator))
+	for i, part := range parts[:len(parts)-1] {
+		if strings.Contains(part, "@") {
+			return filepath.Join(parts[:i+1]...), nil
+		}
+	}
+	return "", os.ErrNotExist
+}
+
+func writeFile(cfs afero.Fs, p string, r io.Reader) error {
+	if err := cfs.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := cfs.Create(p)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close() // nolint:errcheck
+		return err
+	}
+	return f.Close()
+}
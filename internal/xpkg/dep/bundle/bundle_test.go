@@ -0,0 +1,208 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/spf13/afero"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+const (
+	entryAws = "xpkg.upbound.io/upbound/provider-aws@v1.0.0"
+)
+
+func reference(t *testing.T, s string) name.Reference {
+	t.Helper()
+	ref, err := name.ParseReference(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ref
+}
+
+func randomImage(t *testing.T) v1.Image {
+	t.Helper()
+	img, err := random.Image(256, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func writeBundle(t *testing.T, imgs map[string]v1.Image, cfs afero.Fs, entries ...string) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	for ref, img := range imgs {
+		if err := w.AddImage(reference(t, ref), img); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, e := range entries {
+		if err := w.AddCacheEntry(cfs, "/cache", e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func tarball(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for n, b := range files {
+		if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: n, Size: int64(len(b)), Mode: 0o644}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestImport(t *testing.T) {
+	img := randomImage(t)
+	digest, _ := img.Digest()
+
+	src := afero.NewMemMapFs()
+	afero.WriteFile(src, filepath.Join("/cache", entryAws, "package.ndjson"), []byte("{}"), 0o644)         // nolint:errcheck
+	afero.WriteFile(src, filepath.Join("/cache", entryAws, digest.String()), []byte{}, 0o644)              // nolint:errcheck
+	afero.WriteFile(src, filepath.Join("/cache", "xpkg.upbound.io/upbound/other@v1.0.0", "x"), nil, 0o644) // nolint:errcheck
+
+	type want struct {
+		entries []string
+		files   map[string]string
+		err     error
+	}
+	cases := map[string]struct {
+		reason string
+		bundle []byte
+		want   want
+	}{
+		"Success": {
+			reason: "Should import the images and cache entries of the bundle, replacing existing entries.",
+			bundle: writeBundle(t, map[string]v1.Image{"xpkg.upbound.io/upbound/provider-aws:v1.0.0": img}, src, entryAws),
+			want: want{
+				entries: []string{entryAws},
+				files: map[string]string{
+					filepath.Join("/cache", entryAws, "package.ndjson"): "{}",
+					filepath.Join("/cache", entryAws, digest.String()):  "",
+				},
+			},
+		},
+		"InvalidPath": {
+			reason: "Should refuse to import files outside of the cache.",
+			bundle: tarball(t, map[string][]byte{"cache/../../etc/passwd": []byte("x")}),
+			want: want{
+				err: errors.Errorf(errInvalidPathFmt, "cache/../../etc/passwd"),
+			},
+		},
+		"CorruptBlob": {
+			reason: "Should refuse to import blobs that do not match their digest.",
+			bundle: tarball(t, map[string][]byte{"blobs/sha256/" + digest.Hex: []byte("x")}),
+			want: want{
+				err: errors.Errorf(errDigestMismatchFmt, digest, "2d711642b726b04401627ca9fbac32f5c8530fb1903cc4db02258717921a4881"),
+			},
+		},
+		"NoIndex": {
+			reason: "Should return an error if the bundle has no image index.",
+			bundle: tarball(t, map[string][]byte{"oci-layout": []byte("{}")}),
+			want: want{
+				err: errors.New(errNoIndex),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dst := afero.NewMemMapFs()
+			// a stale file of an existing entry should be removed.
+			afero.WriteFile(dst, filepath.Join("/cache", entryAws, "stale"), nil, 0o644) // nolint:errcheck
+
+			s := NewStore(t.TempDir())
+			entries, err := Import(bytes.NewReader(tc.bundle), s, dst, "/cache")
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\nImport(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.entries, entries); diff != "" {
+				t.Errorf("\n%s\nImport(...): -want entries, +got entries:\n%s", tc.reason, diff)
+			}
+			files := map[string]string{}
+			afero.Walk(dst, "/", func(p string, info fs.FileInfo, err error) error { // nolint:errcheck
+				if err == nil && !info.IsDir() {
+					b, _ := afero.ReadFile(dst, p)
+					files[p] = string(b)
+				}
+				return nil
+			})
+			if diff := cmp.Diff(tc.want.files, files); diff != "" {
+				t.Errorf("\n%s\nImport(...): -want files, +got files:\n%s", tc.reason, diff)
+			}
+			got, err := s.Image(reference(t, "xpkg.upbound.io/upbound/provider-aws:v1.0.0"))
+			if err != nil {
+				t.Fatalf("\n%s\nImage(...): unexpected error: %s", tc.reason, err)
+			}
+			if d, _ := got.Digest(); d != digest {
+				t.Errorf("\n%s\nImage(...): want digest %s, got %s", tc.reason, digest, d)
+			}
+		})
+	}
+}
+
+func TestStoreReplace(t *testing.T) {
+	s := NewStore(t.TempDir())
+	ref := "xpkg.upbound.io/upbound/provider-aws:v1.0.0"
+	for i := 0; i < 2; i++ {
+		img := randomImage(t)
+		b := writeBundle(t, map[string]v1.Image{ref: img}, afero.NewMemMapFs())
+		if _, err := Import(bytes.NewReader(b), s, afero.NewMemMapFs(), "/cache"); err != nil {
+			t.Fatal(err)
+		}
+		want, _ := img.Digest()
+		desc, err := s.Descriptor(reference(t, ref))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if desc.Digest != want {
+			t.Errorf("\nImporting an image should replace the image with the same reference.\nDescriptor(...): want digest %s, got %s", want, desc.Digest)
+		}
+	}
+	descs, _ := s.descriptors()
+	if len(descs) != 1 {
+		t.Errorf("\nImporting an image should replace the image with the same reference.\ndescriptors(): want 1 descriptor, got %d", len(descs))
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"context"
+	"os"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+
+	"github.com/upbound/up/internal/xpkg/dep/marshaler/xpkg"
+)
+
+const (
+	errOfflineFmt = "%s is not available offline, import a bundle containing it with up xpkg dep import-bundle"
+)
+
+// Cache is the package cache the OfflineFetcher falls back to.
+type Cache interface {
+	Get(v1beta1.Dependency) (*xpkg.ParsedPackage, error)
+	Versions(v1beta1.Dependency) ([]string, error)
+}
+
+// OfflineFetcher serves package images, digests and tags only from a Store
+// and the package cache, without accessing any registry.
+type OfflineFetcher struct {
+	s *Store
+	c Cache
+}
+
+// NewOfflineFetcher returns an OfflineFetcher serving from the supplied Store
+// and package cache.
+func NewOfflineFetcher(s *Store, c Cache) *OfflineFetcher {
+	return &OfflineFetcher{s: s, c: c}
+}
+
+// Fetch returns the image with the supplied reference from the Store. Images
+// are not available from the package cache, which only holds their contents.
+func (f *OfflineFetcher) Fetch(_ context.Context, ref name.Reference, _ ...string) (v1.Image, error) {
+	img, err := f.s.Image(ref)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errors.Errorf(errOfflineFmt, ref.Name())
+	}
+	return img, err
+}
+
+// Head returns the descriptor of the image with the supplied reference from
+// the Store or, failing that, its digest as recorded in the package cache.
+func (f *OfflineFetcher) Head(_ context.Context, ref name.Reference, _ ...string) (*v1.Descriptor, error) {
+	desc, err := f.s.Descriptor(ref)
+	if !errors.Is(err, os.ErrNotExist) {
+		return desc, err
+	}
+	p, err := f.c.Get(v1beta1.Dependency{Package: ref.Context().Name(), Constraints: ref.Identifier()})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errors.Errorf(errOfflineFmt, ref.Name())
+	}
+	if err != nil {
+		return nil, err
+	}
+	h, err := v1.NewHash(p.Digest())
+	if err != nil {
+		return nil, err
+	}
+	return &v1.Descriptor{Digest: h}, nil
+}
+
+// Tags returns the tags of the repository of the supplied reference that are
+// in the Store or in the package cache, in no particular order.
+func (f *OfflineFetcher) Tags(_ context.Context, ref name.Reference, _ ...string) ([]string, error) {
+	tags, err := f.s.Tags(ref.Context())
+	if err != nil {
+		return nil, err
+	}
+	vers, err := f.c.Versions(v1beta1.Dependency{Package: ref.Context().Name()})
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		seen[t] = true
+	}
+	for _, v := range vers {
+		if !seen[v] {
+			tags = append(tags, v)
+			seen[v] = true
+		}
+	}
+	return tags, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/spf13/afero"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+
+	"github.com/upbound/up/internal/xpkg/dep/marshaler/xpkg"
+)
+
+const cachedDigest = "sha256:d507e508234732c6dc95d29c8a8c932fa8fa6a229231e309927641f99933892e"
+
+// cache is a package cache holding v0.9.0 of every package.
+type cache struct{}
+
+func (cache) Get(d v1beta1.Dependency) (*xpkg.ParsedPackage, error) {
+	if d.Constraints != "v0.9.0" {
+		return nil, os.ErrNotExist
+	}
+	return &xpkg.ParsedPackage{SHA: cachedDigest, Ver: d.Constraints}, nil
+}
+
+func (cache) Versions(v1beta1.Dependency) ([]string, error) {
+	return []string{"v0.9.0", "v1.0.0"}, nil
+}
+
+func TestOfflineFetcher(t *testing.T) {
+	img := randomImage(t)
+	digest, _ := img.Digest()
+	s := NewStore(t.TempDir())
+	b := writeBundle(t, map[string]v1.Image{"xpkg.upbound.io/upbound/provider-aws:v1.0.0": img}, afero.NewMemMapFs())
+	if _, err := Import(bytes.NewReader(b), s, afero.NewMemMapFs(), "/cache"); err != nil {
+		t.Fatal(err)
+	}
+	f := NewOfflineFetcher(s, cache{})
+
+	type want struct {
+		digest   string
+		headErr  error
+		tags     []string
+		fetchErr error
+	}
+	cases := map[string]struct {
+		reason string
+		ref    string
+		want   want
+	}{
+		"Store": {
+			reason: "Should serve images, digests and tags in the store.",
+			ref:    "xpkg.upbound.io/upbound/provider-aws:v1.0.0",
+			want: want{
+				digest: digest.String(),
+				tags:   []string{"v0.9.0", "v1.0.0"},
+			},
+		},
+		"Cache": {
+			reason: "Should serve digests and tags in the cache, but no images.",
+			ref:    "xpkg.upbound.io/upbound/provider-aws:v0.9.0",
+			want: want{
+				digest:   cachedDigest,
+				tags:     []string{"v0.9.0", "v1.0.0"},
+				fetchErr: errors.Errorf(errOfflineFmt, "xpkg.upbound.io/upbound/provider-aws:v0.9.0"),
+			},
+		},
+		"NotAvailable": {
+			reason: "Should return an error for images that are neither in the store nor in the cache.",
+			ref:    "xpkg.upbound.io/upbound/provider-aws:v0.8.0",
+			want: want{
+				headErr:  errors.Errorf(errOfflineFmt, "xpkg.upbound.io/upbound/provider-aws:v0.8.0"),
+				tags:     []string{"v0.9.0", "v1.0.0"},
+				fetchErr: errors.Errorf(errOfflineFmt, "xpkg.upbound.io/upbound/provider-aws:v0.8.0"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ref := reference(t, tc.ref)
+
+			desc, err := f.Head(context.Background(), ref)
+			if diff := cmp.Diff(tc.want.headErr, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nHead(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err == nil {
+				if diff := cmp.Diff(tc.want.digest, desc.Digest.String()); diff != "" {
+					t.Errorf("\n%s\nHead(...): -want digest, +got digest:\n%s", tc.reason, diff)
+				}
+			}
+
+			tags, err := f.Tags(context.Background(), ref)
+			if err != nil {
+				t.Fatalf("\n%s\nTags(...): unexpected error: %s", tc.reason, err)
+			}
+			sort.Strings(tags)
+			if diff := cmp.Diff(tc.want.tags, tags); diff != "" {
+				t.Errorf("\n%s\nTags(...): -want, +got:\n%s", tc.reason, diff)
+			}
+
+			_, err = f.Fetch(context.Background(), ref)
+			if diff := cmp.Diff(tc.want.fetchErr, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nFetch(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/match"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	// StoreDir is the directory of the image store within the package cache.
+	StoreDir = ".images"
+
+	// refNameAnnotation is the annotation holding the reference of an image
+	// in the index of an OCI image layout.
+	refNameAnnotation = "org.opencontainers.image.ref.name"
+
+	errNotInStoreFmt        = "%s is not in the image store"
+	errUnsupportedDigestFmt = "unsupported digest %s"
+	errDigestMismatchFmt    = "content of blob %s does not match its digest, got sha256:%s"
+)
+
+// A Store is an image store backed by an OCI image layout on disk. Images are
+// indexed by their reference, e.g. xpkg.upbound.io/upbound/provider-aws:v1.0.0.
+type Store struct {
+	dir string
+}
+
+// NewStore returns a Store backed by the OCI image layout in the supplied
+// directory. The layout is created when the first image is added.
+func NewStore(dir string) *Store {
+	return &Store{dir: filepath.Clean(dir)}
+}
+
+// Image returns the image with the supplied reference.
+func (s *Store) Image(ref name.Reference) (v1.Image, error) {
+	desc, err := s.Descriptor(ref)
+	if err != nil {
+		return nil, err
+	}
+	return layout.Path(s.dir).Image(desc.Digest)
+}
+
+// Descriptor returns the descriptor of the image with the supplied reference.
+// The returned error satisfies os.IsNotExist if the store does not hold the
+// image.
+func (s *Store) Descriptor(ref name.Reference) (*v1.Descriptor, error) {
+	descs, err := s.descriptors()
+	if err != nil {
+		return nil, err
+	}
+	for i, d := range descs {
+		r, err := name.ParseReference(d.Annotations[refNameAnnotation])
+		if err != nil || r.Context().Name() != ref.Context().Name() {
+			continue
+		}
+		if dig, ok := ref.(name.Digest); ok && d.Digest.String() == dig.DigestStr() {
+			return &descs[i], nil
+		}
+		if r.Identifier() == ref.Identifier() {
+			return &descs[i], nil
+		}
+	}
+	return nil, errors.Wrapf(os.ErrNotExist, errNotInStoreFmt, ref.Name())
+}
+
+// Tags returns the tags of the supplied repository in the store, sorted in
+// ascending order.
+func (s *Store) Tags(repo name.Repository) ([]string, error) {
+	descs, err := s.descriptors()
+	if err != nil {
+		return nil, err
+	}
+	tags := []string{}
+	for _, d := range descs {
+		t, err := name.NewTag(d.Annotations[refNameAnnotation], name.StrictValidation)
+		if err != nil || t.Context().Name() != repo.Name() {
+			continue
+		}
+		tags = append(tags, t.TagStr())
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// addBlob writes the blob with the supplied digest to the store, unless the
+// store already holds it. The blob is removed if its content does not match
+// the digest.
+func (s *Store) addBlob(h v1.Hash, r io.Reader) error {
+	p, err := s.layout()
+	if err != nil {
+		return err
+	}
+	if h.Algorithm != "sha256" {
+		return errors.Errorf(errUnsupportedDigestFmt, h)
+	}
+	if rc, err := p.Blob(h); err == nil {
+		return rc.Close()
+	}
+	hr := sha256.New()
+	if err := p.WriteBlob(h, io.NopCloser(io.TeeReader(r, hr))); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(hr.Sum(nil)); got != h.Hex {
+		_ = p.RemoveBlob(h)
+		return errors.Errorf(errDigestMismatchFmt, h, got)
+	}
+	return nil
+}
+
+// addDescriptor indexes the image with the supplied descriptor, replacing any
+// image with the same reference. Its blobs must have been added beforehand.
+func (s *Store) addDescriptor(desc v1.Descriptor) error {
+	p, err := s.layout()
+	if err != nil {
+		return err
+	}
+	if err := p.RemoveDescriptors(match.Annotation(refNameAnnotation, desc.Annotations[refNameAnnotation])); err != nil {
+		return err
+	}
+	return p.AppendDescriptor(desc)
+}
+
+// layout returns the OCI image layout of the store, creating it if it does not
+// exist yet.
+func (s *Store) layout() (layout.Path, error) {
+	p, err := layout.FromPath(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return layout.Write(s.dir, empty.Index)
+	}
+	return p, err
+}
+
+func (s *Store) descriptors() ([]v1.Descriptor, error) {
+	ii, err := layout.ImageIndexFromPath(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m, err := ii.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	return m.Manifests, nil
+}
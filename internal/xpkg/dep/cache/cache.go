@@ -53,6 +53,9 @@ type Local struct {
 	mu     sync.RWMutex
 	pkgres XpkgMarshaler
 	root   string
+	// maxSize is the size in bytes beyond which the least recently used
+	// entries are evicted. The cache size is not limited if it is zero.
+	maxSize int64
 
 	closed        bool
 	subs          []chan Event
@@ -119,6 +122,15 @@ func WithWatchInterval(i *time.Duration) Option {
 	}
 }
 
+// WithMaxSize limits the size of Local to the supplied number of bytes. The
+// least recently used entries are evicted when a new entry is stored and the
+// cache grows beyond that size.
+func WithMaxSize(size int64) Option {
+	return func(l *Local) {
+		l.maxSize = size
+	}
+}
+
 // Get retrieves an image from the LocalCache.
 func (c *Local) Get(k v1beta1.Dependency) (*xpkg.ParsedPackage, error) {
 	c.mu.RLock()
@@ -128,10 +140,12 @@ func (c *Local) Get(k v1beta1.Dependency) (*xpkg.ParsedPackage, error) {
 		return nil, err
 	}
 
-	e, err := c.currentEntry(calculatePath(&t))
+	path := calculatePath(&t)
+	e, err := c.currentEntry(path)
 	if err != nil {
 		return nil, err
 	}
+	c.touch(path)
 
 	return e.pkg, nil
 }
@@ -168,10 +182,28 @@ func (c *Local) Store(k v1beta1.Dependency, v *xpkg.ParsedPackage) error {
 	if err := c.add(e, path); err != nil {
 		return err
 	}
+	c.markUsed(path)
 
+	if c.maxSize > 0 {
+		return c.evict(path)
+	}
 	return nil
 }
 
+// Delete removes the entry corresponding to the supplied dependency from the
+// cache. Returns nil if the entry does not exist.
+func (c *Local) Delete(k v1beta1.Dependency) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, err := name.NewTag(image.FullTag(k))
+	if err != nil {
+		return err
+	}
+
+	return c.fs.RemoveAll(filepath.Join(c.root, calculatePath(&t)))
+}
+
 // Versions returns a slice of versions that exist in the cache for the given
 // package.
 func (c *Local) Versions(k v1beta1.Dependency) ([]string, error) {
@@ -198,6 +230,18 @@ func (c *Local) Versions(k v1beta1.Dependency) ([]string, error) {
 	return vers, nil
 }
 
+// ObjectPath returns the path of the file that the object with the supplied
+// name is stored in within the entry corresponding to the supplied dependency.
+// The constraints of the dependency must be a resolved version.
+func (c *Local) ObjectPath(k v1beta1.Dependency, objName string) (string, error) {
+	t, err := name.NewTag(image.FullTag(k))
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(c.root, calculatePath(&t), fmt.Sprintf(crdNameFmt, objName)), nil
+}
+
 // Watch returns a channel that can be used to subscribe to events
 // from the cache.
 func (c *Local) Watch() <-chan Event {
@@ -370,6 +370,76 @@ func TestClean(t *testing.T) {
 	}
 }
 
+func TestDelete(t *testing.T) {
+	type args struct {
+		key v1beta1.Dependency
+	}
+
+	type want struct {
+		postDeleteFileCnt int
+		err               error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Success": {
+			reason: "Should only remove the entry for the supplied dependency.",
+			args: args{
+				key: v1beta1.Dependency{
+					Package:     providerAws,
+					Constraints: "v0.20.1-alpha",
+				},
+			},
+			want: want{
+				postDeleteFileCnt: 3,
+			},
+		},
+		"NotExist": {
+			reason: "Should not return an error if the entry does not exist.",
+			args: args{
+				key: v1beta1.Dependency{
+					Package:     providerAws,
+					Constraints: "v0.1.0",
+				},
+			},
+			want: want{
+				postDeleteFileCnt: 6,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			cache, _ := NewLocal(
+				"~/.up/cache",
+				WithFS(fs),
+			)
+
+			e1 := cache.newEntry(pkg1)
+			cache.add(e1, "index.docker.io/crossplane/provider-aws@v0.20.1-alpha")
+
+			e2 := cache.newEntry(pkg2)
+			cache.add(e2, "index.docker.io/crossplane/provider-gcp@v0.14.2")
+
+			err := cache.Delete(tc.args.key)
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nDelete(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+
+			c := cacheFileCnt(fs, cache.root)
+
+			if diff := cmp.Diff(tc.want.postDeleteFileCnt, c); diff != "" {
+				t.Errorf("\n%s\nDelete(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestVersions(t *testing.T) {
 	fs := afero.NewMemMapFs()
 
@@ -440,6 +510,69 @@ func TestVersions(t *testing.T) {
 	}
 }
 
+func TestObjectPath(t *testing.T) {
+	cache, _ := NewLocal(
+		"/cache",
+		WithFS(afero.NewMemMapFs()),
+	)
+
+	type args struct {
+		key  v1beta1.Dependency
+		name string
+	}
+
+	type want struct {
+		path string
+		err  bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Success": {
+			reason: "Should return the path of the object file within the entry of the dependency.",
+			args: args{
+				key: v1beta1.Dependency{
+					Package:     providerAws,
+					Constraints: "v0.20.1-alpha",
+				},
+				name: "buckets.s3.aws.crossplane.io",
+			},
+			want: want{
+				path: "/cache/index.docker.io/crossplane/provider-aws@v0.20.1-alpha/buckets.s3.aws.crossplane.io.yaml",
+			},
+		},
+		"ErrInvalidTag": {
+			reason: "Should return an error if the constraints of the dependency are not a valid tag.",
+			args: args{
+				key: v1beta1.Dependency{
+					Package:     providerAws,
+					Constraints: ">=v0.20.1",
+				},
+				name: "buckets.s3.aws.crossplane.io",
+			},
+			want: want{
+				err: true,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			path, err := cache.ObjectPath(tc.args.key, tc.args.name)
+
+			if diff := cmp.Diff(tc.want.err, err != nil); diff != "" {
+				t.Errorf("\n%s\nObjectPath(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.path, path); diff != "" {
+				t.Errorf("\n%s\nObjectPath(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestCalculatePath(t *testing.T) {
 	tag1, _ := ociname.NewTag("crossplane/provider-aws:v0.20.1-alpha")
 	tag2, _ := ociname.NewTag("gcr.io/crossplane/provider-gcp:v1.0.0")
@@ -0,0 +1,321 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/upbound/up/internal/xpkg"
+)
+
+const (
+	// touchInterval is the interval at which the last use of an entry is
+	// recorded. Recording every use would trigger cache watchers, e.g. the
+	// language server, on every read.
+	touchInterval = time.Hour
+
+	errNoDigest      = "no digest recorded"
+	errIncompleteFmt = "entry for digest %s was not fully written"
+	errNoChecksumFmt = "no checksum recorded for digest %s and its contents cannot be parsed"
+	errChecksumFmt   = "contents of entry for digest %s do not match their checksum"
+	errRecordUse     = "failed to record use of cache entry"
+)
+
+// An Entry describes a package version in the cache.
+type Entry struct {
+	// Package is the repository of the package, including its registry.
+	Package string `json:"package"`
+	// Version is the version of the package.
+	Version string `json:"version"`
+	// Digest is the digest recorded when the package was cached.
+	Digest string `json:"digest"`
+	// Size is the size of the entry on disk in bytes.
+	Size int64 `json:"size"`
+	// LastUsed is the time the entry was last stored or retrieved, accurate
+	// to an hour.
+	LastUsed time.Time `json:"lastUsed"`
+
+	// path is the path of the entry relative to the cache root.
+	path string
+}
+
+// Path returns the path of the entry relative to the cache root.
+func (e Entry) Path() string {
+	return e.path
+}
+
+// List returns the entries in the cache, sorted by package and version.
+func (c *Local) List() ([]Entry, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.list()
+}
+
+func (c *Local) list() ([]Entry, error) {
+	entries := []Entry{}
+	if _, err := c.fs.Stat(c.root); os.IsNotExist(err) {
+		return entries, nil
+	}
+	err := afero.Walk(c.fs, c.root, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() || !strings.Contains(info.Name(), "@") {
+			return nil
+		}
+		rel, err := filepath.Rel(c.root, path)
+		if err != nil {
+			return err
+		}
+		e, err := c.entry(rel, info)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, e)
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Package != entries[j].Package {
+			return entries[i].Package < entries[j].Package
+		}
+		return entries[i].Version < entries[j].Version
+	})
+	return entries, nil
+}
+
+// Defining returns the entries in the cache that hold the definition of a
+// kind in the supplied API group, sorted by package and version.
+func (c *Local) Defining(group string) ([]Entry, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entries, err := c.list()
+	if err != nil {
+		return nil, err
+	}
+	suffix := fmt.Sprintf(crdNameFmt, "."+group)
+	out := []Entry{}
+	for _, e := range entries {
+		matches, err := afero.Glob(c.fs, filepath.Join(c.root, e.path, "*"+suffix))
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			// definitions are named <plural>.<group>, which rules out
+			// groups that merely end with the supplied group.
+			if !strings.Contains(strings.TrimSuffix(filepath.Base(m), suffix), ".") {
+				out = append(out, e)
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+// entry describes the entry at the supplied path relative to the cache root.
+func (c *Local) entry(path string, info fs.FileInfo) (Entry, error) {
+	pkg, ver, _ := strings.Cut(filepath.ToSlash(path), "@")
+	e := Entry{
+		Package:  pkg,
+		Version:  ver,
+		LastUsed: info.ModTime(),
+		path:     path,
+	}
+	err := afero.Walk(c.fs, filepath.Join(c.root, path), func(_ string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			e.Size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return Entry{}, err
+	}
+	if meta, err := c.imageMeta(path); err == nil {
+		e.Digest = meta.Digest
+	}
+	return e, nil
+}
+
+// imageMeta reads the image meta recorded for the entry at the supplied path.
+func (c *Local) imageMeta(path string) (xpkg.ImageMeta, error) {
+	meta := xpkg.ImageMeta{}
+	f, err := c.fs.Open(filepath.Join(c.root, path, xpkg.JSONStreamFile))
+	if err != nil {
+		return meta, err
+	}
+	defer f.Close() // nolint:errcheck
+	s := bufio.NewScanner(f)
+	if !s.Scan() {
+		if s.Err() != nil {
+			return meta, s.Err()
+		}
+		return meta, errors.New(errNoDigest)
+	}
+	err = json.Unmarshal(s.Bytes(), &meta)
+	return meta, err
+}
+
+// Verify checks that the supplied entry is intact, i.e. that a digest was
+// recorded for it, that all of its contents were written, that they match the
+// checksum recorded when the entry was written and that they can be parsed.
+// The checksum of entries written without one is recorded if they can be
+// parsed.
+func (c *Local) Verify(e Entry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	meta, err := c.imageMeta(e.path)
+	if err != nil {
+		return err
+	}
+	if meta.Digest == "" {
+		return errors.New(errNoDigest)
+	}
+	// NOTE: the file named after the digest is written last, so its absence
+	// indicates that the entry was not fully written.
+	want, err := afero.ReadFile(c.fs, filepath.Join(c.root, e.path, meta.Digest))
+	if err != nil {
+		return errors.Errorf(errIncompleteFmt, meta.Digest)
+	}
+	got, err := checksum(c.fs, filepath.Join(c.root, e.path), meta.Digest)
+	if err != nil {
+		return err
+	}
+	// NOTE: entries written by previous versions have an empty digest file.
+	if len(want) == 0 {
+		return c.recordChecksum(e, meta.Digest, got)
+	}
+	if got != string(want) {
+		return errors.Errorf(errChecksumFmt, meta.Digest)
+	}
+	_, err = c.pkgres.FromDir(c.fs, filepath.Join(c.root, e.path))
+	return err
+}
+
+// recordChecksum records the supplied checksum for a legacy entry, provided
+// that its contents can be parsed.
+func (c *Local) recordChecksum(e Entry, digest, sum string) error {
+	if _, err := c.pkgres.FromDir(c.fs, filepath.Join(c.root, e.path)); err != nil {
+		return errors.Errorf(errNoChecksumFmt, digest)
+	}
+	return afero.WriteFile(c.fs, filepath.Join(c.root, e.path, digest), []byte(sum), 0o600)
+}
+
+// Remove removes the supplied entries from the cache.
+func (c *Local) Remove(entries ...Entry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, e := range entries {
+		if err := c.remove(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Local) remove(e Entry) error {
+	if e.path == "" {
+		return nil
+	}
+	return c.fs.RemoveAll(filepath.Join(c.root, e.path))
+}
+
+// touch records the use of the entry at the supplied path, unless its use
+// was recorded within the touch interval.
+func (c *Local) touch(path string) {
+	loc := filepath.Join(c.root, path)
+	info, err := c.fs.Stat(loc)
+	if err != nil {
+		return
+	}
+	if time.Since(info.ModTime()) < touchInterval {
+		return
+	}
+	c.markUsed(path)
+}
+
+// markUsed records the use of the entry at the supplied path.
+func (c *Local) markUsed(path string) {
+	now := time.Now()
+	if err := c.fs.Chtimes(filepath.Join(c.root, path), now, now); err != nil {
+		c.log.Debug(errRecordUse, "path", path, "error", err)
+	}
+}
+
+// evict removes the least recently used entries until the cache is no larger
+// than the maximum size, keeping the entry at the supplied path.
+func (c *Local) evict(keep string) error {
+	entries, err := c.list()
+	if err != nil {
+		return err
+	}
+	kept := make([]Entry, 0, len(entries))
+	var reserved int64
+	for _, e := range entries {
+		if e.path == keep {
+			reserved += e.Size
+			continue
+		}
+		kept = append(kept, e)
+	}
+	for _, e := range LeastRecentlyUsed(kept, c.maxSize-reserved) {
+		if err := c.remove(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LeastRecentlyUsed returns the least recently used of the supplied entries
+// that have to be removed for the rest to be no larger than the supplied
+// size in total.
+func LeastRecentlyUsed(entries []Entry, size int64) []Entry {
+	var total int64
+	for _, e := range entries {
+		total += e.Size
+	}
+	lru := make([]Entry, len(entries))
+	copy(lru, entries)
+	sort.SliceStable(lru, func(i, j int) bool {
+		return lru[i].LastUsed.Before(lru[j].LastUsed)
+	})
+	out := []Entry{}
+	for _, e := range lru {
+		if total <= size {
+			break
+		}
+		out = append(out, e)
+		total -= e.Size
+	}
+	return out
+}
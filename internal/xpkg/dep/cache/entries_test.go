@@ -0,0 +1,322 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/spf13/afero"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+)
+
+const (
+	pathAws = "index.docker.io/crossplane/provider-aws@v0.20.1-alpha"
+	pathGcp = "index.docker.io/crossplane/provider-gcp@v0.18.1"
+)
+
+func TestList(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	cache, _ := NewLocal("/cache", WithFS(fs))
+	cache.add(cache.newEntry(pkg2), pathGcp)
+	cache.add(cache.newEntry(pkg1), pathAws)
+
+	empty, _ := NewLocal("/empty", WithFS(fs))
+
+	cases := map[string]struct {
+		reason string
+		cache  *Local
+		want   []Entry
+	}{
+		"Entries": {
+			reason: "Should return an entry for every cached package version, sorted by package and version.",
+			cache:  cache,
+			want: []Entry{
+				{Package: "index.docker.io/crossplane/provider-aws", Version: "v0.20.1-alpha", Digest: pkg1.SHA, path: pathAws},
+				{Package: "index.docker.io/crossplane/provider-gcp", Version: "v0.18.1", Digest: pkg2.SHA, path: pathGcp},
+			},
+		},
+		"NoCache": {
+			reason: "Should return no entries if the cache root does not exist.",
+			cache:  empty,
+			want:   []Entry{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.cache.List()
+			if err != nil {
+				t.Fatalf("\n%s\nList(): unexpected error: %s", tc.reason, err)
+			}
+			for _, e := range got {
+				if e.Size <= 0 {
+					t.Errorf("\n%s\nList(): size of %s: want > 0, got %d", tc.reason, e.path, e.Size)
+				}
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(Entry{}), cmpopts.IgnoreFields(Entry{}, "Size", "LastUsed")); diff != "" {
+				t.Errorf("\n%s\nList(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDefining(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	cache, _ := NewLocal("/cache", WithFS(fs))
+	cache.add(cache.newEntry(pkg2), pathGcp)
+	cache.add(cache.newEntry(pkg1), pathAws)
+	_ = afero.WriteFile(fs, filepath.Join("/cache", pathAws, "buckets.s3.aws.crossplane.io.yaml"), []byte("{}"), 0o600)
+	_ = afero.WriteFile(fs, filepath.Join("/cache", pathGcp, "buckets.storage.gcp.crossplane.io.yaml"), []byte("{}"), 0o600)
+
+	cases := map[string]struct {
+		reason string
+		group  string
+		want   []Entry
+	}{
+		"Defined": {
+			reason: "Should return the entries that hold a definition of the group.",
+			group:  "s3.aws.crossplane.io",
+			want: []Entry{
+				{Package: "index.docker.io/crossplane/provider-aws", Version: "v0.20.1-alpha", Digest: pkg1.SHA, path: pathAws},
+			},
+		},
+		"ParentGroup": {
+			reason: "Should not return entries that only define a subgroup of the group.",
+			group:  "aws.crossplane.io",
+			want:   []Entry{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := cache.Defining(tc.group)
+			if err != nil {
+				t.Fatalf("\n%s\nDefining(...): unexpected error: %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(Entry{}), cmpopts.IgnoreFields(Entry{}, "Size", "LastUsed")); diff != "" {
+				t.Errorf("\n%s\nDefining(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	type args struct {
+		modify func(fs afero.Fs)
+	}
+	cases := map[string]struct {
+		reason   string
+		args     args
+		want     error
+		checksum bool
+	}{
+		"Intact": {
+			reason: "Should not return an error if the entry is intact.",
+			args: args{
+				modify: func(fs afero.Fs) {},
+			},
+		},
+		"Incomplete": {
+			reason: "Should return an error if the digest file of the entry is missing.",
+			args: args{
+				modify: func(fs afero.Fs) {
+					fs.Remove(filepath.Join("/cache", pathAws, pkg1.SHA)) // nolint:errcheck
+				},
+			},
+			want: errors.Errorf(errIncompleteFmt, pkg1.SHA),
+		},
+		"NoDigest": {
+			reason: "Should return an error if no digest was recorded for the entry.",
+			args: args{
+				modify: func(fs afero.Fs) {
+					afero.WriteFile(fs, filepath.Join("/cache", pathAws, "package.ndjson"), []byte{}, 0644) // nolint:errcheck
+				},
+			},
+			want: errors.New(errNoDigest),
+		},
+		"Legacy": {
+			reason: "Should record the checksum of an entry written without one if it can be parsed.",
+			args: args{
+				modify: func(fs afero.Fs) {
+					afero.WriteFile(fs, filepath.Join("/cache", pathAws, pkg1.SHA), []byte{}, 0644) // nolint:errcheck
+				},
+			},
+			checksum: true,
+		},
+		"NoChecksum": {
+			reason: "Should return an error if no checksum was recorded for the entry and it cannot be parsed.",
+			args: args{
+				modify: func(fs afero.Fs) {
+					afero.WriteFile(fs, filepath.Join("/cache", pathAws, pkg1.SHA), []byte{}, 0644) // nolint:errcheck
+					f, _ := fs.OpenFile(filepath.Join("/cache", pathAws, "package.ndjson"), os.O_APPEND|os.O_WRONLY, 0644)
+					f.Write([]byte("{\n")) // nolint:errcheck
+					f.Close()              // nolint:errcheck
+				},
+			},
+			want: errors.Errorf(errNoChecksumFmt, pkg1.SHA),
+		},
+		"Modified": {
+			reason: "Should return an error if the contents of the entry were modified after it was written.",
+			args: args{
+				modify: func(fs afero.Fs) {
+					f, _ := fs.OpenFile(filepath.Join("/cache", pathAws, "package.ndjson"), os.O_APPEND|os.O_WRONLY, 0644)
+					f.Write([]byte("{}\n")) // nolint:errcheck
+					f.Close()               // nolint:errcheck
+				},
+			},
+			want: errors.Errorf(errChecksumFmt, pkg1.SHA),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			cache, _ := NewLocal("/cache", WithFS(fs))
+			cache.add(cache.newEntry(pkg1), pathAws)
+			tc.args.modify(fs)
+
+			err := cache.Verify(Entry{path: pathAws})
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nVerify(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if tc.checksum {
+				want, _ := checksum(fs, filepath.Join("/cache", pathAws), pkg1.SHA)
+				got, _ := afero.ReadFile(fs, filepath.Join("/cache", pathAws, pkg1.SHA))
+				if diff := cmp.Diff(want, string(got)); diff != "" {
+					t.Errorf("\n%s\nVerify(...): -want checksum, +got checksum:\n%s", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestLeastRecentlyUsed(t *testing.T) {
+	now := time.Now()
+	a := Entry{Package: "a", Size: 10, LastUsed: now.Add(-3 * time.Hour)}
+	b := Entry{Package: "b", Size: 20, LastUsed: now.Add(-1 * time.Hour)}
+	c := Entry{Package: "c", Size: 30, LastUsed: now.Add(-2 * time.Hour)}
+
+	type args struct {
+		entries []Entry
+		size    int64
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   []Entry
+	}{
+		"FitsInSize": {
+			reason: "Should return no entries if the entries fit in the size.",
+			args: args{
+				entries: []Entry{a, b, c},
+				size:    60,
+			},
+			want: []Entry{},
+		},
+		"LeastRecentlyUsedFirst": {
+			reason: "Should return the least recently used entries until the rest fit in the size.",
+			args: args{
+				entries: []Entry{a, b, c},
+				size:    25,
+			},
+			want: []Entry{a, c},
+		},
+		"ZeroSize": {
+			reason: "Should return all entries if the size is zero.",
+			args: args{
+				entries: []Entry{a, b, c},
+			},
+			want: []Entry{a, c, b},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := LeastRecentlyUsed(tc.args.entries, tc.args.size)
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(Entry{})); diff != "" {
+				t.Errorf("\n%s\nLeastRecentlyUsed(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestStoreMaxSize(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	cache, _ := NewLocal("/cache", WithFS(fs))
+	cache.add(cache.newEntry(pkg1), pathAws)
+	entries, _ := cache.List()
+	size := entries[0].Size
+
+	type args struct {
+		maxSize int64
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   []string
+	}{
+		"Evict": {
+			reason: "Should evict the least recently used entries once the cache grows beyond its maximum size.",
+			args: args{
+				maxSize: size + 1,
+			},
+			want: []string{pathGcp},
+		},
+		"Keep": {
+			reason: "Should keep all entries as long as the cache fits in its maximum size.",
+			args: args{
+				maxSize: 10 * size,
+			},
+			want: []string{pathAws, pathGcp},
+		},
+		"KeepStored": {
+			reason: "Should keep the stored entry even if it alone exceeds the maximum size.",
+			args: args{
+				maxSize: 1,
+			},
+			want: []string{pathGcp},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			cache, _ := NewLocal("/cache", WithFS(fs), WithMaxSize(tc.args.maxSize))
+			cache.add(cache.newEntry(pkg1), pathAws)
+			old := time.Now().Add(-time.Hour)
+			fs.Chtimes(filepath.Join("/cache", pathAws), old, old) // nolint:errcheck
+
+			if err := cache.Store(v1beta1.Dependency{Package: "crossplane/provider-gcp", Constraints: "v0.18.1"}, pkg2); err != nil {
+				t.Fatalf("\n%s\nStore(...): unexpected error: %s", tc.reason, err)
+			}
+			entries, _ := cache.List()
+			got := make([]string, len(entries))
+			for i, e := range entries {
+				got[i] = e.path
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nStore(...): -want entries, +got entries:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -15,6 +15,8 @@
 package cache
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -116,15 +118,39 @@ func (e *entry) flush() (*flushstats, error) {
 	}
 	stats.combine(objstats)
 
-	// writing empty digest file
-	_, err = e.fs.Create(filepath.Join(e.location(), e.pkg.Digest()))
+	// NOTE: the cache only holds the objects of the package, not its image,
+	// so its digest cannot be recomputed. The checksum of the contents of the
+	// entry is recorded in the digest file instead, which is written last.
+	sum, err := checksum(e.fs, e.location(), e.pkg.Digest())
 	if err != nil {
 		return stats, err
 	}
-
+	err = afero.WriteFile(e.fs, filepath.Join(e.location(), e.pkg.Digest()), []byte(sum), 0o600)
 	return stats, err
 }
 
+// checksum returns the checksum of the files in the supplied directory,
+// except for the digest file.
+func checksum(fs afero.Fs, dir, digest string) (string, error) {
+	files, err := afero.ReadDir(fs, dir)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	for _, f := range files {
+		if f.IsDir() || f.Name() == digest {
+			continue
+		}
+		b, err := afero.ReadFile(fs, filepath.Join(dir, f.Name()))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s %d\n", f.Name(), len(b))
+		h.Write(b)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
 func (e *entry) writeImageMeta(registry, repo, version, digest string) (*flushstats, error) {
 	stats := &flushstats{}
 
@@ -52,8 +52,11 @@ func NewWithType(pkg string, t string) v1beta1.Dependency {
 	d := New(pkg)
 
 	d.Type = v1beta1.ProviderPackageType
-	if strings.Title(strings.ToLower(t)) == string(v1beta1.ConfigurationPackageType) { //nolint:staticcheck // ignore staticcheck for now
+	switch strings.Title(strings.ToLower(t)) { //nolint:staticcheck // ignore staticcheck for now
+	case string(v1beta1.ConfigurationPackageType):
 		d.Type = v1beta1.ConfigurationPackageType
+	case string(v1beta1.FunctionPackageType):
+		d.Type = v1beta1.FunctionPackageType
 	}
 
 	return d
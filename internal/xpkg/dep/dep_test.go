@@ -78,6 +78,19 @@ func TestNew(t *testing.T) {
 				},
 			},
 		},
+		"FunctionTypeSupplied": {
+			args: args{
+				pkg: fmt.Sprintf("%s@%s", "crossplane-contrib/function-patch-and-transform", "v0.2.1"),
+				t:   "function",
+			},
+			want: want{
+				dep: v1beta1.Dependency{
+					Package:     "crossplane-contrib/function-patch-and-transform",
+					Type:        v1beta1.FunctionPackageType,
+					Constraints: "v0.2.1",
+				},
+			},
+		},
 	}
 
 	for name, tc := range cases {
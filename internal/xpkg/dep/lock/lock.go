@@ -0,0 +1,300 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lock
+
+import (
+	"os"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+
+	"github.com/upbound/up/internal/xpkg/dep/marshaler/xpkg"
+)
+
+const (
+	// Version is the current version of the lock file format.
+	Version = "v1alpha1"
+
+	header = "# This file is generated by up. DO NOT EDIT.\n"
+
+	errUnsupportedVersionFmt = "unsupported lock file version %q"
+	errReadLock              = "failed to read lock file"
+	errWriteLock             = "failed to write lock file"
+	errNotLockedFmt          = "%s is not pinned in the lock file"
+	errConstraintFmt         = "locked version %s of %s does not satisfy constraint %s"
+	errDigestMismatchFmt     = "digest of %s@%s in cache (%s) does not match lock file (%s)"
+)
+
+// Lock pins every direct and transitive dependency of a package to a concrete
+// version and digest.
+type Lock struct {
+	Version  string    `json:"version"`
+	Packages []Package `json:"packages"`
+}
+
+// Package is a single dependency pinned in the Lock.
+type Package struct {
+	// Name is the name of the package, e.g. xpkg.upbound.io/upbound/provider-aws.
+	Name string `json:"name"`
+	// Type is the type of the package.
+	Type v1beta1.PackageType `json:"type"`
+	// Version is the resolved tag of the package.
+	Version string `json:"version"`
+	// Digest is the OCI digest corresponding to Version at the time it was
+	// resolved.
+	Digest string `json:"digest"`
+	// Dependencies are the dependencies declared by the package.
+	Dependencies []v1beta1.Dependency `json:"dependencies,omitempty"`
+}
+
+// Cache defines the API contract for looking up packages in a cache.
+type Cache interface {
+	Get(v1beta1.Dependency) (*xpkg.ParsedPackage, error)
+}
+
+// New returns a new, empty Lock.
+func New() *Lock {
+	return &Lock{
+		Version:  Version,
+		Packages: make([]Package, 0),
+	}
+}
+
+// Read reads the Lock at the supplied path. If the file does not exist the
+// returned error satisfies os.IsNotExist.
+func Read(fs afero.Fs, path string) (*Lock, error) {
+	b, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Lock{}
+	if err := yaml.Unmarshal(b, l); err != nil {
+		return nil, errors.Wrap(err, errReadLock)
+	}
+	if l.Version != Version {
+		return nil, errors.Errorf(errUnsupportedVersionFmt, l.Version)
+	}
+
+	return l, nil
+}
+
+// Write writes the Lock to the supplied path. Packages are sorted by name so
+// that the output is stable.
+func (l *Lock) Write(fs afero.Fs, path string) error {
+	b, err := l.Bytes()
+	if err != nil {
+		return errors.Wrap(err, errWriteLock)
+	}
+
+	return errors.Wrap(afero.WriteFile(fs, path, b, os.ModePerm), errWriteLock)
+}
+
+// Bytes returns the serialized Lock as it is written to disk.
+func (l *Lock) Bytes() ([]byte, error) {
+	sort.Slice(l.Packages, func(i, j int) bool {
+		return l.Packages[i].Name < l.Packages[j].Name
+	})
+
+	b, err := yaml.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(header), b...), nil
+}
+
+// Get returns the locked Package with the supplied name.
+func (l *Lock) Get(pkg string) (Package, bool) {
+	k := Key(pkg)
+	for _, p := range l.Packages {
+		if p.Name == k {
+			return p, true
+		}
+	}
+	return Package{}, false
+}
+
+// Add adds the supplied ParsedPackages to the Lock, replacing any existing
+// entries with the same name.
+func (l *Lock) Add(pkgs ...*xpkg.ParsedPackage) {
+	for _, p := range pkgs {
+		l.upsert(Package{
+			Name:         Key(p.Name()),
+			Type:         p.Type(),
+			Version:      p.Version(),
+			Digest:       p.Digest(),
+			Dependencies: p.Dependencies(),
+		})
+	}
+}
+
+func (l *Lock) upsert(p Package) {
+	for i := range l.Packages {
+		if l.Packages[i].Name == p.Name {
+			l.Packages[i] = p
+			return
+		}
+	}
+	l.Packages = append(l.Packages, p)
+}
+
+// Remove removes the Packages with the supplied names from the Lock.
+func (l *Lock) Remove(pkgs ...string) {
+	rm := make(map[string]bool, len(pkgs))
+	for _, p := range pkgs {
+		rm[Key(p)] = true
+	}
+
+	keep := make([]Package, 0, len(l.Packages))
+	for _, p := range l.Packages {
+		if !rm[p.Name] {
+			keep = append(keep, p)
+		}
+	}
+	l.Packages = keep
+}
+
+// Prune removes every Package that is not reachable from the supplied root
+// dependencies and returns the removed Packages.
+func (l *Lock) Prune(roots []v1beta1.Dependency) []Package {
+	keep := make(map[string]bool)
+
+	var walk func(pkg string)
+	walk = func(pkg string) {
+		k := Key(pkg)
+		if keep[k] {
+			return
+		}
+		keep[k] = true
+		p, ok := l.Get(k)
+		if !ok {
+			return
+		}
+		for _, d := range p.Dependencies {
+			walk(d.Package)
+		}
+	}
+	for _, r := range roots {
+		walk(r.Package)
+	}
+
+	pkgs := make([]Package, 0, len(l.Packages))
+	pruned := make([]Package, 0)
+	for _, p := range l.Packages {
+		if keep[p.Name] {
+			pkgs = append(pkgs, p)
+			continue
+		}
+		pruned = append(pruned, p)
+	}
+	l.Packages = pkgs
+	return pruned
+}
+
+// Resolve returns the locked Package for the supplied dependency if its
+// version satisfies the dependency's constraints.
+func (l *Lock) Resolve(d v1beta1.Dependency) (Package, error) {
+	p, ok := l.Get(d.Package)
+	if !ok {
+		return Package{}, errors.Errorf(errNotLockedFmt, d.Package)
+	}
+	if !Satisfies(d.Constraints, p.Version) {
+		return Package{}, errors.Errorf(errConstraintFmt, p.Version, d.Package, d.Constraints)
+	}
+	return p, nil
+}
+
+// Check ensures that every one of the supplied dependencies, as well as their
+// transitive dependencies, are pinned in the Lock.
+func (l *Lock) Check(deps []v1beta1.Dependency) error {
+	seen := make(map[string]bool)
+	for len(deps) > 0 {
+		d := deps[0]
+		deps = deps[1:]
+
+		p, err := l.Resolve(d)
+		if err != nil {
+			return err
+		}
+		if seen[p.Name] {
+			continue
+		}
+		seen[p.Name] = true
+		deps = append(deps, p.Dependencies...)
+	}
+	return nil
+}
+
+// Verify checks that the digest recorded for each locked Package matches the
+// digest of the corresponding entry in the supplied Cache. Packages that are
+// not present in the Cache are skipped.
+func (l *Lock) Verify(c Cache) error {
+	for _, p := range l.Packages {
+		got, err := c.Get(v1beta1.Dependency{
+			Package:     p.Name,
+			Type:        p.Type,
+			Constraints: p.Version,
+		})
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if got.Digest() != p.Digest {
+			return errors.Errorf(errDigestMismatchFmt, p.Name, p.Version, got.Digest(), p.Digest)
+		}
+	}
+	return nil
+}
+
+// Satisfies returns true if the supplied version satisfies the supplied
+// constraint. Constraints that are not valid semantic version constraints
+// must match the version exactly, and an empty constraint is satisfied by any
+// version.
+func Satisfies(constraint, version string) bool {
+	if constraint == "" || constraint == version {
+		return true
+	}
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false
+	}
+	return c.Check(v)
+}
+
+// Key returns the name used to identify a package in the Lock. Packages
+// hosted on the default registry are identified without the registry, all
+// others include it.
+func Key(pkg string) string {
+	r, err := name.NewRepository(pkg)
+	if err != nil {
+		return pkg
+	}
+	if r.RegistryStr() == name.DefaultRegistry {
+		return r.RepositoryStr()
+	}
+	return r.Name()
+}
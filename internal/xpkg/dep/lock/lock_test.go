@@ -0,0 +1,242 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lock
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+
+	"github.com/upbound/up/internal/xpkg/dep/marshaler/xpkg"
+)
+
+var (
+	providerAws = Package{
+		Name:    "crossplane/provider-aws",
+		Type:    v1beta1.ProviderPackageType,
+		Version: "v0.20.1",
+		Digest:  "sha256:d507e508234732c6dc95d29c8a8c932fa8fa6a229231e309927641f99933892e",
+	}
+
+	platformRef = Package{
+		Name:    "xpkg.upbound.io/upbound/platform-ref-aws",
+		Type:    v1beta1.ConfigurationPackageType,
+		Version: "v0.2.0",
+		Digest:  "sha256:af0e6ed2d2b3c1e2a2d1ff38a6c1b8b1d0b1a9f1e36d04f8b1b9fa3bcf7b7c2e",
+		Dependencies: []v1beta1.Dependency{
+			{
+				Package:     "crossplane/provider-aws",
+				Type:        v1beta1.ProviderPackageType,
+				Constraints: ">=v0.20.0",
+			},
+		},
+	}
+)
+
+type mockCache struct {
+	pkgs map[string]*xpkg.ParsedPackage
+}
+
+func (m *mockCache) Get(d v1beta1.Dependency) (*xpkg.ParsedPackage, error) {
+	p, ok := m.pkgs[d.Package+"@"+d.Constraints]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return p, nil
+}
+
+func TestReadWrite(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	l := New()
+	l.upsert(platformRef)
+	l.upsert(providerAws)
+
+	if err := l.Write(fs, "/crossplane.lock"); err != nil {
+		t.Fatalf("Write(...): unexpected error: %v", err)
+	}
+
+	got, err := Read(fs, "/crossplane.lock")
+	if err != nil {
+		t.Fatalf("Read(...): unexpected error: %v", err)
+	}
+
+	want := &Lock{
+		Version:  Version,
+		Packages: []Package{providerAws, platformRef},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("\nRead(...): -want, +got:\n%s", diff)
+	}
+
+	if _, err := Read(fs, "/missing.lock"); !os.IsNotExist(err) {
+		t.Errorf("\nRead(...): expected not exist error, got: %v", err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	l := &Lock{
+		Version:  Version,
+		Packages: []Package{providerAws, platformRef},
+	}
+
+	type want struct {
+		pkg Package
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		dep    v1beta1.Dependency
+		want   want
+	}{
+		"Satisfied": {
+			reason: "Should return the locked package if it satisfies the constraint.",
+			dep: v1beta1.Dependency{
+				Package:     "crossplane/provider-aws",
+				Constraints: ">=v0.20.0",
+			},
+			want: want{
+				pkg: providerAws,
+			},
+		},
+		"DefaultRegistry": {
+			reason: "Should match packages on the default registry regardless of how they are referenced.",
+			dep: v1beta1.Dependency{
+				Package:     "index.docker.io/crossplane/provider-aws",
+				Constraints: "v0.20.1",
+			},
+			want: want{
+				pkg: providerAws,
+			},
+		},
+		"NotSatisfied": {
+			reason: "Should return an error if the locked version does not satisfy the constraint.",
+			dep: v1beta1.Dependency{
+				Package:     "xpkg.upbound.io/upbound/platform-ref-aws",
+				Constraints: ">=v0.3.0",
+			},
+			want: want{
+				err: errors.Errorf(errConstraintFmt, "v0.2.0", "xpkg.upbound.io/upbound/platform-ref-aws", ">=v0.3.0"),
+			},
+		},
+		"NotLocked": {
+			reason: "Should return an error if the package is not in the lock.",
+			dep: v1beta1.Dependency{
+				Package:     "crossplane/provider-gcp",
+				Constraints: ">=v0.0.0",
+			},
+			want: want{
+				err: errors.Errorf(errNotLockedFmt, "crossplane/provider-gcp"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := l.Resolve(tc.dep)
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nResolve(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.pkg, got); diff != "" {
+				t.Errorf("\n%s\nResolve(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	providerGcp := Package{
+		Name:    "crossplane/provider-gcp",
+		Type:    v1beta1.ProviderPackageType,
+		Version: "v0.18.1",
+	}
+
+	l := &Lock{
+		Version:  Version,
+		Packages: []Package{providerAws, platformRef, providerGcp},
+	}
+
+	pruned := l.Prune([]v1beta1.Dependency{{Package: "xpkg.upbound.io/upbound/platform-ref-aws"}})
+
+	if diff := cmp.Diff([]Package{providerAws, platformRef}, l.Packages); diff != "" {
+		t.Errorf("\nPrune(...): -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff([]Package{providerGcp}, pruned); diff != "" {
+		t.Errorf("\nPrune(...): -want pruned, +got pruned:\n%s", diff)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	type args struct {
+		cache Cache
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   error
+	}{
+		"Match": {
+			reason: "Should not return an error if the cached digest matches the lock.",
+			args: args{
+				cache: &mockCache{
+					pkgs: map[string]*xpkg.ParsedPackage{
+						"crossplane/provider-aws@v0.20.1": {SHA: providerAws.Digest},
+					},
+				},
+			},
+		},
+		"NotCached": {
+			reason: "Should skip packages that are not in the cache.",
+			args: args{
+				cache: &mockCache{},
+			},
+		},
+		"Mismatch": {
+			reason: "Should return an error if the cached digest does not match the lock.",
+			args: args{
+				cache: &mockCache{
+					pkgs: map[string]*xpkg.ParsedPackage{
+						"crossplane/provider-aws@v0.20.1": {SHA: "sha256:abc"},
+					},
+				},
+			},
+			want: errors.Errorf(errDigestMismatchFmt, providerAws.Name, providerAws.Version, "sha256:abc", providerAws.Digest),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			l := &Lock{
+				Version:  Version,
+				Packages: []Package{providerAws},
+			}
+
+			err := l.Verify(tc.args.cache)
+
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nVerify(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
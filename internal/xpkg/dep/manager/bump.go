@@ -0,0 +1,101 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// BumpPolicy determines how far a dependency may be moved when updating it
+// beyond its current constraint.
+type BumpPolicy string
+
+const (
+	// BumpNone keeps the current constraint and only re-resolves to the
+	// latest version satisfying it.
+	BumpNone BumpPolicy = ""
+	// BumpPatch allows moving to a newer patch release of the current minor
+	// version.
+	BumpPatch BumpPolicy = "patch"
+	// BumpMinor allows moving to a newer minor or patch release of the
+	// current major version.
+	BumpMinor BumpPolicy = "minor"
+	// BumpMajor allows moving to any newer release.
+	BumpMajor BumpPolicy = "major"
+)
+
+// constraintOps are the constraint operators that are kept when a constraint
+// is bumped, longest first.
+var constraintOps = []string{">=", "~>", "^", "~", "="}
+
+// Latest returns the highest of the supplied versions that is newer than or
+// equal to the supplied current version and allowed by the supplied policy.
+// Prereleases are never selected. It returns false if there is no such
+// version or the current version is not a semantic version.
+func Latest(vers []string, current string, p BumpPolicy) (string, bool) {
+	cur, err := semver.NewVersion(current)
+	if err != nil {
+		return "", false
+	}
+
+	var (
+		best  string
+		bestV *semver.Version
+	)
+	for _, v := range vers {
+		sv, err := semver.NewVersion(v)
+		if err != nil || sv.Prerelease() != "" || sv.LessThan(cur) {
+			continue
+		}
+		switch p {
+		case BumpPatch:
+			if sv.Major() != cur.Major() || sv.Minor() != cur.Minor() {
+				continue
+			}
+		case BumpMinor:
+			if sv.Major() != cur.Major() {
+				continue
+			}
+		case BumpMajor:
+		default:
+			continue
+		}
+		if bestV == nil || sv.GreaterThan(bestV) {
+			best, bestV = v, sv
+		}
+	}
+	return best, best != ""
+}
+
+// BumpConstraint returns a constraint that requires the supplied version.
+// Simple constraints keep their operator, exact versions are replaced and
+// compound constraints are replaced by a lower bound.
+func BumpConstraint(constraint, version string) string {
+	c := strings.TrimSpace(constraint)
+	if _, err := semver.NewVersion(c); err == nil || c == "" {
+		return version
+	}
+	if strings.ContainsAny(c, ",| ") {
+		return ">=" + version
+	}
+	for _, op := range constraintOps {
+		if strings.HasPrefix(c, op) {
+			return op + version
+		}
+	}
+	return ">=" + version
+}
@@ -0,0 +1,110 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLatest(t *testing.T) {
+	vers := []string{"v1.2.0", "v1.2.3", "v1.3.0", "v1.4.0-rc.1", "v2.0.0", "latest"}
+
+	type want struct {
+		version string
+		ok      bool
+	}
+
+	cases := map[string]struct {
+		reason  string
+		current string
+		policy  BumpPolicy
+		want    want
+	}{
+		"Patch": {
+			reason:  "Should only move to a newer patch release.",
+			current: "v1.2.0",
+			policy:  BumpPatch,
+			want:    want{version: "v1.2.3", ok: true},
+		},
+		"Minor": {
+			reason:  "Should move to a newer minor release, skipping prereleases.",
+			current: "v1.2.0",
+			policy:  BumpMinor,
+			want:    want{version: "v1.3.0", ok: true},
+		},
+		"Major": {
+			reason:  "Should move to the newest release.",
+			current: "v1.2.0",
+			policy:  BumpMajor,
+			want:    want{version: "v2.0.0", ok: true},
+		},
+		"NotSemver": {
+			reason:  "Should not select a version if the current one is not a semantic version.",
+			current: "latest",
+			policy:  BumpMajor,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			v, ok := Latest(vers, tc.current, tc.policy)
+
+			if diff := cmp.Diff(tc.want, want{version: v, ok: ok}, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nLatest(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestBumpConstraint(t *testing.T) {
+	cases := map[string]struct {
+		reason     string
+		constraint string
+		want       string
+	}{
+		"Exact": {
+			reason:     "Should replace an exact version.",
+			constraint: "v1.2.0",
+			want:       "v2.0.0",
+		},
+		"LowerBound": {
+			reason:     "Should keep the operator of a simple constraint.",
+			constraint: ">=v1.2.0",
+			want:       ">=v2.0.0",
+		},
+		"Tilde": {
+			reason:     "Should keep the tilde operator.",
+			constraint: "~v1.2.0",
+			want:       "~v2.0.0",
+		},
+		"Compound": {
+			reason:     "Should replace a compound constraint with a lower bound.",
+			constraint: ">=v1.0.0, <v2.0.0",
+			want:       ">=v2.0.0",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := BumpConstraint(tc.constraint, "v2.0.0")
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nBumpConstraint(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+
+	"github.com/upbound/up/internal/xpkg/dep/lock"
+)
+
+const (
+	errResolveNodeFmt = "failed to resolve %s:%s: %w"
+)
+
+// Node is a node in a resolved dependency graph.
+type Node struct {
+	// Package is the package as it is referenced by its dependant.
+	Package string `json:"package"`
+	// Type is the type of the package.
+	Type v1beta1.PackageType `json:"type"`
+	// Constraint is the version constraint declared by the dependant.
+	Constraint string `json:"constraint"`
+	// Version is the version the constraint resolved to.
+	Version string `json:"version"`
+	// Digest is the digest of the resolved version.
+	Digest string `json:"digest"`
+	// Dependencies are the resolved dependencies of the package.
+	Dependencies []*Node `json:"dependencies,omitempty"`
+}
+
+// Graph resolves the supplied dependencies, as well as their transitive
+// dependencies, from the cache and returns them as a graph rooted at the
+// supplied dependencies. Dependencies must have already been added to the
+// cache.
+func (m *Manager) Graph(ctx context.Context, deps []v1beta1.Dependency) ([]*Node, error) {
+	return m.graph(ctx, deps, map[string]bool{})
+}
+
+func (m *Manager) graph(ctx context.Context, deps []v1beta1.Dependency, visiting map[string]bool) ([]*Node, error) {
+	nodes := make([]*Node, 0, len(deps))
+	for _, d := range deps {
+		p, err := m.retrievePkg(ctx, d)
+		if err != nil {
+			return nil, fmt.Errorf(errResolveNodeFmt, d.Package, d.Constraints, err)
+		}
+
+		n := &Node{
+			Package:    d.Package,
+			Type:       p.Type(),
+			Constraint: d.Constraints,
+			Version:    p.Version(),
+			Digest:     p.Digest(),
+		}
+
+		// guard against cycles in the graph.
+		k := lock.Key(d.Package)
+		if !visiting[k] {
+			visiting[k] = true
+			n.Dependencies, err = m.graph(ctx, p.Dependencies(), visiting)
+			if err != nil {
+				return nil, err
+			}
+			delete(visiting, k)
+		}
+
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+// Paths returns every path from the supplied roots to a node referencing the
+// supplied package. The package may either be the full package name or the
+// trailing part of its repository, e.g. provider-aws.
+func Paths(roots []*Node, pkg string) [][]*Node {
+	paths := make([][]*Node, 0)
+
+	var walk func(n *Node, path []*Node)
+	walk = func(n *Node, path []*Node) {
+		path = append(path, n)
+		if matches(n.Package, pkg) {
+			p := make([]*Node, len(path))
+			copy(p, path)
+			paths = append(paths, p)
+		}
+		for _, d := range n.Dependencies {
+			walk(d, path)
+		}
+	}
+
+	for _, r := range roots {
+		walk(r, nil)
+	}
+	return paths
+}
+
+func matches(pkg, query string) bool {
+	k, q := lock.Key(pkg), lock.Key(query)
+	return k == q || strings.HasSuffix(k, "/"+query)
+}
@@ -0,0 +1,75 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPaths(t *testing.T) {
+	ec2 := &Node{Package: "xpkg.upbound.io/upbound/provider-aws-ec2", Version: "v0.47.0"}
+	rds := &Node{Package: "xpkg.upbound.io/upbound/provider-aws-rds", Version: "v0.47.0"}
+	network := &Node{
+		Package:      "xpkg.upbound.io/upbound/configuration-aws-network",
+		Version:      "v0.7.0",
+		Dependencies: []*Node{ec2},
+	}
+	database := &Node{
+		Package:      "xpkg.upbound.io/upbound/configuration-aws-database",
+		Version:      "v0.5.0",
+		Dependencies: []*Node{rds, network},
+	}
+
+	roots := []*Node{network, database}
+
+	cases := map[string]struct {
+		reason string
+		pkg    string
+		want   [][]*Node
+	}{
+		"FullName": {
+			reason: "Should return every path to a package referenced by its full name.",
+			pkg:    "xpkg.upbound.io/upbound/provider-aws-ec2",
+			want: [][]*Node{
+				{network, ec2},
+				{database, network, ec2},
+			},
+		},
+		"ShortName": {
+			reason: "Should return every path to a package referenced by its repository name.",
+			pkg:    "provider-aws-rds",
+			want: [][]*Node{
+				{database, rds},
+			},
+		},
+		"NotFound": {
+			reason: "Should return no paths if the package is not in the graph.",
+			pkg:    "provider-gcp",
+			want:   [][]*Node{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Paths(roots, tc.pkg)
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nPaths(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
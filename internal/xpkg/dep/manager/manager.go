@@ -33,6 +33,7 @@ import (
 
 	ixpkg "github.com/upbound/up/internal/xpkg"
 	"github.com/upbound/up/internal/xpkg/dep/cache"
+	"github.com/upbound/up/internal/xpkg/dep/lock"
 	xpkg "github.com/upbound/up/internal/xpkg/dep/marshaler/xpkg"
 	"github.com/upbound/up/internal/xpkg/dep/resolver/image"
 )
@@ -45,6 +46,9 @@ const (
 	defaultWatchInterval = "100ms"
 
 	errInvalidSemVerConstraintFmt = "invalid semver constraint %v: %w"
+	errFrozenFmt                  = "frozen: %v"
+	errLockedDigestFmt            = "digest %s of %s@%s does not match lock file digest %s"
+	errSignatureFmt               = "signature verification of %s@%s failed: %w"
 )
 
 // Manager defines a dependency Manager
@@ -56,6 +60,18 @@ type Manager struct {
 	cacheRoot     string
 	watchInterval *time.Duration
 
+	// l is the lock file used to pin resolved versions. frozen indicates
+	// that no dependency may be resolved outside of the lock file.
+	l      *lock.Lock
+	frozen bool
+
+	// v verifies the signature of every package added to the cache.
+	v Verifier
+
+	// solved holds the versions selected by the last call to Solve, keyed
+	// by package name.
+	solved map[string]string
+
 	acc []*xpkg.ParsedPackage
 }
 
@@ -64,6 +80,8 @@ type Cache interface {
 	Get(v1beta1.Dependency) (*xpkg.ParsedPackage, error)
 	Store(v1beta1.Dependency, *xpkg.ParsedPackage) error
 	Versions(v1beta1.Dependency) ([]string, error)
+	ObjectPath(v1beta1.Dependency, string) (string, error)
+	Defining(string) ([]cache.Entry, error)
 	Watch() <-chan cache.Event
 }
 
@@ -73,6 +91,12 @@ type ImageResolver interface {
 	ResolveDigest(context.Context, v1beta1.Dependency) (string, error)
 	ResolveImage(context.Context, v1beta1.Dependency) (string, v1.Image, error)
 	ResolveTag(context.Context, v1beta1.Dependency) (string, error)
+	ResolveVersions(context.Context, v1beta1.Dependency) ([]string, error)
+}
+
+// Verifier defines the API contract for verifying package signatures.
+type Verifier interface {
+	Verify(context.Context, name.Digest) error
 }
 
 // XpkgMarshaler defines the API contract for working with an
@@ -161,16 +185,50 @@ func WithWatchInterval(i *time.Duration) Option {
 	}
 }
 
+// WithLock sets the supplied lock.Lock on the Manager. Dependencies pinned in
+// the lock are resolved to their locked version as long as it satisfies the
+// requested constraints.
+func WithLock(l *lock.Lock) Option {
+	return func(m *Manager) {
+		m.l = l
+	}
+}
+
+// WithFrozen configures the Manager to refuse to resolve any dependency that
+// is not pinned in the lock file, or whose digest differs from the one in the
+// lock file.
+func WithFrozen() Option {
+	return func(m *Manager) {
+		m.frozen = true
+	}
+}
+
+// WithVerifier configures the Manager to refuse any dependency that is not
+// signed according to the supplied Verifier.
+func WithVerifier(v Verifier) Option {
+	return func(m *Manager) {
+		m.v = v
+	}
+}
+
 // View returns a View corresponding to the supplied dependency slice
-// (both defined and transitive).
+// (both defined and transitive). Dependencies that cannot be resolved because
+// they are not pinned in the lock file of a frozen Manager are recorded in the
+// View rather than returned as an error.
 func (m *Manager) View(ctx context.Context, deps []v1beta1.Dependency) (*View, error) {
 	packages := make(map[string]*xpkg.ParsedPackage)
+	errs := make(map[string]error)
 
 	for _, d := range deps {
 		_, acc, err := m.Resolve(ctx, d)
 		if err != nil && errors.Is(err, os.ErrNotExist) {
 			continue
 		}
+		var fe *FrozenError
+		if errors.As(err, &fe) {
+			errs[lock.Key(d.Package)] = err
+			continue
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -181,6 +239,7 @@ func (m *Manager) View(ctx context.Context, deps []v1beta1.Dependency) (*View, e
 
 	return &View{
 		packages: packages,
+		errs:     errs,
 	}, nil
 }
 
@@ -190,6 +249,25 @@ func (m *Manager) Versions(ctx context.Context, d v1beta1.Dependency) ([]string,
 	return m.c.Versions(d)
 }
 
+// ObjectPath returns the path of the file in the cache that the object with
+// the supplied name of the supplied resolved dependency is stored in.
+func (m *Manager) ObjectPath(d v1beta1.Dependency, objName string) (string, error) {
+	return m.c.ObjectPath(d, objName)
+}
+
+// Defining returns the packages in the cache that define a kind in the
+// supplied API group.
+func (m *Manager) Defining(group string) ([]cache.Entry, error) {
+	return m.c.Defining(group)
+}
+
+// RemoteVersions returns the versions corresponding to the supplied
+// v1beta1.Dependency that exist in the remote registry, sorted in ascending
+// order.
+func (m *Manager) RemoteVersions(ctx context.Context, d v1beta1.Dependency) ([]string, error) {
+	return m.i.ResolveVersions(ctx, d)
+}
+
 // Watch provides a hook for watching changes coming from the cache.
 func (m *Manager) Watch() <-chan cache.Event {
 	return m.c.Watch()
@@ -242,6 +320,13 @@ func (m *Manager) AddAll(ctx context.Context, d v1beta1.Dependency) (v1beta1.Dep
 	return ud, m.acc, nil
 }
 
+// Fetch fetches the given package from its registry and stores it in the
+// cache, replacing the cached package if one exists. Transitive dependencies
+// are not fetched.
+func (m *Manager) Fetch(ctx context.Context, d v1beta1.Dependency) (*xpkg.ParsedPackage, error) {
+	return m.addPkg(ctx, d)
+}
+
 func (m *Manager) retrieveAllDeps(ctx context.Context, p *xpkg.ParsedPackage) error {
 	if len(p.Dependencies()) == 0 {
 		// no remaining dependencies to resolve
@@ -288,6 +373,23 @@ func (m *Manager) addAllDeps(ctx context.Context, p *xpkg.ParsedPackage) error {
 }
 
 func (m *Manager) addPkg(ctx context.Context, d v1beta1.Dependency) (*xpkg.ParsedPackage, error) {
+	p, err := m.fetchPkg(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	// add xpkg to cache
+	err = m.c.Store(d, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// fetchPkg fetches and parses the package of the supplied dependency from its
+// registry without adding it to the cache.
+func (m *Manager) fetchPkg(ctx context.Context, d v1beta1.Dependency) (*xpkg.ParsedPackage, error) {
 	// this is expensive
 	t, i, err := m.i.ResolveImage(ctx, d)
 	if err != nil {
@@ -304,6 +406,11 @@ func (m *Manager) addPkg(ctx context.Context, d v1beta1.Dependency) (*xpkg.Parse
 		return nil, err
 	}
 
+	// verify the signature before the package makes it into the cache
+	if err := m.verify(ctx, d.Package, t, digest.String()); err != nil {
+		return nil, err
+	}
+
 	p, err := m.x.FromImage(ixpkg.Image{
 		Meta: ixpkg.ImageMeta{
 			Repo:     deriveRepoName(tag),
@@ -317,12 +424,6 @@ func (m *Manager) addPkg(ctx context.Context, d v1beta1.Dependency) (*xpkg.Parse
 		return nil, err
 	}
 
-	// add xpkg to cache
-	err = m.c.Store(d, p)
-	if err != nil {
-		return nil, err
-	}
-
 	return p, nil
 }
 
@@ -339,7 +440,26 @@ func (m *Manager) retrievePkg(ctx context.Context, d v1beta1.Dependency) (*xpkg.
 		return nil, err
 	}
 
-	return m.c.Get(d)
+	p, err := m.c.Get(d)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.checkLocked(p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// loadPkg returns the package of the supplied resolved dependency from the
+// cache, fetching it from its registry without storing it if it is not cached.
+func (m *Manager) loadPkg(ctx context.Context, d v1beta1.Dependency) (*xpkg.ParsedPackage, error) {
+	p, err := m.c.Get(d)
+	if os.IsNotExist(err) {
+		return m.fetchPkg(ctx, d)
+	}
+	return p, err
 }
 
 func (m *Manager) retrieveAndStorePkg(ctx context.Context, d v1beta1.Dependency) (*xpkg.ParsedPackage, error) {
@@ -372,14 +492,77 @@ func (m *Manager) retrieveAndStorePkg(ctx context.Context, d v1beta1.Dependency)
 			if err != nil {
 				return nil, err
 			}
+		} else if err := m.verify(ctx, d.Package, p.Version(), p.Digest()); err != nil {
+			return nil, err
 		}
 	}
 
+	if err := m.checkLocked(p); err != nil {
+		return nil, err
+	}
+
 	return p, nil
 }
 
+// verify ensures that the package with the supplied digest is signed if the
+// Manager has been configured with a Verifier.
+func (m *Manager) verify(ctx context.Context, pkg, version, digest string) error {
+	if m.v == nil {
+		return nil
+	}
+	ref, err := name.NewDigest(fmt.Sprintf("%s@%s", pkg, digest))
+	if err != nil {
+		return err
+	}
+	if err := m.v.Verify(ctx, ref); err != nil {
+		return fmt.Errorf(errSignatureFmt, pkg, version, err)
+	}
+	return nil
+}
+
+// checkLocked ensures that the supplied package matches the digest recorded
+// in the lock file when the Manager is frozen.
+func (m *Manager) checkLocked(p *xpkg.ParsedPackage) error {
+	if m.l == nil || !m.frozen {
+		return nil
+	}
+	lp, ok := m.l.Get(p.Name())
+	if ok && lp.Digest != p.Digest() {
+		return fmt.Errorf(errLockedDigestFmt, p.Digest(), lp.Name, lp.Version, lp.Digest)
+	}
+	return nil
+}
+
+// lockedVersion returns the version selected by the last call to Solve, or
+// the version pinned in the lock file, for the supplied v1beta1.Dependency.
+func (m *Manager) lockedVersion(d v1beta1.Dependency) (string, error) {
+	if v, ok := m.solved[lock.Key(d.Package)]; ok && lock.Satisfies(d.Constraints, v) {
+		return v, nil
+	}
+	if m.l == nil {
+		return "", nil
+	}
+	p, err := m.l.Resolve(d)
+	if err != nil {
+		if m.frozen {
+			return "", &FrozenError{Dependency: d, err: err}
+		}
+		return "", nil
+	}
+	return p.Version, nil
+}
+
 // finalizeExtDepVersion sets the resolved tag version on the supplied v1beta1.Dependency.
 func (m *Manager) finalizeExtDepVersion(ctx context.Context, d *v1beta1.Dependency) error {
+	lv, err := m.lockedVersion(*d)
+	if err != nil {
+		return err
+	}
+	if lv != "" {
+		d.Constraints = lv
+		return nil
+	}
+
 	// determine the version (using resolver) to use based on the supplied constraints
 	v, err := m.i.ResolveTag(ctx, *d)
 	if err != nil {
@@ -393,6 +576,15 @@ func (m *Manager) finalizeExtDepVersion(ctx context.Context, d *v1beta1.Dependen
 // finalizeLocalDepVersion sets the resolve tag version on the supplied v1beta1.Dependency
 // based on versions currently located in the cache.
 func (m *Manager) finalizeLocalDepVersion(_ context.Context, d *v1beta1.Dependency) error {
+	lv, err := m.lockedVersion(*d)
+	if err != nil {
+		return err
+	}
+	if lv != "" {
+		d.Constraints = lv
+		return nil
+	}
+
 	// check up front if we already have a semver constraint
 	c, err := semver.NewConstraint(d.Constraints)
 	if err != nil {
@@ -434,12 +626,38 @@ func (m *Manager) finalizeLocalDepVersion(_ context.Context, d *v1beta1.Dependen
 	return nil
 }
 
+// FrozenError is returned when a frozen Manager is asked to resolve a
+// dependency that is not pinned in the lock file at a version satisfying its
+// constraints.
+type FrozenError struct {
+	// Dependency is the dependency that could not be resolved.
+	Dependency v1beta1.Dependency
+	err        error
+}
+
+// Error returns the reason the dependency could not be resolved.
+func (e *FrozenError) Error() string {
+	return fmt.Sprintf(errFrozenFmt, e.err)
+}
+
+// Unwrap returns the underlying lock file error.
+func (e *FrozenError) Unwrap() error {
+	return e.err
+}
+
 // View represents the processed View corresponding to some dependencies.
 type View struct {
 	packages map[string]*xpkg.ParsedPackage
+	errs     map[string]error
 }
 
 // Packages returns the packages map for the view.
 func (v *View) Packages() map[string]*xpkg.ParsedPackage {
 	return v.packages
 }
+
+// Errors returns the errors of the dependencies that could not be resolved,
+// keyed by the lock key of the dependency's package.
+func (v *View) Errors() map[string]error {
+	return v.errs
+}
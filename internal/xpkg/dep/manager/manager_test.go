@@ -26,6 +26,7 @@ import (
 	metav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
 	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
@@ -40,6 +41,7 @@ import (
 
 	"github.com/upbound/up/internal/xpkg"
 	"github.com/upbound/up/internal/xpkg/dep/cache"
+	"github.com/upbound/up/internal/xpkg/dep/lock"
 	"github.com/upbound/up/internal/xpkg/dep/resolver/image"
 )
 
@@ -444,6 +446,169 @@ func TestSnapshot(t *testing.T) {
 	}
 }
 
+func TestViewFrozen(t *testing.T) {
+	dep := v1beta1.Dependency{
+		Package:     "crossplane/provider-aws",
+		Constraints: "v0.1.0",
+	}
+	meta := &metav1.Provider{
+		TypeMeta: apimetav1.TypeMeta{
+			APIVersion: "meta.pkg.crossplane.io/v1alpha1",
+			Kind:       "Provider",
+		},
+	}
+
+	type want struct {
+		keys []string
+		errs []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		pinned bool
+		want   want
+	}{
+		"Pinned": {
+			reason: "A dependency pinned in the lock file should be in the view.",
+			pinned: true,
+			want:   want{keys: []string{"crossplane/provider-aws"}},
+		},
+		"NotPinned": {
+			reason: "A dependency that is not pinned in the lock file should be recorded as an error of the view rather than skipped.",
+			want:   want{errs: []string{lock.Key(dep.Package)}},
+		},
+	}
+
+	for n, tc := range cases {
+		t.Run(n, func(t *testing.T) {
+			c, _ := cache.NewLocal("/tmp/cache", cache.WithFS(afero.NewMemMapFs()))
+			ref, _ := name.ParseReference(image.FullTag(dep))
+
+			m, _ := New(
+				WithCache(c),
+				WithResolver(
+					image.NewResolver(
+						image.WithFetcher(
+							NewMockFetcher(
+								WithPackageObjects(ref, meta),
+							),
+						),
+					),
+				),
+			)
+			// add the pkg to the cache before freezing the manager.
+			p, _ := m.addPkg(context.Background(), dep)
+			l := lock.New()
+			if tc.pinned {
+				l.Add(p)
+			}
+			WithLock(l)(m)
+			WithFrozen()(m)
+
+			got, err := m.View(context.Background(), []v1beta1.Dependency{dep})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			keys := []string{}
+			for k := range got.Packages() {
+				keys = append(keys, k)
+			}
+			errs := []string{}
+			for k, err := range got.Errors() {
+				var fe *FrozenError
+				if !errors.As(err, &fe) {
+					t.Errorf("\n%s\nView(...): want frozen error, got %v", tc.reason, err)
+				}
+				errs = append(errs, k)
+			}
+			if diff := cmp.Diff(tc.want.keys, keys, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("\n%s\nView(...): -want packages, +got packages:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.errs, errs, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("\n%s\nView(...): -want errors, +got errors:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestAddAllVerify(t *testing.T) {
+	errBoom := errors.New("boom")
+	dep := v1beta1.Dependency{
+		Package:     "crossplane/provider-aws",
+		Constraints: "v0.1.0",
+	}
+	meta := &metav1.Provider{
+		TypeMeta: apimetav1.TypeMeta{
+			APIVersion: "meta.pkg.crossplane.io/v1alpha1",
+			Kind:       "Provider",
+		},
+	}
+
+	type want struct {
+		err    error
+		cached bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		v      Verifier
+		want   want
+	}{
+		"Signed": {
+			reason: "A package with a valid signature should be added to the cache.",
+			v:      MockVerifier(func(context.Context, name.Digest) error { return nil }),
+			want:   want{cached: true},
+		},
+		"NotSigned": {
+			reason: "A package without a valid signature should be rejected and not be added to the cache.",
+			v:      MockVerifier(func(context.Context, name.Digest) error { return errBoom }),
+			want: want{
+				err: errors.Errorf(errSignatureFmt, dep.Package, "v0.1.0", errBoom),
+			},
+		},
+	}
+
+	for n, tc := range cases {
+		t.Run(n, func(t *testing.T) {
+			c, _ := cache.NewLocal("/tmp/cache", cache.WithFS(afero.NewMemMapFs()))
+			ref, _ := name.ParseReference(image.FullTag(dep))
+
+			m, _ := New(
+				WithCache(c),
+				WithResolver(
+					image.NewResolver(
+						image.WithFetcher(
+							NewMockFetcher(
+								WithPackageObjects(ref, meta),
+							),
+						),
+					),
+				),
+				WithVerifier(tc.v),
+			)
+
+			_, _, err := m.AddAll(context.Background(), dep)
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAddAll(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			_, err = c.Get(dep)
+			if diff := cmp.Diff(tc.want.cached, err == nil); diff != "" {
+				t.Errorf("\n%s\nAddAll(...): -want cached, +got cached:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// MockVerifier is a mock Verifier.
+type MockVerifier func(context.Context, name.Digest) error
+
+// Verify calls the underlying function.
+func (v MockVerifier) Verify(ctx context.Context, d name.Digest) error {
+	return v(ctx, d)
+}
+
 type MockFetcher struct {
 	pkgMeta map[name.Reference][]runtime.Object
 	tags    []string
@@ -0,0 +1,172 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+
+	"github.com/upbound/up/internal/xpkg/dep/lock"
+)
+
+const (
+	errListVersionsFmt = "failed to list versions of %s: %w"
+)
+
+// OutdatedPackage compares the resolved version of a package with the
+// versions available in its registry.
+type OutdatedPackage struct {
+	// Package is the name of the package.
+	Package string `json:"package"`
+	// Type is the type of the package.
+	Type v1beta1.PackageType `json:"type"`
+	// Direct is true if the package is declared in crossplane.yaml.
+	Direct bool `json:"direct"`
+	// Constraint is the combination of all constraints imposed on the
+	// package.
+	Constraint string `json:"constraint"`
+	// Current is the currently resolved version.
+	Current string `json:"current"`
+	// Wanted is the latest available version satisfying the constraint.
+	Wanted string `json:"wanted"`
+	// Latest is the latest available version.
+	Latest string `json:"latest"`
+}
+
+// Behind returns how far the current version is behind the latest version,
+// or BumpNone if it is up to date or either version is not a semantic
+// version.
+func (o OutdatedPackage) Behind() BumpPolicy {
+	cur, err := semver.NewVersion(o.Current)
+	if err != nil {
+		return BumpNone
+	}
+	latest, err := semver.NewVersion(o.Latest)
+	if err != nil || !latest.GreaterThan(cur) {
+		return BumpNone
+	}
+	switch {
+	case latest.Major() != cur.Major():
+		return BumpMajor
+	case latest.Minor() != cur.Minor():
+		return BumpMinor
+	}
+	return BumpPatch
+}
+
+// Outdated reports the current, wanted and latest version of every package in
+// the supplied resolved dependency graph. Packages are reported once, sorted
+// by name, with the constraints imposed by all of their dependants.
+func (m *Manager) Outdated(ctx context.Context, roots []*Node) ([]OutdatedPackage, error) {
+	type entry struct {
+		node   *Node
+		direct bool
+		cs     []Constraint
+	}
+	entries := make(map[string]*entry)
+
+	var walk func(n *Node, source string)
+	walk = func(n *Node, source string) {
+		k := lock.Key(n.Package)
+		e, ok := entries[k]
+		if !ok {
+			e = &entry{node: n}
+			entries[k] = e
+		}
+		e.direct = e.direct || source == ""
+		if !containsConstraint(e.cs, source, n.Constraint) {
+			e.cs = append(e.cs, Constraint{Source: source, Constraint: n.Constraint})
+		}
+		for _, d := range n.Dependencies {
+			walk(d, k)
+		}
+	}
+	for _, r := range roots {
+		walk(r, "")
+	}
+
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make([]OutdatedPackage, 0, len(keys))
+	for _, k := range keys {
+		e := entries[k]
+		vers, err := m.i.ResolveVersions(ctx, v1beta1.Dependency{
+			Package: e.node.Package,
+			Type:    e.node.Type,
+		})
+		if err != nil {
+			return nil, fmt.Errorf(errListVersionsFmt, e.node.Package, err)
+		}
+
+		wanted, _ := HighestMatching(vers, e.cs)
+		out = append(out, OutdatedPackage{
+			Package:    k,
+			Type:       e.node.Type,
+			Direct:     e.direct,
+			Constraint: joinConstraints(e.cs),
+			Current:    e.node.Version,
+			Wanted:     wanted,
+			Latest:     LatestRelease(vers),
+		})
+	}
+	return out, nil
+}
+
+// LatestRelease returns the highest of the supplied versions that is not a
+// prerelease.
+func LatestRelease(vers []string) string {
+	var (
+		best  string
+		bestV *semver.Version
+	)
+	for _, v := range vers {
+		sv, err := semver.NewVersion(v)
+		if err != nil || sv.Prerelease() != "" {
+			continue
+		}
+		if bestV == nil || sv.GreaterThan(bestV) {
+			best, bestV = v, sv
+		}
+	}
+	return best
+}
+
+func joinConstraints(cs []Constraint) string {
+	parts := make([]string, 0, len(cs))
+	for _, c := range cs {
+		if c.Constraint != "" && !containsString(parts, c.Constraint) {
+			parts = append(parts, c.Constraint)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func containsString(ss []string, s string) bool {
+	for _, t := range ss {
+		if t == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/xpkg/dep/cache"
+	"github.com/upbound/up/internal/xpkg/dep/resolver/image"
+)
+
+func TestOutdated(t *testing.T) {
+	f := NewMockFetcher()
+	f.tags = []string{"v1.0.0", "v1.2.0", "v1.5.0", "v2.0.0", "v2.1.0-rc.1"}
+
+	c, _ := cache.NewLocal("/tmp/cache", cache.WithFS(afero.NewMemMapFs()))
+	m, _ := New(
+		WithCache(c),
+		WithResolver(image.NewResolver(image.WithFetcher(f))),
+	)
+
+	provider := &Node{
+		Package:    "crossplane/provider-aws",
+		Type:       v1beta1.ProviderPackageType,
+		Constraint: ">=v1.0.0, <v2.0.0",
+		Version:    "v1.0.0",
+	}
+	roots := []*Node{
+		{
+			Package:      "crossplane/configuration-a",
+			Type:         v1beta1.ConfigurationPackageType,
+			Constraint:   ">=v1.0.0",
+			Version:      "v2.0.0",
+			Dependencies: []*Node{provider},
+		},
+		{
+			Package:    "crossplane/provider-aws",
+			Type:       v1beta1.ProviderPackageType,
+			Constraint: ">=v1.2.0",
+			Version:    "v1.0.0",
+		},
+	}
+
+	got, err := m.Outdated(context.Background(), roots)
+	if err != nil {
+		t.Fatalf("Outdated(...): unexpected error: %v", err)
+	}
+
+	want := []OutdatedPackage{
+		{
+			Package:    "crossplane/configuration-a",
+			Type:       v1beta1.ConfigurationPackageType,
+			Direct:     true,
+			Constraint: ">=v1.0.0",
+			Current:    "v2.0.0",
+			Wanted:     "v2.0.0",
+			Latest:     "v2.0.0",
+		},
+		{
+			Package:    "crossplane/provider-aws",
+			Type:       v1beta1.ProviderPackageType,
+			Direct:     true,
+			Constraint: ">=v1.0.0, <v2.0.0, >=v1.2.0",
+			Current:    "v1.0.0",
+			Wanted:     "v1.5.0",
+			Latest:     "v2.0.0",
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("\nOutdated(...): -want, +got:\n%s", diff)
+	}
+
+	behind := []BumpPolicy{BumpNone, BumpMajor}
+	for i, o := range got {
+		if diff := cmp.Diff(behind[i], o.Behind()); diff != "" {
+			t.Errorf("\nBehind(%s): -want, +got:\n%s", o.Package, diff)
+		}
+	}
+}
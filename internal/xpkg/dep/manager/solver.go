@@ -0,0 +1,307 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+
+	"github.com/upbound/up/internal/xpkg/dep/lock"
+	xpkg "github.com/upbound/up/internal/xpkg/dep/marshaler/xpkg"
+)
+
+const (
+	// maxSolveIterations bounds the number of times the solver re-evaluates
+	// the graph after selecting a different version of a package.
+	maxSolveIterations = 100
+
+	rootSource = "crossplane.yaml"
+
+	errSolveNotConvergedFmt = "failed to find a consistent set of dependency versions after %d iterations"
+	errConflictFmt          = "no version of %s satisfies all constraints:"
+	errFrozenUnsatisfiedFmt = "%s is not pinned in the lock file at a version satisfying all constraints"
+)
+
+// Constraint is a version constraint imposed on a package.
+type Constraint struct {
+	// Source is the package that imposed the constraint. It is empty if the
+	// constraint was declared in the root crossplane.yaml.
+	Source string
+	// Constraint is the version constraint.
+	Constraint string
+}
+
+// Requirement is the set of constraints imposed on a single package across a
+// dependency graph.
+type Requirement struct {
+	// Dependency is the first dependency seen for the package.
+	Dependency v1beta1.Dependency
+	// Constraints are all constraints imposed on the package.
+	Constraints []Constraint
+	// Roots are the indexes of the root dependencies through which the
+	// package is reached.
+	Roots []int
+}
+
+// ConflictError is returned when no version of a package satisfies every
+// constraint imposed on it.
+type ConflictError struct {
+	Package     string
+	Constraints []Constraint
+}
+
+// Error returns a report of the conflicting constraints and the packages
+// that imposed them.
+func (e *ConflictError) Error() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, errConflictFmt, e.Package)
+	for _, c := range e.Constraints {
+		src := c.Source
+		if src == "" {
+			src = rootSource
+		}
+		fmt.Fprintf(b, "\n  %s required by %s", c.Constraint, src)
+	}
+	return b.String()
+}
+
+// Requirements collects the constraints imposed on every package reachable
+// from the supplied root dependencies. The supplied function is used to look
+// up the package whose dependencies should be followed for a given package
+// name. Packages it returns nil for are treated as leaves.
+func Requirements(roots []v1beta1.Dependency, get func(string) *xpkg.ParsedPackage) map[string]*Requirement {
+	reqs := make(map[string]*Requirement)
+
+	type item struct {
+		dep    v1beta1.Dependency
+		source string
+		root   int
+	}
+
+	queue := make([]item, len(roots))
+	for i, d := range roots {
+		queue[i] = item{dep: d, root: i}
+	}
+
+	// expanded tracks the (package, root) pairs we have already followed so
+	// that cycles terminate and every root is recorded.
+	expanded := make(map[string]bool)
+	for len(queue) > 0 {
+		it := queue[0]
+		queue = queue[1:]
+
+		k := lock.Key(it.dep.Package)
+		r, ok := reqs[k]
+		if !ok {
+			r = &Requirement{Dependency: it.dep}
+			reqs[k] = r
+		}
+		if !containsConstraint(r.Constraints, it.source, it.dep.Constraints) {
+			r.Constraints = append(r.Constraints, Constraint{
+				Source:     it.source,
+				Constraint: it.dep.Constraints,
+			})
+		}
+		if !containsInt(r.Roots, it.root) {
+			r.Roots = append(r.Roots, it.root)
+		}
+
+		e := fmt.Sprintf("%s/%d", k, it.root)
+		if expanded[e] {
+			continue
+		}
+		expanded[e] = true
+
+		p := get(k)
+		if p == nil {
+			continue
+		}
+		for _, d := range p.Dependencies() {
+			queue = append(queue, item{dep: d, source: k, root: it.root})
+		}
+	}
+
+	return reqs
+}
+
+// HighestMatching returns the highest of the supplied versions that satisfies
+// every supplied constraint. Constraints that are not semantic version
+// constraints are treated as exact tags.
+func HighestMatching(vers []string, cs []Constraint) (string, bool) {
+	cands := append([]string{}, vers...)
+	for _, c := range cs {
+		if _, err := semver.NewConstraint(c.Constraint); c.Constraint != "" && err != nil {
+			cands = append(cands, c.Constraint)
+		}
+	}
+
+	var (
+		best  string
+		bestV *semver.Version
+	)
+	for _, v := range cands {
+		if !satisfiesAll(v, cs) {
+			continue
+		}
+		sv, err := semver.NewVersion(v)
+		if err != nil {
+			// exact, non semver tag that satisfies all constraints.
+			return v, true
+		}
+		if bestV == nil || sv.GreaterThan(bestV) {
+			best, bestV = v, sv
+		}
+	}
+	return best, best != ""
+}
+
+// Solve computes a single consistent set of versions for the supplied
+// dependencies and their transitive dependencies, fetching packages as
+// necessary. For every package the highest available version that satisfies
+// all constraints imposed on it across the graph is selected. A
+// *ConflictError is returned if no such version exists. Subsequent calls to
+// AddAll resolve to the selected versions.
+func (m *Manager) Solve(ctx context.Context, deps []v1beta1.Dependency) (map[string]*xpkg.ParsedPackage, error) {
+	return m.solve(ctx, deps, m.i.ResolveVersions, m.retrieveAndStorePkg)
+}
+
+// DrySolve is like Solve, but packages that are not in the cache are fetched
+// without being added to it, so that versions can be resolved without side
+// effects.
+func (m *Manager) DrySolve(ctx context.Context, deps []v1beta1.Dependency) (map[string]*xpkg.ParsedPackage, error) {
+	return m.solve(ctx, deps, m.i.ResolveVersions, m.loadPkg)
+}
+
+type versionsFn func(context.Context, v1beta1.Dependency) ([]string, error)
+type loadFn func(context.Context, v1beta1.Dependency) (*xpkg.ParsedPackage, error)
+
+func (m *Manager) solve(ctx context.Context, deps []v1beta1.Dependency, versions versionsFn, load loadFn) (map[string]*xpkg.ParsedPackage, error) {
+	// clear any previous solution so that it does not influence this one.
+	m.solved = nil
+
+	selected := make(map[string]*xpkg.ParsedPackage)
+	get := func(k string) *xpkg.ParsedPackage { return selected[k] }
+
+	for i := 0; i < maxSolveIterations; i++ {
+		reqs := Requirements(deps, get)
+
+		keys := make([]string, 0, len(reqs))
+		for k := range reqs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		changed := false
+		for _, k := range keys {
+			r := reqs[k]
+			v, err := m.pick(ctx, k, r, versions)
+			if err != nil {
+				return nil, err
+			}
+			if p, ok := selected[k]; ok && p.Version() == v {
+				continue
+			}
+
+			p, err := load(ctx, v1beta1.Dependency{
+				Package:     r.Dependency.Package,
+				Type:        r.Dependency.Type,
+				Constraints: v,
+			})
+			if err != nil {
+				return nil, err
+			}
+			selected[k] = p
+			changed = true
+		}
+
+		// drop packages that are no longer part of the graph.
+		for k := range selected {
+			if _, ok := reqs[k]; !ok {
+				delete(selected, k)
+				changed = true
+			}
+		}
+
+		if !changed {
+			m.solved = make(map[string]string, len(selected))
+			for k, p := range selected {
+				m.solved[k] = p.Version()
+			}
+			return selected, nil
+		}
+	}
+
+	return nil, fmt.Errorf(errSolveNotConvergedFmt, maxSolveIterations)
+}
+
+// pick selects the version to use for the package with the supplied key,
+// preferring the version pinned in the lock file if it satisfies every
+// constraint.
+func (m *Manager) pick(ctx context.Context, k string, r *Requirement, versions versionsFn) (string, error) {
+	if m.l != nil {
+		if p, ok := m.l.Get(k); ok && satisfiesAll(p.Version, r.Constraints) {
+			return p.Version, nil
+		}
+		if m.frozen {
+			return "", fmt.Errorf(errFrozenUnsatisfiedFmt, k)
+		}
+	}
+
+	vers, err := versions(ctx, r.Dependency)
+	if err != nil {
+		return "", err
+	}
+
+	v, ok := HighestMatching(vers, r.Constraints)
+	if !ok {
+		return "", &ConflictError{
+			Package:     k,
+			Constraints: r.Constraints,
+		}
+	}
+	return v, nil
+}
+
+func satisfiesAll(v string, cs []Constraint) bool {
+	for _, c := range cs {
+		if !lock.Satisfies(c.Constraint, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsConstraint(cs []Constraint, source, constraint string) bool {
+	for _, c := range cs {
+		if c.Source == source && c.Constraint == constraint {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(is []int, i int) bool {
+	for _, j := range is {
+		if i == j {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,220 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	metav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/spf13/afero"
+	apimetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/upbound/up/internal/xpkg/dep/cache"
+	"github.com/upbound/up/internal/xpkg/dep/resolver/image"
+)
+
+func TestHighestMatching(t *testing.T) {
+	vers := []string{"v1.0.0", "v1.2.0", "v1.5.0", "v2.0.0"}
+
+	type want struct {
+		version string
+		ok      bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		cs     []Constraint
+		want   want
+	}{
+		"SingleConstraint": {
+			reason: "Should return the highest version satisfying a single constraint.",
+			cs:     []Constraint{{Constraint: ">=v1.0.0"}},
+			want:   want{version: "v2.0.0", ok: true},
+		},
+		"Intersection": {
+			reason: "Should return the highest version satisfying every constraint.",
+			cs: []Constraint{
+				{Constraint: ">=v1.0.0, <v2.0.0"},
+				{Source: "upbound/configuration-b", Constraint: ">=v1.2.0"},
+			},
+			want: want{version: "v1.5.0", ok: true},
+		},
+		"NoIntersection": {
+			reason: "Should return false if no version satisfies every constraint.",
+			cs: []Constraint{
+				{Constraint: "<v1.2.0"},
+				{Source: "upbound/configuration-b", Constraint: ">=v2.0.0"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			v, ok := HighestMatching(vers, tc.cs)
+
+			if diff := cmp.Diff(tc.want, want{version: v, ok: ok}, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nHighestMatching(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSolve(t *testing.T) {
+	configuration := func(constraint string) *metav1.Configuration {
+		return &metav1.Configuration{
+			TypeMeta: apimetav1.TypeMeta{
+				APIVersion: "meta.pkg.crossplane.io/v1",
+				Kind:       "Configuration",
+			},
+			Spec: metav1.ConfigurationSpec{
+				MetaSpec: metav1.MetaSpec{
+					DependsOn: []metav1.Dependency{
+						{
+							Provider: pointer.String("crossplane/provider-aws"),
+							Version:  constraint,
+						},
+					},
+				},
+			},
+		}
+	}
+	provider := &metav1.Provider{
+		TypeMeta: apimetav1.TypeMeta{
+			APIVersion: "meta.pkg.crossplane.io/v1",
+			Kind:       "Provider",
+		},
+	}
+
+	ref := func(s string) name.Reference {
+		r, _ := name.ParseReference(s)
+		return r
+	}
+
+	roots := []v1beta1.Dependency{
+		{
+			Package:     "crossplane/configuration-a",
+			Type:        v1beta1.ConfigurationPackageType,
+			Constraints: "v1.0.0",
+		},
+		{
+			Package:     "crossplane/configuration-b",
+			Type:        v1beta1.ConfigurationPackageType,
+			Constraints: "v1.2.0",
+		},
+	}
+
+	type want struct {
+		versions map[string]string
+		cached   bool
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason string
+		b      *metav1.Configuration
+		dry    bool
+		want   want
+	}{
+		"Diamond": {
+			reason: "Should select the highest version satisfying the constraints of both dependants.",
+			b:      configuration(">=v1.2.0"),
+			want: want{
+				versions: map[string]string{
+					"crossplane/configuration-a": "v1.0.0",
+					"crossplane/configuration-b": "v1.2.0",
+					"crossplane/provider-aws":    "v1.5.0",
+				},
+				cached: true,
+			},
+		},
+		"DiamondDryRun": {
+			reason: "Should select the same versions without adding packages to the cache in a dry run.",
+			b:      configuration(">=v1.2.0"),
+			dry:    true,
+			want: want{
+				versions: map[string]string{
+					"crossplane/configuration-a": "v1.0.0",
+					"crossplane/configuration-b": "v1.2.0",
+					"crossplane/provider-aws":    "v1.5.0",
+				},
+			},
+		},
+		"Conflict": {
+			reason: "Should report the conflicting constraints if no version satisfies both dependants.",
+			b:      configuration(">=v2.0.0"),
+			want: want{
+				err: &ConflictError{
+					Package: "crossplane/provider-aws",
+					Constraints: []Constraint{
+						{Source: "crossplane/configuration-a", Constraint: ">=v1.0.0, <v2.0.0"},
+						{Source: "crossplane/configuration-b", Constraint: ">=v2.0.0"},
+					},
+				},
+				cached: true,
+			},
+		},
+	}
+
+	for n, tc := range cases {
+		t.Run(n, func(t *testing.T) {
+			c, _ := cache.NewLocal("/tmp/cache", cache.WithFS(afero.NewMemMapFs()))
+
+			f := NewMockFetcher(
+				WithPackageObjects(ref("crossplane/configuration-a:v1.0.0"), configuration(">=v1.0.0, <v2.0.0")),
+				WithPackageObjects(ref("crossplane/configuration-b:v1.2.0"), tc.b),
+				WithPackageObjects(ref("crossplane/provider-aws:v1.0.0"), provider),
+				WithPackageObjects(ref("crossplane/provider-aws:v1.5.0"), provider),
+				WithPackageObjects(ref("crossplane/provider-aws:v2.0.0"), provider),
+			)
+			f.tags = []string{"v1.0.0", "v1.2.0", "v1.5.0", "v2.0.0"}
+
+			m, _ := New(
+				WithCache(c),
+				WithResolver(image.NewResolver(image.WithFetcher(f))),
+			)
+
+			solve := m.Solve
+			if tc.dry {
+				solve = m.DrySolve
+			}
+			got, err := solve(context.Background(), roots)
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nSolve(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+
+			var vers map[string]string
+			if got != nil {
+				vers = make(map[string]string, len(got))
+				for k, p := range got {
+					vers[k] = p.Version()
+				}
+			}
+			if diff := cmp.Diff(tc.want.versions, vers); diff != "" {
+				t.Errorf("\n%s\nSolve(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			_, err = c.Get(roots[0])
+			if diff := cmp.Diff(tc.want.cached, err == nil); diff != "" {
+				t.Errorf("\n%s\nSolve(...): -want cached, +got cached:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
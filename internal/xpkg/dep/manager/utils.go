@@ -35,6 +35,11 @@ func ConvertToV1beta1(in metav1.Dependency) v1beta1.Dependency {
 		betaD.Type = v1beta1.ConfigurationPackageType
 	}
 
+	if in.Function != nil && in.Provider == nil && in.Configuration == nil {
+		betaD.Package = *in.Function
+		betaD.Type = v1beta1.FunctionPackageType
+	}
+
 	return betaD
 }
 
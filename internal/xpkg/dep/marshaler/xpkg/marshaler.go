@@ -28,6 +28,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 
 	xpmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
+	xpmetav1beta1 "github.com/crossplane/crossplane/apis/pkg/meta/v1beta1"
 
 	"github.com/crossplane/crossplane-runtime/pkg/parser"
 	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
@@ -156,10 +157,14 @@ func processPackage(pkg linter.Package) (*ParsedPackage, error) {
 	meta := metas[0]
 	var linter linter.Linter
 	var pkgType v1beta1.PackageType
-	if meta.GetObjectKind().GroupVersionKind().Kind == xpmetav1.ConfigurationKind {
+	switch meta.GetObjectKind().GroupVersionKind().Kind {
+	case xpmetav1.ConfigurationKind:
 		linter = xpkg.NewConfigurationLinter()
 		pkgType = v1beta1.ConfigurationPackageType
-	} else {
+	case xpmetav1beta1.FunctionKind:
+		linter = xpkg.NewFunctionLinter()
+		pkgType = v1beta1.FunctionPackageType
+	default:
 		linter = xpkg.NewProviderLinter()
 		pkgType = v1beta1.ProviderPackageType
 	}
@@ -235,5 +240,10 @@ func convertToV1beta1(in xpmetav1.Dependency) v1beta1.Dependency {
 		betaD.Type = v1beta1.ConfigurationPackageType
 	}
 
+	if in.Function != nil && in.Provider == nil && in.Configuration == nil {
+		betaD.Package = *in.Function
+		betaD.Type = v1beta1.FunctionPackageType
+	}
+
 	return betaD
 }
@@ -0,0 +1,82 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package image
+
+import (
+	"context"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+
+	"github.com/upbound/up/internal/mirror"
+)
+
+// MirrorFetcher fetches images from the mirrors of their registries. The
+// endpoints of a mirror are tried in order until one of them succeeds.
+type MirrorFetcher struct {
+	f Fetcher
+	m mirror.Mirrors
+}
+
+// NewMirrorFetcher returns a MirrorFetcher that fetches images with the
+// supplied Fetcher from the supplied mirrors.
+func NewMirrorFetcher(f Fetcher, m mirror.Mirrors) *MirrorFetcher {
+	return &MirrorFetcher{f: f, m: m}
+}
+
+// Fetch fetches a package image.
+func (f *MirrorFetcher) Fetch(ctx context.Context, ref name.Reference, secrets ...string) (v1.Image, error) {
+	var img v1.Image
+	err := f.each(ref, func(r name.Reference) (err error) {
+		img, err = f.f.Fetch(ctx, r, secrets...)
+		return err
+	})
+	return img, err
+}
+
+// Head fetches a package descriptor.
+func (f *MirrorFetcher) Head(ctx context.Context, ref name.Reference, secrets ...string) (*v1.Descriptor, error) {
+	var desc *v1.Descriptor
+	err := f.each(ref, func(r name.Reference) (err error) {
+		desc, err = f.f.Head(ctx, r, secrets...)
+		return err
+	})
+	return desc, err
+}
+
+// Tags fetches a package's tags.
+func (f *MirrorFetcher) Tags(ctx context.Context, ref name.Reference, secrets ...string) ([]string, error) {
+	var tags []string
+	err := f.each(ref, func(r name.Reference) (err error) {
+		tags, err = f.f.Tags(ctx, r, secrets...)
+		return err
+	})
+	return tags, err
+}
+
+// each calls fn with the mirrored references of the supplied reference until
+// it succeeds. It returns the error of the last attempt if none succeeds.
+func (f *MirrorFetcher) each(ref name.Reference, fn func(name.Reference) error) error {
+	refs, err := f.m.References(ref)
+	if err != nil {
+		return err
+	}
+	for _, r := range refs {
+		if err = fn(r); err == nil {
+			return nil
+		}
+	}
+	return err
+}
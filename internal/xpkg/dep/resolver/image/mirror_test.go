@@ -0,0 +1,118 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package image
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/upbound/up/internal/mirror"
+)
+
+// registryFetcher returns the registry of a reference as its only tag, or the
+// error configured for the registry, recording the repositories it is asked
+// for.
+type registryFetcher struct {
+	MockFetcher
+	registries map[string]error
+	calls      []string
+}
+
+func (f *registryFetcher) Tags(_ context.Context, ref name.Reference, _ ...string) ([]string, error) {
+	f.calls = append(f.calls, ref.Context().Name())
+	if err := f.registries[ref.Context().RegistryStr()]; err != nil {
+		return nil, err
+	}
+	return []string{ref.Context().RegistryStr()}, nil
+}
+
+func TestMirrorFetcher(t *testing.T) {
+	errBoom := errors.New("boom")
+	errNope := errors.New("nope")
+
+	type want struct {
+		tags  []string
+		calls []string
+		err   error
+	}
+	cases := map[string]struct {
+		reason     string
+		mirrors    mirror.Mirrors
+		registries map[string]error
+		want       want
+	}{
+		"NoMirror": {
+			reason:     "Should fetch from the registry of the reference if no mirror matches it.",
+			registries: map[string]error{},
+			want: want{
+				tags:  []string{"xpkg.upbound.io"},
+				calls: []string{"xpkg.upbound.io/upbound/provider-aws"},
+			},
+		},
+		"Fallback": {
+			reason: "Should fall back to the next endpoint of a mirror if fetching from one fails.",
+			mirrors: mirror.Mirrors{
+				{Prefix: "xpkg.upbound.io", Endpoints: []string{"a.example.com/proxy", "b.example.com/proxy", "c.example.com"}},
+			},
+			registries: map[string]error{"a.example.com": errBoom},
+			want: want{
+				tags: []string{"b.example.com"},
+				calls: []string{
+					"a.example.com/proxy/upbound/provider-aws",
+					"b.example.com/proxy/upbound/provider-aws",
+				},
+			},
+		},
+		"AllFailed": {
+			reason: "Should return the error of the last endpoint if fetching from every endpoint fails.",
+			mirrors: mirror.Mirrors{
+				{Prefix: "xpkg.upbound.io", Endpoints: []string{"a.example.com", "b.example.com"}},
+			},
+			registries: map[string]error{"a.example.com": errBoom, "b.example.com": errNope},
+			want: want{
+				calls: []string{
+					"a.example.com/upbound/provider-aws",
+					"b.example.com/upbound/provider-aws",
+				},
+				err: errNope,
+			},
+		},
+	}
+
+	for n, tc := range cases {
+		t.Run(n, func(t *testing.T) {
+			ref, err := name.ParseReference("xpkg.upbound.io/upbound/provider-aws:v1.0.0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			rf := &registryFetcher{registries: tc.registries}
+			tags, err := NewMirrorFetcher(rf, tc.mirrors).Tags(context.Background(), ref)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nTags(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.tags, tags); diff != "" {
+				t.Errorf("\n%s\nTags(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.calls, rf.calls); diff != "" {
+				t.Errorf("\n%s\nTags(...): -want calls, +got calls:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -126,14 +126,37 @@ func (r *Resolver) ResolveTag(ctx context.Context, dep v1beta1.Dependency) (stri
 		return "", errors.Wrap(err, errInvalidConstraint)
 	}
 
+	vers, err := r.ResolveVersions(ctx, dep)
+	if err != nil {
+		return "", err
+	}
+
+	var ver string
+	for _, t := range vers {
+		v, _ := semver.NewVersion(t)
+		if c.Check(v) {
+			ver = t
+		}
+	}
+
+	if ver == "" {
+		return "", errors.New(errNoMatchingVersion)
+	}
+
+	return ver, nil
+}
+
+// ResolveVersions returns the tags of the given v1beta1.Dependency that are
+// valid semantic versions, sorted in ascending order.
+func (r *Resolver) ResolveVersions(ctx context.Context, dep v1beta1.Dependency) ([]string, error) {
 	ref, err := name.ParseReference(dep.Identifier())
 	if err != nil {
-		return "", errors.Wrap(err, errInvalidProviderRef)
+		return nil, errors.Wrap(err, errInvalidProviderRef)
 	}
 
 	tags, err := r.f.Tags(ctx, ref)
 	if err != nil {
-		return "", errors.Wrap(err, errFailedToFetchTags)
+		return nil, errors.Wrap(err, errFailedToFetchTags)
 	}
 
 	vs := []*semver.Version{}
@@ -151,18 +174,12 @@ func (r *Resolver) ResolveTag(ctx context.Context, dep v1beta1.Dependency) (stri
 	}
 
 	sort.Sort(semver.Collection(vs))
-	var ver string
-	for _, v := range vs {
-		if c.Check(v) {
-			ver = v.Original()
-		}
-	}
 
-	if ver == "" {
-		return "", errors.New(errNoMatchingVersion)
+	vers := make([]string, len(vs))
+	for i, v := range vs {
+		vers[i] = v.Original()
 	}
-
-	return ver, nil
+	return vers, nil
 }
 
 // ResolveDigest performs a head request to the configured registry in order to determine
@@ -0,0 +1,396 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/spf13/afero"
+)
+
+const (
+	errParsePlatformFmt      = "invalid platform %q, must be of the form os/arch[/variant]"
+	errDuplicatePlatformFmt  = "platform %s specified more than once"
+	errNoImageForPlatformFmt = "no image found for platform %s"
+	errPlatformMismatchFmt   = "image is built for platform %s, not %s"
+	errReadIndexManifest     = "failed to read image index manifest"
+	errReadLayout            = "failed to read OCI image layout"
+	errReadTarball           = "failed to read package tarball"
+	errNoImagesInPackage     = "package does not contain any images"
+	errPlatformTagFmt        = "failed to construct tag for platform %s"
+)
+
+// ParsePlatforms parses the supplied platforms, each of which must be of the
+// form os/arch[/variant], e.g. linux/amd64 or linux/arm64/v8.
+func ParsePlatforms(ps []string) ([]v1.Platform, error) {
+	out := make([]v1.Platform, 0, len(ps))
+	seen := make(map[string]bool, len(ps))
+	for _, s := range ps {
+		parts := strings.Split(s, "/")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.Errorf(errParsePlatformFmt, s)
+		}
+		p := v1.Platform{OS: parts[0], Architecture: parts[1]}
+		if len(parts) == 3 {
+			p.Variant = parts[2]
+		}
+		if seen[p.String()] {
+			return nil, errors.Errorf(errDuplicatePlatformFmt, p.String())
+		}
+		seen[p.String()] = true
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// ImagePlatform returns the platform recorded in the configuration of the
+// supplied image.
+func ImagePlatform(img v1.Image) (v1.Platform, error) {
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return v1.Platform{}, err
+	}
+	if p := cfg.Platform(); p != nil {
+		return *p, nil
+	}
+	return v1.Platform{}, nil
+}
+
+// CheckPlatform returns an error if the supplied image was not built for the
+// supplied platform.
+func CheckPlatform(img v1.Image, p v1.Platform) error {
+	got, err := ImagePlatform(img)
+	if err != nil {
+		return err
+	}
+	if !got.Satisfies(p) {
+		return errors.Errorf(errPlatformMismatchFmt, got.String(), p.String())
+	}
+	return nil
+}
+
+// setPlatform records the supplied platform in the configuration of the
+// image.
+func setPlatform(img v1.Image, p v1.Platform) (v1.Image, error) {
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	cfg = cfg.DeepCopy()
+	cfg.OS = p.OS
+	cfg.Architecture = p.Architecture
+	cfg.Variant = p.Variant
+	cfg.OSVersion = p.OSVersion
+	return mutate.ConfigFile(img, cfg)
+}
+
+// Index assembles an OCI image index from the supplied package images. The
+// platform of each manifest in the index is taken from the configuration of
+// the corresponding image.
+func Index(imgs ...v1.Image) (v1.ImageIndex, error) {
+	adds := make([]mutate.IndexAddendum, len(imgs))
+	for i, img := range imgs {
+		mt, err := img.MediaType()
+		if err != nil {
+			return nil, err
+		}
+		p, err := ImagePlatform(img)
+		if err != nil {
+			return nil, err
+		}
+		adds[i] = mutate.IndexAddendum{
+			Add: img,
+			Descriptor: v1.Descriptor{
+				MediaType: mt,
+				Platform:  &p,
+			},
+		}
+	}
+	return mutate.AppendManifests(mutate.IndexMediaType(empty.Index, types.OCIImageIndex), adds...), nil
+}
+
+// ImageForPlatform returns the image in the supplied index that was built for
+// the supplied platform. Nested indexes are searched as well.
+func ImageForPlatform(ii v1.ImageIndex, p v1.Platform) (v1.Image, error) {
+	m, err := ii.IndexManifest()
+	if err != nil {
+		return nil, errors.Wrap(err, errReadIndexManifest)
+	}
+	for _, d := range m.Manifests {
+		switch {
+		case d.MediaType.IsIndex():
+			child, err := ii.ImageIndex(d.Digest)
+			if err != nil {
+				return nil, err
+			}
+			img, err := ImageForPlatform(child, p)
+			if err == nil {
+				return img, nil
+			}
+		case d.MediaType.IsImage():
+			if d.Platform != nil {
+				if !d.Platform.Satisfies(p) {
+					continue
+				}
+				return ii.Image(d.Digest)
+			}
+			// Fall back to the image configuration if the descriptor does
+			// not carry a platform.
+			img, err := ii.Image(d.Digest)
+			if err != nil {
+				return nil, err
+			}
+			if CheckPlatform(img, p) == nil {
+				return img, nil
+			}
+		}
+	}
+	return nil, errors.Errorf(errNoImageForPlatformFmt, p.String())
+}
+
+// ImagesFromIndex returns all images in the supplied index, including those
+// in nested indexes.
+func ImagesFromIndex(ii v1.ImageIndex) ([]v1.Image, error) {
+	m, err := ii.IndexManifest()
+	if err != nil {
+		return nil, errors.Wrap(err, errReadIndexManifest)
+	}
+	imgs := make([]v1.Image, 0, len(m.Manifests))
+	for _, d := range m.Manifests {
+		switch {
+		case d.MediaType.IsIndex():
+			child, err := ii.ImageIndex(d.Digest)
+			if err != nil {
+				return nil, err
+			}
+			c, err := ImagesFromIndex(child)
+			if err != nil {
+				return nil, err
+			}
+			imgs = append(imgs, c...)
+		case d.MediaType.IsImage():
+			img, err := ii.Image(d.Digest)
+			if err != nil {
+				return nil, err
+			}
+			imgs = append(imgs, img)
+		}
+	}
+	return imgs, nil
+}
+
+// PlatformTag returns the tag under which the image for the supplied platform
+// is stored in a multi-manifest package tarball.
+func PlatformTag(repo string, p v1.Platform) (name.Tag, error) {
+	t, err := name.NewTag(fmt.Sprintf("%s:%s", repo, strings.ReplaceAll(p.String(), "/", "-")))
+	return t, errors.Wrapf(err, errPlatformTagFmt, p.String())
+}
+
+// WriteIndexTarball writes the images in the supplied index to w as a
+// multi-manifest tarball, tagging each image with its platform.
+func WriteIndexTarball(repo string, ii v1.ImageIndex, w io.Writer) error {
+	imgs, err := ImagesFromIndex(ii)
+	if err != nil {
+		return err
+	}
+	refs := make(map[name.Reference]v1.Image, len(imgs))
+	for _, img := range imgs {
+		p, err := ImagePlatform(img)
+		if err != nil {
+			return err
+		}
+		t, err := PlatformTag(repo, p)
+		if err != nil {
+			return err
+		}
+		refs[t] = img
+	}
+	return tarball.MultiRefWrite(refs, w)
+}
+
+// WriteLayout writes the supplied index as an OCI image layout directory at
+// the supplied path, replacing any existing directory. The layout is written
+// to a temporary directory first, because go-containerregistry can only write
+// layouts to the OS filesystem.
+func WriteLayout(afs afero.Fs, path string, ii v1.ImageIndex) error {
+	tmp, err := os.MkdirTemp("", "xpkg-layout-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp) // nolint:errcheck
+	if _, err := layout.Write(tmp, ii); err != nil {
+		return err
+	}
+	if err := afs.RemoveAll(path); err != nil {
+		return err
+	}
+	osfs := afero.NewOsFs()
+	return afero.Walk(osfs, tmp, func(src string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(tmp, src)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(path, rel)
+		if info.IsDir() {
+			return afs.MkdirAll(dst, 0o755)
+		}
+		return copyFile(osfs, src, afs, dst)
+	})
+}
+
+func copyFile(srcFS afero.Fs, src string, dstFS afero.Fs, dst string) error {
+	in, err := srcFS.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close() // nolint:errcheck
+	out, err := dstFS.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// ImagesFromPath reads the package images stored at the supplied path, which
+// may either be an xpkg tarball with one or more images or an OCI image
+// layout directory. Images are returned sorted by platform.
+func ImagesFromPath(path string) ([]v1.Image, error) {
+	path = filepath.Clean(path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var imgs []v1.Image
+	if info.IsDir() {
+		ii, err := layout.ImageIndexFromPath(path)
+		if err != nil {
+			return nil, errors.Wrap(err, errReadLayout)
+		}
+		imgs, err = ImagesFromIndex(ii)
+		if err != nil {
+			return nil, errors.Wrap(err, errReadLayout)
+		}
+	} else {
+		imgs, err = imagesFromTarball(path)
+		if err != nil {
+			return nil, errors.Wrap(err, errReadTarball)
+		}
+	}
+	if len(imgs) == 0 {
+		return nil, errors.New(errNoImagesInPackage)
+	}
+	return sortByPlatform(imgs)
+}
+
+// ImageFromPath reads the package image for the supplied platform from the
+// supplied path. Packages with a single image, e.g. those built without
+// --platform, may not record a platform, so their image is always returned.
+func ImageFromPath(path string, p v1.Platform) (v1.Image, error) {
+	imgs, err := ImagesFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(imgs) == 1 {
+		return imgs[0], nil
+	}
+	for _, img := range imgs {
+		if CheckPlatform(img, p) == nil {
+			return img, nil
+		}
+	}
+	return nil, errors.Errorf(errNoImageForPlatformFmt, p.String())
+}
+
+func imagesFromTarball(path string) ([]v1.Image, error) {
+	opener := func() (io.ReadCloser, error) {
+		return os.Open(path) //nolint:gosec // path is supplied by the user.
+	}
+	m, err := tarball.LoadManifest(opener)
+	if err != nil {
+		return nil, err
+	}
+	// Single image tarballs, e.g. those written by previous versions of the
+	// build command, are not necessarily tagged.
+	if len(m) == 1 {
+		img, err := tarball.Image(opener, nil)
+		if err != nil {
+			return nil, err
+		}
+		return []v1.Image{img}, nil
+	}
+	imgs := make([]v1.Image, 0, len(m))
+	for _, d := range m {
+		if len(d.RepoTags) == 0 {
+			continue
+		}
+		t, err := name.NewTag(d.RepoTags[0])
+		if err != nil {
+			return nil, err
+		}
+		img, err := tarball.Image(opener, &t)
+		if err != nil {
+			return nil, err
+		}
+		imgs = append(imgs, img)
+	}
+	return imgs, nil
+}
+
+func sortByPlatform(imgs []v1.Image) ([]v1.Image, error) {
+	keys := make([]string, len(imgs))
+	for i, img := range imgs {
+		p, err := ImagePlatform(img)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = p.String()
+	}
+	sort.Stable(byKey{keys: keys, imgs: imgs})
+	return imgs, nil
+}
+
+type byKey struct {
+	keys []string
+	imgs []v1.Image
+}
+
+func (b byKey) Len() int           { return len(b.keys) }
+func (b byKey) Less(i, j int) bool { return b.keys[i] < b.keys[j] }
+func (b byKey) Swap(i, j int) {
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+	b.imgs[i], b.imgs[j] = b.imgs[j], b.imgs[i]
+}
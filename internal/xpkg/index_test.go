@@ -0,0 +1,275 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+var (
+	linuxAmd64 = v1.Platform{OS: "linux", Architecture: "amd64"}
+	linuxArm64 = v1.Platform{OS: "linux", Architecture: "arm64"}
+)
+
+func platformImage(t *testing.T, p v1.Platform) v1.Image {
+	t.Helper()
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err = setPlatform(img, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func platforms(t *testing.T, imgs []v1.Image) []string {
+	t.Helper()
+	out := make([]string, len(imgs))
+	for i, img := range imgs {
+		p, err := ImagePlatform(img)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out[i] = p.String()
+	}
+	return out
+}
+
+func TestParsePlatforms(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		ps     []string
+		want   []v1.Platform
+		err    error
+	}{
+		"Empty": {
+			reason: "No platforms should result in an empty slice.",
+			want:   []v1.Platform{},
+		},
+		"Valid": {
+			reason: "Platforms with and without variants should be parsed.",
+			ps:     []string{"linux/amd64", "linux/arm64/v8"},
+			want: []v1.Platform{
+				linuxAmd64,
+				{OS: "linux", Architecture: "arm64", Variant: "v8"},
+			},
+		},
+		"Invalid": {
+			reason: "Platforms that are not of the form os/arch should be rejected.",
+			ps:     []string{"linux_amd64"},
+			err:    errors.Errorf(errParsePlatformFmt, "linux_amd64"),
+		},
+		"Duplicate": {
+			reason: "Platforms should not be specified more than once.",
+			ps:     []string{"linux/amd64", "linux/amd64"},
+			err:    errors.Errorf(errDuplicatePlatformFmt, "linux/amd64"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParsePlatforms(tc.ps)
+
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nParsePlatforms(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nParsePlatforms(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestImageForPlatform(t *testing.T) {
+	amd64 := platformImage(t, linuxAmd64)
+	arm64 := platformImage(t, linuxArm64)
+	ii, err := Index(amd64, arm64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		reason   string
+		platform v1.Platform
+		want     v1.Image
+		err      error
+	}{
+		"Found": {
+			reason:   "The image for the requested platform should be returned.",
+			platform: linuxArm64,
+			want:     arm64,
+		},
+		"NotFound": {
+			reason:   "An error should be returned if no image matches the requested platform.",
+			platform: v1.Platform{OS: "windows", Architecture: "amd64"},
+			err:      errors.Errorf(errNoImageForPlatformFmt, "windows/amd64"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ImageForPlatform(ii, tc.platform)
+
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nImageForPlatform(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if tc.want == nil {
+				return
+			}
+			wd, _ := tc.want.Digest()
+			gd, _ := got.Digest()
+			if diff := cmp.Diff(wd, gd); diff != "" {
+				t.Errorf("\n%s\nImageForPlatform(...): -want digest, +got digest:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestImagesFromPath(t *testing.T) {
+	ii, err := Index(platformImage(t, linuxArm64), platformImage(t, linuxAmd64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+
+	tarPath := filepath.Join(dir, "multi.xpkg")
+	f, err := os.Create(tarPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteIndexTarball("provider-test", ii, f); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+
+	layoutPath := filepath.Join(dir, "layout.xpkg")
+	if _, err := layout.Write(layoutPath, ii); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		reason string
+		path   string
+		want   []string
+	}{
+		"MultiManifestTarball": {
+			reason: "All images in a multi-manifest tarball should be returned sorted by platform.",
+			path:   tarPath,
+			want:   []string{"linux/amd64", "linux/arm64"},
+		},
+		"Layout": {
+			reason: "All images in an OCI image layout should be returned sorted by platform.",
+			path:   layoutPath,
+			want:   []string{"linux/amd64", "linux/arm64"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			imgs, err := ImagesFromPath(tc.path)
+			if err != nil {
+				t.Fatalf("\n%s\nImagesFromPath(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, platforms(t, imgs)); diff != "" {
+				t.Errorf("\n%s\nImagesFromPath(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestImageFromPath(t *testing.T) {
+	arm64 := platformImage(t, linuxArm64)
+	ii, err := Index(platformImage(t, linuxAmd64), arm64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+
+	multiPath := filepath.Join(dir, "multi.xpkg")
+	f, err := os.Create(multiPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteIndexTarball("provider-test", ii, f); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+
+	single, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	singlePath := filepath.Join(dir, "single.xpkg")
+	if err := tarball.WriteToFile(singlePath, nil, single); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		reason   string
+		path     string
+		platform v1.Platform
+		want     v1.Image
+		err      error
+	}{
+		"MultiManifestTarball": {
+			reason:   "The image for the requested platform should be read from a multi-manifest tarball.",
+			path:     multiPath,
+			platform: linuxArm64,
+			want:     arm64,
+		},
+		"SingleImage": {
+			reason:   "The only image of a package should be returned regardless of its platform.",
+			path:     singlePath,
+			platform: linuxArm64,
+			want:     single,
+		},
+		"NotFound": {
+			reason:   "An error should be returned if no image matches the requested platform.",
+			path:     multiPath,
+			platform: v1.Platform{OS: "windows", Architecture: "amd64"},
+			err:      errors.Errorf(errNoImageForPlatformFmt, "windows/amd64"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ImageFromPath(tc.path, tc.platform)
+
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nImageFromPath(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if tc.want == nil {
+				return
+			}
+			wd, _ := tc.want.Digest()
+			gd, _ := got.Digest()
+			if diff := cmp.Diff(wd, gd); diff != "" {
+				t.Errorf("\n%s\nImageFromPath(...): -want digest, +got digest:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package inspect summarizes the contents of package images.
+package inspect
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
+
+	"github.com/upbound/up/internal/xpkg"
+	mxpkg "github.com/upbound/up/internal/xpkg/dep/marshaler/xpkg"
+	"github.com/upbound/up/internal/xpkg/scheme"
+)
+
+const (
+	errGetManifest             = "failed to get package image manifest"
+	errGetConfig               = "failed to get package image config"
+	errGetDigest               = "failed to get package image digest"
+	errFetchLayer              = "failed to fetch package layer"
+	errMultipleAnnotatedLayers = "package is invalid due to multiple annotated base layers"
+	errParsePackage            = "failed to parse package"
+)
+
+// Summary is a structured summary of the contents of a package image.
+type Summary struct {
+	// Digest is the digest of the package image.
+	Digest string `json:"digest"`
+	// Meta identifies the package.
+	Meta Meta `json:"meta"`
+	// Crossplane is the Crossplane version constraint of the package, if any.
+	Crossplane string `json:"crossplane,omitempty"`
+	// Dependencies are the dependencies declared by the package.
+	Dependencies []Dependency `json:"dependencies"`
+	// Objects are the objects contained in the package, grouped by their
+	// GroupVersionKind.
+	Objects []ObjectGroup `json:"objects"`
+	// Examples is true if the package contains an examples layer.
+	Examples bool `json:"examples"`
+	// Layers are the layers of the package image.
+	Layers []Layer `json:"layers"`
+	// Config is the runtime configuration of the package image, i.e. of the
+	// controller or function runtime it was built on.
+	Config Config `json:"config"`
+}
+
+// Meta identifies a package.
+type Meta struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	// Version is the version of the package, if known, e.g. from the tag it
+	// was fetched from.
+	Version string `json:"version,omitempty"`
+}
+
+// Dependency is a dependency declared by a package.
+type Dependency struct {
+	Package     string `json:"package"`
+	Type        string `json:"type"`
+	Constraints string `json:"constraints"`
+}
+
+// ObjectGroup is a group of objects of the same GroupVersionKind.
+type ObjectGroup struct {
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Count      int      `json:"count"`
+	Names      []string `json:"names"`
+}
+
+// Layer is a layer of a package image.
+type Layer struct {
+	Digest    string `json:"digest"`
+	MediaType string `json:"mediaType"`
+	Size      int64  `json:"size"`
+	// Annotation is the xpkg annotation of the layer, if any.
+	Annotation string `json:"annotation,omitempty"`
+}
+
+// Config is the runtime configuration of a package image.
+type Config struct {
+	Platform     string            `json:"platform,omitempty"`
+	User         string            `json:"user,omitempty"`
+	WorkingDir   string            `json:"workingDir,omitempty"`
+	Entrypoint   []string          `json:"entrypoint,omitempty"`
+	Cmd          []string          `json:"cmd,omitempty"`
+	Env          []string          `json:"env,omitempty"`
+	ExposedPorts []string          `json:"exposedPorts,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+}
+
+// Inspector inspects package images.
+type Inspector struct {
+	m *mxpkg.Marshaler
+}
+
+// NewInspector returns a new Inspector.
+func NewInspector() (*Inspector, error) {
+	m, err := mxpkg.NewMarshaler()
+	if err != nil {
+		return nil, err
+	}
+	return &Inspector{m: m}, nil
+}
+
+// Parse parses the package contained in the supplied image. Only the package
+// layer is read if the image has one, otherwise the contents of the package
+// are read from the flattened image filesystem.
+func (i *Inspector) Parse(img v1.Image) (*mxpkg.ParsedPackage, error) {
+	layers, err := Layers(img)
+	if err != nil {
+		return nil, err
+	}
+	src := img
+	found := false
+	for _, l := range layers {
+		if l.Annotation != xpkg.PackageAnnotation {
+			continue
+		}
+		if found {
+			return nil, errors.New(errMultipleAnnotatedLayers)
+		}
+		found = true
+		h, err := v1.NewHash(l.Digest)
+		if err != nil {
+			return nil, errors.Wrap(err, errFetchLayer)
+		}
+		layer, err := img.LayerByDigest(h)
+		if err != nil {
+			return nil, errors.Wrap(err, errFetchLayer)
+		}
+		if src, err = mutate.AppendLayers(empty.Image, layer); err != nil {
+			return nil, errors.Wrap(err, errFetchLayer)
+		}
+	}
+	pkg, err := i.m.FromImage(xpkg.Image{Image: src})
+	return pkg, errors.Wrap(err, errParsePackage)
+}
+
+// Inspect returns a summary of the package contained in the supplied image.
+func (i *Inspector) Inspect(img v1.Image) (*Summary, error) {
+	pkg, err := i.Parse(img)
+	if err != nil {
+		return nil, err
+	}
+	d, err := img.Digest()
+	if err != nil {
+		return nil, errors.Wrap(err, errGetDigest)
+	}
+	layers, err := Layers(img)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return nil, errors.Wrap(err, errGetConfig)
+	}
+
+	s := &Summary{
+		Digest:       d.String(),
+		Meta:         meta(pkg.Meta()),
+		Dependencies: make([]Dependency, 0, len(pkg.Dependencies())),
+		Objects:      Objects(pkg.Objects()),
+		Layers:       layers,
+		Config:       config(cfg),
+	}
+	if p, ok := scheme.TryConvertToPkg(pkg.Meta(), &pkgmetav1.Provider{}, &pkgmetav1.Configuration{}); ok {
+		if c := p.GetCrossplaneConstraints(); c != nil {
+			s.Crossplane = c.Version
+		}
+	}
+	for _, d := range pkg.Dependencies() {
+		s.Dependencies = append(s.Dependencies, Dependency{
+			Package:     d.Package,
+			Type:        string(d.Type),
+			Constraints: d.Constraints,
+		})
+	}
+	for _, l := range layers {
+		if l.Annotation == xpkg.ExamplesAnnotation {
+			s.Examples = true
+		}
+	}
+	return s, nil
+}
+
+// Layers returns the layers of the supplied image. Layers of packages that
+// have been pushed carry their xpkg annotation in the manifest, while those of
+// packages that have only been built carry it as a label in the image config,
+// so both are considered.
+func Layers(img v1.Image) ([]Layer, error) {
+	m, err := img.Manifest()
+	if err != nil {
+		return nil, errors.Wrap(err, errGetManifest)
+	}
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return nil, errors.Wrap(err, errGetConfig)
+	}
+	out := make([]Layer, len(m.Layers))
+	for i, l := range m.Layers {
+		a, ok := l.Annotations[xpkg.AnnotationKey]
+		if !ok {
+			a = cfg.Config.Labels[xpkg.Label(l.Digest.String())]
+		}
+		out[i] = Layer{
+			Digest:     l.Digest.String(),
+			MediaType:  string(l.MediaType),
+			Size:       l.Size,
+			Annotation: a,
+		}
+	}
+	return out, nil
+}
+
+// Objects groups the supplied objects by their GroupVersionKind. Groups are
+// sorted by kind and API version, and names within a group alphabetically.
+func Objects(objs []runtime.Object) []ObjectGroup {
+	groups := map[string]*ObjectGroup{}
+	for _, o := range objs {
+		gvk := o.GetObjectKind().GroupVersionKind()
+		k := gvk.String()
+		g, ok := groups[k]
+		if !ok {
+			g = &ObjectGroup{APIVersion: gvk.GroupVersion().String(), Kind: gvk.Kind, Names: []string{}}
+			groups[k] = g
+		}
+		g.Count++
+		if m, ok := o.(metav1.Object); ok {
+			g.Names = append(g.Names, m.GetName())
+		}
+	}
+	out := make([]ObjectGroup, 0, len(groups))
+	for _, g := range groups {
+		sort.Strings(g.Names)
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Kind != out[j].Kind {
+			return out[i].Kind < out[j].Kind
+		}
+		return out[i].APIVersion < out[j].APIVersion
+	})
+	return out
+}
+
+func meta(o runtime.Object) Meta {
+	gvk := o.GetObjectKind().GroupVersionKind()
+	m := Meta{APIVersion: gvk.GroupVersion().String(), Kind: gvk.Kind}
+	if mo, ok := o.(metav1.Object); ok {
+		m.Name = mo.GetName()
+	}
+	return m
+}
+
+func config(cfg *v1.ConfigFile) Config {
+	c := Config{
+		User:       cfg.Config.User,
+		WorkingDir: cfg.Config.WorkingDir,
+		Entrypoint: cfg.Config.Entrypoint,
+		Cmd:        cfg.Config.Cmd,
+		Env:        cfg.Config.Env,
+	}
+	if p := cfg.Platform(); p != nil && p.OS != "" {
+		c.Platform = p.String()
+	}
+	for p := range cfg.Config.ExposedPorts {
+		c.ExposedPorts = append(c.ExposedPorts, p)
+	}
+	sort.Strings(c.ExposedPorts)
+	// NOTE: xpkg layer annotations are stored as labels until the package is
+	// pushed. They are reported with the layers rather than here.
+	for k, v := range cfg.Config.Labels {
+		if strings.HasPrefix(k, xpkg.AnnotationKey+":") {
+			continue
+		}
+		if c.Labels == nil {
+			c.Labels = map[string]string{}
+		}
+		c.Labels[k] = v
+	}
+	return c
+}
@@ -0,0 +1,164 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inspect
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+
+	"github.com/upbound/up/internal/xpkg"
+)
+
+// packageImage builds a package image from the supplied package stream the
+// way the build command does, optionally with an examples layer.
+func packageImage(t *testing.T, path string, examples bool) v1.Image {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := v1.Config{
+		Labels:     map[string]string{"org.opencontainers.image.source": "github.com/upbound/platform-ref-aws"},
+		Entrypoint: []string{"/provider"},
+	}
+	layers := []v1.Layer{}
+	l, err := xpkg.Layer(bytes.NewReader(b), xpkg.StreamFile, xpkg.PackageAnnotation, int64(len(b)), xpkg.StreamFileMode, &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layers = append(layers, l)
+	if examples {
+		ex := []byte("apiVersion: v1\nkind: ConfigMap\n")
+		l, err := xpkg.Layer(bytes.NewReader(ex), xpkg.XpkgExamplesFile, xpkg.ExamplesAnnotation, int64(len(ex)), xpkg.StreamFileMode, &cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		layers = append(layers, l)
+	}
+	img, err := mutate.AppendLayers(empty.Image, layers...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err = mutate.Config(img, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestInspect(t *testing.T) {
+	type want struct {
+		summary *Summary
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason   string
+		examples bool
+		want     want
+	}{
+		"Configuration": {
+			reason: "The summary of a configuration should include its meta, dependencies and objects.",
+			want: want{
+				summary: &Summary{
+					Meta: Meta{
+						APIVersion: "meta.pkg.crossplane.io/v1alpha1",
+						Kind:       "Configuration",
+						Name:       "platform-ref-aws",
+					},
+					Crossplane: ">=v1.0.0-0",
+					Dependencies: []Dependency{
+						{Package: "registry.upbound.io/crossplane/provider-aws", Type: "Provider", Constraints: ">=v0.19.0-0"},
+						{Package: "registry.upbound.io/crossplane/provider-helm", Type: "Provider", Constraints: ">=v0.3.6-0"},
+					},
+					Objects: []ObjectGroup{{
+						APIVersion: "apiextensions.crossplane.io/v1",
+						Kind:       "CompositeResourceDefinition",
+						Count:      2,
+						Names:      []string{"compositeclusters.aws.platformref.crossplane.io", "eks.aws.platformref.crossplane.io"},
+					}},
+					Config: Config{
+						Entrypoint: []string{"/provider"},
+						Labels:     map[string]string{"org.opencontainers.image.source": "github.com/upbound/platform-ref-aws"},
+					},
+				},
+			},
+		},
+		"Examples": {
+			reason:   "The summary of a package with an examples layer should report it.",
+			examples: true,
+			want: want{
+				summary: &Summary{
+					Meta: Meta{
+						APIVersion: "meta.pkg.crossplane.io/v1alpha1",
+						Kind:       "Configuration",
+						Name:       "platform-ref-aws",
+					},
+					Crossplane: ">=v1.0.0-0",
+					Dependencies: []Dependency{
+						{Package: "registry.upbound.io/crossplane/provider-aws", Type: "Provider", Constraints: ">=v0.19.0-0"},
+						{Package: "registry.upbound.io/crossplane/provider-helm", Type: "Provider", Constraints: ">=v0.3.6-0"},
+					},
+					Objects: []ObjectGroup{{
+						APIVersion: "apiextensions.crossplane.io/v1",
+						Kind:       "CompositeResourceDefinition",
+						Count:      2,
+						Names:      []string{"compositeclusters.aws.platformref.crossplane.io", "eks.aws.platformref.crossplane.io"},
+					}},
+					Examples: true,
+					Config: Config{
+						Entrypoint: []string{"/provider"},
+						Labels:     map[string]string{"org.opencontainers.image.source": "github.com/upbound/platform-ref-aws"},
+					},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			img := packageImage(t, "../testdata/config_package.yaml", tc.examples)
+			i, err := NewInspector()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := i.Inspect(img)
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nInspect(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.summary, got, cmpopts.IgnoreFields(Summary{}, "Digest", "Layers")); diff != "" {
+				t.Errorf("\n%s\nInspect(...): -want, +got:\n%s", tc.reason, diff)
+			}
+
+			layers, _ := img.Layers()
+			if diff := cmp.Diff(len(layers), len(got.Layers)); diff != "" {
+				t.Errorf("\n%s\nInspect(...): -want layers, +got layers:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(xpkg.PackageAnnotation, got.Layers[0].Annotation); diff != "" {
+				t.Errorf("\n%s\nInspect(...): -want annotation, +got annotation:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
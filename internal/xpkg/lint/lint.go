@@ -0,0 +1,266 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lint reports the validations of a package workspace snapshot as
+// findings that can be filtered per rule and written as text, JSON or SARIF.
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+
+	"github.com/upbound/up/internal/xpkg/snapshot/validator"
+)
+
+// Severities of findings.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+const (
+	errUnknownRuleFmt = "unknown rule %q"
+	errEnableDisable  = "rules cannot be both enabled and disabled"
+)
+
+// A Rule is a check that is performed on the files of a package.
+type Rule struct {
+	ID          string `json:"id"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+}
+
+var rules = []Rule{
+	{
+		ID:          validator.RuleObjectSchema,
+		Severity:    SeverityError,
+		Description: "Objects must match the schema of their CRD or XRD.",
+	},
+	{
+		ID:          validator.RuleExampleSchema,
+		Severity:    SeverityError,
+		Description: "Examples must match the schema of their CRD or XRD.",
+	},
+	{
+		ID:          validator.RuleDefinitionNotFound,
+		Severity:    SeverityWarning,
+		Description: "A CRD or XRD must be known for the kind of every object.",
+	},
+	{
+		ID:          validator.RuleXRDSchema,
+		Severity:    SeverityError,
+		Description: "XRD schemas must be valid structural schemas.",
+	},
+	{
+		ID:          validator.RuleCompositionResources,
+		Severity:    SeverityError,
+		Description: "Composition resources must be composable.",
+	},
+	{
+		ID:          validator.RuleCompositionPatches,
+		Severity:    SeverityError,
+		Description: "Composed resources must match the schema of their CRD once patches are applied.",
+	},
+	{
+		ID:          validator.RuleDeprecatedAPIVersion,
+		Severity:    SeverityWarning,
+		Description: "Meta files should not use a deprecated apiVersion.",
+	},
+	{
+		ID:          validator.RuleDependencyType,
+		Severity:    SeverityError,
+		Description: "Dependencies must be declared with the type of the package they refer to.",
+	},
+	{
+		ID:          validator.RuleDependencyVersion,
+		Severity:    SeverityError,
+		Description: "A version matching the constraint of every dependency must exist.",
+	},
+	{
+		ID:          validator.RuleDependencyConflict,
+		Severity:    SeverityError,
+		Description: "The constraints on a package in the dependency graph must not conflict.",
+	},
+	{
+		ID:          validator.RuleDependencyLocked,
+		Severity:    SeverityError,
+		Description: "Dependencies must be pinned in the lock file when resolution is frozen.",
+	},
+}
+
+// Rules returns the catalog of rules.
+func Rules() []Rule {
+	out := make([]Rule, len(rules))
+	copy(out, rules)
+	return out
+}
+
+// rule returns the rule with the supplied ID.
+func rule(id string) (Rule, bool) {
+	for _, r := range rules {
+		if r.ID == id {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// A Finding is a violation of a rule in a file. Lines and columns start at 1.
+type Finding struct {
+	Rule      string `json:"rule"`
+	Severity  string `json:"severity"`
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndColumn int    `json:"endColumn"`
+	Message   string `json:"message"`
+}
+
+// Findings converts the supplied diagnostics into findings, sorted by file
+// and position. File paths are relative to root.
+func Findings(root string, diags map[span.URI][]protocol.Diagnostic) []Finding {
+	out := []Finding{}
+	for uri, ds := range diags {
+		file := uri.Filename()
+		if rel, err := filepath.Rel(root, file); err == nil {
+			file = rel
+		}
+		for _, d := range ds {
+			f := Finding{
+				File:      filepath.ToSlash(file),
+				Line:      int(d.Range.Start.Line) + 1,
+				Column:    int(d.Range.Start.Character) + 1,
+				EndColumn: int(d.Range.End.Character) + 1,
+				Message:   d.Message,
+			}
+			if id, ok := d.Code.(string); ok {
+				f.Rule = id
+			}
+			f.Severity = severity(d.Severity, f.Rule)
+			out = append(out, f)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return a.Rule < b.Rule
+	})
+	return out
+}
+
+// severity returns the severity of a diagnostic, falling back to the default
+// severity of its rule if the diagnostic has none.
+func severity(s protocol.DiagnosticSeverity, id string) string {
+	switch s { // nolint:exhaustive
+	case protocol.SeverityError:
+		return SeverityError
+	case protocol.SeverityWarning, protocol.SeverityInformation, protocol.SeverityHint:
+		return SeverityWarning
+	}
+	if r, ok := rule(id); ok {
+		return r.Severity
+	}
+	return SeverityError
+}
+
+// Filter returns the findings of enabled rules. If enable is empty all rules
+// but the disabled ones are enabled.
+func Filter(findings []Finding, enable, disable []string) ([]Finding, error) {
+	enabled := map[string]bool{}
+	for _, id := range enable {
+		if _, ok := rule(id); !ok {
+			return nil, errors.Errorf(errUnknownRuleFmt, id)
+		}
+		enabled[id] = true
+	}
+	disabled := map[string]bool{}
+	for _, id := range disable {
+		if _, ok := rule(id); !ok {
+			return nil, errors.Errorf(errUnknownRuleFmt, id)
+		}
+		if enabled[id] {
+			return nil, errors.New(errEnableDisable)
+		}
+		disabled[id] = true
+	}
+
+	out := []Finding{}
+	for _, f := range findings {
+		if disabled[f.Rule] || (len(enabled) > 0 && !enabled[f.Rule]) {
+			continue
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// Warn returns the findings with the severity of findings of the supplied
+// rules lowered to warning.
+func Warn(findings []Finding, ids ...string) []Finding {
+	warn := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		warn[id] = true
+	}
+	out := make([]Finding, len(findings))
+	for i, f := range findings {
+		if warn[f.Rule] {
+			f.Severity = SeverityWarning
+		}
+		out[i] = f
+	}
+	return out
+}
+
+// Errors returns the number of findings with error severity.
+func Errors(findings []Finding) int {
+	n := 0
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteText writes the findings in a compiler-like format, one per line.
+func WriteText(w io.Writer, findings []Finding) error {
+	for _, f := range findings {
+		if _, err := fmt.Fprintf(w, "%s:%d:%d: %s: %s [%s]\n", f.File, f.Line, f.Column, f.Severity, f.Message, f.Rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the findings as a JSON array.
+func WriteJSON(w io.Writer, findings []Finding) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(findings)
+}
@@ -0,0 +1,249 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/xpkg/snapshot/validator"
+)
+
+func diagnostic(line, start, end uint32, sev protocol.DiagnosticSeverity, rule, msg string) protocol.Diagnostic {
+	d := protocol.Diagnostic{
+		Range: protocol.Range{
+			Start: protocol.Position{Line: line, Character: start},
+			End:   protocol.Position{Line: line, Character: end},
+		},
+		Severity: sev,
+		Message:  msg,
+	}
+	if rule != "" {
+		d.Code = rule
+	}
+	return d
+}
+
+func TestFindings(t *testing.T) {
+	type args struct {
+		root  string
+		diags map[span.URI][]protocol.Diagnostic
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   []Finding
+	}{
+		"NoDiagnostics": {
+			reason: "No findings should be returned if there are no diagnostics.",
+			args: args{
+				root:  "/ws",
+				diags: map[span.URI][]protocol.Diagnostic{span.URIFromPath("/ws/xr.yaml"): {}},
+			},
+			want: []Finding{},
+		},
+		"Sorted": {
+			reason: "Findings should be relative to the root, start at line and column 1, and be sorted by file and position.",
+			args: args{
+				root: "/ws",
+				diags: map[span.URI][]protocol.Diagnostic{
+					span.URIFromPath("/ws/b/xr.yaml"): {
+						diagnostic(7, 8, 12, protocol.SeverityWarning, validator.RuleDefinitionNotFound, "no definition"),
+						diagnostic(5, 8, 11, protocol.SeverityError, validator.RuleObjectSchema, "invalid"),
+					},
+					span.URIFromPath("/ws/a.yaml"): {
+						diagnostic(0, 12, 40, protocol.SeverityWarning, validator.RuleDeprecatedAPIVersion, "deprecated"),
+					},
+				},
+			},
+			want: []Finding{
+				{Rule: validator.RuleDeprecatedAPIVersion, Severity: SeverityWarning, File: "a.yaml", Line: 1, Column: 13, EndColumn: 41, Message: "deprecated"},
+				{Rule: validator.RuleObjectSchema, Severity: SeverityError, File: "b/xr.yaml", Line: 6, Column: 9, EndColumn: 12, Message: "invalid"},
+				{Rule: validator.RuleDefinitionNotFound, Severity: SeverityWarning, File: "b/xr.yaml", Line: 8, Column: 9, EndColumn: 13, Message: "no definition"},
+			},
+		},
+		"DefaultSeverity": {
+			reason: "Diagnostics without severity should take the default severity of their rule.",
+			args: args{
+				root: "/ws",
+				diags: map[span.URI][]protocol.Diagnostic{
+					span.URIFromPath("/ws/crossplane.yaml"): {
+						diagnostic(9, 4, 10, 0, validator.RuleDependencyVersion, "no version"),
+						diagnostic(10, 4, 10, 0, "", "unknown"),
+					},
+				},
+			},
+			want: []Finding{
+				{Rule: validator.RuleDependencyVersion, Severity: SeverityError, File: "crossplane.yaml", Line: 10, Column: 5, EndColumn: 11, Message: "no version"},
+				{Severity: SeverityError, File: "crossplane.yaml", Line: 11, Column: 5, EndColumn: 11, Message: "unknown"},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Findings(tc.args.root, tc.args.diags)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nFindings(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	findings := []Finding{
+		{Rule: validator.RuleObjectSchema, Severity: SeverityError},
+		{Rule: validator.RuleDefinitionNotFound, Severity: SeverityWarning},
+		{Rule: validator.RuleXRDSchema, Severity: SeverityError},
+	}
+	type args struct {
+		enable  []string
+		disable []string
+	}
+	type want struct {
+		findings []Finding
+		err      error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"All": {
+			reason: "All findings should be returned if no rules are selected.",
+			want:   want{findings: findings},
+		},
+		"Enable": {
+			reason: "Only findings of enabled rules should be returned.",
+			args:   args{enable: []string{validator.RuleObjectSchema, validator.RuleXRDSchema}},
+			want:   want{findings: []Finding{findings[0], findings[2]}},
+		},
+		"Disable": {
+			reason: "Findings of disabled rules should be dropped.",
+			args:   args{disable: []string{validator.RuleDefinitionNotFound}},
+			want:   want{findings: []Finding{findings[0], findings[2]}},
+		},
+		"UnknownRule": {
+			reason: "Selecting an unknown rule should return an error.",
+			args:   args{disable: []string{"unknown"}},
+			want:   want{err: errors.Errorf(errUnknownRuleFmt, "unknown")},
+		},
+		"EnabledAndDisabled": {
+			reason: "A rule should not be both enabled and disabled.",
+			args: args{
+				enable:  []string{validator.RuleObjectSchema},
+				disable: []string{validator.RuleObjectSchema},
+			},
+			want: want{err: errors.New(errEnableDisable)},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Filter(findings, tc.args.enable, tc.args.disable)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nFilter(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.findings, got); diff != "" {
+				t.Errorf("\n%s\nFilter(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	idx := 0
+	cases := map[string]struct {
+		reason   string
+		findings []Finding
+		want     []sarifResult
+	}{
+		"NoFindings": {
+			reason: "A log without results should be written if there are no findings.",
+			want:   []sarifResult{},
+		},
+		"Findings": {
+			reason: "Findings should be written as results referring to the rule catalog.",
+			findings: []Finding{
+				{Rule: validator.RuleObjectSchema, Severity: SeverityError, File: "b/xr.yaml", Line: 6, Column: 9, EndColumn: 12, Message: "invalid"},
+				{Severity: SeverityError, File: "a.yaml", Line: 1, Column: 1, EndColumn: 1, Message: "unknown"},
+			},
+			want: []sarifResult{
+				{
+					RuleID:    validator.RuleObjectSchema,
+					RuleIndex: &idx,
+					Level:     SeverityError,
+					Message:   sarifMessage{Text: "invalid"},
+					Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: "b/xr.yaml", URIBaseID: sarifSrcRoot},
+						Region:           sarifRegion{StartLine: 6, StartColumn: 9, EndColumn: 12},
+					}}},
+				},
+				{
+					Level:   SeverityError,
+					Message: sarifMessage{Text: "unknown"},
+					Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: "a.yaml", URIBaseID: sarifSrcRoot},
+						Region:           sarifRegion{StartLine: 1, StartColumn: 1},
+					}}},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if err := WriteSARIF(b, tc.findings, "v0.1.0"); err != nil {
+				t.Fatalf("\n%s\nWriteSARIF(...): unexpected error: %s", tc.reason, err)
+			}
+			got := sarifLog{}
+			if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+				t.Fatalf("\n%s\nWriteSARIF(...): invalid JSON: %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(sarifVersion, got.Version); diff != "" {
+				t.Errorf("\n%s\nWriteSARIF(...): -want version, +got version:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(len(Rules()), len(got.Runs[0].Tool.Driver.Rules)); diff != "" {
+				t.Errorf("\n%s\nWriteSARIF(...): -want rules, +got rules:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want, got.Runs[0].Results); diff != "" {
+				t.Errorf("\n%s\nWriteSARIF(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWarn(t *testing.T) {
+	findings := []Finding{
+		{Rule: validator.RuleObjectSchema, Severity: SeverityError},
+		{Rule: validator.RuleExampleSchema, Severity: SeverityError},
+	}
+	want := []Finding{
+		{Rule: validator.RuleObjectSchema, Severity: SeverityError},
+		{Rule: validator.RuleExampleSchema, Severity: SeverityWarning},
+	}
+	got := Warn(findings, validator.RuleExampleSchema)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("\nFindings of the supplied rules should be lowered to warnings.\nWarn(...): -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(SeverityError, findings[1].Severity); diff != "" {
+		t.Errorf("\nThe supplied findings should not be modified.\nWarn(...): -want, +got:\n%s", diff)
+	}
+}
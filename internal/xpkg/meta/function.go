@@ -0,0 +1,214 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package meta
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	metav1beta1 "github.com/crossplane/crossplane/apis/pkg/meta/v1beta1"
+
+	"github.com/upbound/up/internal/xpkg"
+)
+
+const (
+	// FunctionInputVersion is the version of the input type scaffolded for
+	// new Function packages.
+	FunctionInputVersion = "v1beta1"
+	// FunctionInputKind is the kind of the input type scaffolded for new
+	// Function packages.
+	FunctionInputKind = "Input"
+
+	functionInputGroupSuffix = ".fn.crossplane.io"
+	functionNamePrefix       = "function-"
+)
+
+// NewFunctionXPkg returns a slice of bytes containing a fully rendered
+// Function template given the provided InitContext.
+func NewFunctionXPkg(c xpkg.InitContext) ([]byte, error) {
+	// name is required
+	if c.Name == "" {
+		return nil, errors.New(errXPkgNameNotProvided)
+	}
+
+	f := metav1beta1.Function{
+		TypeMeta: v1.TypeMeta{
+			APIVersion: metav1beta1.SchemeGroupVersion.String(),
+			Kind:       metav1beta1.FunctionKind,
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name: c.Name,
+		},
+	}
+
+	for _, d := range c.DependsOn {
+		f.Spec.DependsOn = append(f.Spec.DependsOn, metav1beta1.Dependency{
+			Provider:      d.Provider,
+			Configuration: d.Configuration,
+			Function:      d.Function,
+			Version:       d.Version,
+		})
+	}
+
+	if c.XPVersion != "" {
+		f.Spec.Crossplane = &metav1beta1.CrossplaneConstraints{Version: c.XPVersion}
+	}
+
+	b, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return cleanNullTs(b)
+}
+
+// FunctionInputGroup returns the API group of the input type scaffolded for
+// the Function with the supplied name, e.g. patch.fn.crossplane.io for
+// function-patch.
+func FunctionInputGroup(name string) string {
+	return strings.TrimPrefix(strings.ToLower(name), functionNamePrefix) + functionInputGroupSuffix
+}
+
+// NewFunctionInputCRD returns a slice of bytes containing a
+// CustomResourceDefinition for the input of the Function described by the
+// provided InitContext.
+func NewFunctionInputCRD(c xpkg.InitContext) ([]byte, error) {
+	// name is required
+	if c.Name == "" {
+		return nil, errors.New(errXPkgNameNotProvided)
+	}
+
+	group := FunctionInputGroup(c.Name)
+	plural := strings.ToLower(FunctionInputKind) + "s"
+
+	crd := extv1.CustomResourceDefinition{
+		TypeMeta: v1.TypeMeta{
+			APIVersion: extv1.SchemeGroupVersion.String(),
+			Kind:       "CustomResourceDefinition",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name: fmt.Sprintf("%s.%s", plural, group),
+		},
+		Spec: extv1.CustomResourceDefinitionSpec{
+			Group: group,
+			Names: extv1.CustomResourceDefinitionNames{
+				Kind:       FunctionInputKind,
+				ListKind:   FunctionInputKind + "List",
+				Plural:     plural,
+				Singular:   strings.ToLower(FunctionInputKind),
+				Categories: []string{"crossplane"},
+			},
+			Scope: extv1.NamespaceScoped,
+			Versions: []extv1.CustomResourceDefinitionVersion{
+				{
+					Name:    FunctionInputVersion,
+					Served:  true,
+					Storage: true,
+					Schema: &extv1.CustomResourceValidation{
+						OpenAPIV3Schema: &extv1.JSONSchemaProps{
+							Description: fmt.Sprintf("%s can be used to provide input to %s.", FunctionInputKind, c.Name),
+							Type:        "object",
+							Properties: map[string]extv1.JSONSchemaProps{
+								"apiVersion": {Type: "string"},
+								"kind":       {Type: "string"},
+								"metadata":   {Type: "object"},
+								"example": {
+									Description: "Example is an example field. Replace it with the input your function needs.",
+									Type:        "string",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	b, err := json.Marshal(crd)
+	if err != nil {
+		return nil, err
+	}
+	b, err = withoutStatus(b)
+	if err != nil {
+		return nil, err
+	}
+	return cleanNullTs(b)
+}
+
+// NewFunctionExample returns a slice of bytes containing an example
+// Composition that runs the Function described by the provided InitContext in
+// its pipeline.
+func NewFunctionExample(c xpkg.InitContext) ([]byte, error) {
+	// name is required
+	if c.Name == "" {
+		return nil, errors.New(errXPkgNameNotProvided)
+	}
+
+	input, err := json.Marshal(map[string]string{
+		"apiVersion": fmt.Sprintf("%s/%s", FunctionInputGroup(c.Name), FunctionInputVersion),
+		"kind":       FunctionInputKind,
+		"example":    "Hello world",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mode := xpextv1.CompositionModePipeline
+	comp := xpextv1.Composition{
+		TypeMeta: v1.TypeMeta{
+			APIVersion: xpextv1.SchemeGroupVersion.String(),
+			Kind:       xpextv1.CompositionKind,
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name: c.Name + "-example",
+		},
+		Spec: xpextv1.CompositionSpec{
+			CompositeTypeRef: xpextv1.TypeReference{
+				APIVersion: "example.crossplane.io/v1",
+				Kind:       "XR",
+			},
+			Mode: &mode,
+			Pipeline: []xpextv1.PipelineStep{
+				{
+					Step:        "run-" + c.Name,
+					FunctionRef: xpextv1.FunctionReference{Name: c.Name},
+					Input:       &runtime.RawExtension{Raw: input},
+				},
+			},
+		},
+	}
+
+	b, err := json.Marshal(comp)
+	if err != nil {
+		return nil, err
+	}
+	return cleanNullTs(b)
+}
+
+// withoutStatus removes the status from the supplied marshaled object.
+func withoutStatus(b []byte) ([]byte, error) {
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	delete(m, "status")
+	return json.Marshal(m)
+}
@@ -0,0 +1,121 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package meta
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	v1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/xpkg"
+)
+
+func TestFunctionTemplate(t *testing.T) {
+	providerAws := "crossplane/provider-aws"
+
+	cases := map[string]struct {
+		reason string
+		ctx    xpkg.InitContext
+		want   []byte
+		err    error
+	}{
+		"NameProvided": {
+			reason: "We should return a Function with just name filled in.",
+			ctx: xpkg.InitContext{
+				Name: "function-test",
+			},
+			want: []byte(`apiVersion: meta.pkg.crossplane.io/v1beta1
+kind: Function
+metadata:
+  name: function-test
+spec: {}
+`),
+		},
+		"NameNotProvided": {
+			reason: "We should return an error if name not provided.",
+			ctx:    xpkg.InitContext{},
+			err:    errors.New(errXPkgNameNotProvided),
+		},
+		"DependsOnAndCrossplaneVersionProvided": {
+			reason: "We should return a Function with crossplane version and dependsOn filled in.",
+			ctx: xpkg.InitContext{
+				Name:      "function-test",
+				XPVersion: ">=v1.14.0",
+				DependsOn: []v1.Dependency{
+					{
+						Provider: &providerAws,
+						Version:  ">=v0.14.0",
+					},
+				},
+			},
+			want: []byte(`apiVersion: meta.pkg.crossplane.io/v1beta1
+kind: Function
+metadata:
+  name: function-test
+spec:
+  crossplane:
+    version: '>=v1.14.0'
+  dependsOn:
+  - provider: crossplane/provider-aws
+    version: '>=v0.14.0'
+`),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewFunctionXPkg(tc.ctx)
+
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nNewFunctionXPkg(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nNewFunctionXPkg(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFunctionInputGroup(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		name   string
+		want   string
+	}{
+		"Prefixed": {
+			reason: "We should strip the function- prefix from the name.",
+			name:   "function-patch-and-transform",
+			want:   "patch-and-transform.fn.crossplane.io",
+		},
+		"NotPrefixed": {
+			reason: "We should use the name as is if it is not prefixed.",
+			name:   "Auto-Ready",
+			want:   "auto-ready.fn.crossplane.io",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := FunctionInputGroup(tc.name)
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nFunctionInputGroup(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
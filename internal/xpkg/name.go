@@ -28,6 +28,10 @@ const (
 	// MetaFile is the name of a Crossplane package metadata file.
 	MetaFile string = "crossplane.yaml"
 
+	// LockFile is the name of the file that pins the resolved dependencies
+	// of a Crossplane package.
+	LockFile string = "crossplane.lock"
+
 	// StreamFile is the name of the file in a Crossplane package image that
 	// contains its YAML stream.
 	StreamFile string = "package.yaml"
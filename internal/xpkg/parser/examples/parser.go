@@ -30,6 +30,11 @@ type Examples struct {
 	objects []unstructured.Unstructured
 }
 
+// Objects returns the objects of the examples.
+func (e *Examples) Objects() []unstructured.Unstructured {
+	return e.objects
+}
+
 // Parser is a Parser implementation for parsing examples.
 type Parser struct {
 	objScheme parser.ObjectCreaterTyper
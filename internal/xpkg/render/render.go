@@ -0,0 +1,308 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package render renders the resources a composite resource or claim composes
+// with a Patch and Transform Composition, without a control plane.
+package render
+
+import (
+	"context"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	icomposite "github.com/crossplane/crossplane/controller/apiextensions/composite"
+	icompositions "github.com/crossplane/crossplane/controller/apiextensions/compositions"
+	"github.com/crossplane/crossplane/xcrd"
+
+	"github.com/upbound/up/internal/xpkg/snapshot"
+	"github.com/upbound/up/internal/xpkg/snapshot/validator"
+)
+
+const (
+	errNoDefinitionFmt  = "no XRD defines %s"
+	errNoCompositionFmt = "no composition is compatible with %s"
+	errNotFoundFmt      = "composition %q not found"
+	errIncompatibleFmt  = "composition %q is not compatible with %s"
+	errAmbiguousFmt     = "%d compositions are compatible with %s"
+	errSelectorFmt      = "invalid composition selector of %s"
+	errNoSelectedFmt    = "no composition compatible with %s matches its composition selector"
+	errComposeFmt       = "failed to compose %s"
+	errRenderComposite  = "failed to apply patches to the composite resource"
+)
+
+// claimOnlyFields are the fields of a claim's spec that are not propagated to
+// its composite resource.
+var claimOnlyFields = []string{"resourceRef", "writeConnectionSecretToRef", "compositeDeletePolicy"}
+
+// A Renderer renders composite resources and claims with the Compositions
+// among a set of objects, typically those of a package workspace and its
+// dependencies.
+type Renderer struct {
+	xrds         []*xpextv1.CompositeResourceDefinition
+	compositions []*xpextv1.Composition
+	validator    *snapshot.ExampleValidator
+}
+
+// New returns a Renderer for the XRDs and Compositions among the supplied
+// objects. Composed resources are validated against the schemas of the CRDs
+// among them.
+func New(ctx context.Context, objs ...runtime.Object) *Renderer {
+	r := &Renderer{validator: snapshot.NewExampleValidator(ctx, objs...)}
+	for _, o := range objs {
+		switch t := o.(type) {
+		case *xpextv1.CompositeResourceDefinition:
+			r.xrds = append(r.xrds, t)
+		case *xpextv1.Composition:
+			r.compositions = append(r.compositions, t)
+		}
+	}
+	return r
+}
+
+// Output is the result of rendering a composite resource or claim.
+type Output struct {
+	// Composite is the composite resource after all patches have been
+	// applied. Claims are rendered as their composite resource.
+	Composite *unstructured.Unstructured
+	// Composition is the name of the selected Composition.
+	Composition string
+	// Resources are the composed resources in the order of the templates of
+	// the Composition.
+	Resources []Resource
+}
+
+// A Resource is a composed resource.
+type Resource struct {
+	// Name is the name of the template the resource was rendered from, or
+	// its index if the template is anonymous.
+	Name string
+	// Object is the rendered resource. It is nil if the resource could not
+	// be rendered.
+	Object *unstructured.Unstructured
+	// Err is the error that occurred rendering the resource, if any.
+	Err error
+	// Validations are the fields of the resource that do not match the
+	// schema of its CRD.
+	Validations []*validator.Validation
+}
+
+// Render renders the resources composed by the supplied composite resource or
+// claim. The Composition is selected the same way Crossplane does: an enforced
+// Composition of the XRD takes precedence over the composition reference and
+// selector of the resource, which take precedence over the default Composition
+// of the XRD. If none of them is set, the only compatible Composition is used.
+//
+// Patches from the composite resource are applied to the composed resources
+// and patches to the composite resource are applied back. If the latter change
+// the composite resource, the resources are composed once more so that values
+// patched between resources through the composite resource are rendered.
+// Values only known once a resource is observed, e.g. status fields, cannot be
+// rendered.
+func (r *Renderer) Render(ctx context.Context, in *unstructured.Unstructured) (*Output, error) {
+	xrd, xr, err := r.composite(in)
+	if err != nil {
+		return nil, err
+	}
+	comp, err := r.selectComposition(xrd, xr)
+	if err != nil {
+		return nil, err
+	}
+	xr.SetCompositionReference(&corev1.ObjectReference{Name: comp.GetName()})
+
+	// NOTE: converting to a revision and back sets the defaults of the
+	// Composition, as the API server would.
+	defaulted := icomposite.AsComposition(icompositions.NewCompositionRevision(comp, 1))
+	if err := icomposite.NewAPINamingConfigurator().Configure(ctx, xr, defaulted); err != nil {
+		return nil, err
+	}
+
+	composer := icomposite.NewPTComposer()
+	req := icomposite.CompositionRequest{Composition: defaulted}
+	cds, err := composer.Compose(ctx, xr, req)
+	if err != nil {
+		return nil, errors.Wrapf(err, errComposeFmt, describe(xr.GetObjectKind().GroupVersionKind(), xr.GetName()))
+	}
+	before := xr.GetUnstructured().DeepCopy()
+	toXR := make([]error, len(cds))
+	for i, cd := range cds {
+		if cd.TemplateRenderErr != nil {
+			continue
+		}
+		if err := icomposite.RenderComposite(ctx, xr, cd.Resource, *cd.Template, nil); err != nil {
+			toXR[i] = errors.Wrap(err, errRenderComposite)
+		}
+	}
+	if !reflect.DeepEqual(before.Object, xr.Object) {
+		if cds, err = composer.Compose(ctx, xr, req); err != nil {
+			return nil, errors.Wrapf(err, errComposeFmt, describe(xr.GetObjectKind().GroupVersionKind(), xr.GetName()))
+		}
+	}
+
+	out := &Output{
+		Composite:   xr.GetUnstructured(),
+		Composition: comp.GetName(),
+		Resources:   make([]Resource, len(cds)),
+	}
+	for i, cd := range cds {
+		res := Resource{Name: string(cd.ResourceName), Err: cd.TemplateRenderErr}
+		if res.Err == nil {
+			res.Err = toXR[i]
+		}
+		if cd.TemplateRenderErr == nil {
+			res.Object = cd.Resource.(*composed.Unstructured).GetUnstructured()
+			res.Validations = r.validator.Validate(ctx, res.Object)
+		}
+		out.Resources[i] = res
+	}
+	return out, nil
+}
+
+// composite returns the composite resource for the supplied composite resource
+// or claim, along with its XRD.
+func (r *Renderer) composite(in *unstructured.Unstructured) (*xpextv1.CompositeResourceDefinition, *composite.Unstructured, error) {
+	gvk := in.GroupVersionKind()
+	for _, xrd := range r.xrds {
+		if xrd.Spec.Group != gvk.Group || !hasVersion(xrd, gvk.Version) {
+			continue
+		}
+		if xrd.Spec.Names.Kind == gvk.Kind {
+			xr := composite.New(composite.WithGroupVersionKind(gvk))
+			xr.SetUnstructuredContent(in.DeepCopy().Object)
+			return xrd, xr, nil
+		}
+		if xrd.Spec.ClaimNames != nil && xrd.Spec.ClaimNames.Kind == gvk.Kind {
+			return xrd, fromClaim(in, gvk.GroupVersion().WithKind(xrd.Spec.Names.Kind)), nil
+		}
+	}
+	return nil, nil, errors.Errorf(errNoDefinitionFmt, describe(gvk, in.GetName()))
+}
+
+// fromClaim returns the composite resource Crossplane would create for the
+// supplied claim. Unlike Crossplane, the composite resource is named after
+// the claim so that rendering is deterministic.
+func fromClaim(cm *unstructured.Unstructured, gvk schema.GroupVersionKind) *composite.Unstructured {
+	xr := composite.New(composite.WithGroupVersionKind(gvk))
+	xr.SetName(cm.GetName())
+	xr.SetAnnotations(cm.GetAnnotations())
+	xr.SetLabels(cm.GetLabels())
+	meta.AddLabels(xr, map[string]string{
+		xcrd.LabelKeyClaimName:      cm.GetName(),
+		xcrd.LabelKeyClaimNamespace: cm.GetNamespace(),
+	})
+	if spec, ok := cm.DeepCopy().Object["spec"].(map[string]any); ok {
+		for _, f := range claimOnlyFields {
+			delete(spec, f)
+		}
+		xr.Object["spec"] = spec
+	}
+	xr.SetClaimReference(&corev1.ObjectReference{
+		APIVersion: cm.GetAPIVersion(),
+		Kind:       cm.GetKind(),
+		Namespace:  cm.GetNamespace(),
+		Name:       cm.GetName(),
+	})
+	return xr
+}
+
+// selectComposition selects the Composition of the supplied composite
+// resource.
+func (r *Renderer) selectComposition(xrd *xpextv1.CompositeResourceDefinition, xr *composite.Unstructured) (*xpextv1.Composition, error) { // nolint:gocyclo
+	gvk := xr.GetObjectKind().GroupVersionKind()
+	switch {
+	case xrd.Spec.EnforcedCompositionRef != nil:
+		return r.composition(xrd.Spec.EnforcedCompositionRef.Name, gvk)
+	case xr.GetCompositionReference() != nil && xr.GetCompositionReference().Name != "":
+		return r.composition(xr.GetCompositionReference().Name, gvk)
+	case xr.GetCompositionSelector() != nil:
+		sel, err := metav1.LabelSelectorAsSelector(xr.GetCompositionSelector())
+		if err != nil {
+			return nil, errors.Wrapf(err, errSelectorFmt, describe(gvk, xr.GetName()))
+		}
+		for _, c := range r.compatible(gvk) {
+			if sel.Matches(labels.Set(c.GetLabels())) {
+				return c, nil
+			}
+		}
+		return nil, errors.Errorf(errNoSelectedFmt, describe(gvk, xr.GetName()))
+	case xrd.Spec.DefaultCompositionRef != nil:
+		return r.composition(xrd.Spec.DefaultCompositionRef.Name, gvk)
+	}
+	comps := r.compatible(gvk)
+	switch len(comps) {
+	case 0:
+		return nil, errors.Errorf(errNoCompositionFmt, describe(gvk, xr.GetName()))
+	case 1:
+		return comps[0], nil
+	}
+	return nil, errors.Errorf(errAmbiguousFmt, len(comps), describe(gvk, xr.GetName()))
+}
+
+// composition returns the named Composition if it is compatible with the
+// supplied kind of composite resource.
+func (r *Renderer) composition(name string, gvk schema.GroupVersionKind) (*xpextv1.Composition, error) {
+	for _, c := range r.compositions {
+		if c.GetName() != name {
+			continue
+		}
+		if !compatible(c, gvk) {
+			return nil, errors.Errorf(errIncompatibleFmt, name, gvk.Kind)
+		}
+		return c, nil
+	}
+	return nil, errors.Errorf(errNotFoundFmt, name)
+}
+
+// compatible returns the Compositions compatible with the supplied kind of
+// composite resource.
+func (r *Renderer) compatible(gvk schema.GroupVersionKind) []*xpextv1.Composition {
+	out := []*xpextv1.Composition{}
+	for _, c := range r.compositions {
+		if compatible(c, gvk) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func compatible(c *xpextv1.Composition, gvk schema.GroupVersionKind) bool {
+	apiVersion, kind := gvk.ToAPIVersionAndKind()
+	return c.Spec.CompositeTypeRef.APIVersion == apiVersion && c.Spec.CompositeTypeRef.Kind == kind
+}
+
+func hasVersion(xrd *xpextv1.CompositeResourceDefinition, version string) bool {
+	for _, v := range xrd.Spec.Versions {
+		if v.Name == version {
+			return true
+		}
+	}
+	return false
+}
+
+func describe(gvk schema.GroupVersionKind, name string) string {
+	if name == "" {
+		return gvk.Kind
+	}
+	return gvk.Kind + "/" + name
+}
@@ -0,0 +1,391 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+const (
+	xrd = `
+apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xbuckets.example.org
+spec:
+  group: example.org
+  names:
+    kind: XBucket
+    plural: xbuckets
+  claimNames:
+    kind: Bucket
+    plural: buckets
+  versions:
+  - name: v1alpha1
+    served: true
+    referenceable: true
+    schema:
+      openAPIV3Schema:
+        type: object
+`
+	crd = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: buckets.s3.aws.example.org
+spec:
+  group: s3.aws.example.org
+  names:
+    kind: Bucket
+    plural: buckets
+  scope: Cluster
+  versions:
+  - name: v1beta1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              forProvider:
+                type: object
+                properties:
+                  region:
+                    type: string
+                  versioning:
+                    type: boolean
+                  tags:
+                    type: object
+                    additionalProperties:
+                      type: string
+`
+	compAWS = `
+apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: xbuckets-aws
+  labels:
+    provider: aws
+spec:
+  compositeTypeRef:
+    apiVersion: example.org/v1alpha1
+    kind: XBucket
+  resources:
+  - name: bucket
+    base:
+      apiVersion: s3.aws.example.org/v1beta1
+      kind: Bucket
+      spec:
+        forProvider:
+          region: us-east-1
+    patches:
+    - fromFieldPath: spec.region
+      toFieldPath: spec.forProvider.region
+    - type: CombineFromComposite
+      combine:
+        variables:
+        - fromFieldPath: spec.region
+        - fromFieldPath: spec.size
+        strategy: string
+        string:
+          fmt: "%s-%s"
+      toFieldPath: spec.forProvider.tags.name
+    - fromFieldPath: spec.size
+      toFieldPath: spec.forProvider.versioning
+      transforms:
+      - type: map
+        map:
+          small: false
+          large: true
+    - type: ToCompositeFieldPath
+      fromFieldPath: spec.forProvider.region
+      toFieldPath: status.region
+  - name: replica
+    base:
+      apiVersion: s3.aws.example.org/v1beta1
+      kind: Bucket
+    patches:
+    - fromFieldPath: status.region
+      toFieldPath: spec.forProvider.tags.source
+`
+	compInvalid = `
+apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: xbuckets-invalid
+  labels:
+    provider: invalid
+spec:
+  compositeTypeRef:
+    apiVersion: example.org/v1alpha1
+    kind: XBucket
+  resources:
+  - name: bucket
+    base:
+      apiVersion: s3.aws.example.org/v1beta1
+      kind: Bucket
+    patches:
+    - fromFieldPath: spec.size
+      toFieldPath: spec.forProvider.versioning
+  - name: required
+    base:
+      apiVersion: s3.aws.example.org/v1beta1
+      kind: Bucket
+    patches:
+    - fromFieldPath: spec.missing
+      toFieldPath: spec.forProvider.region
+      policy:
+        fromFieldPath: Required
+`
+)
+
+func object[T runtime.Object](t *testing.T, s string, o T) T {
+	t.Helper()
+	if err := yaml.Unmarshal([]byte(s), o); err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
+
+func resource(kind string, spec map[string]any) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "example.org/v1alpha1",
+		"kind":       kind,
+		"metadata":   map[string]any{"name": "my-bucket", "namespace": "default"},
+		"spec":       spec,
+	}}
+	return u
+}
+
+// rendered is the part of a rendered resource the tests are interested in.
+type rendered struct {
+	Name        string
+	Err         string
+	Spec        any
+	Validations []string
+}
+
+func summarize(out *Output) []rendered {
+	res := make([]rendered, len(out.Resources))
+	for i, r := range out.Resources {
+		res[i] = rendered{Name: r.Name}
+		if r.Err != nil {
+			res[i].Err = r.Err.Error()
+		}
+		if r.Object != nil {
+			res[i].Spec = r.Object.Object["spec"]
+		}
+		for _, v := range r.Validations {
+			res[i].Validations = append(res[i].Validations, v.Error())
+		}
+	}
+	return res
+}
+
+func TestRender(t *testing.T) {
+	objs := []runtime.Object{
+		object(t, xrd, &xpextv1.CompositeResourceDefinition{}),
+		object(t, crd, &extv1.CustomResourceDefinition{}),
+		object(t, compAWS, &xpextv1.Composition{}),
+		object(t, compInvalid, &xpextv1.Composition{}),
+	}
+	awsResources := []rendered{
+		{
+			Name: "bucket",
+			Spec: map[string]any{"forProvider": map[string]any{
+				"region":     "eu-west-1",
+				"versioning": false,
+				"tags":       map[string]any{"name": "eu-west-1-small"},
+			}},
+		},
+		{
+			Name: "replica",
+			Spec: map[string]any{"forProvider": map[string]any{
+				"tags": map[string]any{"source": "eu-west-1"},
+			}},
+		},
+	}
+
+	type args struct {
+		objs []runtime.Object
+		in   *unstructured.Unstructured
+	}
+	type want struct {
+		composition string
+		status      map[string]any
+		resources   []rendered
+		err         error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Composite": {
+			reason: "Patches and transforms should be applied to the resources composed by a composite resource, and patches to the composite resource should be applied back.",
+			args: args{
+				objs: objs,
+				in: resource("XBucket", map[string]any{
+					"region":         "eu-west-1",
+					"size":           "small",
+					"compositionRef": map[string]any{"name": "xbuckets-aws"},
+				}),
+			},
+			want: want{
+				composition: "xbuckets-aws",
+				status:      map[string]any{"region": "eu-west-1"},
+				resources:   awsResources,
+			},
+		},
+		"Claim": {
+			reason: "A claim should be rendered as its composite resource.",
+			args: args{
+				objs: objs,
+				in: resource("Bucket", map[string]any{
+					"region":                     "eu-west-1",
+					"size":                       "small",
+					"writeConnectionSecretToRef": map[string]any{"name": "bucket"},
+					"compositionSelector":        map[string]any{"matchLabels": map[string]any{"provider": "aws"}},
+				}),
+			},
+			want: want{
+				composition: "xbuckets-aws",
+				status:      map[string]any{"region": "eu-west-1"},
+				resources:   awsResources,
+			},
+		},
+		"Invalid": {
+			reason: "Resources that cannot be rendered or do not match their schema should be reported.",
+			args: args{
+				objs: objs,
+				in: resource("XBucket", map[string]any{
+					"size":           "small",
+					"compositionRef": map[string]any{"name": "xbuckets-invalid"},
+				}),
+			},
+			want: want{
+				composition: "xbuckets-invalid",
+				resources: []rendered{
+					{
+						Name:        "bucket",
+						Spec:        map[string]any{"forProvider": map[string]any{"versioning": "small"}},
+						Validations: []string{`spec.forProvider.versioning in body must be of type boolean: "string"`},
+					},
+					{
+						Name: "required",
+						Err:  `cannot apply the patch at index 0: spec.missing: no such field`,
+					},
+				},
+			},
+		},
+		"Ambiguous": {
+			reason: "An error should be returned if more than one composition could be selected.",
+			args: args{
+				objs: objs,
+				in:   resource("XBucket", map[string]any{}),
+			},
+			want: want{err: errors.Errorf(errAmbiguousFmt, 2, "XBucket/my-bucket")},
+		},
+		"CompositionNotFound": {
+			reason: "An error should be returned if the referenced composition does not exist.",
+			args: args{
+				objs: objs,
+				in:   resource("XBucket", map[string]any{"compositionRef": map[string]any{"name": "xbuckets-gcp"}}),
+			},
+			want: want{err: errors.Errorf(errNotFoundFmt, "xbuckets-gcp")},
+		},
+		"NoSelectedComposition": {
+			reason: "An error should be returned if no composition matches the composition selector.",
+			args: args{
+				objs: objs,
+				in:   resource("XBucket", map[string]any{"compositionSelector": map[string]any{"matchLabels": map[string]any{"provider": "gcp"}}}),
+			},
+			want: want{err: errors.Errorf(errNoSelectedFmt, "XBucket/my-bucket")},
+		},
+		"NoDefinition": {
+			reason: "An error should be returned if no XRD defines the kind of the resource.",
+			args: args{
+				objs: objs[1:],
+				in:   resource("XBucket", map[string]any{}),
+			},
+			want: want{err: errors.Errorf(errNoDefinitionFmt, "XBucket/my-bucket")},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			out, err := New(context.Background(), tc.args.objs...).Render(context.Background(), tc.args.in)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\nRender(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.composition, out.Composition); diff != "" {
+				t.Errorf("\n%s\nRender(...): -want composition, +got composition:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.status, out.Composite.Object["status"]); tc.want.status != nil && diff != "" {
+				t.Errorf("\n%s\nRender(...): -want composite status, +got composite status:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.resources, summarize(out)); diff != "" {
+				t.Errorf("\n%s\nRender(...): -want resources, +got resources:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFromClaim(t *testing.T) {
+	cm := resource("Bucket", map[string]any{
+		"region":                     "eu-west-1",
+		"writeConnectionSecretToRef": map[string]any{"name": "bucket"},
+	})
+	want := map[string]any{
+		"apiVersion": "example.org/v1alpha1",
+		"kind":       "XBucket",
+		"metadata": map[string]any{
+			"name": "my-bucket",
+			"labels": map[string]any{
+				"crossplane.io/claim-name":      "my-bucket",
+				"crossplane.io/claim-namespace": "default",
+			},
+		},
+		"spec": map[string]any{
+			"region": "eu-west-1",
+			"claimRef": map[string]any{
+				"apiVersion": "example.org/v1alpha1",
+				"kind":       "Bucket",
+				"namespace":  "default",
+				"name":       "my-bucket",
+			},
+		},
+	}
+	got := fromClaim(cm, cm.GroupVersionKind().GroupVersion().WithKind("XBucket"))
+	if diff := cmp.Diff(want, got.Object); diff != "" {
+		t.Errorf("\nThe claim should be converted to a composite resource without claim-only fields.\nfromClaim(...): -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+const (
+	errGetSubjectFmt     = "failed to get descriptor of %s"
+	errBuildArtifact     = "failed to build SBOM artifact"
+	errWriteArtifact     = "failed to write SBOM artifact"
+	errUpdateFallbackTag = "failed to update referrers fallback tag"
+)
+
+// emptyMediaType is the media type of the empty config of OCI artifacts.
+// See https://github.com/opencontainers/image-spec/blob/main/manifest.md#guidance-for-an-empty-descriptor
+const emptyMediaType types.MediaType = "application/vnd.oci.empty.v1+json"
+
+// artifact is an OCI image manifest with an artifact type.
+// NOTE: v1.Manifest does not support the artifactType field yet.
+type artifact struct {
+	SchemaVersion int64           `json:"schemaVersion"`
+	MediaType     types.MediaType `json:"mediaType"`
+	ArtifactType  types.MediaType `json:"artifactType"`
+	Config        v1.Descriptor   `json:"config"`
+	Layers        []v1.Descriptor `json:"layers"`
+	Subject       *v1.Descriptor  `json:"subject,omitempty"`
+}
+
+// rawManifest is a manifest that can be written with remote.Put.
+type rawManifest struct {
+	raw []byte
+	mt  types.MediaType
+}
+
+func (m rawManifest) RawManifest() ([]byte, error)        { return m.raw, nil }
+func (m rawManifest) MediaType() (types.MediaType, error) { return m.mt, nil }
+
+// Attach pushes the supplied SBOM as an OCI artifact that refers to the
+// package image or image index with the supplied digest, so that it is
+// returned by the referrers API for that digest. The artifact has an empty
+// config and its artifact type is the media type of the SBOM. It returns the
+// digest of the pushed artifact.
+func Attach(ctx context.Context, subject name.Digest, format string, sbom []byte, opts ...remote.Option) (name.Digest, error) {
+	mt, err := MediaType(format)
+	if err != nil {
+		return name.Digest{}, err
+	}
+	opts = append(opts, remote.WithContext(ctx))
+	desc, err := remote.Head(subject, opts...)
+	if err != nil {
+		return name.Digest{}, errors.Wrapf(err, errGetSubjectFmt, subject.String())
+	}
+
+	cfg := static.NewLayer([]byte("{}"), emptyMediaType)
+	l := static.NewLayer(sbom, mt)
+	for _, b := range []v1.Layer{cfg, l} {
+		if err := remote.WriteLayer(subject.Context(), b, opts...); err != nil {
+			return name.Digest{}, errors.Wrap(err, errWriteArtifact)
+		}
+	}
+	cd, err := descriptor(cfg, emptyMediaType)
+	if err != nil {
+		return name.Digest{}, errors.Wrap(err, errBuildArtifact)
+	}
+	ld, err := descriptor(l, mt)
+	if err != nil {
+		return name.Digest{}, errors.Wrap(err, errBuildArtifact)
+	}
+
+	raw, err := json.Marshal(artifact{
+		SchemaVersion: 2,
+		MediaType:     types.OCIManifestSchema1,
+		ArtifactType:  mt,
+		Config:        cd,
+		Layers:        []v1.Descriptor{ld},
+		Subject: &v1.Descriptor{
+			MediaType: desc.MediaType,
+			Digest:    desc.Digest,
+			Size:      desc.Size,
+		},
+	})
+	if err != nil {
+		return name.Digest{}, errors.Wrap(err, errBuildArtifact)
+	}
+	h, _, err := v1.SHA256(bytes.NewReader(raw))
+	if err != nil {
+		return name.Digest{}, errors.Wrap(err, errBuildArtifact)
+	}
+	d := subject.Context().Digest(h.String())
+	if err := remote.Put(d, rawManifest{raw: raw, mt: types.OCIManifestSchema1}, opts...); err != nil {
+		return name.Digest{}, errors.Wrap(err, errWriteArtifact)
+	}
+	if err := setFallbackArtifactType(subject, h, mt, opts...); err != nil {
+		return name.Digest{}, errors.Wrap(err, errUpdateFallbackTag)
+	}
+	return d, nil
+}
+
+// setFallbackArtifactType sets the artifact type of the artifact with the
+// supplied digest in the fallback tag of the subject. Registries without
+// support for the referrers API list the referrers of an image in an index
+// tagged with its digest instead, e.g. sha256-abc123.
+// NOTE: go-containerregistry adds artifacts to the fallback tag with the media
+// type of their config as artifact type, which is empty for SBOMs.
+func setFallbackArtifactType(subject name.Digest, artifact v1.Hash, mt types.MediaType, opts ...remote.Option) error {
+	t := subject.Context().Tag(strings.Replace(subject.DigestStr(), ":", "-", 1))
+	desc, err := remote.Get(t, opts...)
+	var terr *transport.Error
+	if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+		// The registry supports the referrers API.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	ii, err := desc.ImageIndex()
+	if err != nil {
+		return err
+	}
+	m, err := ii.IndexManifest()
+	if err != nil {
+		return err
+	}
+	m = m.DeepCopy()
+	changed := false
+	for i, d := range m.Manifests {
+		if d.Digest == artifact && d.ArtifactType != string(mt) {
+			m.Manifests[i].ArtifactType = string(mt)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return remote.Put(t, rawManifest{raw: raw, mt: types.OCIImageIndex}, opts...)
+}
+
+// descriptor returns the descriptor of the supplied blob.
+func descriptor(l v1.Layer, mt types.MediaType) (v1.Descriptor, error) {
+	h, err := l.Digest()
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	size, err := l.Size()
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	return v1.Descriptor{MediaType: mt, Digest: h, Size: size}, nil
+}
@@ -0,0 +1,172 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/uuid"
+)
+
+const (
+	cdxFormat      = "CycloneDX"
+	cdxSpecVersion = "1.5"
+
+	cdxTypeContainer = "container"
+	cdxTypeData      = "data"
+)
+
+type cdxDocument struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type        string        `json:"type"`
+	BOMRef      string        `json:"bom-ref,omitempty"`
+	Name        string        `json:"name"`
+	Version     string        `json:"version,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Hashes      []cdxHash     `json:"hashes,omitempty"`
+	PURL        string        `json:"purl,omitempty"`
+	Properties  []cdxProperty `json:"properties,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// CycloneDX generates a CycloneDX 1.5 JSON document for the package.
+func CycloneDX(p Package) ([]byte, error) {
+	root := cdxComponent{
+		Type:        cdxTypeContainer,
+		BOMRef:      p.Name,
+		Name:        p.Name,
+		Version:     p.Version,
+		Description: fmt.Sprintf("Crossplane %s package", p.Kind),
+		Hashes:      cdxHashes(p.Digest),
+	}
+	doc := cdxDocument{
+		BOMFormat:    cdxFormat,
+		SpecVersion:  cdxSpecVersion,
+		SerialNumber: cdxSerialNumber(p.Name, p.Digest),
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: p.Created.UTC().Format(time.RFC3339),
+			Tools: cdxTools{Components: []cdxComponent{{
+				Type: "application",
+				Name: toolName,
+			}}},
+			Component: root,
+		},
+		Components: []cdxComponent{},
+	}
+
+	deps := []string{}
+	for _, o := range p.Objects {
+		doc.Components = append(doc.Components, cdxComponent{
+			Type:    cdxTypeData,
+			BOMRef:  fmt.Sprintf("%s/%s/%s", o.APIVersion, o.Kind, o.Name),
+			Name:    fmt.Sprintf("%s/%s", o.Kind, o.Name),
+			Version: o.APIVersion,
+		})
+	}
+	for _, d := range p.Dependencies {
+		c := cdxComponent{
+			Type:    cdxTypeContainer,
+			BOMRef:  d.Package,
+			Name:    d.Package,
+			Version: d.Version,
+			Hashes:  cdxHashes(d.Digest),
+			PURL:    purl(d.Package, d.Digest, d.Version),
+			Properties: []cdxProperty{
+				{Name: "upbound:package:type", Value: d.Type},
+			},
+		}
+		if d.Constraint != "" {
+			c.Properties = append(c.Properties, cdxProperty{Name: "upbound:package:constraint", Value: d.Constraint})
+		}
+		doc.Components = append(doc.Components, c)
+		deps = append(deps, c.BOMRef)
+	}
+	for _, b := range p.Bases {
+		c := cdxComponent{
+			Type:   cdxTypeContainer,
+			BOMRef: b.Ref,
+			Name:   repository(b.Ref),
+			Hashes: cdxHashes(b.Digest),
+			PURL:   purl(repository(b.Ref), b.Digest, ""),
+		}
+		if b.Platform != "" {
+			c.BOMRef = b.Ref + "#" + b.Platform
+			c.Properties = []cdxProperty{{Name: "upbound:image:platform", Value: b.Platform}}
+		}
+		doc.Components = append(doc.Components, c)
+		deps = append(deps, c.BOMRef)
+	}
+	doc.Dependencies = []cdxDependency{{Ref: root.BOMRef, DependsOn: deps}}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func cdxSetDigest(sbom []byte, digest string) ([]byte, error) {
+	doc := cdxDocument{}
+	if err := json.Unmarshal(sbom, &doc); err != nil {
+		return nil, errors.Wrap(err, errParseSBOM)
+	}
+	doc.SerialNumber = cdxSerialNumber(doc.Metadata.Component.Name, digest)
+	doc.Metadata.Component.Hashes = cdxHashes(digest)
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func cdxSerialNumber(name, digest string) string {
+	return "urn:uuid:" + uuid.NewSHA1(uuid.NameSpaceURL, []byte(name+"@"+digest)).String()
+}
+
+func cdxHashes(digest string) []cdxHash {
+	if digest == "" {
+		return nil
+	}
+	return []cdxHash{{Alg: "SHA-256", Content: hex(digest)}}
+}
@@ -0,0 +1,206 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sbom generates software bills of materials for packages and
+// attaches them to package images in a registry.
+package sbom
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Supported SBOM formats.
+const (
+	FormatSPDX      = "spdx"
+	FormatCycloneDX = "cyclonedx"
+)
+
+// Media types of the supported SBOM formats.
+const (
+	SPDXMediaType      types.MediaType = "application/spdx+json"
+	CycloneDXMediaType types.MediaType = "application/vnd.cyclonedx+json"
+)
+
+const (
+	errUnknownFormatFmt = "unknown SBOM format %q"
+	errParseSBOM        = "failed to parse SBOM"
+)
+
+// toolName is recorded as the creator of generated SBOMs.
+const toolName = "up"
+
+// Package describes the contents of a built package.
+type Package struct {
+	// Name is the name of the package, as declared in crossplane.yaml.
+	Name string
+	// Kind is the kind of the package meta, e.g. Provider.
+	Kind string
+	// Version is the version of the package, if known.
+	Version string
+	// Digest is the digest of the package image or image index.
+	Digest string
+	// Created is the time the package was built.
+	Created time.Time
+	// Objects are the objects contained in the package.
+	Objects []Object
+	// Dependencies are the package dependencies.
+	Dependencies []Dependency
+	// Bases are the controller or function runtime images the package was
+	// built on.
+	Bases []Image
+}
+
+// Object is a Kubernetes object contained in a package, e.g. a CRD.
+type Object struct {
+	APIVersion string
+	Kind       string
+	Name       string
+}
+
+// Dependency is a package dependency.
+type Dependency struct {
+	// Package is the reference of the dependency without tag.
+	Package string
+	// Type is the type of the dependency, e.g. Provider.
+	Type string
+	// Constraint is the version constraint declared for the dependency. It is
+	// empty for transitive dependencies.
+	Constraint string
+	// Version and Digest are the resolved version and digest of the
+	// dependency, if it has been resolved.
+	Version string
+	Digest  string
+}
+
+// Image is a container image a package was built on.
+type Image struct {
+	Ref      string
+	Digest   string
+	Platform string
+}
+
+// Objects returns the supplied Kubernetes objects as SBOM objects, sorted by
+// kind and name.
+func Objects(objs []runtime.Object) []Object {
+	out := make([]Object, 0, len(objs))
+	for _, o := range objs {
+		gvk := o.GetObjectKind().GroupVersionKind()
+		obj := Object{APIVersion: gvk.GroupVersion().String(), Kind: gvk.Kind}
+		if m, ok := o.(metav1.Object); ok {
+			obj.Name = m.GetName()
+		}
+		out = append(out, obj)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Kind != out[j].Kind {
+			return out[i].Kind < out[j].Kind
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// Generate generates an SBOM in the supplied format for the package.
+func Generate(format string, p Package) ([]byte, error) {
+	switch format {
+	case FormatSPDX:
+		return SPDX(p)
+	case FormatCycloneDX:
+		return CycloneDX(p)
+	}
+	return nil, errors.Errorf(errUnknownFormatFmt, format)
+}
+
+// SetDigest sets the digest of the package described by the supplied SBOM.
+// Pushing a package modifies its images, so an SBOM generated at build time
+// must be updated with the digest of the pushed package before it is attached.
+func SetDigest(format string, sbom []byte, digest string) ([]byte, error) {
+	switch format {
+	case FormatSPDX:
+		return spdxSetDigest(sbom, digest)
+	case FormatCycloneDX:
+		return cdxSetDigest(sbom, digest)
+	}
+	return nil, errors.Errorf(errUnknownFormatFmt, format)
+}
+
+// MediaType returns the media type of the supplied SBOM format.
+func MediaType(format string) (types.MediaType, error) {
+	switch format {
+	case FormatSPDX:
+		return SPDXMediaType, nil
+	case FormatCycloneDX:
+		return CycloneDXMediaType, nil
+	}
+	return "", errors.Errorf(errUnknownFormatFmt, format)
+}
+
+// Extension returns the file extension used for SBOMs in the supplied format.
+func Extension(format string) string {
+	switch format {
+	case FormatSPDX:
+		return ".spdx.json"
+	case FormatCycloneDX:
+		return ".cdx.json"
+	}
+	return ".json"
+}
+
+// Formats returns the supported formats.
+func Formats() []string {
+	return []string{FormatSPDX, FormatCycloneDX}
+}
+
+// purl returns the package URL of an OCI artifact.
+// See https://github.com/package-url/purl-spec/blob/master/PURL-TYPES.rst#oci
+func purl(ref, digest, tag string) string {
+	if digest == "" {
+		return ""
+	}
+	repo := ref
+	if r, err := name.NewRepository(ref); err == nil {
+		repo = r.Name()
+	}
+	n := repo[strings.LastIndex(repo, "/")+1:]
+	q := url.Values{}
+	q.Set("repository_url", repo)
+	if tag != "" {
+		q.Set("tag", tag)
+	}
+	return fmt.Sprintf("pkg:oci/%s@%s?%s", n, url.QueryEscape(digest), q.Encode())
+}
+
+// repository strips the tag or digest from the supplied image reference.
+func repository(ref string) string {
+	r, err := name.ParseReference(ref)
+	if err != nil {
+		return ref
+	}
+	return r.Context().Name()
+}
+
+// hex returns the hex encoded part of the supplied digest.
+func hex(digest string) string {
+	return digest[strings.Index(digest, ":")+1:]
+}
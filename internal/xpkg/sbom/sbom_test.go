@@ -0,0 +1,271 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+var testPackage = Package{
+	Name:    "provider-foo",
+	Kind:    "Provider",
+	Digest:  "sha256:aaaa",
+	Created: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	Objects: []Object{
+		{APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition", Name: "buckets.foo.example.org"},
+	},
+	Dependencies: []Dependency{
+		{Package: "xpkg.upbound.io/acme/provider-bar", Type: "Provider", Constraint: ">=v1.0.0", Version: "v1.2.0", Digest: "sha256:bbbb"},
+	},
+	Bases: []Image{
+		{Ref: "xpkg.upbound.io/acme/provider-foo-controller:v1.0.0", Digest: "sha256:cccc", Platform: "linux/amd64"},
+	},
+}
+
+func TestSPDX(t *testing.T) {
+	b, err := SPDX(testPackage)
+	if err != nil {
+		t.Fatalf("SPDX(...): unexpected error: %v", err)
+	}
+	doc := spdxDocument{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("SPDX(...): invalid JSON: %v", err)
+	}
+
+	want := []spdxRelationship{
+		{SPDXElementID: spdxDocumentID, RelationshipType: "DESCRIBES", RelatedSPDXElement: spdxRootID},
+		{SPDXElementID: spdxRootID, RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-Package-Object-CustomResourceDefinition-buckets.foo.example.org"},
+		{SPDXElementID: spdxRootID, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Package-Dependency-xpkg.upbound.io-acme-provider-bar"},
+		{SPDXElementID: spdxRootID, RelationshipType: "DESCENDANT_OF", RelatedSPDXElement: "SPDXRef-Package-Base-xpkg.upbound.io-acme-provider-foo-controller-v1.0.0-linux-amd64"},
+	}
+	if diff := cmp.Diff(want, doc.Relationships); diff != "" {
+		t.Errorf("SPDX(...): -want relationships, +got relationships:\n%s", diff)
+	}
+	if diff := cmp.Diff(len(want), len(doc.Packages)); diff != "" {
+		t.Errorf("SPDX(...): -want packages, +got packages:\n%s", diff)
+	}
+	wantPurl := "pkg:oci/provider-bar@sha256%3Abbbb?repository_url=xpkg.upbound.io%2Facme%2Fprovider-bar&tag=v1.2.0"
+	if diff := cmp.Diff(wantPurl, doc.Packages[2].ExternalRefs[0].ReferenceLocator); diff != "" {
+		t.Errorf("SPDX(...): -want purl, +got purl:\n%s", diff)
+	}
+}
+
+func TestCycloneDX(t *testing.T) {
+	b, err := CycloneDX(testPackage)
+	if err != nil {
+		t.Fatalf("CycloneDX(...): unexpected error: %v", err)
+	}
+	doc := cdxDocument{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("CycloneDX(...): invalid JSON: %v", err)
+	}
+
+	want := []cdxDependency{{
+		Ref: "provider-foo",
+		DependsOn: []string{
+			"xpkg.upbound.io/acme/provider-bar",
+			"xpkg.upbound.io/acme/provider-foo-controller:v1.0.0#linux/amd64",
+		},
+	}}
+	if diff := cmp.Diff(want, doc.Dependencies); diff != "" {
+		t.Errorf("CycloneDX(...): -want dependencies, +got dependencies:\n%s", diff)
+	}
+	if diff := cmp.Diff(3, len(doc.Components)); diff != "" {
+		t.Errorf("CycloneDX(...): -want components, +got components:\n%s", diff)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		format string
+		err    error
+	}{
+		"SPDX": {
+			reason: "SPDX SBOMs should be generated.",
+			format: FormatSPDX,
+		},
+		"CycloneDX": {
+			reason: "CycloneDX SBOMs should be generated.",
+			format: FormatCycloneDX,
+		},
+		"Unknown": {
+			reason: "Unknown formats should be rejected.",
+			format: "swid",
+			err:    errors.Errorf(errUnknownFormatFmt, "swid"),
+		},
+	}
+
+	for n, tc := range cases {
+		t.Run(n, func(t *testing.T) {
+			_, err := Generate(tc.format, testPackage)
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGenerate(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSetDigest(t *testing.T) {
+	want := testPackage
+	want.Digest = "sha256:dddd"
+
+	cases := map[string]struct {
+		reason string
+		format string
+	}{
+		"SPDX": {
+			reason: "The digest of the package should be replaced in SPDX SBOMs.",
+			format: FormatSPDX,
+		},
+		"CycloneDX": {
+			reason: "The digest of the package should be replaced in CycloneDX SBOMs.",
+			format: FormatCycloneDX,
+		},
+	}
+
+	for n, tc := range cases {
+		t.Run(n, func(t *testing.T) {
+			b, err := Generate(tc.format, testPackage)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := SetDigest(tc.format, b, want.Digest)
+			if err != nil {
+				t.Fatalf("\n%s\nSetDigest(...): unexpected error: %v", tc.reason, err)
+			}
+			w, err := Generate(tc.format, want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(string(w), string(got)); diff != "" {
+				t.Errorf("\n%s\nSetDigest(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestAttach(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.WithReferrersSupport(true)))
+	defer s.Close()
+	u, _ := url.Parse(s.URL)
+
+	img, _ := random.Image(256, 1)
+	ref, err := name.ParseReference(fmt.Sprintf("%s/acme/provider-foo:v1", u.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+	h, _ := img.Digest()
+	subject := ref.Context().Digest(h.String())
+
+	d, err := Attach(context.Background(), subject, FormatSPDX, []byte(`{}`))
+	if err != nil {
+		t.Fatalf("Attach(...): unexpected error: %v", err)
+	}
+
+	ii, err := remote.Referrers(subject)
+	if err != nil {
+		t.Fatalf("Referrers(...): unexpected error: %v", err)
+	}
+	m, err := ii.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(1, len(m.Manifests)); diff != "" {
+		t.Fatalf("Attach(...): -want referrers, +got referrers:\n%s", diff)
+	}
+	if diff := cmp.Diff(d.DigestStr(), m.Manifests[0].Digest.String()); diff != "" {
+		t.Errorf("Attach(...): -want digest, +got digest:\n%s", diff)
+	}
+
+	// NOTE: the test registry reports the config media type of referrers as
+	// their artifact type, so the artifact manifest is checked instead.
+	raw, err := remote.Get(d)
+	if err != nil {
+		t.Fatalf("Get(...): unexpected error: %v", err)
+	}
+	a := artifact{}
+	if err := json.Unmarshal(raw.Manifest, &a); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(SPDXMediaType, a.ArtifactType); diff != "" {
+		t.Errorf("Attach(...): -want artifact type, +got artifact type:\n%s", diff)
+	}
+	if diff := cmp.Diff(emptyMediaType, a.Config.MediaType); diff != "" {
+		t.Errorf("Attach(...): -want config media type, +got config media type:\n%s", diff)
+	}
+	if diff := cmp.Diff(h, a.Subject.Digest); diff != "" {
+		t.Errorf("Attach(...): -want subject, +got subject:\n%s", diff)
+	}
+}
+
+func TestAttachFallbackTag(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, _ := url.Parse(s.URL)
+
+	img, _ := random.Image(256, 1)
+	ref, err := name.ParseReference(fmt.Sprintf("%s/acme/provider-foo:v1", u.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+	h, _ := img.Digest()
+	subject := ref.Context().Digest(h.String())
+
+	d, err := Attach(context.Background(), subject, FormatCycloneDX, []byte(`{}`))
+	if err != nil {
+		t.Fatalf("Attach(...): unexpected error: %v", err)
+	}
+
+	// Registries without support for the referrers API list referrers in the
+	// fallback tag of the subject, which is filtered by artifact type.
+	ii, err := remote.Referrers(subject, remote.WithFilter("artifactType", string(CycloneDXMediaType)))
+	if err != nil {
+		t.Fatalf("Referrers(...): unexpected error: %v", err)
+	}
+	m, err := ii.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(1, len(m.Manifests)); diff != "" {
+		t.Fatalf("Attach(...): -want referrers, +got referrers:\n%s", diff)
+	}
+	if diff := cmp.Diff(d.DigestStr(), m.Manifests[0].Digest.String()); diff != "" {
+		t.Errorf("Attach(...): -want digest, +got digest:\n%s", diff)
+	}
+	if diff := cmp.Diff(string(CycloneDXMediaType), m.Manifests[0].ArtifactType); diff != "" {
+		t.Errorf("Attach(...): -want artifact type, +got artifact type:\n%s", diff)
+	}
+}
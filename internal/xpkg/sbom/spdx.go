@@ -0,0 +1,230 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	spdxVersion       = "SPDX-2.3"
+	spdxDataLicense   = "CC0-1.0"
+	spdxDocumentID    = "SPDXRef-DOCUMENT"
+	spdxRootID        = "SPDXRef-Package"
+	spdxNoAssertion   = "NOASSERTION"
+	spdxNamespaceBase = "https://upbound.io/spdxdocs/"
+)
+
+// spdxInvalidID matches characters that are not allowed in SPDX identifiers.
+var spdxInvalidID = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name                  string            `json:"name"`
+	SPDXID                string            `json:"SPDXID"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose,omitempty"`
+	Checksums             []spdxChecksum    `json:"checksums,omitempty"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs,omitempty"`
+	Comment               string            `json:"comment,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// SPDX generates an SPDX 2.3 JSON document for the package.
+func SPDX(p Package) ([]byte, error) {
+	doc := spdxDocument{
+		SPDXVersion:       spdxVersion,
+		DataLicense:       spdxDataLicense,
+		SPDXID:            spdxDocumentID,
+		Name:              p.Name,
+		DocumentNamespace: spdxNamespace(p.Name, p.Digest),
+		CreationInfo: spdxCreationInfo{
+			Created:  p.Created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: " + toolName},
+		},
+	}
+
+	root := spdxPackage{
+		Name:                  p.Name,
+		SPDXID:                spdxRootID,
+		VersionInfo:           p.Version,
+		DownloadLocation:      spdxNoAssertion,
+		PrimaryPackagePurpose: "CONTAINER",
+		Checksums:             spdxChecksums(p.Digest),
+		Comment:               fmt.Sprintf("Crossplane %s package", p.Kind),
+	}
+	doc.Packages = append(doc.Packages, root)
+	doc.Relationships = append(doc.Relationships, spdxRelationship{
+		SPDXElementID:      spdxDocumentID,
+		RelationshipType:   "DESCRIBES",
+		RelatedSPDXElement: spdxRootID,
+	})
+
+	for _, o := range p.Objects {
+		id := spdxID("Object", o.Kind, o.Name)
+		doc.Packages = append(doc.Packages, spdxPackage{
+			Name:                  fmt.Sprintf("%s/%s", o.Kind, o.Name),
+			SPDXID:                id,
+			VersionInfo:           o.APIVersion,
+			DownloadLocation:      spdxNoAssertion,
+			PrimaryPackagePurpose: "OTHER",
+		})
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      spdxRootID,
+			RelationshipType:   "CONTAINS",
+			RelatedSPDXElement: id,
+		})
+	}
+
+	for _, d := range p.Dependencies {
+		id := spdxID("Dependency", d.Package)
+		pkg := spdxPackage{
+			Name:                  d.Package,
+			SPDXID:                id,
+			VersionInfo:           d.Version,
+			DownloadLocation:      spdxNoAssertion,
+			PrimaryPackagePurpose: "CONTAINER",
+			Checksums:             spdxChecksums(d.Digest),
+			ExternalRefs:          spdxPurl(d.Package, d.Digest, d.Version),
+			Comment:               spdxDependencyComment(d),
+		}
+		doc.Packages = append(doc.Packages, pkg)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      spdxRootID,
+			RelationshipType:   "DEPENDS_ON",
+			RelatedSPDXElement: id,
+		})
+	}
+
+	for _, b := range p.Bases {
+		id := spdxID("Base", b.Ref, b.Platform)
+		doc.Packages = append(doc.Packages, spdxPackage{
+			Name:                  repository(b.Ref),
+			SPDXID:                id,
+			DownloadLocation:      spdxNoAssertion,
+			PrimaryPackagePurpose: "CONTAINER",
+			Checksums:             spdxChecksums(b.Digest),
+			ExternalRefs:          spdxPurl(repository(b.Ref), b.Digest, ""),
+			Comment:               spdxBaseComment(b),
+		})
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      spdxRootID,
+			RelationshipType:   "DESCENDANT_OF",
+			RelatedSPDXElement: id,
+		})
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func spdxSetDigest(sbom []byte, digest string) ([]byte, error) {
+	doc := spdxDocument{}
+	if err := json.Unmarshal(sbom, &doc); err != nil {
+		return nil, errors.Wrap(err, errParseSBOM)
+	}
+	doc.DocumentNamespace = spdxNamespace(doc.Name, digest)
+	for i := range doc.Packages {
+		if doc.Packages[i].SPDXID == spdxRootID {
+			doc.Packages[i].Checksums = spdxChecksums(digest)
+		}
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func spdxNamespace(name, digest string) string {
+	return spdxNamespaceBase + name + "-" + hex(digest)
+}
+
+func spdxID(parts ...string) string {
+	id := spdxRootID
+	for _, p := range parts {
+		if p == "" {
+			continue
+		}
+		id += "-" + spdxInvalidID.ReplaceAllString(p, "-")
+	}
+	return id
+}
+
+func spdxChecksums(digest string) []spdxChecksum {
+	if digest == "" {
+		return nil
+	}
+	return []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: hex(digest)}}
+}
+
+func spdxPurl(ref, digest, tag string) []spdxExternalRef {
+	p := purl(ref, digest, tag)
+	if p == "" {
+		return nil
+	}
+	return []spdxExternalRef{{
+		ReferenceCategory: "PACKAGE-MANAGER",
+		ReferenceType:     "purl",
+		ReferenceLocator:  p,
+	}}
+}
+
+func spdxBaseComment(b Image) string {
+	if b.Platform == "" {
+		return "Base image"
+	}
+	return fmt.Sprintf("Base image for %s", b.Platform)
+}
+
+func spdxDependencyComment(d Dependency) string {
+	if d.Constraint == "" {
+		return fmt.Sprintf("Transitive %s dependency", d.Type)
+	}
+	return fmt.Sprintf("%s dependency with constraint %q", d.Type, d.Constraint)
+}
@@ -0,0 +1,330 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schemadiff compares the APIs defined by two versions of a package,
+// i.e. the CRDs and XRDs they contain, and reports the changes between them.
+package schemadiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	extv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	xpv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+const (
+	errConvertSchemaFmt = "failed to convert schema of %s %s"
+)
+
+// ChangeType is the type of a change.
+type ChangeType string
+
+// Types of changes.
+const (
+	KindAdded       ChangeType = "KindAdded"
+	KindRemoved     ChangeType = "KindRemoved"
+	VersionAdded    ChangeType = "VersionAdded"
+	VersionRemoved  ChangeType = "VersionRemoved"
+	VersionServed   ChangeType = "VersionServed"
+	VersionUnserved ChangeType = "VersionUnserved"
+	FieldAdded      ChangeType = "FieldAdded"
+	FieldRemoved    ChangeType = "FieldRemoved"
+	TypeChanged     ChangeType = "TypeChanged"
+	RequiredAdded   ChangeType = "RequiredAdded"
+	RequiredRemoved ChangeType = "RequiredRemoved"
+	EnumNarrowed    ChangeType = "EnumNarrowed"
+	EnumWidened     ChangeType = "EnumWidened"
+)
+
+// Change is a single change to the APIs defined by a package.
+type Change struct {
+	// Kind is the API kind the change applies to, in group/Kind form.
+	Kind string `json:"kind"`
+	// Version is the API version the change applies to, if any.
+	Version string `json:"version,omitempty"`
+	// Field is the path of the schema field the change applies to, if any.
+	Field string `json:"field,omitempty"`
+	// Type is the type of the change.
+	Type ChangeType `json:"type"`
+	// Breaking is true if the change may break existing users of the API.
+	Breaking bool `json:"breaking"`
+	// Message describes the change.
+	Message string `json:"message"`
+}
+
+// Report is the result of comparing two versions of a package.
+type Report struct {
+	Old      string   `json:"old"`
+	New      string   `json:"new"`
+	Breaking int      `json:"breaking"`
+	Changes  []Change `json:"changes"`
+}
+
+// api is an API kind defined by a CRD or an XRD.
+type api struct {
+	versions map[string]version
+}
+
+type version struct {
+	served bool
+	schema *extv1.JSONSchemaProps
+}
+
+// Compare compares the CRDs and XRDs in the supplied objects of two versions
+// of a package. Objects are matched by the group and kind of the API they
+// define. Other objects, e.g. Compositions, are ignored.
+func Compare(oldObjs, newObjs []runtime.Object) ([]Change, error) {
+	oldAPIs, err := apis(oldObjs)
+	if err != nil {
+		return nil, err
+	}
+	newAPIs, err := apis(newObjs)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []Change{}
+	for _, k := range keys(oldAPIs, newAPIs) {
+		o, inOld := oldAPIs[k]
+		n, inNew := newAPIs[k]
+		switch {
+		case !inNew:
+			changes = append(changes, Change{Kind: k, Type: KindRemoved, Breaking: true, Message: "kind removed"})
+		case !inOld:
+			changes = append(changes, Change{Kind: k, Type: KindAdded, Message: "kind added"})
+		default:
+			changes = append(changes, compareVersions(k, o, n)...)
+		}
+	}
+	return changes, nil
+}
+
+// NewReport returns a report of the supplied changes.
+func NewReport(oldPkg, newPkg string, changes []Change) *Report {
+	r := &Report{Old: oldPkg, New: newPkg, Changes: changes}
+	for _, c := range changes {
+		if c.Breaking {
+			r.Breaking++
+		}
+	}
+	return r
+}
+
+func compareVersions(kind string, o, n api) []Change {
+	changes := []Change{}
+	for _, v := range keys(o.versions, n.versions) {
+		ov, inOld := o.versions[v]
+		nv, inNew := n.versions[v]
+		switch {
+		case !inNew:
+			changes = append(changes, Change{Kind: kind, Version: v, Type: VersionRemoved, Breaking: ov.served, Message: "version removed"})
+			continue
+		case !inOld:
+			changes = append(changes, Change{Kind: kind, Version: v, Type: VersionAdded, Message: "version added"})
+			continue
+		case ov.served && !nv.served:
+			changes = append(changes, Change{Kind: kind, Version: v, Type: VersionUnserved, Breaking: true, Message: "version no longer served"})
+		case !ov.served && nv.served:
+			changes = append(changes, Change{Kind: kind, Version: v, Type: VersionServed, Message: "version now served"})
+		}
+		for _, c := range compareSchemas("", ov.schema, nv.schema) {
+			c.Kind, c.Version = kind, v
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// compareSchemas compares the supplied schemas of the field at the supplied
+// path.
+func compareSchemas(path string, o, n *extv1.JSONSchemaProps) []Change { // nolint:gocyclo
+	if o == nil || n == nil {
+		return nil
+	}
+	changes := []Change{}
+	if o.Type != n.Type {
+		changes = append(changes, Change{Field: path, Type: TypeChanged, Breaking: true, Message: fmt.Sprintf("type changed from %q to %q", o.Type, n.Type)})
+		return changes
+	}
+	changes = append(changes, compareEnums(path, o.Enum, n.Enum)...)
+
+	oldReq, newReq := set(o.Required), set(n.Required)
+	for _, p := range keys(o.Properties, n.Properties) {
+		fp := join(path, p)
+		op, inOld := o.Properties[p]
+		np, inNew := n.Properties[p]
+		switch {
+		case !inNew:
+			changes = append(changes, Change{Field: fp, Type: FieldRemoved, Breaking: true, Message: "field removed"})
+		case !inOld:
+			c := Change{Field: fp, Type: FieldAdded, Message: "field added"}
+			if newReq[p] {
+				c.Breaking, c.Message = true, "required field added"
+			}
+			changes = append(changes, c)
+		default:
+			if newReq[p] && !oldReq[p] {
+				changes = append(changes, Change{Field: fp, Type: RequiredAdded, Breaking: true, Message: "field is now required"})
+			}
+			if oldReq[p] && !newReq[p] {
+				changes = append(changes, Change{Field: fp, Type: RequiredRemoved, Message: "field is no longer required"})
+			}
+			changes = append(changes, compareSchemas(fp, &op, &np)...)
+		}
+	}
+	if o.Items != nil && n.Items != nil {
+		changes = append(changes, compareSchemas(path+"[*]", o.Items.Schema, n.Items.Schema)...)
+	}
+	return changes
+}
+
+func compareEnums(path string, o, n []extv1.JSON) []Change {
+	if len(o) == 0 && len(n) == 0 {
+		return nil
+	}
+	oldVals, newVals := enumSet(o), enumSet(n)
+	removed, added := []string{}, []string{}
+	for _, v := range keys(oldVals, newVals) {
+		switch {
+		case !newVals[v]:
+			removed = append(removed, v)
+		case !oldVals[v]:
+			added = append(added, v)
+		}
+	}
+	switch {
+	case len(n) == 0:
+		return []Change{{Field: path, Type: EnumWidened, Message: "allowed values no longer restricted"}}
+	case len(o) == 0:
+		return []Change{{Field: path, Type: EnumNarrowed, Breaking: true, Message: "allowed values restricted to " + strings.Join(added, ", ")}}
+	case len(removed) > 0:
+		return []Change{{Field: path, Type: EnumNarrowed, Breaking: true, Message: "allowed values narrowed, removed " + strings.Join(removed, ", ")}}
+	case len(added) > 0:
+		return []Change{{Field: path, Type: EnumWidened, Message: "allowed values widened, added " + strings.Join(added, ", ")}}
+	}
+	return nil
+}
+
+// apis returns the APIs defined by the CRDs and XRDs in the supplied objects,
+// keyed by group/Kind.
+func apis(objs []runtime.Object) (map[string]api, error) { //nolint:gocyclo
+	out := map[string]api{}
+	for _, o := range objs {
+		switch crd := o.(type) {
+		case *extv1.CustomResourceDefinition:
+			a := api{versions: map[string]version{}}
+			for _, v := range crd.Spec.Versions {
+				var s *extv1.JSONSchemaProps
+				if v.Schema != nil {
+					s = v.Schema.OpenAPIV3Schema
+				}
+				a.versions[v.Name] = version{served: v.Served, schema: s}
+			}
+			out[crd.Spec.Group+"/"+crd.Spec.Names.Kind] = a
+		case *extv1beta1.CustomResourceDefinition:
+			a := api{versions: map[string]version{}}
+			vs := crd.Spec.Versions
+			if len(vs) == 0 && crd.Spec.Version != "" {
+				vs = []extv1beta1.CustomResourceDefinitionVersion{{Name: crd.Spec.Version, Served: true}}
+			}
+			for _, v := range vs {
+				var vl *extv1beta1.CustomResourceValidation
+				switch {
+				case v.Schema != nil:
+					vl = v.Schema
+				case crd.Spec.Validation != nil:
+					vl = crd.Spec.Validation
+				}
+				var s *extv1.JSONSchemaProps
+				if vl != nil && vl.OpenAPIV3Schema != nil {
+					s = &extv1.JSONSchemaProps{}
+					if err := convert(vl.OpenAPIV3Schema, s); err != nil {
+						return nil, errors.Wrapf(err, errConvertSchemaFmt, crd.GetName(), v.Name)
+					}
+				}
+				a.versions[v.Name] = version{served: v.Served, schema: s}
+			}
+			out[crd.Spec.Group+"/"+crd.Spec.Names.Kind] = a
+		case *xpv1.CompositeResourceDefinition:
+			a := api{versions: map[string]version{}}
+			for _, v := range crd.Spec.Versions {
+				var s *extv1.JSONSchemaProps
+				if v.Schema != nil && len(v.Schema.OpenAPIV3Schema.Raw) > 0 {
+					s = &extv1.JSONSchemaProps{}
+					if err := json.Unmarshal(v.Schema.OpenAPIV3Schema.Raw, s); err != nil {
+						return nil, errors.Wrapf(err, errConvertSchemaFmt, crd.GetName(), v.Name)
+					}
+				}
+				a.versions[v.Name] = version{served: v.Served, schema: s}
+			}
+			out[crd.Spec.Group+"/"+crd.Spec.Names.Kind] = a
+		}
+	}
+	return out, nil
+}
+
+// convert converts between the JSON compatible supplied types.
+func convert(from, to any) error {
+	b, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, to)
+}
+
+func join(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+func set(s []string) map[string]bool {
+	out := make(map[string]bool, len(s))
+	for _, v := range s {
+		out[v] = true
+	}
+	return out
+}
+
+func enumSet(vals []extv1.JSON) map[string]bool {
+	out := make(map[string]bool, len(vals))
+	for _, v := range vals {
+		out[string(v.Raw)] = true
+	}
+	return out
+}
+
+// keys returns the sorted union of the keys of the supplied maps.
+func keys[V any](maps ...map[string]V) []string {
+	s := map[string]bool{}
+	for _, m := range maps {
+		for k := range m {
+			s[k] = true
+		}
+	}
+	out := make([]string, 0, len(s))
+	for k := range s {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,179 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemadiff
+
+import (
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	xpv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+type crdVersion struct {
+	name   string
+	served bool
+	schema *extv1.JSONSchemaProps
+}
+
+func crd(versions ...crdVersion) *extv1.CustomResourceDefinition {
+	c := &extv1.CustomResourceDefinition{
+		Spec: extv1.CustomResourceDefinitionSpec{
+			Group: "s3.aws.upbound.io",
+			Names: extv1.CustomResourceDefinitionNames{Kind: "Bucket"},
+		},
+	}
+	for _, v := range versions {
+		c.Spec.Versions = append(c.Spec.Versions, extv1.CustomResourceDefinitionVersion{
+			Name:   v.name,
+			Served: v.served,
+			Schema: &extv1.CustomResourceValidation{OpenAPIV3Schema: v.schema},
+		})
+	}
+	return c
+}
+
+func object(required []string, props map[string]extv1.JSONSchemaProps) *extv1.JSONSchemaProps {
+	return &extv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]extv1.JSONSchemaProps{
+			"spec": {Type: "object", Required: required, Properties: props},
+		},
+	}
+}
+
+func enum(vals ...string) []extv1.JSON {
+	out := make([]extv1.JSON, len(vals))
+	for i, v := range vals {
+		out[i] = extv1.JSON{Raw: []byte(`"` + v + `"`)}
+	}
+	return out
+}
+
+func TestCompare(t *testing.T) {
+	const kind = "s3.aws.upbound.io/Bucket"
+	str := extv1.JSONSchemaProps{Type: "string"}
+
+	type args struct {
+		oldObjs []runtime.Object
+		newObjs []runtime.Object
+	}
+	type want struct {
+		changes []Change
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Unchanged": {
+			reason: "Identical APIs should not result in any changes.",
+			args: args{
+				oldObjs: []runtime.Object{crd(crdVersion{"v1", true, object(nil, map[string]extv1.JSONSchemaProps{"region": str})})},
+				newObjs: []runtime.Object{crd(crdVersion{"v1", true, object(nil, map[string]extv1.JSONSchemaProps{"region": str})})},
+			},
+			want: want{changes: []Change{}},
+		},
+		"KindRemoved": {
+			reason: "A removed kind should be a breaking change.",
+			args: args{
+				oldObjs: []runtime.Object{crd(crdVersion{"v1", true, nil})},
+			},
+			want: want{changes: []Change{{Kind: kind, Type: KindRemoved, Breaking: true, Message: "kind removed"}}},
+		},
+		"KindAdded": {
+			reason: "An added XRD should be reported as an added kind.",
+			args: args{
+				newObjs: []runtime.Object{&xpv1.CompositeResourceDefinition{
+					Spec: xpv1.CompositeResourceDefinitionSpec{
+						Group: "platform.example.org",
+						Names: extv1.CustomResourceDefinitionNames{Kind: "XCluster"},
+					},
+				}},
+			},
+			want: want{changes: []Change{{Kind: "platform.example.org/XCluster", Type: KindAdded, Message: "kind added"}}},
+		},
+		"Versions": {
+			reason: "Removed served versions should be breaking, added versions should not.",
+			args: args{
+				oldObjs: []runtime.Object{crd(crdVersion{"v1beta1", true, nil}, crdVersion{"v1beta2", true, nil})},
+				newObjs: []runtime.Object{crd(crdVersion{"v1", true, nil}, crdVersion{"v1beta2", false, nil})},
+			},
+			want: want{changes: []Change{
+				{Kind: kind, Version: "v1", Type: VersionAdded, Message: "version added"},
+				{Kind: kind, Version: "v1beta1", Type: VersionRemoved, Breaking: true, Message: "version removed"},
+				{Kind: kind, Version: "v1beta2", Type: VersionUnserved, Breaking: true, Message: "version no longer served"},
+			}},
+		},
+		"Fields": {
+			reason: "Removed fields, new required fields and type changes should be breaking.",
+			args: args{
+				oldObjs: []runtime.Object{crd(crdVersion{"v1", true, object(nil, map[string]extv1.JSONSchemaProps{
+					"region": str,
+					"acl":    str,
+					"size":   str,
+				})})},
+				newObjs: []runtime.Object{crd(crdVersion{"v1", true, object([]string{"region", "tags"}, map[string]extv1.JSONSchemaProps{
+					"region": str,
+					"size":   {Type: "integer"},
+					"tags":   str,
+					"policy": str,
+				})})},
+			},
+			want: want{changes: []Change{
+				{Kind: kind, Version: "v1", Field: "spec.acl", Type: FieldRemoved, Breaking: true, Message: "field removed"},
+				{Kind: kind, Version: "v1", Field: "spec.policy", Type: FieldAdded, Message: "field added"},
+				{Kind: kind, Version: "v1", Field: "spec.region", Type: RequiredAdded, Breaking: true, Message: "field is now required"},
+				{Kind: kind, Version: "v1", Field: "spec.size", Type: TypeChanged, Breaking: true, Message: `type changed from "string" to "integer"`},
+				{Kind: kind, Version: "v1", Field: "spec.tags", Type: FieldAdded, Breaking: true, Message: "required field added"},
+			}},
+		},
+		"Enums": {
+			reason: "Narrowed enums should be breaking, widened enums should not.",
+			args: args{
+				oldObjs: []runtime.Object{crd(crdVersion{"v1", true, object(nil, map[string]extv1.JSONSchemaProps{
+					"acl":   {Type: "string", Enum: enum("private", "public-read")},
+					"class": {Type: "string", Enum: enum("STANDARD")},
+				})})},
+				newObjs: []runtime.Object{crd(crdVersion{"v1", true, object(nil, map[string]extv1.JSONSchemaProps{
+					"acl":   {Type: "string", Enum: enum("private")},
+					"class": {Type: "string", Enum: enum("STANDARD", "GLACIER")},
+				})})},
+			},
+			want: want{changes: []Change{
+				{Kind: kind, Version: "v1", Field: "spec.acl", Type: EnumNarrowed, Breaking: true, Message: `allowed values narrowed, removed "public-read"`},
+				{Kind: kind, Version: "v1", Field: "spec.class", Type: EnumWidened, Message: `allowed values widened, added "GLACIER"`},
+			}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Compare(tc.args.oldObjs, tc.args.newObjs)
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nCompare(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.changes, got); diff != "" {
+				t.Errorf("\n%s\nCompare(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
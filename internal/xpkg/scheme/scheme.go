@@ -24,6 +24,7 @@ import (
 	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
 	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
 	pkgmetav1alpha1 "github.com/crossplane/crossplane/apis/pkg/meta/v1alpha1"
+	pkgmetav1beta1 "github.com/crossplane/crossplane/apis/pkg/meta/v1beta1"
 )
 
 // BuildMetaScheme builds the default scheme used for identifying metadata in a
@@ -36,6 +37,9 @@ func BuildMetaScheme() (*runtime.Scheme, error) {
 	if err := pkgmetav1.SchemeBuilder.AddToScheme(metaScheme); err != nil {
 		return nil, err
 	}
+	if err := pkgmetav1beta1.SchemeBuilder.AddToScheme(metaScheme); err != nil {
+		return nil, err
+	}
 	return metaScheme, nil
 }
 
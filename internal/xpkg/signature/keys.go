@@ -0,0 +1,154 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	pemPrivateKey           = "PRIVATE KEY"
+	pemECPrivateKey         = "EC PRIVATE KEY"
+	pemRSAPrivateKey        = "RSA PRIVATE KEY"
+	pemPublicKey            = "PUBLIC KEY"
+	pemEncryptedCosignKey   = "ENCRYPTED COSIGN PRIVATE KEY"
+	pemEncryptedSigstoreKey = "ENCRYPTED SIGSTORE PRIVATE KEY"
+
+	kdfScrypt        = "scrypt"
+	cipherSecretbox  = "nacl/secretbox"
+	secretboxKeySize = 32
+	secretboxNonce   = 24
+)
+
+const (
+	errDecodePEM            = "failed to decode PEM block"
+	errUnsupportedPEMFmt    = "unsupported PEM block type %q"
+	errNotASigner           = "private key cannot be used for signing"
+	errDecryptKey           = "failed to decrypt private key, check the password"
+	errUnsupportedKDFFmt    = "unsupported key derivation function %q"
+	errUnsupportedCipherFmt = "unsupported cipher %q"
+	errParseEncryptedKey    = "failed to parse encrypted private key"
+	errInvalidNonceLength   = "invalid nonce length"
+)
+
+// encryptedKey is the format of encrypted cosign private keys.
+type encryptedKey struct {
+	KDF struct {
+		Name   string `json:"name"`
+		Params struct {
+			N int `json:"N"`
+			R int `json:"r"`
+			P int `json:"p"`
+		} `json:"params"`
+		Salt []byte `json:"salt"`
+	} `json:"kdf"`
+	Cipher struct {
+		Name  string `json:"name"`
+		Nonce []byte `json:"nonce"`
+	} `json:"cipher"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// LoadPrivateKey parses the supplied PEM encoded private key. Unencrypted
+// PKCS #8, EC and RSA keys are supported, as well as keys generated with
+// `cosign generate-key-pair`, which are decrypted with the supplied password.
+func LoadPrivateKey(b, password []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New(errDecodePEM)
+	}
+
+	var (
+		key any
+		err error
+	)
+	switch block.Type {
+	case pemPrivateKey:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case pemECPrivateKey:
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case pemRSAPrivateKey:
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case pemEncryptedCosignKey, pemEncryptedSigstoreKey:
+		var der []byte
+		der, err = decrypt(block.Bytes, password)
+		if err != nil {
+			return nil, err
+		}
+		key, err = x509.ParsePKCS8PrivateKey(der)
+	default:
+		return nil, errors.Errorf(errUnsupportedPEMFmt, block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New(errNotASigner)
+	}
+	return s, nil
+}
+
+// LoadPublicKey parses the supplied PEM encoded PKIX public key.
+func LoadPublicKey(b []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New(errDecodePEM)
+	}
+	if block.Type != pemPublicKey {
+		return nil, errors.Errorf(errUnsupportedPEMFmt, block.Type)
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+func decrypt(b, password []byte) ([]byte, error) {
+	k := encryptedKey{}
+	if err := json.Unmarshal(b, &k); err != nil {
+		return nil, errors.Wrap(err, errParseEncryptedKey)
+	}
+	if k.KDF.Name != kdfScrypt {
+		return nil, errors.Errorf(errUnsupportedKDFFmt, k.KDF.Name)
+	}
+	if k.Cipher.Name != cipherSecretbox {
+		return nil, errors.Errorf(errUnsupportedCipherFmt, k.Cipher.Name)
+	}
+	if len(k.Cipher.Nonce) != secretboxNonce {
+		return nil, errors.New(errInvalidNonceLength)
+	}
+
+	dk, err := scrypt.Key(password, k.KDF.Salt, k.KDF.Params.N, k.KDF.Params.R, k.KDF.Params.P, secretboxKeySize)
+	if err != nil {
+		return nil, errors.Wrap(err, errDecryptKey)
+	}
+	var (
+		key   [secretboxKeySize]byte
+		nonce [secretboxNonce]byte
+	)
+	copy(key[:], dk)
+	copy(nonce[:], k.Cipher.Nonce)
+
+	out, ok := secretbox.Open(nil, k.Ciphertext, &nonce, &key)
+	if !ok {
+		return nil, errors.New(errDecryptKey)
+	}
+	return out, nil
+}
@@ -0,0 +1,266 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package signature signs package images and verifies their signatures. The
+// signatures are stored as cosign compatible OCI artifacts, i.e. they can be
+// verified with `cosign verify --key` and vice versa.
+package signature
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+const (
+	// SimpleSigningMediaType is the media type of signature payload layers.
+	SimpleSigningMediaType types.MediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+	// SignatureAnnotation is the layer annotation holding the base64 encoded
+	// signature of the payload.
+	SignatureAnnotation = "dev.cosignproject.cosign/signature"
+
+	signatureTagSuffix = ".sig"
+	payloadType        = "cosign container image signature"
+)
+
+const (
+	errFetchSignatures     = "failed to fetch signatures"
+	errWriteSignature      = "failed to write signature"
+	errSign                = "failed to sign payload"
+	errNoSignaturesFmt     = "no signatures found for %s"
+	errNoValidSignatureFmt = "no valid signature found for %s"
+	errUnsupportedKeyFmt   = "unsupported key type %T"
+	errInvalidSignature    = "invalid signature"
+	errDigestMismatchFmt   = "signature payload is for %s, not %s"
+	errResolveDigestFmt    = "failed to resolve digest of %s"
+)
+
+// Payload is the simple signing payload that is signed for a package image.
+type Payload struct {
+	Critical Critical          `json:"critical"`
+	Optional map[string]string `json:"optional"`
+}
+
+// Critical holds the signed identity of a package image.
+type Critical struct {
+	Identity Identity `json:"identity"`
+	Image    Image    `json:"image"`
+	Type     string   `json:"type"`
+}
+
+// Identity identifies the repository of a package image.
+type Identity struct {
+	DockerReference string `json:"docker-reference"`
+}
+
+// Image identifies the manifest of a package image.
+type Image struct {
+	DockerManifestDigest string `json:"docker-manifest-digest"`
+}
+
+// NewPayload returns the simple signing payload for the supplied digest.
+func NewPayload(d name.Digest) ([]byte, error) {
+	return json.Marshal(Payload{
+		Critical: Critical{
+			Identity: Identity{DockerReference: d.Context().Name()},
+			Image:    Image{DockerManifestDigest: d.DigestStr()},
+			Type:     payloadType,
+		},
+	})
+}
+
+// Tag returns the tag under which signatures for the supplied digest are
+// stored.
+func Tag(d name.Digest) (name.Tag, error) {
+	return name.NewTag(fmt.Sprintf("%s:%s%s", d.Context().Name(), strings.Replace(d.DigestStr(), ":", "-", 1), signatureTagSuffix))
+}
+
+// Sign signs the package image or image index with the supplied digest and
+// stores the signature next to it in the registry. Existing signatures are
+// retained.
+func Sign(ctx context.Context, d name.Digest, key crypto.Signer, opts ...remote.Option) error {
+	payload, err := NewPayload(d)
+	if err != nil {
+		return errors.Wrap(err, errSign)
+	}
+	sig, err := signPayload(key, payload)
+	if err != nil {
+		return errors.Wrap(err, errSign)
+	}
+
+	t, err := Tag(d)
+	if err != nil {
+		return err
+	}
+	opts = append(opts, remote.WithContext(ctx))
+	img, err := signatures(t, opts...)
+	if err != nil {
+		return errors.Wrap(err, errFetchSignatures)
+	}
+	if img == nil {
+		img = mutate.ConfigMediaType(mutate.MediaType(empty.Image, types.OCIManifestSchema1), types.OCIConfigJSON)
+	}
+	img, err = mutate.Append(img, mutate.Addendum{
+		Layer: static.NewLayer(payload, SimpleSigningMediaType),
+		Annotations: map[string]string{
+			SignatureAnnotation: base64.StdEncoding.EncodeToString(sig),
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, errWriteSignature)
+	}
+	return errors.Wrap(remote.Write(t, img, opts...), errWriteSignature)
+}
+
+// ResolveDigest returns the digest the supplied reference points to.
+func ResolveDigest(ctx context.Context, ref name.Reference, opts ...remote.Option) (name.Digest, error) {
+	if d, ok := ref.(name.Digest); ok {
+		return d, nil
+	}
+	desc, err := remote.Head(ref, append(opts, remote.WithContext(ctx))...)
+	if err != nil {
+		return name.Digest{}, errors.Wrapf(err, errResolveDigestFmt, ref.String())
+	}
+	return ref.Context().Digest(desc.Digest.String()), nil
+}
+
+// Verifier verifies package signatures against a public key.
+type Verifier struct {
+	pub  crypto.PublicKey
+	opts []remote.Option
+}
+
+// NewVerifier returns a Verifier that verifies signatures against the
+// supplied public key, fetching them with the supplied options.
+func NewVerifier(pub crypto.PublicKey, opts ...remote.Option) *Verifier {
+	return &Verifier{pub: pub, opts: opts}
+}
+
+// Verify returns an error unless the package image or image index with the
+// supplied digest has at least one valid signature.
+func (v *Verifier) Verify(ctx context.Context, d name.Digest) error {
+	t, err := Tag(d)
+	if err != nil {
+		return err
+	}
+	img, err := signatures(t, append(v.opts, remote.WithContext(ctx))...)
+	if err != nil {
+		return errors.Wrap(err, errFetchSignatures)
+	}
+	if img == nil {
+		return errors.Errorf(errNoSignaturesFmt, d.String())
+	}
+	m, err := img.Manifest()
+	if err != nil {
+		return errors.Wrap(err, errFetchSignatures)
+	}
+	for _, l := range m.Layers {
+		if l.MediaType != SimpleSigningMediaType {
+			continue
+		}
+		if err := v.verifyLayer(img, l, d); err == nil {
+			return nil
+		}
+	}
+	return errors.Errorf(errNoValidSignatureFmt, d.String())
+}
+
+func (v *Verifier) verifyLayer(img v1.Image, desc v1.Descriptor, d name.Digest) error {
+	sig, err := base64.StdEncoding.DecodeString(desc.Annotations[SignatureAnnotation])
+	if err != nil {
+		return err
+	}
+	l, err := img.LayerByDigest(desc.Digest)
+	if err != nil {
+		return err
+	}
+	rc, err := l.Uncompressed()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rc.Close() }()
+	payload, err := io.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+	if err := verifyPayload(v.pub, payload, sig); err != nil {
+		return err
+	}
+	p := Payload{}
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return err
+	}
+	if p.Critical.Image.DockerManifestDigest != d.DigestStr() {
+		return errors.Errorf(errDigestMismatchFmt, p.Critical.Image.DockerManifestDigest, d.DigestStr())
+	}
+	return nil
+}
+
+// signatures returns the signature image with the supplied tag, or nil if it
+// does not exist.
+func signatures(t name.Tag, opts ...remote.Option) (v1.Image, error) {
+	img, err := remote.Image(t, opts...)
+	var terr *transport.Error
+	if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	return img, err
+}
+
+func signPayload(key crypto.Signer, payload []byte) ([]byte, error) {
+	if _, ok := key.(ed25519.PrivateKey); ok {
+		return key.Sign(rand.Reader, payload, crypto.Hash(0))
+	}
+	h := sha256.Sum256(payload)
+	return key.Sign(rand.Reader, h[:], crypto.SHA256)
+}
+
+func verifyPayload(pub crypto.PublicKey, payload, sig []byte) error {
+	h := sha256.Sum256(payload)
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, h[:], sig) {
+			return errors.New(errInvalidSignature)
+		}
+		return nil
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, h[:], sig)
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, payload, sig) {
+			return errors.New(errInvalidSignature)
+		}
+		return nil
+	}
+	return errors.Errorf(errUnsupportedKeyFmt, pub)
+}
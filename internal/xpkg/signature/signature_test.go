@@ -0,0 +1,207 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+func TestSignVerify(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, _ := url.Parse(s.URL)
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	push := func(t *testing.T, repo string) name.Digest {
+		t.Helper()
+		img, _ := random.Image(256, 1)
+		ref, err := name.ParseReference(fmt.Sprintf("%s/%s:v1", u.Host, strings.ToLower(repo)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := remote.Write(ref, img); err != nil {
+			t.Fatal(err)
+		}
+		h, _ := img.Digest()
+		d, _ := name.NewDigest(fmt.Sprintf("%s/%s@%s", u.Host, strings.ToLower(repo), h.String()))
+		return d
+	}
+
+	cases := map[string]struct {
+		reason  string
+		signers []crypto.Signer
+		pub     crypto.PublicKey
+		err     func(d name.Digest) error
+	}{
+		"Signed": {
+			reason:  "A package signed with the private key should be verified.",
+			signers: []crypto.Signer{key},
+			pub:     key.Public(),
+		},
+		"Ed25519": {
+			reason:  "A package signed with an ed25519 key should be verified.",
+			signers: []crypto.Signer{edKey},
+			pub:     edKey.Public(),
+		},
+		"MultipleSigners": {
+			reason:  "A package with multiple signatures should be verified if any of them is valid.",
+			signers: []crypto.Signer{other, key},
+			pub:     key.Public(),
+		},
+		"WrongKey": {
+			reason:  "A package signed with another key should not be verified.",
+			signers: []crypto.Signer{other},
+			pub:     key.Public(),
+			err: func(d name.Digest) error {
+				return errors.Errorf(errNoValidSignatureFmt, d.String())
+			},
+		},
+		"Unsigned": {
+			reason: "A package without signatures should not be verified.",
+			pub:    key.Public(),
+			err: func(d name.Digest) error {
+				return errors.Errorf(errNoSignaturesFmt, d.String())
+			},
+		},
+	}
+
+	for n, tc := range cases {
+		t.Run(n, func(t *testing.T) {
+			d := push(t, n)
+			for _, k := range tc.signers {
+				if err := Sign(context.Background(), d, k); err != nil {
+					t.Fatalf("\n%s\nSign(...): unexpected error: %v", tc.reason, err)
+				}
+			}
+
+			err := NewVerifier(tc.pub).Verify(context.Background(), d)
+
+			var want error
+			if tc.err != nil {
+				want = tc.err(d)
+			}
+			if diff := cmp.Diff(want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nVerify(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestLoadPrivateKey(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	password := []byte("hunter2")
+
+	cases := map[string]struct {
+		reason   string
+		pem      []byte
+		password []byte
+		err      error
+	}{
+		"PKCS8": {
+			reason: "An unencrypted PKCS #8 key should be loaded.",
+			pem:    pem.EncodeToMemory(&pem.Block{Type: pemPrivateKey, Bytes: der}),
+		},
+		"Encrypted": {
+			reason:   "An encrypted cosign key should be loaded with the correct password.",
+			pem:      encryptKey(t, der, password),
+			password: password,
+		},
+		"WrongPassword": {
+			reason:   "An encrypted cosign key should not be loaded with the wrong password.",
+			pem:      encryptKey(t, der, password),
+			password: []byte("wrong"),
+			err:      errors.New(errDecryptKey),
+		},
+		"Unsupported": {
+			reason: "Unsupported PEM blocks should be rejected.",
+			pem:    pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			err:    errors.Errorf(errUnsupportedPEMFmt, "CERTIFICATE"),
+		},
+	}
+
+	for n, tc := range cases {
+		t.Run(n, func(t *testing.T) {
+			got, err := LoadPrivateKey(tc.pem, tc.password)
+
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nLoadPrivateKey(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if tc.err != nil {
+				return
+			}
+			if !key.Equal(got) {
+				t.Errorf("\n%s\nLoadPrivateKey(...): loaded key does not match", tc.reason)
+			}
+		})
+	}
+}
+
+// encryptKey encrypts the supplied PKCS #8 key the way cosign does.
+func encryptKey(t *testing.T, der, password []byte) []byte {
+	t.Helper()
+	k := encryptedKey{}
+	k.KDF.Name = kdfScrypt
+	k.KDF.Params.N, k.KDF.Params.R, k.KDF.Params.P = 32768, 8, 1
+	k.KDF.Salt = make([]byte, 32)
+	k.Cipher.Name = cipherSecretbox
+	k.Cipher.Nonce = make([]byte, secretboxNonce)
+	_, _ = rand.Read(k.KDF.Salt)
+	_, _ = rand.Read(k.Cipher.Nonce)
+
+	dk, err := scrypt.Key(password, k.KDF.Salt, k.KDF.Params.N, k.KDF.Params.R, k.KDF.Params.P, secretboxKeySize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		key   [secretboxKeySize]byte
+		nonce [secretboxNonce]byte
+	)
+	copy(key[:], dk)
+	copy(nonce[:], k.Cipher.Nonce)
+	k.Ciphertext = secretbox.Seal(nil, der, &nonce, &key)
+
+	b, err := json.Marshal(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemEncryptedCosignKey, Bytes: b})
+}
@@ -0,0 +1,347 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
+
+	"github.com/upbound/up/internal/xpkg"
+	"github.com/upbound/up/internal/xpkg/dep/lock"
+	"github.com/upbound/up/internal/xpkg/dep/manager"
+	"github.com/upbound/up/internal/xpkg/snapshot/validator"
+	"github.com/upbound/up/internal/xpkg/workspace/meta"
+)
+
+const (
+	titleChangeAPIVersionFmt = "Change apiVersion to %s"
+	titleAddDependencyFmt    = "Add dependency on %s (%s)"
+	titleChangeVersionFmt    = "Change version constraint to %s"
+	titleAddRequiredFmt      = "Add required fields: %s"
+)
+
+// CodeActions returns the quick fixes for the supplied diagnostics of the file
+// at the supplied URI. Each quick fix is a workspace edit that:
+// - corrects the apiVersion of an object of an unknown GVK to a served version
+// - adds a cached provider that defines an unknown GVK to the dependencies
+// - changes the version constraint of a dependency to an available version
+// - adds the missing required fields of an object with placeholder values
+func (s *Snapshot) CodeActions(ctx context.Context, uri span.URI, diags []protocol.Diagnostic) ([]protocol.CodeAction, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.wsview.FileDetails()[uri]; !ok {
+		return nil, errors.New(errInvalidFileURI)
+	}
+
+	actions := []protocol.CodeAction{}
+	for _, d := range diags {
+		if d.Source != serverName {
+			continue
+		}
+		f, err := s.fieldAt(uri, d.Range.Start)
+		if err != nil {
+			return nil, err
+		}
+		if f == nil {
+			continue
+		}
+		var fixes []protocol.CodeAction
+		switch d.Code {
+		case validator.RuleDefinitionNotFound:
+			fixes = append(s.apiVersionFixes(uri, f), s.dependencyFixes(f)...)
+		case validator.RuleDependencyVersion:
+			fixes = s.versionFixes(ctx, uri, f)
+		case validator.RuleObjectSchema, validator.RuleExampleSchema, validator.RuleCompositionPatches:
+			fixes = s.requiredFieldFixes(uri, f)
+		}
+		for i := range fixes {
+			fixes[i].Kind = protocol.QuickFix
+			fixes[i].Diagnostics = []protocol.Diagnostic{d}
+		}
+		actions = append(actions, fixes...)
+	}
+	return actions, nil
+}
+
+// apiVersionFixes returns quick fixes that change the apiVersion at the
+// supplied field to the served versions of its kind.
+func (s *Snapshot) apiVersionFixes(uri span.URI, f *nodeField) []protocol.CodeAction {
+	gvk, path := f.objectOf()
+	if f.key || len(path) != 1 || path[0].Field != keyAPIVersion {
+		return nil
+	}
+	fixes := []protocol.CodeAction{}
+	for _, v := range s.schemas.servedVersions(gvk.GroupKind()) {
+		if v == gvk {
+			continue
+		}
+		apiVersion := v.GroupVersion().String()
+		fixes = append(fixes, editAction(fmt.Sprintf(titleChangeAPIVersionFmt, apiVersion), uri, protocol.TextEdit{
+			Range:   tokenRange(f.tok),
+			NewText: apiVersion,
+		}))
+	}
+	if len(fixes) > 0 {
+		fixes[0].IsPreferred = true
+	}
+	return fixes
+}
+
+// dependencyFixes returns quick fixes that add the packages in the cache that
+// define the group of the object at the supplied field to the dependencies of
+// the meta file. Groups that are already defined are left alone, as the
+// apiVersion or kind of the object is wrong rather than a dependency missing.
+func (s *Snapshot) dependencyFixes(f *nodeField) []protocol.CodeAction {
+	gvk, _ := f.objectOf()
+	if gvk.Group == "" || s.wsview.Meta() == nil || s.schemas.definesGroup(gvk.Group) {
+		return nil
+	}
+	metaURI := span.URIFromPath(filepath.Join(s.wsview.MetaLocation(), xpkg.MetaFile))
+	details, ok := s.wsview.FileDetails()[metaURI]
+	if !ok {
+		return nil
+	}
+	deps, err := s.wsview.Meta().DependsOn()
+	if err != nil {
+		return nil
+	}
+	entries, err := s.dm.Defining(gvk.Group)
+	if err != nil {
+		return nil
+	}
+
+	vers := map[string][]string{}
+	for _, e := range entries {
+		k := lock.Key(e.Package)
+		vers[k] = append(vers[k], e.Version)
+	}
+	for _, d := range deps {
+		delete(vers, lock.Key(d.Package))
+	}
+	pkgs := make([]string, 0, len(vers))
+	for p := range vers {
+		pkgs = append(pkgs, p)
+	}
+	sort.Strings(pkgs)
+
+	fixes := []protocol.CodeAction{}
+	for _, p := range pkgs {
+		latest := manager.LatestRelease(vers[p])
+		if latest == "" {
+			latest = vers[p][len(vers[p])-1]
+		}
+		d := v1beta1.Dependency{
+			Package:     p,
+			Type:        v1beta1.ProviderPackageType,
+			Constraints: ">=" + latest,
+		}
+		b, err := meta.AddDependency(details.Body, d)
+		if err != nil {
+			return nil
+		}
+		fixes = append(fixes, editAction(fmt.Sprintf(titleAddDependencyFmt, d.Package, d.Constraints), metaURI, fileEdit(details.Body, b)))
+	}
+	return fixes
+}
+
+// versionFixes returns a quick fix that changes the version constraint of the
+// dependency at the supplied field so that it matches the latest version of
+// the package in the cache.
+func (s *Snapshot) versionFixes(ctx context.Context, uri span.URI, f *nodeField) []protocol.CodeAction {
+	if f.node.GetGVK().Group != pkgmetav1.Group || !hasPrefix(f.path, keySpec, keyDependsOn) || len(f.path) < 3 || s.wsview.Meta() == nil {
+		return nil
+	}
+	deps, err := s.wsview.Meta().DependsOn()
+	if err != nil || f.path[2].Type != fieldpath.SegmentIndex || int(f.path[2].Index) >= len(deps) {
+		return nil
+	}
+	d := deps[f.path[2].Index]
+	vers, err := s.dm.Versions(ctx, d)
+	if err != nil {
+		return nil
+	}
+	latest := manager.LatestRelease(vers)
+	if latest == "" {
+		return nil
+	}
+	c := manager.BumpConstraint(d.Constraints, latest)
+	if c == d.Constraints {
+		return nil
+	}
+	body := s.wsview.FileDetails()[uri].Body
+	b, err := meta.SetDependencyVersion(body, d.Package, c)
+	if err != nil {
+		return nil
+	}
+	fix := editAction(fmt.Sprintf(titleChangeVersionFmt, c), uri, fileEdit(body, b))
+	fix.IsPreferred = true
+	return []protocol.CodeAction{fix}
+}
+
+// requiredFieldFixes returns a quick fix that adds the required fields that
+// are missing from the object that the supplied field belongs to, at the
+// level of the supplied field.
+func (s *Snapshot) requiredFieldFixes(uri span.URI, f *nodeField) []protocol.CodeAction {
+	gvk, path := f.objectOf()
+	if !f.key || len(path) == 0 {
+		return nil
+	}
+	sch := schemaAt(s.Schema(gvk), path[:len(path)-1])
+	u, ok := f.node.GetObject().(runtime.Unstructured)
+	if sch == nil || !ok {
+		return nil
+	}
+	var v any = u.UnstructuredContent()
+	if parent := f.path[:len(f.path)-1]; len(parent) > 0 {
+		var err error
+		if v, err = fieldpath.Pave(u.UnstructuredContent()).GetValue(parent.String()); err != nil {
+			return nil
+		}
+	}
+	set, _ := v.(map[string]any)
+
+	missing := []string{}
+	b := &strings.Builder{}
+	indent := strings.Repeat(" ", f.tok.Position.Column-1)
+	for _, r := range sch.Required {
+		if _, ok := set[r]; ok {
+			continue
+		}
+		prop := sch.Properties[r]
+		missing = append(missing, r)
+		fmt.Fprintf(b, "%s: %s\n%s", r, placeholder(&prop), indent)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	// NOTE: the fields are inserted in front of the key of the supplied field
+	// so that they are indented correctly, even if the key follows a sequence
+	// entry indicator.
+	start := tokenRange(f.tok).Start
+	fix := editAction(fmt.Sprintf(titleAddRequiredFmt, strings.Join(missing, ", ")), uri, protocol.TextEdit{
+		Range:   protocol.Range{Start: start, End: start},
+		NewText: b.String(),
+	})
+	fix.IsPreferred = true
+	return []protocol.CodeAction{fix}
+}
+
+// placeholder returns a YAML value for a field of the supplied schema, which
+// is its default, its first allowed value or the zero value of its type.
+func placeholder(sch *spec.Schema) string {
+	var v any
+	switch {
+	case sch.Default != nil:
+		v = sch.Default
+	case len(sch.Enum) > 0:
+		v = sch.Enum[0]
+	case sch.Type.Contains("object"):
+		v = map[string]any{}
+	case sch.Type.Contains("array"):
+		v = []any{}
+	case sch.Type.Contains("integer"), sch.Type.Contains("number"):
+		v = 0
+	case sch.Type.Contains("boolean"):
+		v = false
+	default:
+		v = ""
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return `""`
+	}
+	return string(b)
+}
+
+// servedVersions returns the served versions of the supplied kind, from the
+// most to the least stable.
+func (s *Schemas) servedVersions(gk schema.GroupKind) []schema.GroupVersionKind {
+	gvks := []schema.GroupVersionKind{}
+	for gvk := range s.served {
+		if gvk.GroupKind() == gk {
+			gvks = append(gvks, gvk)
+		}
+	}
+	sort.Slice(gvks, func(i, j int) bool {
+		return version.CompareKubeAwareVersionStrings(gvks[i].Version, gvks[j].Version) > 0
+	})
+	return gvks
+}
+
+// definesGroup returns true if a kind of the supplied group is known.
+func (s *Schemas) definesGroup(group string) bool {
+	for gvk := range s.schemas {
+		if gvk.Group == group {
+			return true
+		}
+	}
+	return false
+}
+
+// editAction returns a code action that applies the supplied edits to the
+// file at the supplied URI.
+func editAction(title string, uri span.URI, edits ...protocol.TextEdit) protocol.CodeAction {
+	return protocol.CodeAction{
+		Title: title,
+		Edit: protocol.WorkspaceEdit{
+			Changes: map[string][]protocol.TextEdit{
+				string(protocol.URIFromSpanURI(uri)): edits,
+			},
+		},
+	}
+}
+
+// fileEdit returns an edit that replaces the lines of the supplied contents of
+// a file that differ from the supplied updated contents.
+func fileEdit(before, after []byte) protocol.TextEdit {
+	ol := strings.SplitAfter(string(before), "\n")
+	nl := strings.SplitAfter(string(after), "\n")
+	p := 0
+	for p < len(ol) && p < len(nl) && ol[p] == nl[p] {
+		p++
+	}
+	q := 0
+	for q < len(ol)-p && q < len(nl)-p && ol[len(ol)-1-q] == nl[len(nl)-1-q] {
+		q++
+	}
+
+	end := protocol.Position{Line: uint32(len(ol) - q)}
+	if last := len(ol) - 1 - q; last >= p && !strings.HasSuffix(ol[last], "\n") {
+		// the last replaced line is not terminated, so the edit ends at
+		// the end of the file rather than at the start of the next line.
+		end = protocol.Position{Line: uint32(last), Character: uint32(len(utf16.Encode([]rune(ol[last]))))}
+	}
+	return protocol.TextEdit{
+		Range:   protocol.Range{Start: protocol.Position{Line: uint32(p)}, End: end},
+		NewText: strings.Join(nl[p:len(nl)-q], ""),
+	}
+}
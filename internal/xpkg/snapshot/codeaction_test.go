@@ -0,0 +1,211 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/xpkg/dep/cache"
+	"github.com/upbound/up/internal/xpkg/snapshot/validator"
+)
+
+var testMeta = `apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: getting-started
+spec:
+  dependsOn:
+  - provider: xpkg.upbound.io/upbound/provider-aws-s3
+    version: "v2.0.0"
+`
+
+func TestCodeActions(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		file   string
+		body   string
+		diag   protocol.Diagnostic
+		dm     *MockDepManager
+		want   []protocol.CodeAction
+	}{
+		"APIVersion": {
+			reason: "An apiVersion that is not served should be changed to a served version of its kind.",
+			file:   "examples/claim.yaml",
+			body:   "apiVersion: example.org/v1\nkind: CertificateClaim\n",
+			diag: protocol.Diagnostic{
+				Range:  rng(0, 12, 26),
+				Source: serverName,
+				Code:   validator.RuleDefinitionNotFound,
+			},
+			want: []protocol.CodeAction{{
+				Title:       "Change apiVersion to example.org/v1alpha1",
+				IsPreferred: true,
+				Edit: protocol.WorkspaceEdit{Changes: map[string][]protocol.TextEdit{
+					"file:///ws/examples/claim.yaml": {{Range: rng(0, 12, 26), NewText: "example.org/v1alpha1"}},
+				}},
+			}},
+		},
+		"AddDependency": {
+			reason: "A cached provider that defines the group of an unknown composed resource should be added to the dependencies.",
+			file:   "composition.yaml",
+			body:   testComposition,
+			diag: protocol.Diagnostic{
+				Range:  rng(13, 18, 43),
+				Source: serverName,
+				Code:   validator.RuleDefinitionNotFound,
+			},
+			dm: &MockDepManager{entries: []cache.Entry{
+				{Package: "xpkg.upbound.io/upbound/provider-aws-s3", Version: "v1.0.0"},
+				{Package: "xpkg.upbound.io/upbound/provider-family-aws", Version: "v1.2.0"},
+				{Package: "xpkg.upbound.io/upbound/provider-family-aws", Version: "v1.10.0"},
+			}},
+			want: []protocol.CodeAction{{
+				Title: "Add dependency on xpkg.upbound.io/upbound/provider-family-aws (>=v1.10.0)",
+				Edit: protocol.WorkspaceEdit{Changes: map[string][]protocol.TextEdit{
+					"file:///ws/crossplane.yaml": {{
+						Range:   rng(8, 0, 0),
+						NewText: "  - provider: xpkg.upbound.io/upbound/provider-family-aws\n    version: \">=v1.10.0\"\n",
+					}},
+				}},
+			}},
+		},
+		"DependencyVersion": {
+			reason: "A version constraint that no version satisfies should be changed to the latest version.",
+			file:   "crossplane.yaml",
+			body:   testMeta,
+			diag: protocol.Diagnostic{
+				Range:  rng(7, 13, 21),
+				Source: serverName,
+				Code:   validator.RuleDependencyVersion,
+			},
+			dm: &MockDepManager{versions: []string{"v1.0.0", "v1.1.0", "v2.0.0-rc.1"}},
+			want: []protocol.CodeAction{{
+				Title:       "Change version constraint to v1.1.0",
+				IsPreferred: true,
+				Edit: protocol.WorkspaceEdit{Changes: map[string][]protocol.TextEdit{
+					"file:///ws/crossplane.yaml": {{
+						Range:   protocol.Range{Start: protocol.Position{Line: 7}, End: protocol.Position{Line: 8}},
+						NewText: "    version: \"v1.1.0\"\n",
+					}},
+				}},
+			}},
+		},
+		"RequiredFields": {
+			reason: "The missing required fields of an object should be added with placeholder values.",
+			file:   "examples/claim.yaml",
+			body:   "apiVersion: example.org/v1alpha1\nkind: CertificateClaim\nspec:\n  validation: DNS\n",
+			diag: protocol.Diagnostic{
+				Range:  rng(3, 12, 13),
+				Source: serverName,
+				Code:   validator.RuleExampleSchema,
+			},
+			want: []protocol.CodeAction{{
+				Title:       "Add required fields: domain",
+				IsPreferred: true,
+				Edit: protocol.WorkspaceEdit{Changes: map[string][]protocol.TextEdit{
+					"file:///ws/examples/claim.yaml": {{Range: rng(3, 2, 2), NewText: "domain: \"\"\n  "}},
+				}},
+			}},
+		},
+		"OtherSource": {
+			reason: "Diagnostics that were not published by the server should be ignored.",
+			file:   "examples/claim.yaml",
+			body:   "apiVersion: example.org/v1\nkind: CertificateClaim\n",
+			diag: protocol.Diagnostic{
+				Range:  rng(0, 12, 26),
+				Source: "yaml",
+				Code:   validator.RuleDefinitionNotFound,
+			},
+			want: []protocol.CodeAction{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			files := map[string][]byte{
+				"crd.yaml": testSingleVersionCRD,
+				"xrd.yaml": testXRD,
+				tc.file:    []byte(tc.body),
+			}
+			if _, ok := files["crossplane.yaml"]; !ok {
+				files["crossplane.yaml"] = []byte(testMeta)
+			}
+			s := newTestSnapshot(t, files)
+			if tc.dm != nil {
+				s.dm = tc.dm
+			}
+
+			for i := range tc.want {
+				tc.want[i].Kind = protocol.QuickFix
+				tc.want[i].Diagnostics = []protocol.Diagnostic{tc.diag}
+			}
+			got, err := s.CodeActions(context.Background(), span.URIFromPath("/ws/"+tc.file), []protocol.Diagnostic{tc.diag})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nCodeActions(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFileEdit(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		before string
+		after  string
+		want   protocol.TextEdit
+	}{
+		"Insert": {
+			reason: "Inserted lines should be added without replacing their neighbours.",
+			before: "a\nc\n",
+			after:  "a\nb\nc\n",
+			want:   protocol.TextEdit{Range: rng(1, 0, 0), NewText: "b\n"},
+		},
+		"Replace": {
+			reason: "Changed lines should be replaced.",
+			before: "a\nb\nc\n",
+			after:  "a\nB\nc\n",
+			want: protocol.TextEdit{
+				Range:   protocol.Range{Start: protocol.Position{Line: 1}, End: protocol.Position{Line: 2}},
+				NewText: "B\n",
+			},
+		},
+		"UnterminatedLastLine": {
+			reason: "An edit of an unterminated last line should end at the end of the file.",
+			before: "a\nb",
+			after:  "a\nb\nc\n",
+			want: protocol.TextEdit{
+				Range:   protocol.Range{Start: protocol.Position{Line: 1}, End: protocol.Position{Line: 1, Character: 1}},
+				NewText: "b\nc\n",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := fileEdit([]byte(tc.before), []byte(tc.after))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nfileEdit(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
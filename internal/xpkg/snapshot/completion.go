@@ -0,0 +1,362 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+const (
+	keyBase             = "base"
+	keyCombine          = "combine"
+	keyCompositeTypeRef = "compositeTypeRef"
+	keyFromFieldPath    = "fromFieldPath"
+	keyPatches          = "patches"
+	keyPatchSets        = "patchSets"
+	keyResources        = "resources"
+	keySpec             = "spec"
+	keyToFieldPath      = "toFieldPath"
+	keyType             = "type"
+	keyVariables        = "variables"
+)
+
+// Complete returns the completion items at the supplied position of the file
+// at the supplied URI. Items are derived from the schemas of the CRDs and XRDs
+// of the workspace and its dependencies.
+func (s *Snapshot) Complete(_ context.Context, uri span.URI, pos protocol.Position) (*protocol.CompletionList, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	details, ok := s.wsview.FileDetails()[uri]
+	if !ok {
+		return nil, errors.New(errInvalidFileURI)
+	}
+
+	list := &protocol.CompletionList{Items: []protocol.CompletionItem{}}
+	d, c := parseDocument(details.Body, pos)
+	if c == nil {
+		return list, nil
+	}
+	list.Items = append(list.Items, s.complete(d, c)...)
+	return list, nil
+}
+
+func (s *Snapshot) complete(d *document, c *cursor) []protocol.CompletionItem { // nolint:gocyclo
+	gvk, path, kinds := d.gvk(nil), c.path, s.gvks()
+
+	if isComposition(gvk) {
+		switch {
+		case hasPrefix(path, keySpec, keyCompositeTypeRef) && len(path) == 3:
+			kinds = s.composites()
+			path = path[2:]
+		case isResource(path) && len(path) > 3 && path[3].Field == keyBase:
+			gvk, path, kinds = d.gvk(path[:4]), path[4:], s.schemaGVKs()
+		case c.value && isPatchPath(path):
+			return fieldPathItems(s.patchSchema(d, path), c)
+		}
+	}
+
+	if c.value && len(path) == 1 {
+		obj := c.path[: len(c.path)-1 : len(c.path)-1]
+		switch path[0].Field {
+		case keyAPIVersion:
+			return apiVersionItems(kinds, d.values[append(obj, fieldpath.Field(keyKind)).String()], c)
+		case keyKind:
+			return kindItems(kinds, d.values[append(obj, fieldpath.Field(keyAPIVersion)).String()], c)
+		}
+	}
+
+	sch := schemaAt(s.Schema(gvk), path)
+	if c.value {
+		return valueItems(sch, c)
+	}
+	return keyItems(sch, d.keys[c.path.String()], c)
+}
+
+// gvks returns the GVKs known to the Snapshot.
+func (s *Snapshot) gvks() []schema.GroupVersionKind {
+	gvks := make([]schema.GroupVersionKind, 0, len(s.validators))
+	for gvk := range s.validators {
+		gvks = append(gvks, gvk)
+	}
+	return gvks
+}
+
+// schemaGVKs returns the GVKs of the CRDs and XRDs known to the Snapshot.
+func (s *Snapshot) schemaGVKs() []schema.GroupVersionKind {
+	gvks := make([]schema.GroupVersionKind, 0, len(s.schemas.schemas))
+	for gvk := range s.schemas.schemas {
+		gvks = append(gvks, gvk)
+	}
+	return gvks
+}
+
+// composites returns the GVKs of the composite resources known to the
+// Snapshot.
+func (s *Snapshot) composites() []schema.GroupVersionKind {
+	gvks := make([]schema.GroupVersionKind, 0, len(s.schemas.composites))
+	for gvk := range s.schemas.composites {
+		gvks = append(gvks, gvk)
+	}
+	return gvks
+}
+
+// patchSchema returns the schema that the field path of the Composition patch
+// at the supplied path refers to, i.e. either the schema of the composite
+// resource or of the composed resource. It returns nil if the schema is not
+// known.
+func (s *Snapshot) patchSchema(d *document, path fieldpath.Segments) *spec.Schema {
+	t := xpextv1.PatchType(d.values[append(path[:5:5], fieldpath.Field(keyType)).String()])
+	if t == "" {
+		t = xpextv1.PatchTypeFromCompositeFieldPath
+	}
+
+	var composite bool
+	switch t { // nolint:exhaustive
+	case xpextv1.PatchTypeFromCompositeFieldPath, xpextv1.PatchTypeCombineFromComposite:
+		composite = path[len(path)-1].Field == keyFromFieldPath
+	case xpextv1.PatchTypeToCompositeFieldPath, xpextv1.PatchTypeCombineToComposite:
+		composite = path[len(path)-1].Field == keyToFieldPath
+	default:
+		// field paths of other patch types refer to the environment.
+		return nil
+	}
+
+	if composite {
+		return s.Schema(d.gvk(fieldpath.Segments{fieldpath.Field(keySpec), fieldpath.Field(keyCompositeTypeRef)}))
+	}
+	if path[1].Field != keyResources {
+		// the composed resources of patch sets are not known.
+		return nil
+	}
+	return s.Schema(d.gvk(append(path[:3:3], fieldpath.Field(keyBase))))
+}
+
+// isComposition returns true if the supplied GVK is the GVK of a Composition.
+func isComposition(gvk schema.GroupVersionKind) bool {
+	return gvk.Group == xpextv1.Group && gvk.Kind == xpextv1.CompositionKind
+}
+
+// isResource returns true if the supplied path is within a composed resource
+// of a Composition, i.e. spec.resources[i].
+func isResource(path fieldpath.Segments) bool {
+	return hasPrefix(path, keySpec, keyResources) && len(path) > 2 && path[2].Type == fieldpath.SegmentIndex
+}
+
+// isPatchPath returns true if the supplied path is the fromFieldPath or
+// toFieldPath of a patch of a Composition, or the fromFieldPath of a variable
+// of a combine patch.
+func isPatchPath(path fieldpath.Segments) bool {
+	if !hasPrefix(path, keySpec, keyResources) && !hasPrefix(path, keySpec, keyPatchSets) {
+		return false
+	}
+	if len(path) < 6 || path[2].Type != fieldpath.SegmentIndex || path[3].Field != keyPatches || path[4].Type != fieldpath.SegmentIndex {
+		return false
+	}
+	switch rest := path[5:]; len(rest) {
+	case 1:
+		return rest[0].Field == keyFromFieldPath || rest[0].Field == keyToFieldPath
+	case 4:
+		return rest[0].Field == keyCombine && rest[1].Field == keyVariables && rest[2].Type == fieldpath.SegmentIndex && rest[3].Field == keyFromFieldPath
+	}
+	return false
+}
+
+// hasPrefix returns true if the supplied path starts with the supplied fields.
+func hasPrefix(path fieldpath.Segments, fields ...string) bool {
+	if len(path) < len(fields) {
+		return false
+	}
+	for i, f := range fields {
+		if path[i].Type != fieldpath.SegmentField || path[i].Field != f {
+			return false
+		}
+	}
+	return true
+}
+
+// apiVersionItems returns the API versions of the supplied GVKs, limited to
+// those of the supplied kind if one is specified.
+func apiVersionItems(gvks []schema.GroupVersionKind, kind string, c *cursor) []protocol.CompletionItem {
+	versions := map[string]bool{}
+	for _, gvk := range gvks {
+		if kind == "" || gvk.Kind == kind {
+			versions[gvk.GroupVersion().String()] = true
+		}
+	}
+	items := make([]protocol.CompletionItem, 0, len(versions))
+	for v := range versions {
+		items = append(items, item(v, protocol.ModuleCompletion, v, c))
+	}
+	return sortItems(items)
+}
+
+// kindItems returns the kinds of the supplied GVKs, limited to those of the
+// supplied API version if one is specified.
+func kindItems(gvks []schema.GroupVersionKind, apiVersion string, c *cursor) []protocol.CompletionItem {
+	kinds := map[string][]string{}
+	for _, gvk := range gvks {
+		if apiVersion == "" || gvk.GroupVersion().String() == apiVersion {
+			kinds[gvk.Kind] = append(kinds[gvk.Kind], gvk.GroupVersion().String())
+		}
+	}
+	items := make([]protocol.CompletionItem, 0, len(kinds))
+	for k, versions := range kinds {
+		sort.Strings(versions)
+		i := item(k, protocol.ClassCompletion, k, c)
+		i.Detail = strings.Join(versions, ", ")
+		items = append(items, i)
+	}
+	return sortItems(items)
+}
+
+// keyItems returns the properties of the supplied schema, except for the
+// supplied keys that are already specified.
+func keyItems(sch *spec.Schema, specified []string, c *cursor) []protocol.CompletionItem {
+	if sch == nil {
+		return nil
+	}
+	skip := map[string]bool{}
+	for _, k := range specified {
+		skip[k] = true
+	}
+	required := map[string]bool{}
+	for _, r := range sch.Required {
+		required[r] = true
+	}
+	items := make([]protocol.CompletionItem, 0, len(sch.Properties))
+	for name, p := range sch.Properties {
+		if skip[name] {
+			continue
+		}
+		insert := name + ": "
+		if p.Type.Contains("object") || p.Type.Contains("array") {
+			insert = name + ":"
+		}
+		i := item(name, protocol.FieldCompletion, insert, c)
+		i.Detail = typeOf(&p)
+		i.Documentation = p.Description
+		// list required properties first.
+		i.SortText = "1" + name
+		if required[name] {
+			i.Detail += " (required)"
+			i.SortText = "0" + name
+		}
+		items = append(items, i)
+	}
+	return sortItems(items)
+}
+
+// valueItems returns the enum values of the supplied schema, or the boolean
+// values if it is a boolean.
+func valueItems(sch *spec.Schema, c *cursor) []protocol.CompletionItem {
+	if sch == nil {
+		return nil
+	}
+	values := []string{}
+	for _, e := range sch.Enum {
+		values = append(values, fmt.Sprint(e))
+	}
+	if len(values) == 0 && sch.Type.Contains("boolean") {
+		values = []string{"true", "false"}
+	}
+	items := make([]protocol.CompletionItem, 0, len(values))
+	for _, v := range values {
+		i := item(v, protocol.EnumMemberCompletion, v, c)
+		if sch.Default != nil && fmt.Sprint(sch.Default) == v {
+			i.Detail = "default"
+		}
+		items = append(items, i)
+	}
+	return sortItems(items)
+}
+
+// fieldPathItems returns the properties of the supplied schema at the field
+// path preceding the last segment of the cursor's prefix. Only the last
+// segment is replaced by the items.
+func fieldPathItems(sch *spec.Schema, c *cursor) []protocol.CompletionItem {
+	if sch == nil {
+		return nil
+	}
+	parent := fieldpath.Segments{}
+	last := c.prefix
+	if i := strings.LastIndex(c.prefix, "."); i >= 0 {
+		p, err := fieldpath.Parse(c.prefix[:i])
+		if err != nil {
+			return nil
+		}
+		parent, last = p, c.prefix[i+1:]
+	}
+	sch = schemaAt(sch, parent)
+	if sch == nil {
+		return nil
+	}
+
+	lc := *c
+	lc.rng.Start.Character = c.rng.End.Character - character(last, len(last))
+	items := make([]protocol.CompletionItem, 0, len(sch.Properties))
+	for name, p := range sch.Properties {
+		i := item(name, protocol.FieldCompletion, name, &lc)
+		i.Detail = typeOf(&p)
+		i.Documentation = p.Description
+		items = append(items, i)
+	}
+	return sortItems(items)
+}
+
+// item returns a completion item with the supplied label that replaces the
+// prefix of the cursor with the supplied text.
+func item(label string, kind protocol.CompletionItemKind, text string, c *cursor) protocol.CompletionItem {
+	return protocol.CompletionItem{
+		Label: label,
+		Kind:  kind,
+		TextEdit: &protocol.TextEdit{
+			Range:   c.rng,
+			NewText: text,
+		},
+	}
+}
+
+// typeOf returns a short description of the type of the supplied schema.
+func typeOf(sch *spec.Schema) string {
+	switch {
+	case sch == nil, len(sch.Type) == 0:
+		return ""
+	case sch.Type.Contains("array") && sch.Items != nil:
+		return "[]" + typeOf(sch.Items.Schema)
+	}
+	return sch.Type[0]
+}
+
+func sortItems(items []protocol.CompletionItem) []protocol.CompletionItem {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].SortText != items[j].SortText {
+			return items[i].SortText < items[j].SortText
+		}
+		return items[i].Label < items[j].Label
+	})
+	return items
+}
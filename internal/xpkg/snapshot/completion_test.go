@@ -0,0 +1,259 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/xpkg/workspace"
+)
+
+var testXRD = []byte(`apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xcertificates.example.org
+spec:
+  group: example.org
+  names:
+    kind: XCertificate
+    plural: xcertificates
+  claimNames:
+    kind: CertificateClaim
+    plural: certificateclaims
+  versions:
+  - name: v1alpha1
+    served: true
+    referenceable: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              domain:
+                type: string
+                description: Domain of the certificate.
+              validation:
+                type: string
+                enum: [DNS, EMAIL]
+            required: [domain]
+`)
+
+func TestComplete(t *testing.T) {
+	type want struct {
+		labels []string
+		edit   *protocol.TextEdit
+		err    error
+	}
+	cases := map[string]struct {
+		reason string
+		// body of the composition, with the position marked by a |.
+		body string
+		file string
+		want want
+	}{
+		"APIVersion": {
+			reason: "The API versions of the kind of the object should be completed.",
+			body:   "apiVersion: |\nkind: Certificate\n",
+			want: want{
+				labels: []string{"acm.aws.crossplane.io/v1alpha1"},
+				edit:   &protocol.TextEdit{Range: rng(0, 12, 12), NewText: "acm.aws.crossplane.io/v1alpha1"},
+			},
+		},
+		"CompositeTypeRef": {
+			reason: "Only the kinds of composite resources should be completed for the composite type of a Composition.",
+			body: `apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+spec:
+  compositeTypeRef:
+    apiVersion: example.org/v1alpha1
+    kind: X|
+`,
+			want: want{
+				labels: []string{"XCertificate"},
+				edit:   &protocol.TextEdit{Range: rng(5, 10, 11), NewText: "XCertificate"},
+			},
+		},
+		"BaseKind": {
+			reason: "The kinds of the API version of a composed resource should be completed.",
+			body: `apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+spec:
+  resources:
+  - base:
+      apiVersion: example.org/v1alpha1
+      kind: |
+`,
+			want: want{
+				labels: []string{"CertificateClaim", "XCertificate"},
+			},
+		},
+		"BaseFields": {
+			reason: "The fields of a composed resource should be completed from its schema, except for those that are specified.",
+			body: `apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+spec:
+  resources:
+  - base:
+      apiVersion: acm.aws.crossplane.io/v1alpha1
+      kind: Certificate
+      spec:
+        forProvider:
+          domainName: example.org
+        |
+`,
+			want: want{
+				labels: []string{"deletionPolicy", "providerConfigRef", "providerRef", "writeConnectionSecretToRef"},
+			},
+		},
+		"RequiredFieldsFirst": {
+			reason: "Required fields should be completed first, with a space following the key of a scalar.",
+			body: `apiVersion: example.org/v1alpha1
+kind: CertificateClaim
+spec:
+  v|
+`,
+			want: want{
+				labels: []string{"domain", "compositionRef", "compositionRevisionRef", "compositionSelector", "compositionUpdatePolicy", "resourceRef", "validation", "writeConnectionSecretToRef"},
+				edit:   &protocol.TextEdit{Range: rng(3, 2, 3), NewText: "domain: "},
+			},
+		},
+		"Enum": {
+			reason: "The values of an enum should be completed.",
+			body: `apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+spec:
+  resources:
+  - base:
+      apiVersion: acm.aws.crossplane.io/v1alpha1
+      kind: Certificate
+      spec:
+        deletionPolicy: |
+`,
+			want: want{
+				labels: []string{"Delete", "Orphan"},
+			},
+		},
+		"FromCompositeFieldPath": {
+			reason: "The fromFieldPath of a patch should be completed from the schema of the composite resource.",
+			body: `apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+spec:
+  compositeTypeRef:
+    apiVersion: example.org/v1alpha1
+    kind: XCertificate
+  resources:
+  - base:
+      apiVersion: acm.aws.crossplane.io/v1alpha1
+      kind: Certificate
+    patches:
+    - fromFieldPath: spec.d|
+      toFieldPath: spec.forProvider.domainName
+`,
+			want: want{
+				labels: []string{"compositionRef", "compositionRevisionRef", "compositionSelector", "compositionUpdatePolicy", "domain", "resourceRef", "validation", "writeConnectionSecretToRef"},
+				edit:   &protocol.TextEdit{Range: rng(11, 26, 27), NewText: "compositionRef"},
+			},
+		},
+		"ToCompositeFieldPath": {
+			reason: "The fromFieldPath of a ToCompositeFieldPath patch should be completed from the schema of the composed resource.",
+			body: `apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+spec:
+  compositeTypeRef:
+    apiVersion: example.org/v1alpha1
+    kind: XCertificate
+  resources:
+  - base:
+      apiVersion: acm.aws.crossplane.io/v1alpha1
+      kind: Certificate
+    patches:
+    - type: ToCompositeFieldPath
+      fromFieldPath: spec.providerConfigRef.|
+`,
+			want: want{
+				labels: []string{"name"},
+			},
+		},
+		"UnknownFile": {
+			reason: "An error should be returned for files that are not in the workspace.",
+			file:   "/ws/other.yaml",
+			want: want{
+				err: errors.New(errInvalidFileURI),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			lines := strings.Split(tc.body, "\n")
+			pos := protocol.Position{}
+			for i, l := range lines {
+				if c := strings.Index(l, "|"); c >= 0 {
+					pos = protocol.Position{Line: uint32(i), Character: uint32(c)}
+					lines[i] = l[:c] + l[c+1:]
+				}
+			}
+
+			fs := afero.NewMemMapFs()
+			_ = fs.Mkdir("/ws", os.ModePerm)
+			_ = afero.WriteFile(fs, "/ws/crd.yaml", testSingleVersionCRD, os.ModePerm)
+			_ = afero.WriteFile(fs, "/ws/xrd.yaml", testXRD, os.ModePerm)
+			_ = afero.WriteFile(fs, "/ws/composition.yaml", []byte(strings.Join(lines, "\n")), os.ModePerm)
+			ws, _ := workspace.New("/ws", workspace.WithFS(fs), workspace.WithPermissiveParser())
+			factory, _ := NewFactory("/ws", WithDepManager(NewMockDepManager()))
+			snap, err := factory.New(context.Background(), WithWorkspace(ws))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			file := "/ws/composition.yaml"
+			if tc.file != "" {
+				file = tc.file
+			}
+			list, err := snap.Complete(context.Background(), span.URIFromPath(file), pos)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nComplete(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			labels := []string{}
+			for _, i := range list.Items {
+				labels = append(labels, i.Label)
+			}
+			if diff := cmp.Diff(tc.want.labels, labels); diff != "" {
+				t.Errorf("\n%s\nComplete(...): -want labels, +got labels:\n%s", tc.reason, diff)
+			}
+			if tc.want.edit == nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.edit, list.Items[0].TextEdit); diff != "" {
+				t.Errorf("\n%s\nComplete(...): -want edit, +got edit:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -85,6 +85,7 @@ func (c *CompositionValidator) Validate(ctx context.Context, data any) *validate
 			TypeCode: validator.ErrorTypeCode,
 			Message:  err.Error(),
 			Name:     resources,
+			Rule:     validator.RuleCompositionResources,
 		}
 		errs = append(errs, ie)
 	}
@@ -165,6 +166,7 @@ func (p *PatchesValidator) validate(ctx context.Context, idx int, cd resource.Co
 				TypeCode: ve.Code(),
 				Message:  fmt.Sprintf(errFmt, ve.Error(), cdgvk),
 				Name:     fmt.Sprintf(resourceBaseFmt, idx, ve.Name),
+				Rule:     validator.RuleCompositionPatches,
 			}
 			errs = append(errs, ie)
 		}
@@ -84,6 +84,7 @@ func TestCompositionValidation(t *testing.T) {
 							TypeCode: validator.WarningTypeCode,
 							Message:  "no definition found for resource (database.aws.crossplane.io/v1beta1, Kind=RDSInstance)",
 							Name:     "spec.resources[0].base.apiVersion",
+							Rule:     validator.RuleDefinitionNotFound,
 						},
 					},
 				},
@@ -132,6 +133,7 @@ func TestCompositionValidation(t *testing.T) {
 							TypeCode: 602,
 							Message:  "spec.writeConnectionSecretToRef.name in body is required (acm.aws.crossplane.io/v1alpha1, Kind=Certificate)",
 							Name:     "spec.resources[0].base.spec.writeConnectionSecretToRef.name",
+							Rule:     validator.RuleCompositionPatches,
 						},
 					},
 				},
@@ -333,6 +335,7 @@ func TestCompositionValidation(t *testing.T) {
 							TypeCode: validator.ErrorTypeCode,
 							Message:  "spec.resources[1].name: Required value: cannot mix named and anonymous resources, all resources must have a name or none must have a name",
 							Name:     "spec.resources",
+							Rule:     validator.RuleCompositionResources,
 						},
 					},
 				},
@@ -369,6 +372,7 @@ func TestCompositionValidation(t *testing.T) {
 							TypeCode: validator.ErrorTypeCode,
 							Message:  `spec.resources[1].name: Duplicate value: "r1"`,
 							Name:     "spec.resources",
+							Rule:     validator.RuleCompositionResources,
 						},
 					},
 				},
@@ -0,0 +1,303 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	extv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	"github.com/upbound/up/internal/xpkg/workspace"
+)
+
+const (
+	crdKind = "CustomResourceDefinition"
+
+	errCachedDefinitionPath = "failed to find path of cached definition"
+
+	pathKind         = "$.kind"
+	pathMetadataName = "$.metadata.name"
+)
+
+// Definition returns the location of the CRD or XRD that defines the type
+// referenced at the supplied position of the file at the supplied URI. Types
+// are referenced by the apiVersion and kind of objects, of composed resources
+// and of the composite type of Compositions. Types that are not defined in the
+// workspace are looked up in the package cache. The returned slice is empty
+// if there is no type at the position or if its definition cannot be found.
+func (s *Snapshot) Definition(_ context.Context, uri span.URI, pos protocol.Position) ([]protocol.Location, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f, err := s.fieldAt(uri, pos)
+	if err != nil || f == nil {
+		return nil, err
+	}
+	gvk, ok := f.typeRef()
+	if !ok {
+		return nil, nil
+	}
+	if n := s.definitionNode(gvk.GroupKind()); n != nil {
+		return []protocol.Location{nodeLocation(n, pathMetadataName)}, nil
+	}
+	if path := s.cachedDefinition(gvk.GroupKind()); path != "" {
+		return []protocol.Location{{URI: protocol.DocumentURI(span.URIFromPath(path))}}, nil
+	}
+	return nil, nil
+}
+
+// References returns the locations of the Compositions, composed resources,
+// claims and examples that refer to the types defined by the workspace XRD or
+// CRD at the supplied position of the file at the supplied URI, or to the type
+// referenced at the position. The location of the definition itself is only
+// included if includeDecl is true.
+func (s *Snapshot) References(_ context.Context, uri span.URI, pos protocol.Position, includeDecl bool) ([]protocol.Location, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f, err := s.fieldAt(uri, pos)
+	if err != nil || f == nil {
+		return nil, err
+	}
+	def := f.node
+	if _, gks := definedKinds(def.GetObject()); len(gks) == 0 {
+		gvk, ok := f.typeRef()
+		if !ok {
+			return nil, nil
+		}
+		if def = s.definitionNode(gvk.GroupKind()); def == nil {
+			return nil, nil
+		}
+	}
+
+	_, gks := definedKinds(def.GetObject())
+	locs := s.referencesTo(gks)
+	if includeDecl {
+		locs = append([]protocol.Location{nodeLocation(def, pathMetadataName)}, locs...)
+	}
+	return locs, nil
+}
+
+// definitionNode returns the workspace node of the CRD or XRD that defines
+// the supplied kind, or nil if it is not defined in the workspace.
+func (s *Snapshot) definitionNode(gk schema.GroupKind) workspace.Node {
+	for _, details := range s.wsview.FileDetails() {
+		for id := range details.NodeIDs {
+			n, ok := s.wsview.Nodes()[id]
+			if !ok {
+				continue
+			}
+			if _, gks := definedKinds(n.GetObject()); containsKind(gks, gk) {
+				return n
+			}
+		}
+	}
+	return nil
+}
+
+// cachedDefinition returns the path of the file in the package cache that
+// holds the CRD or XRD of a dependency that defines the supplied kind, or an
+// empty string if no dependency defines it.
+func (s *Snapshot) cachedDefinition(gk schema.GroupKind) string {
+	for _, p := range s.packages {
+		for _, o := range p.Objects() {
+			name, gks := definedKinds(o)
+			if !containsKind(gks, gk) {
+				continue
+			}
+			path, err := s.dm.ObjectPath(v1beta1.Dependency{
+				Package:     p.Name(),
+				Type:        p.Type(),
+				Constraints: p.Version(),
+			}, name)
+			if err != nil {
+				s.log.Debug(errCachedDefinitionPath, "error", err)
+				return ""
+			}
+			return path
+		}
+	}
+	return ""
+}
+
+// referencesTo returns the locations of the workspace objects that refer to
+// any of the supplied kinds, sorted by file and line.
+func (s *Snapshot) referencesTo(gks []schema.GroupKind) []protocol.Location {
+	locs := make([]protocol.Location, 0)
+	for _, n := range s.rootNodes() {
+		if containsKind(gks, n.GetGVK().GroupKind()) {
+			locs = append(locs, nodeLocation(n, pathKind))
+		}
+		if isComposition(n.GetGVK()) {
+			locs = append(locs, compositionReferences(n, gks)...)
+		}
+	}
+	sort.Slice(locs, func(i, j int) bool {
+		if locs[i].URI != locs[j].URI {
+			return locs[i].URI < locs[j].URI
+		}
+		return locs[i].Range.Start.Line < locs[j].Range.Start.Line
+	})
+	return locs
+}
+
+// compositionReferences returns the locations of the composite type and the
+// composed resources of the supplied Composition node that are of any of the
+// supplied kinds.
+func compositionReferences(n workspace.Node, gks []schema.GroupKind) []protocol.Location {
+	u, ok := n.GetObject().(runtime.Unstructured)
+	if !ok {
+		return nil
+	}
+	p := fieldpath.Pave(u.UnstructuredContent())
+
+	locs := make([]protocol.Location, 0)
+	if containsKind(gks, gvkAt(p, "spec.compositeTypeRef").GroupKind()) {
+		locs = append(locs, nodeLocation(n, "$.spec.compositeTypeRef.kind"))
+	}
+	resources, _ := p.GetValue("spec.resources")
+	rs, _ := resources.([]any)
+	for i := range rs {
+		base := fmt.Sprintf("spec.resources[%d].base", i)
+		if containsKind(gks, gvkAt(p, base).GroupKind()) {
+			locs = append(locs, nodeLocation(n, "$."+base+".kind"))
+		}
+	}
+	return locs
+}
+
+// typeRef returns the GVK of the type that the field refers to if it is the
+// apiVersion or kind of an object, of a composed resource, or of the
+// composite type of a Composition.
+func (f *nodeField) typeRef() (schema.GroupVersionKind, bool) {
+	if isComposition(f.node.GetGVK()) && hasPrefix(f.path, keySpec, keyCompositeTypeRef) && len(f.path) == 3 {
+		u, ok := f.node.GetObject().(runtime.Unstructured)
+		if !ok {
+			return schema.GroupVersionKind{}, false
+		}
+		return gvkAt(fieldpath.Pave(u.UnstructuredContent()), f.path[:2].String()), isTypeField(f.path[2])
+	}
+	gvk, path := f.objectOf()
+	return gvk, len(path) == 1 && isTypeField(path[0])
+}
+
+// isTypeField returns true if the supplied segment is the apiVersion or kind
+// field of an object.
+func isTypeField(s fieldpath.Segment) bool {
+	return s.Type == fieldpath.SegmentField && (s.Field == keyAPIVersion || s.Field == keyKind)
+}
+
+// gvkAt returns the GVK of the apiVersion and kind fields of the object at
+// the supplied path of the supplied paved object.
+func gvkAt(p *fieldpath.Paved, path string) schema.GroupVersionKind {
+	apiVersion, _ := p.GetString(path + "." + keyAPIVersion)
+	kind, _ := p.GetString(path + "." + keyKind)
+	return schema.FromAPIVersionAndKind(apiVersion, kind)
+}
+
+// definedKinds returns the name of the supplied CRD or XRD along with the
+// kinds that it defines. The kinds defined by an XRD include its claim. No
+// kinds are returned for other objects.
+func definedKinds(o runtime.Object) (string, []schema.GroupKind) { // nolint:gocyclo
+	switch rd := o.(type) {
+	case *extv1.CustomResourceDefinition:
+		return rd.GetName(), []schema.GroupKind{{Group: rd.Spec.Group, Kind: rd.Spec.Names.Kind}}
+	case *extv1beta1.CustomResourceDefinition:
+		return rd.GetName(), []schema.GroupKind{{Group: rd.Spec.Group, Kind: rd.Spec.Names.Kind}}
+	case *xpextv1.CompositeResourceDefinition:
+		gks := []schema.GroupKind{{Group: rd.Spec.Group, Kind: rd.Spec.Names.Kind}}
+		if rd.Spec.ClaimNames != nil {
+			gks = append(gks, schema.GroupKind{Group: rd.Spec.Group, Kind: rd.Spec.ClaimNames.Kind})
+		}
+		return rd.GetName(), gks
+	case runtime.Unstructured:
+		gvk := o.GetObjectKind().GroupVersionKind()
+		isCRD := gvk.Group == extv1.GroupName && gvk.Kind == crdKind
+		isXRD := gvk.Group == xpextv1.Group && gvk.Kind == xpextv1.CompositeResourceDefinitionKind
+		if !isCRD && !isXRD {
+			return "", nil
+		}
+		p := fieldpath.Pave(rd.UnstructuredContent())
+		name, _ := p.GetString("metadata.name")
+		group, _ := p.GetString("spec.group")
+		gks := make([]schema.GroupKind, 0, 2)
+		for _, path := range []string{"spec.names.kind", "spec.claimNames.kind"} {
+			if kind, err := p.GetString(path); err == nil && kind != "" {
+				gks = append(gks, schema.GroupKind{Group: group, Kind: kind})
+			}
+		}
+		return name, gks
+	}
+	return "", nil
+}
+
+// containsKind returns true if the supplied kinds contain the supplied kind.
+func containsKind(gks []schema.GroupKind, gk schema.GroupKind) bool {
+	if gk.Kind == "" {
+		return false
+	}
+	for _, k := range gks {
+		if k == gk {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeLocation returns the location of the value at the supplied YAML path of
+// the supplied node. The location is the start of the node's file if there
+// is no value at the path.
+func nodeLocation(n workspace.Node, path string) protocol.Location {
+	return protocol.Location{
+		URI:   protocol.DocumentURI(span.URIFromPath(n.GetFileName())),
+		Range: valueRange(n.GetAST(), path),
+	}
+}
+
+// valueRange returns the range of the value at the supplied YAML path of the
+// supplied AST node, or an empty range if there is no value at the path.
+func valueRange(n ast.Node, path string) protocol.Range {
+	v := nodeAt(n, path)
+	if v == nil || v.GetToken() == nil {
+		return protocol.Range{}
+	}
+	return tokenRange(v.GetToken())
+}
+
+// nodeAt returns the AST node at the supplied YAML path of the supplied AST
+// node, or nil if there is none.
+func nodeAt(n ast.Node, path string) ast.Node {
+	p, err := yaml.PathString(path)
+	if err != nil {
+		return nil
+	}
+	v, err := p.FilterNode(n)
+	if err != nil {
+		return nil
+	}
+	return v
+}
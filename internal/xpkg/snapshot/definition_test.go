@@ -0,0 +1,201 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	mxpkg "github.com/upbound/up/internal/xpkg/dep/marshaler/xpkg"
+)
+
+var testComposition = `apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: certificates
+spec:
+  compositeTypeRef:
+    apiVersion: example.org/v1alpha1
+    kind: XCertificate
+  resources:
+  - base:
+      apiVersion: acm.aws.crossplane.io/v1alpha1
+      kind: Certificate
+  - base:
+      apiVersion: s3.aws.upbound.io/v1beta1
+      kind: Bucket
+`
+
+var testClaim = `apiVersion: example.org/v1alpha1
+kind: CertificateClaim
+metadata:
+  name: example
+spec:
+  domain: example.org
+`
+
+var testPackages = map[string]*mxpkg.ParsedPackage{
+	"xpkg.upbound.io/upbound/provider-aws-s3": {
+		DepName: "xpkg.upbound.io/upbound/provider-aws-s3",
+		PType:   v1beta1.ProviderPackageType,
+		Ver:     "v1.1.0",
+		Objs: []runtime.Object{
+			&extv1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "buckets.s3.aws.upbound.io"},
+				Spec: extv1.CustomResourceDefinitionSpec{
+					Group: "s3.aws.upbound.io",
+					Names: extv1.CustomResourceDefinitionNames{Kind: "Bucket"},
+				},
+			},
+		},
+	},
+}
+
+func TestDefinition(t *testing.T) {
+	xrdName := protocol.Location{URI: "file:///ws/xrd.yaml", Range: rng(3, 8, 33)}
+	crdName := protocol.Location{URI: "file:///ws/crd.yaml", Range: rng(4, 8, 42)}
+
+	cases := map[string]struct {
+		reason string
+		file   string
+		pos    protocol.Position
+		want   []protocol.Location
+	}{
+		"CompositeTypeRef": {
+			reason: "The composite type of a Composition should be defined by its XRD.",
+			file:   "composition.yaml",
+			pos:    protocol.Position{Line: 7, Character: 12},
+			want:   []protocol.Location{xrdName},
+		},
+		"Claim": {
+			reason: "The kind of a claim should be defined by the XRD that offers it.",
+			file:   "examples/claim.yaml",
+			pos:    protocol.Position{Line: 0, Character: 16},
+			want:   []protocol.Location{xrdName},
+		},
+		"ComposedResource": {
+			reason: "The kind of a composed resource should be defined by the CRD of the workspace.",
+			file:   "composition.yaml",
+			pos:    protocol.Position{Line: 11, Character: 20},
+			want:   []protocol.Location{crdName},
+		},
+		"CachedComposedResource": {
+			reason: "A composed resource that is not defined in the workspace should be defined by the cached CRD of a dependency.",
+			file:   "composition.yaml",
+			pos:    protocol.Position{Line: 14, Character: 13},
+			want:   []protocol.Location{{URI: "file:///cache/xpkg.upbound.io/upbound/provider-aws-s3@v1.1.0/buckets.s3.aws.upbound.io.yaml"}},
+		},
+		"NotAType": {
+			reason: "Nothing should be returned for fields that do not refer to a type.",
+			file:   "composition.yaml",
+			pos:    protocol.Position{Line: 3, Character: 10},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := newTestSnapshot(t, map[string][]byte{
+				"crd.yaml":            testSingleVersionCRD,
+				"xrd.yaml":            testXRD,
+				"composition.yaml":    []byte(testComposition),
+				"examples/claim.yaml": []byte(testClaim),
+			})
+			s.packages = testPackages
+
+			got, err := s.Definition(context.Background(), span.URIFromPath("/ws/"+tc.file), tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nDefinition(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestReferences(t *testing.T) {
+	refs := []protocol.Location{
+		{URI: "file:///ws/composition.yaml", Range: rng(7, 10, 22)},
+		{URI: "file:///ws/examples/claim.yaml", Range: rng(1, 6, 22)},
+		{URI: "file:///ws/examples/other.yaml", Range: rng(1, 6, 18)},
+	}
+
+	cases := map[string]struct {
+		reason      string
+		file        string
+		pos         protocol.Position
+		includeDecl bool
+		want        []protocol.Location
+	}{
+		"XRD": {
+			reason: "The Compositions, claims and composite resources that use the kinds of an XRD should be referenced.",
+			file:   "xrd.yaml",
+			pos:    protocol.Position{Line: 7, Character: 12},
+			want:   refs,
+		},
+		"ClaimWithDeclaration": {
+			reason: "The kind of a claim should be resolved to its XRD, which is included if the declaration is requested.",
+			file:   "examples/claim.yaml",
+			pos:    protocol.Position{Line: 1, Character: 8},
+			want: append([]protocol.Location{
+				{URI: "file:///ws/xrd.yaml", Range: rng(3, 8, 33)},
+			}, refs...),
+			includeDecl: true,
+		},
+		"CRD": {
+			reason: "The composed resources of the kind of a CRD should be referenced.",
+			file:   "crd.yaml",
+			pos:    protocol.Position{Line: 0, Character: 0},
+			want: []protocol.Location{
+				{URI: "file:///ws/composition.yaml", Range: rng(11, 12, 23)},
+			},
+		},
+		"NotAType": {
+			reason: "Nothing should be returned for fields that do not refer to a type.",
+			file:   "examples/claim.yaml",
+			pos:    protocol.Position{Line: 5, Character: 4},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := newTestSnapshot(t, map[string][]byte{
+				"crd.yaml":            testSingleVersionCRD,
+				"xrd.yaml":            testXRD,
+				"composition.yaml":    []byte(testComposition),
+				"examples/claim.yaml": []byte(testClaim),
+				"examples/other.yaml": []byte(strings.ReplaceAll(testClaim, "CertificateClaim", "XCertificate")),
+			})
+
+			got, err := s.References(context.Background(), span.URIFromPath("/ws/"+tc.file), tc.pos, tc.includeDecl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nReferences(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -35,6 +35,7 @@ func gvkDNEWarning(gvk schema.GroupVersionKind, location string) []error {
 			TypeCode: validator.WarningTypeCode,
 			Message:  fmt.Sprintf(errFmt, warnNoDefinitionFound, gvk),
 			Name:     location,
+			Rule:     validator.RuleDefinitionNotFound,
 		},
 	}
 }
@@ -0,0 +1,316 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/golang/tools/lsp/protocol"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	docSeparator  = "---"
+	keyAPIVersion = "apiVersion"
+	keyKind       = "kind"
+)
+
+// document is a line based representation of the YAML document that is being
+// edited. Unlike the AST of the workspace, it is built from documents that do
+// not parse, which is usually the case while they are being typed.
+// NOTE: only block style mappings and sequences are supported, flow style
+// collections and multi-line scalars are treated as scalars.
+type document struct {
+	// values of the fields that have an inline scalar value, by the string
+	// representation of their path.
+	values map[string]string
+	// keys of the mappings, by the string representation of their path.
+	keys map[string][]string
+}
+
+// cursor is the position within a document at which the key or value of a
+// field is being edited.
+type cursor struct {
+	// path of the mapping whose key is being edited, or of the field whose
+	// value is being edited.
+	path fieldpath.Segments
+	// value is true if the value of a field, rather than a key, is being
+	// edited.
+	value bool
+	// prefix is the part of the key or value that precedes the position.
+	prefix string
+	// rng is the range of the prefix.
+	rng protocol.Range
+}
+
+// parseDocument parses the YAML document of the supplied body that contains
+// the supplied position. The line of the position is not part of the returned
+// document, but is returned as a cursor. The cursor is nil if the position is
+// not at a key or value.
+func parseDocument(body []byte, pos protocol.Position) (*document, *cursor) {
+	lines := strings.Split(string(body), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSuffix(lines[i], "\r")
+	}
+	ln := int(pos.Line)
+	if ln >= len(lines) {
+		lines = append(lines, make([]string, ln-len(lines)+1)...)
+	}
+
+	start, end := 0, len(lines)
+	for i := range lines {
+		if !strings.HasPrefix(lines[i], docSeparator) {
+			continue
+		}
+		if i < ln {
+			start = i + 1
+			continue
+		}
+		if i > ln {
+			end = i
+			break
+		}
+	}
+
+	w := &walker{doc: &document{
+		values: map[string]string{},
+		keys:   map[string][]string{},
+	}}
+	for _, l := range lines[start:ln] {
+		for _, e := range parseLine(l) {
+			w.push(e)
+		}
+	}
+	c := w.cursor(lines[ln], offset(lines[ln], pos.Character))
+	if c != nil {
+		c.rng.Start.Line, c.rng.End.Line = pos.Line, pos.Line
+	}
+	for _, l := range lines[ln+1 : end] {
+		for _, e := range parseLine(l) {
+			w.push(e)
+		}
+	}
+	return w.doc, c
+}
+
+// gvk returns the GVK of the object at the supplied path of the document.
+func (d *document) gvk(path fieldpath.Segments) schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(
+		d.values[append(path[:len(path):len(path)], fieldpath.Field(keyAPIVersion)).String()],
+		d.values[append(path[:len(path):len(path)], fieldpath.Field(keyKind)).String()],
+	)
+}
+
+// entry is a sequence item indicator, key or scalar of a line.
+type entry struct {
+	col    int
+	dash   bool
+	scalar bool
+	key    string
+	value  string
+}
+
+// parseLine returns the entries of the supplied line, i.e. its sequence item
+// indicators followed by a key or scalar, if any.
+func parseLine(line string) []entry {
+	entries := []entry{}
+	i := indent(line)
+	for i < len(line) {
+		rest := line[i:]
+		switch {
+		case rest == "-" || strings.HasPrefix(rest, "- "):
+			entries = append(entries, entry{col: i, dash: true})
+			i++
+			i += indent(line[i:])
+			continue
+		case strings.HasPrefix(rest, "#"):
+		default:
+			k, v, ok := splitKey(rest)
+			if !ok {
+				entries = append(entries, entry{col: i, scalar: true, value: v})
+				break
+			}
+			entries = append(entries, entry{col: i, key: k, value: v})
+		}
+		break
+	}
+	return entries
+}
+
+// splitKey splits the supplied text into the key and value of a field. It
+// returns false, and the text as value, if the text is not a field.
+func splitKey(s string) (key, value string, ok bool) {
+	i := strings.Index(s, ": ")
+	switch {
+	case i >= 0:
+	case strings.HasSuffix(s, ":"):
+		i = len(s) - 1
+	default:
+		return "", unquote(stripComment(s)), false
+	}
+	return unquote(s[:i]), unquote(stripComment(s[i+1:])), true
+}
+
+func stripComment(s string) string {
+	if strings.HasPrefix(s, "#") {
+		return ""
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+func unquote(s string) string {
+	return strings.Trim(strings.TrimSpace(s), `"'`)
+}
+
+func indent(s string) int {
+	return len(s) - len(strings.TrimLeft(s, " "))
+}
+
+// frame is a sequence item or key that may have children.
+type frame struct {
+	col  int
+	dash bool
+	seg  fieldpath.Segment
+}
+
+// walker builds a document from the entries of its lines, keeping track of
+// the path of the current entry by the indentation of its ancestors.
+type walker struct {
+	doc   *document
+	stack []frame
+}
+
+func (w *walker) path() fieldpath.Segments {
+	p := make(fieldpath.Segments, len(w.stack))
+	for i, f := range w.stack {
+		p[i] = f.seg
+	}
+	return p
+}
+
+// parent pops the frames that are not ancestors of the supplied entry off the
+// stack. It returns the index of the entry if it is a sequence item.
+func (w *walker) parent(e entry) uint {
+	var index uint
+	for len(w.stack) > 0 {
+		top := w.stack[len(w.stack)-1]
+		// the items of a sequence may be indented as much as its key.
+		if top.col < e.col || (e.dash && !top.dash && top.col == e.col) {
+			break
+		}
+		if e.dash && top.dash && top.col == e.col {
+			index = top.seg.Index + 1
+		}
+		w.stack = w.stack[:len(w.stack)-1]
+	}
+	return index
+}
+
+// push adds the supplied entry to the document.
+func (w *walker) push(e entry) {
+	if e.scalar {
+		return
+	}
+	index := w.parent(e)
+	if e.dash {
+		w.stack = append(w.stack, frame{col: e.col, dash: true, seg: fieldpath.Segment{Type: fieldpath.SegmentIndex, Index: index}})
+		return
+	}
+	parent := w.path()
+	w.doc.keys[parent.String()] = append(w.doc.keys[parent.String()], e.key)
+	if e.value != "" {
+		w.doc.values[append(parent, fieldpath.Field(e.key)).String()] = e.value
+	}
+	w.stack = append(w.stack, frame{col: e.col, seg: fieldpath.Field(e.key)})
+}
+
+// cursor returns the cursor at the supplied byte offset of the supplied line.
+// The sequence item indicators of the line that precede the offset are added
+// to the document.
+func (w *walker) cursor(line string, off int) *cursor {
+	before := line[:off]
+	col := len(before)
+	for _, e := range parseLine(before) {
+		if !e.dash {
+			col = e.col
+			break
+		}
+		w.push(e)
+	}
+	switch {
+	case strings.HasPrefix(strings.TrimSpace(before), "#"), strings.HasPrefix(before, docSeparator):
+		return nil
+	case col == len(before) && strings.HasSuffix(before, "-"):
+		// the position immediately follows a sequence item indicator.
+		return nil
+	}
+
+	w.parent(entry{col: col})
+	c := &cursor{path: w.path(), prefix: before[col:]}
+	start := col
+	if i := strings.Index(c.prefix, ": "); i >= 0 {
+		c.path = append(c.path, fieldpath.Field(unquote(c.prefix[:i])))
+		c.value = true
+		start = col + i + 1
+		start += indent(before[start:])
+		if strings.HasPrefix(before[start:], `"`) || strings.HasPrefix(before[start:], "'") {
+			start++
+		}
+		c.prefix = before[start:]
+	}
+	if strings.Contains(c.prefix, ":") || strings.Contains(c.prefix, "#") {
+		// the position is at a flow style collection or a comment, or
+		// between a key and its value.
+		return nil
+	}
+	c.rng.Start.Character = character(line, start)
+	c.rng.End.Character = character(line, off)
+	return c
+}
+
+// offset returns the byte offset of the supplied UTF-16 character offset of
+// the supplied line.
+func offset(line string, char uint32) int {
+	var n uint32
+	for i, r := range line {
+		if n >= char {
+			return i
+		}
+		n++
+		if r >= 0x10000 {
+			// runes outside of the basic multilingual plane are encoded
+			// as surrogate pairs.
+			n++
+		}
+	}
+	return len(line)
+}
+
+// character returns the UTF-16 character offset of the supplied byte offset
+// of the supplied line.
+func character(line string, off int) uint32 {
+	var n uint32
+	for _, r := range line[:off] {
+		n++
+		if r >= 0x10000 {
+			n++
+		}
+	}
+	return n
+}
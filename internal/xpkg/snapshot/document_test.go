@@ -0,0 +1,150 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseDocument(t *testing.T) {
+	type want struct {
+		path   string
+		value  bool
+		prefix string
+		rng    protocol.Range
+		values map[string]string
+	}
+	cases := map[string]struct {
+		reason string
+		body   string
+		pos    protocol.Position
+		want   *want
+	}{
+		"RootKey": {
+			reason: "A key at the root of a document should have an empty path.",
+			body:   "apiVersion: v1\nki\n",
+			pos:    protocol.Position{Line: 1, Character: 2},
+			want: &want{
+				prefix: "ki",
+				rng:    rng(1, 0, 2),
+				values: map[string]string{"apiVersion": "v1"},
+			},
+		},
+		"Value": {
+			reason: "The path of a value should include its key.",
+			body:   "metadata:\n  name: \"ex",
+			pos:    protocol.Position{Line: 1, Character: 11},
+			want: &want{
+				path:   "metadata.name",
+				value:  true,
+				prefix: "ex",
+				rng:    rng(1, 9, 11),
+				values: map[string]string{},
+			},
+		},
+		"SequenceItem": {
+			reason: "Sequence items should be indexed by the number of preceding items.",
+			body: `kind: Composition
+spec:
+  resources:
+  - name: a
+    base:
+      kind: A
+  - name: b
+    base:
+      apiVersion: b.example.org/v1
+      kind: B
+    patches:
+    - fromFieldPath: spec.a
+    - fromFieldPath: spec.
+`,
+			pos: protocol.Position{Line: 12, Character: 26},
+			want: &want{
+				path:   "spec.resources[1].patches[1].fromFieldPath",
+				value:  true,
+				prefix: "spec.",
+				rng:    rng(12, 21, 26),
+				values: map[string]string{
+					"kind":                                       "Composition",
+					"spec.resources[0].name":                     "a",
+					"spec.resources[0].base.kind":                "A",
+					"spec.resources[1].name":                     "b",
+					"spec.resources[1].base.apiVersion":          "b.example.org/v1",
+					"spec.resources[1].base.kind":                "B",
+					"spec.resources[1].patches[0].fromFieldPath": "spec.a",
+				},
+			},
+		},
+		"NewSequenceItem": {
+			reason: "A key following a sequence item indicator should be within a new item.",
+			body:   "items:\n- a: 1\n- ",
+			pos:    protocol.Position{Line: 2, Character: 2},
+			want: &want{
+				path:   "items[1]",
+				rng:    rng(2, 2, 2),
+				values: map[string]string{"items[0].a": "1"},
+			},
+		},
+		"Document": {
+			reason: "Only the document of the position should be parsed, including lines that follow it.",
+			body:   "kind: A\n---\nspec:\n  \n  b: 1\nkind: B\n---\nkind: C\n",
+			pos:    protocol.Position{Line: 3, Character: 2},
+			want: &want{
+				path:   "spec",
+				rng:    rng(3, 2, 2),
+				values: map[string]string{"spec.b": "1", "kind": "B"},
+			},
+		},
+		"Comment": {
+			reason: "There should be no cursor in a comment.",
+			body:   "# kind",
+			pos:    protocol.Position{Line: 0, Character: 6},
+		},
+		"BetweenKeyAndValue": {
+			reason: "There should be no cursor between a key and the space preceding its value.",
+			body:   "kind:",
+			pos:    protocol.Position{Line: 0, Character: 5},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d, c := parseDocument([]byte(tc.body), tc.pos)
+			if tc.want == nil {
+				if c != nil {
+					t.Errorf("\n%s\nparseDocument(...): want no cursor, got path %q", tc.reason, c.path)
+				}
+				return
+			}
+			if c == nil {
+				t.Fatalf("\n%s\nparseDocument(...): want cursor, got none", tc.reason)
+			}
+			got := &want{path: c.path.String(), value: c.value, prefix: c.prefix, rng: c.rng, values: d.values}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nparseDocument(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func rng(line, start, end uint32) protocol.Range {
+	return protocol.Range{
+		Start: protocol.Position{Line: line, Character: start},
+		End:   protocol.Position{Line: line, Character: end},
+	}
+}
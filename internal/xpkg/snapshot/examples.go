@@ -0,0 +1,86 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+	"errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	verrors "k8s.io/kube-openapi/pkg/validation/errors"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	extv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	"github.com/upbound/up/internal/xpkg/snapshot/validator"
+)
+
+// ExampleValidator validates example manifests against the schemas of a set
+// of CRDs and XRDs, typically those of a package and its dependencies.
+type ExampleValidator struct {
+	validators map[schema.GroupVersionKind]*validator.ObjectValidator
+}
+
+// NewExampleValidator returns an ExampleValidator for the CRDs and XRDs among
+// the supplied objects. Other objects, as well as CRDs and XRDs whose schema
+// cannot be built, are skipped.
+func NewExampleValidator(ctx context.Context, objs ...runtime.Object) *ExampleValidator {
+	validators := make(map[schema.GroupVersionKind]*validator.ObjectValidator)
+	for _, o := range objs {
+		// NOTE: broken definitions are reported by the linters, so we
+		// only validate against the ones we can build.
+		switch rd := o.(type) {
+		case *extv1beta1.CustomResourceDefinition:
+			_ = validatorsFromV1Beta1CRD(rd, validators)
+		case *extv1.CustomResourceDefinition:
+			_ = validatorsFromV1CRD(rd, validators)
+		case *xpextv1.CompositeResourceDefinition:
+			_ = validatorsFromV1XRD(ctx, rd, validators)
+		}
+	}
+	return &ExampleValidator{validators: validators}
+}
+
+// Validate validates the supplied example against the schema of its kind. It
+// returns a validation for every field that does not match the schema. Kinds
+// without a known schema, e.g. core Kubernetes kinds, are not validated.
+func (v *ExampleValidator) Validate(ctx context.Context, ex *unstructured.Unstructured) []*validator.Validation {
+	ov, ok := v.validators[ex.GroupVersionKind()]
+	if !ok {
+		return nil
+	}
+	res := ov.Validate(ctx, ex)
+	if res == nil {
+		return nil
+	}
+	out := make([]*validator.Validation, 0, len(res.Errors))
+	for _, err := range res.Errors {
+		var ve *verrors.Validation
+		if !errors.As(err, &ve) {
+			continue
+		}
+		out = append(out, &validator.Validation{
+			TypeCode: validator.ErrorTypeCode,
+			Message:  ve.Error(),
+			Name:     ve.Name,
+			Rule:     validator.RuleExampleSchema,
+		})
+	}
+	return out
+}
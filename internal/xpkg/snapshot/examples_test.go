@@ -0,0 +1,142 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	"github.com/upbound/up/internal/xpkg/snapshot/validator"
+)
+
+func TestExampleValidator(t *testing.T) {
+	xrd := &xpextv1.CompositeResourceDefinition{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "apiextensions.crossplane.io/v1",
+			Kind:       "CompositeResourceDefinition",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "xpostgresqlinstances.database.example.org",
+		},
+		Spec: xpextv1.CompositeResourceDefinitionSpec{
+			Group: "database.example.org",
+			Names: v1.CustomResourceDefinitionNames{
+				Kind:   "XPostgreSQLInstance",
+				Plural: "xpostgresqlinstances",
+			},
+			ClaimNames: &v1.CustomResourceDefinitionNames{
+				Kind:   "PostgreSQLInstance",
+				Plural: "postgresqlinstances",
+			},
+			Versions: []xpextv1.CompositeResourceDefinitionVersion{
+				{
+					Name:          "v1alpha1",
+					Served:        true,
+					Referenceable: true,
+					Schema: &xpextv1.CompositeResourceValidation{
+						OpenAPIV3Schema: runtime.RawExtension{
+							Raw: []byte(`{
+								"type": "object",
+								"properties": {
+									"spec": {
+										"type": "object",
+										"properties": {
+											"storageGB": {
+												"type": "integer"
+											}
+										},
+										"required": ["storageGB"]
+									}
+								}
+							}`),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	example := func(kind string, spec map[string]any) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "database.example.org/v1alpha1",
+			"kind":       kind,
+			"metadata":   map[string]any{"name": "example"},
+			"spec":       spec,
+		}}
+		return u
+	}
+
+	cases := map[string]struct {
+		reason  string
+		example *unstructured.Unstructured
+		want    []*validator.Validation
+	}{
+		"Valid": {
+			reason:  "No validations should be returned for a valid example.",
+			example: example("XPostgreSQLInstance", map[string]any{"storageGB": int64(20)}),
+			want:    []*validator.Validation{},
+		},
+		"InvalidClaim": {
+			reason:  "Claims should be validated against the schema of their XRD.",
+			example: example("PostgreSQLInstance", map[string]any{"storageGB": "big"}),
+			want: []*validator.Validation{
+				{
+					TypeCode: validator.ErrorTypeCode,
+					Message:  `spec.storageGB in body must be of type integer: "string"`,
+					Name:     "spec.storageGB",
+					Rule:     validator.RuleExampleSchema,
+				},
+			},
+		},
+		"MissingRequiredField": {
+			reason:  "Missing required fields should be reported.",
+			example: example("XPostgreSQLInstance", map[string]any{}),
+			want: []*validator.Validation{
+				{
+					TypeCode: validator.ErrorTypeCode,
+					Message:  "spec.storageGB in body is required",
+					Name:     "spec.storageGB",
+					Rule:     validator.RuleExampleSchema,
+				},
+			},
+		},
+		"UnknownKind": {
+			reason: "Examples of kinds without a schema should not be validated.",
+			example: &unstructured.Unstructured{Object: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "Secret",
+				"metadata":   map[string]any{"name": "example"},
+			}},
+		},
+	}
+
+	v := NewExampleValidator(context.Background(), xrd)
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := v.Validate(context.Background(), tc.example)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nValidate(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
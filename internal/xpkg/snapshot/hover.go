@@ -0,0 +1,161 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
+)
+
+const (
+	keyDependsOn = "dependsOn"
+
+	depNotResolvedFmt = "%s is not in the package cache. Run `up xpkg dep` to resolve it."
+)
+
+// Hover returns the documentation of the field at the supplied position of the
+// file at the supplied URI, derived from the schemas of the CRDs and XRDs of
+// the workspace and its dependencies. For dependencies of the meta file, it
+// returns the resolved package instead. The returned Hover is nil if there is
+// nothing to document at the position.
+func (s *Snapshot) Hover(_ context.Context, uri span.URI, pos protocol.Position) (*protocol.Hover, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f, err := s.fieldAt(uri, pos)
+	if err != nil || f == nil {
+		return nil, err
+	}
+
+	var contents string
+	if f.node.GetGVK().Group == pkgmetav1.Group && hasPrefix(f.path, keySpec, keyDependsOn) && len(f.path) > 2 {
+		contents = s.dependencyDoc(f.path[2])
+	} else {
+		gvk, path := f.objectOf()
+		contents = fieldDoc(s.Schema(gvk), path)
+	}
+	if contents == "" {
+		return nil, nil
+	}
+	return &protocol.Hover{
+		Contents: protocol.MarkupContent{
+			Kind:  protocol.Markdown,
+			Value: contents,
+		},
+		Range: tokenRange(f.tok),
+	}, nil
+}
+
+// dependencyDoc documents the dependency at the supplied index of the meta
+// file with the package that it resolves to.
+func (s *Snapshot) dependencyDoc(index fieldpath.Segment) string {
+	if index.Type != fieldpath.SegmentIndex || s.wsview.Meta() == nil {
+		return ""
+	}
+	deps, err := s.wsview.Meta().DependsOn()
+	if err != nil || int(index.Index) >= len(deps) {
+		return ""
+	}
+	d := deps[index.Index]
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "**%s** `%s`\n\n", d.Package, strings.ToLower(string(d.Type)))
+	p := s.Package(d.Package)
+	if p == nil {
+		fmt.Fprintf(b, depNotResolvedFmt, d.Package)
+		return b.String()
+	}
+	if d.Constraints != "" {
+		fmt.Fprintf(b, "Constraint: `%s`  \n", d.Constraints)
+	}
+	fmt.Fprintf(b, "Resolved version: `%s`  \n", p.Version())
+	fmt.Fprintf(b, "Digest: `%s`  \n", p.Digest())
+	fmt.Fprintf(b, "Package kind: `%s`", p.Type())
+	return b.String()
+}
+
+// fieldDoc documents the field at the supplied path of the supplied schema.
+func fieldDoc(sch *spec.Schema, path fieldpath.Segments) string {
+	if len(path) == 0 {
+		return ""
+	}
+	prop := schemaAt(sch, path)
+	if prop == nil {
+		return ""
+	}
+
+	b := &strings.Builder{}
+	last := path[len(path)-1]
+	name := last.Field
+	if last.Type == fieldpath.SegmentIndex {
+		name = path.String()
+	}
+	fmt.Fprintf(b, "**%s**", name)
+	if t := typeOf(prop); t != "" {
+		fmt.Fprintf(b, " `%s`", t)
+	}
+	if last.Type == fieldpath.SegmentField && isRequired(schemaAt(sch, path[:len(path)-1]), name) {
+		b.WriteString(" (required)")
+	}
+	if prop.Description != "" {
+		fmt.Fprintf(b, "\n\n%s", prop.Description)
+	}
+	if prop.Default != nil {
+		fmt.Fprintf(b, "\n\nDefault: `%s`", jsonValue(prop.Default))
+	}
+	if len(prop.Enum) > 0 {
+		values := make([]string, len(prop.Enum))
+		for i, e := range prop.Enum {
+			values[i] = fmt.Sprintf("`%s`", jsonValue(e))
+		}
+		fmt.Fprintf(b, "\n\nAllowed values: %s", strings.Join(values, ", "))
+	}
+	return b.String()
+}
+
+// isRequired returns true if the supplied schema requires the supplied
+// property.
+func isRequired(sch *spec.Schema, name string) bool {
+	if sch == nil {
+		return false
+	}
+	for _, r := range sch.Required {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonValue returns the JSON representation of the supplied value, or its
+// default format if it cannot be represented as JSON. Strings are not quoted.
+func jsonValue(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,208 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	mxpkg "github.com/upbound/up/internal/xpkg/dep/marshaler/xpkg"
+	"github.com/upbound/up/internal/xpkg/scheme"
+	"github.com/upbound/up/internal/xpkg/snapshot/validator"
+	"github.com/upbound/up/internal/xpkg/workspace"
+)
+
+func TestHover(t *testing.T) {
+	cases := map[string]struct {
+		reason   string
+		file     string
+		body     string
+		packages map[string]*mxpkg.ParsedPackage
+		want     *protocol.Hover
+	}{
+		"ComposedResourceField": {
+			reason: "Hovering the key of a field of a composed resource should document it from the schema of the composed resource.",
+			file:   "composition.yaml",
+			body: `apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: certificates
+spec:
+  resources:
+  - base:
+      apiVersion: acm.aws.crossplane.io/v1alpha1
+      kind: Certificate
+      spec:
+        deletion|Policy: Delete
+`,
+			want: &protocol.Hover{
+				Contents: protocol.MarkupContent{
+					Kind:  protocol.Markdown,
+					Value: "**deletionPolicy** `string`\n\nDeletionPolicy specifies what will happen to the underlying external when this managed resource is deleted - either \"Delete\" or \"Orphan\" the external resource.\n\nDefault: `Delete`\n\nAllowed values: `Orphan`, `Delete`",
+				},
+				Range: rng(10, 8, 22),
+			},
+		},
+		"ClaimValue": {
+			reason: "Hovering the value of a field of a claim should document the field, including whether it is required.",
+			file:   "examples/claim.yaml",
+			body: `apiVersion: example.org/v1alpha1
+kind: CertificateClaim
+metadata:
+  name: example
+spec:
+  domain: exa|mple.org
+`,
+			want: &protocol.Hover{
+				Contents: protocol.MarkupContent{
+					Kind:  protocol.Markdown,
+					Value: "**domain** `string` (required)\n\nDomain of the certificate.",
+				},
+				Range: rng(5, 10, 21),
+			},
+		},
+		"UnknownField": {
+			reason: "Nothing should be returned for fields that are not in the schema.",
+			file:   "examples/claim.yaml",
+			body: `apiVersion: example.org/v1alpha1
+kind: CertificateClaim
+metadata:
+  name: example
+spec:
+  doma|in2: example.org
+`,
+		},
+		"Dependency": {
+			reason: "Hovering a dependency of the meta file should show the package it resolves to.",
+			file:   "crossplane.yaml",
+			body: `apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: getting-started
+spec:
+  dependsOn:
+  - provider: xpkg.upbound.io/upbound/provider-aws-s3
+    version: ">=v1.0.0|"
+`,
+			packages: map[string]*mxpkg.ParsedPackage{
+				"xpkg.upbound.io/upbound/provider-aws-s3": {
+					DepName: "xpkg.upbound.io/upbound/provider-aws-s3",
+					PType:   v1beta1.ProviderPackageType,
+					SHA:     "sha256:0ff2",
+					Ver:     "v1.1.0",
+				},
+			},
+			want: &protocol.Hover{
+				Contents: protocol.MarkupContent{
+					Kind:  protocol.Markdown,
+					Value: "**xpkg.upbound.io/upbound/provider-aws-s3** `provider`\n\nConstraint: `>=v1.0.0`  \nResolved version: `v1.1.0`  \nDigest: `sha256:0ff2`  \nPackage kind: `Provider`",
+				},
+				Range: rng(7, 13, 23),
+			},
+		},
+		"UnresolvedDependency": {
+			reason: "Hovering a dependency that is not in the package cache should say so.",
+			file:   "crossplane.yaml",
+			body: `apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: getting-started
+spec:
+  dependsOn:
+  - pro|vider: xpkg.upbound.io/upbound/provider-aws-s3
+`,
+			want: &protocol.Hover{
+				Contents: protocol.MarkupContent{
+					Kind:  protocol.Markdown,
+					Value: "**xpkg.upbound.io/upbound/provider-aws-s3** `provider`\n\nxpkg.upbound.io/upbound/provider-aws-s3 is not in the package cache. Run `up xpkg dep` to resolve it.",
+				},
+				Range: rng(6, 4, 12),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			lines := strings.Split(tc.body, "\n")
+			pos := protocol.Position{}
+			for i, l := range lines {
+				if c := strings.Index(l, "|"); c >= 0 {
+					pos = protocol.Position{Line: uint32(i), Character: uint32(c)}
+					lines[i] = l[:c] + l[c+1:]
+				}
+			}
+
+			s := newTestSnapshot(t, map[string][]byte{
+				"crd.yaml": testSingleVersionCRD,
+				"xrd.yaml": testXRD,
+				tc.file:    []byte(strings.Join(lines, "\n")),
+			})
+			s.packages = tc.packages
+
+			got, err := s.Hover(context.Background(), span.URIFromPath("/ws/"+tc.file), pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nHover(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// newTestSnapshot returns a Snapshot of a workspace at /ws with the supplied
+// files, without resolving the dependencies of its meta file.
+func newTestSnapshot(t *testing.T, files map[string][]byte) *Snapshot {
+	t.Helper()
+	fs := afero.NewMemMapFs()
+	for f, b := range files {
+		_ = fs.MkdirAll(filepath.Dir("/ws/"+f), os.ModePerm)
+		_ = afero.WriteFile(fs, "/ws/"+f, b, os.ModePerm)
+	}
+	ws, err := workspace.New("/ws", workspace.WithFS(fs), workspace.WithPermissiveParser())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.Parse(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	objScheme, _ := scheme.BuildObjectScheme()
+	metaScheme, _ := scheme.BuildMetaScheme()
+	s := &Snapshot{
+		dm:         NewMockDepManager(),
+		w:          ws,
+		log:        logging.NewNopLogger(),
+		objScheme:  objScheme,
+		metaScheme: metaScheme,
+		validators: map[schema.GroupVersionKind]validator.Validator{},
+		schemas:    newSchemas(),
+		wsview:     ws.View(),
+	}
+	if err := s.loadWSValidators(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
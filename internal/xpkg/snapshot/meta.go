@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
@@ -31,7 +32,9 @@ import (
 	"k8s.io/kube-openapi/pkg/validation/validate"
 	"sigs.k8s.io/yaml"
 
+	"github.com/upbound/up/internal/xpkg/dep/lock"
 	"github.com/upbound/up/internal/xpkg/dep/manager"
+	mxpkg "github.com/upbound/up/internal/xpkg/dep/marshaler/xpkg"
 	pyaml "github.com/upbound/up/internal/xpkg/parser/yaml"
 	"github.com/upbound/up/internal/xpkg/scheme"
 	"github.com/upbound/up/internal/xpkg/snapshot/validator"
@@ -55,7 +58,8 @@ type MetaValidator struct {
 	// TODO(@tnthornton) move to accepting a snapshot rather than the map
 	// once Snapshots are first class citizens.
 	// packages   map[string]*mxpkg.ParsedPackage
-	validators []metaValidator
+	validators      []metaValidator
+	graphValidators []graphValidator
 }
 
 // DefaultMetaValidators returns a new Meta validator.
@@ -68,11 +72,17 @@ func DefaultMetaValidators(s *Snapshot) (*MetaValidator, error) {
 	validators := []metaValidator{
 		NewTypeValidator(s),
 		NewVersionValidator(s.dm),
+		NewLockValidator(s),
+	}
+
+	graphValidators := []graphValidator{
+		NewConflictValidator(s),
 	}
 
 	return &MetaValidator{
-		p:          p,
-		validators: validators,
+		p:               p,
+		validators:      validators,
+		graphValidators: graphValidators,
 	}, nil
 }
 
@@ -90,11 +100,17 @@ func (m *MetaValidator) Validate(ctx context.Context, data any) *validate.Result
 	// validate the current apiVersion of the meta file
 	errs = append(errs, validateAPIVersion(o))
 
+	deps := make([]v1beta1.Dependency, len(pkg.GetDependencies()))
 	for i, d := range pkg.GetDependencies() {
 		cd := manager.ConvertToV1beta1(d)
 		for _, v := range m.validators {
 			errs = append(errs, v.validate(ctx, i, cd))
 		}
+		deps[i] = cd
+	}
+
+	for _, v := range m.graphValidators {
+		errs = append(errs, v.validate(ctx, deps)...)
 	}
 
 	return &validate.Result{
@@ -131,6 +147,12 @@ type metaValidator interface {
 	validate(context.Context, int, v1beta1.Dependency) error
 }
 
+// graphValidator validates the dependency graph formed by all dependencies in
+// a meta file.
+type graphValidator interface {
+	validate(context.Context, []v1beta1.Dependency) []error
+}
+
 // validateAPIVersion tests if the provided object is a deprecated version
 func validateAPIVersion(o runtime.Object) error {
 	switch o.(type) {
@@ -143,6 +165,7 @@ func validateAPIVersion(o runtime.Object) error {
 				metav1.ConfigurationGroupVersionKind.GroupVersion(),
 			),
 			TypeCode: validator.WarningTypeCode,
+			Rule:     validator.RuleDeprecatedAPIVersion,
 		}
 	case *v1alpha1.Provider:
 		return &validator.Validation{
@@ -153,6 +176,7 @@ func validateAPIVersion(o runtime.Object) error {
 				metav1.ProviderGroupVersionKind.GroupVersion(),
 			),
 			TypeCode: validator.WarningTypeCode,
+			Rule:     validator.RuleDeprecatedAPIVersion,
 		}
 	}
 	return nil
@@ -185,6 +209,7 @@ func (v *TypeValidator) validate(_ context.Context, i int, d v1beta1.Dependency)
 				strings.ToLower(d.Package),
 				strings.ToLower(string(got.PType)),
 			),
+			Rule: validator.RuleDependencyType,
 		}
 	}
 	return nil
@@ -217,12 +242,14 @@ func (v *VersionValidator) validate(ctx context.Context, i int, d v1beta1.Depend
 		return &validator.Validation{
 			Name:    fmt.Sprintf(dependsOnPathFmt, i, strings.ToLower(string(d.Type))),
 			Message: fmt.Sprintf(errPackageDNEFmt, d.Package),
+			Rule:    validator.RuleDependencyVersion,
 		}
 	}
 	if !versionMatch(d.Constraints, vers) {
 		return &validator.Validation{
 			Name:    fmt.Sprintf(dependsOnPathFmt, i, versionField),
 			Message: fmt.Sprintf(errVersionDENFmt, d.Constraints),
+			Rule:    validator.RuleDependencyVersion,
 		}
 	}
 	return nil
@@ -257,3 +284,84 @@ func versionMatch(constraint string, vers []string) bool {
 	}
 	return found
 }
+
+// LockValidator validates that the dependencies in a meta file could be
+// resolved from the lock file.
+type LockValidator struct {
+	s *Snapshot
+}
+
+// NewLockValidator returns a new LockValidator.
+func NewLockValidator(s *Snapshot) *LockValidator {
+	return &LockValidator{
+		s: s,
+	}
+}
+
+// validate reports the dependencies that are not pinned in the lock file
+// when resolution is frozen. Unpinned transitive dependencies are reported on
+// the dependency through which they are reached.
+func (v *LockValidator) validate(_ context.Context, i int, d v1beta1.Dependency) error {
+	err, ok := v.s.depErrs[lock.Key(d.Package)]
+	if !ok {
+		return nil
+	}
+	return &validator.Validation{
+		Name:    fmt.Sprintf(dependsOnPathFmt, i, versionField),
+		Message: err.Error(),
+		Rule:    validator.RuleDependencyLocked,
+	}
+}
+
+// ConflictValidator validates that the packages in the dependency graph of a
+// meta file can be resolved to a single consistent set of versions.
+type ConflictValidator struct {
+	s *Snapshot
+}
+
+// NewConflictValidator returns a new ConflictValidator.
+func NewConflictValidator(s *Snapshot) *ConflictValidator {
+	return &ConflictValidator{
+		s: s,
+	}
+}
+
+// validate validates that no package in the dependency graph has conflicting
+// constraints. Conflicts are reported on the version of each dependency in
+// the meta file through which the conflicting package is reached.
+func (v *ConflictValidator) validate(ctx context.Context, deps []v1beta1.Dependency) []error {
+	reqs := manager.Requirements(deps, func(name string) *mxpkg.ParsedPackage {
+		return v.s.Package(name)
+	})
+
+	names := make([]string, 0, len(reqs))
+	for n := range reqs {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	errs := []error{}
+	for _, n := range names {
+		r := reqs[n]
+		// a single constraint is covered by the VersionValidator.
+		if len(r.Constraints) < 2 {
+			continue
+		}
+		vers, err := v.s.dm.Versions(ctx, r.Dependency)
+		if err != nil || len(vers) == 0 {
+			continue
+		}
+		if _, ok := manager.HighestMatching(vers, r.Constraints); ok {
+			continue
+		}
+		msg := (&manager.ConflictError{Package: n, Constraints: r.Constraints}).Error()
+		for _, i := range r.Roots {
+			errs = append(errs, &validator.Validation{
+				Name:    fmt.Sprintf(dependsOnPathFmt, i, versionField),
+				Message: msg,
+				Rule:    validator.RuleDependencyConflict,
+			})
+		}
+	}
+	return errs
+}
@@ -0,0 +1,133 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/xpkg/dep/manager"
+	mxpkg "github.com/upbound/up/internal/xpkg/dep/marshaler/xpkg"
+	"github.com/upbound/up/internal/xpkg/snapshot/validator"
+)
+
+func TestLockValidator(t *testing.T) {
+	errNotPinned := errors.New("frozen: index.docker.io/crossplane/provider-aws is not pinned in the lock file")
+
+	cases := map[string]struct {
+		reason  string
+		dep     v1beta1.Dependency
+		depErrs map[string]error
+		want    error
+	}{
+		"Resolved": {
+			reason: "A dependency that could be resolved should be valid.",
+			dep:    v1beta1.Dependency{Package: "xpkg.upbound.io/upbound/provider-aws-s3"},
+		},
+		"NotPinned": {
+			reason: "A dependency that is not pinned in the lock file should be reported on its version, matching packages by their lock key.",
+			dep:    v1beta1.Dependency{Package: "index.docker.io/crossplane/provider-aws"},
+			depErrs: map[string]error{
+				"crossplane/provider-aws": errNotPinned,
+			},
+			want: &validator.Validation{
+				Name:    "spec.dependsOn[1].version",
+				Message: errNotPinned.Error(),
+				Rule:    validator.RuleDependencyLocked,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			v := NewLockValidator(&Snapshot{depErrs: tc.depErrs})
+
+			err := v.validate(context.Background(), 1, tc.dep)
+			if diff := cmp.Diff(tc.want, err); diff != "" {
+				t.Errorf("\n%s\nvalidate(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConflictValidator(t *testing.T) {
+	// NOTE: the packages are named with their registry, whereas the meta file
+	// and the dependencies of the packages refer to them without it.
+	packages := map[string]*mxpkg.ParsedPackage{
+		"index.docker.io/crossplane/configuration-a": {
+			DepName: "index.docker.io/crossplane/configuration-a",
+			Ver:     "v1.0.0",
+			Deps:    []v1beta1.Dependency{{Package: "crossplane/provider-c", Constraints: ">=v2.0.0"}},
+		},
+		"index.docker.io/crossplane/configuration-b": {
+			DepName: "index.docker.io/crossplane/configuration-b",
+			Ver:     "v1.0.0",
+			Deps:    []v1beta1.Dependency{{Package: "crossplane/provider-c", Constraints: "<v2.0.0"}},
+		},
+	}
+	conflict := (&manager.ConflictError{
+		Package: "crossplane/provider-c",
+		Constraints: []manager.Constraint{
+			{Source: "crossplane/configuration-a", Constraint: ">=v2.0.0"},
+			{Source: "crossplane/configuration-b", Constraint: "<v2.0.0"},
+		},
+	}).Error()
+
+	cases := map[string]struct {
+		reason   string
+		deps     []v1beta1.Dependency
+		versions []string
+		want     []error
+	}{
+		"Diamond": {
+			reason: "A package required at conflicting versions by two dependencies should be reported on both of them.",
+			deps: []v1beta1.Dependency{
+				{Package: "crossplane/configuration-a", Constraints: ">=v1.0.0"},
+				{Package: "crossplane/configuration-b", Constraints: ">=v1.0.0"},
+			},
+			versions: []string{"v1.0.0", "v2.0.0"},
+			want: []error{
+				&validator.Validation{Name: "spec.dependsOn[0].version", Message: conflict, Rule: validator.RuleDependencyConflict},
+				&validator.Validation{Name: "spec.dependsOn[1].version", Message: conflict, Rule: validator.RuleDependencyConflict},
+			},
+		},
+		"NoConflict": {
+			reason: "A package required by a single dependency should not be reported.",
+			deps: []v1beta1.Dependency{
+				{Package: "crossplane/configuration-a", Constraints: ">=v1.0.0"},
+			},
+			versions: []string{"v1.0.0", "v2.0.0"},
+			want:     []error{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			v := NewConflictValidator(&Snapshot{
+				dm:       &MockDepManager{versions: tc.versions},
+				packages: packages,
+			})
+
+			errs := v.validate(context.Background(), tc.deps)
+			if diff := cmp.Diff(tc.want, errs); diff != "" {
+				t.Errorf("\n%s\nvalidate(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/token"
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/upbound/up/internal/xpkg/workspace"
+)
+
+// nodeField is the key or value of a field of a workspace node.
+type nodeField struct {
+	node workspace.Node
+	// path of the field within the node.
+	path fieldpath.Segments
+	// tok is the token of the key or value.
+	tok *token.Token
+	// key is true if tok is the key of the field.
+	key bool
+}
+
+// fieldAt returns the field at the supplied position of the file at the
+// supplied URI, or nil if there is no field at the position.
+func (s *Snapshot) fieldAt(uri span.URI, pos protocol.Position) (*nodeField, error) {
+	details, ok := s.wsview.FileDetails()[uri]
+	if !ok {
+		return nil, errors.New(errInvalidFileURI)
+	}
+	for id := range details.NodeIDs {
+		n, ok := s.wsview.Nodes()[id]
+		if !ok {
+			return nil, errors.New(errInvalidNodeID)
+		}
+		if f := findField(n.GetAST(), fieldpath.Segments{}, pos); f != nil {
+			f.node = n
+			return f, nil
+		}
+	}
+	return nil, nil
+}
+
+// findField returns the field at the supplied position within the supplied
+// AST node at the supplied path, or nil if there is none.
+func findField(n ast.Node, path fieldpath.Segments, pos protocol.Position) *nodeField { // nolint:gocyclo
+	switch t := n.(type) {
+	case nil:
+		return nil
+	case *ast.DocumentNode:
+		return findField(t.Body, path, pos)
+	case *ast.MappingNode:
+		for _, v := range t.Values {
+			if f := findField(v, path, pos); f != nil {
+				return f
+			}
+		}
+	case *ast.MappingValueNode:
+		tok := t.Key.GetToken()
+		if tok == nil {
+			return nil
+		}
+		p := append(path[:len(path):len(path)], fieldpath.Field(tok.Value))
+		if contains(tokenRange(tok), pos) {
+			return &nodeField{path: p, tok: tok, key: true}
+		}
+		return findField(t.Value, p, pos)
+	case *ast.SequenceNode:
+		for i, v := range t.Values {
+			if f := findField(v, append(path[:len(path):len(path)], fieldpath.Segment{Type: fieldpath.SegmentIndex, Index: uint(i)}), pos); f != nil {
+				return f
+			}
+		}
+	case *ast.TagNode:
+		return findField(t.Value, path, pos)
+	case *ast.AnchorNode:
+		return findField(t.Value, path, pos)
+	default:
+		if tok := n.GetToken(); tok != nil && contains(tokenRange(tok), pos) {
+			return &nodeField{path: path, tok: tok}
+		}
+	}
+	return nil
+}
+
+// objectOf returns the GVK of the object that the field belongs to, along
+// with the path of the field within that object. Fields of the composed
+// resources of a Composition belong to the composed resource.
+func (f *nodeField) objectOf() (schema.GroupVersionKind, fieldpath.Segments) {
+	gvk, path := f.node.GetGVK(), f.path
+	if !isComposition(gvk) || !isResource(path) || len(path) < 4 || path[3].Field != keyBase {
+		return gvk, path
+	}
+	u, ok := f.node.GetObject().(runtime.Unstructured)
+	if !ok {
+		return schema.GroupVersionKind{}, nil
+	}
+	p := fieldpath.Pave(u.UnstructuredContent())
+	apiVersion, _ := p.GetString(append(path[:4:4], fieldpath.Field(keyAPIVersion)).String())
+	kind, _ := p.GetString(append(path[:4:4], fieldpath.Field(keyKind)).String())
+	return schema.FromAPIVersionAndKind(apiVersion, kind), path[4:]
+}
+
+// tokenRange returns the range of the supplied token. Token positions are not
+// zero-indexed, whereas ranges are.
+func tokenRange(tok *token.Token) protocol.Range {
+	start, end := tok.Position.Column-1, 0
+	// end character can be unmatched if we have doublequotes
+	switch tok.Type { // nolint:exhaustive
+	case token.DoubleQuoteType:
+		end = tok.Position.Column + len(tok.Value) + 1
+	default:
+		end = tok.Position.Column + len(tok.Value) - 1
+	}
+	return protocol.Range{
+		Start: protocol.Position{Line: uint32(tok.Position.Line - 1), Character: uint32(start)},
+		End:   protocol.Position{Line: uint32(tok.Position.Line - 1), Character: uint32(end)},
+	}
+}
+
+// contains returns true if the supplied single line range contains the
+// supplied position.
+func contains(r protocol.Range, pos protocol.Position) bool {
+	return r.Start.Line == pos.Line && r.Start.Character <= pos.Character && pos.Character <= r.End.Character
+}
@@ -0,0 +1,187 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	extv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+// Schemas are the OpenAPI schemas of the kinds defined by the CRDs and XRDs
+// of a Snapshot.
+type Schemas struct {
+	// schemas of all kinds, including composite resources and claims.
+	schemas map[schema.GroupVersionKind]*spec.Schema
+	// composites are the kinds of composite resources.
+	composites map[schema.GroupVersionKind]bool
+	// served are the kinds of versions that are served.
+	served map[schema.GroupVersionKind]bool
+}
+
+func newSchemas() *Schemas {
+	return &Schemas{
+		schemas:    map[schema.GroupVersionKind]*spec.Schema{},
+		composites: map[schema.GroupVersionKind]bool{},
+		served:     map[schema.GroupVersionKind]bool{},
+	}
+}
+
+// SchemasForObj returns a mapping of GVK -> OpenAPI schema for the kinds
+// defined by the given CRD or XRD.
+func SchemasForObj(o runtime.Object) (*Schemas, error) {
+	s := newSchemas()
+
+	switch rd := o.(type) {
+	case *extv1beta1.CustomResourceDefinition:
+		if err := s.fromV1Beta1CRD(rd); err != nil {
+			return nil, err
+		}
+	case *extv1.CustomResourceDefinition:
+		if err := s.fromV1CRD(rd); err != nil {
+			return nil, err
+		}
+	case *xpextv1.CompositeResourceDefinition:
+		if err := s.fromV1XRD(rd); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New(errObjectNotKnownType)
+	}
+
+	return s, nil
+}
+
+func (s *Schemas) fromV1Beta1CRD(c *extv1beta1.CustomResourceDefinition) error {
+	internal := &apiextensions.CustomResourceDefinition{}
+	if err := extv1beta1.Convert_v1beta1_CustomResourceDefinition_To_apiextensions_CustomResourceDefinition(c, internal, nil); err != nil {
+		return err
+	}
+
+	for _, v := range internal.Spec.Versions {
+		if v.Served {
+			s.served[gvk(internal.Spec.Group, v.Name, internal.Spec.Names.Kind)] = true
+		}
+		props := v.Schema
+		if internal.Spec.Validation != nil {
+			props = internal.Spec.Validation
+		}
+		if props == nil || props.OpenAPIV3Schema == nil {
+			continue
+		}
+		sch := &spec.Schema{}
+		if err := validation.ConvertJSONSchemaPropsWithPostProcess(props.OpenAPIV3Schema, sch, validation.StripUnsupportedFormatsPostProcess); err != nil {
+			return err
+		}
+		s.schemas[gvk(internal.Spec.Group, v.Name, internal.Spec.Names.Kind)] = sch
+	}
+	return nil
+}
+
+func (s *Schemas) fromV1CRD(c *extv1.CustomResourceDefinition) error {
+	for _, v := range c.Spec.Versions {
+		if v.Served {
+			s.served[gvk(c.Spec.Group, v.Name, c.Spec.Names.Kind)] = true
+		}
+		if v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+		sch, err := newV1Schema(*v.Schema.OpenAPIV3Schema)
+		if err != nil {
+			return err
+		}
+		s.schemas[gvk(c.Spec.Group, v.Name, c.Spec.Names.Kind)] = sch
+	}
+	return nil
+}
+
+func (s *Schemas) fromV1XRD(x *xpextv1.CompositeResourceDefinition) error {
+	for _, v := range x.Spec.Versions {
+		if v.Served {
+			s.served[gvk(x.Spec.Group, v.Name, x.Spec.Names.Kind)] = true
+			if x.Spec.ClaimNames != nil {
+				s.served[gvk(x.Spec.Group, v.Name, x.Spec.ClaimNames.Kind)] = true
+			}
+		}
+		if v.Schema == nil {
+			continue
+		}
+		props, err := buildSchema(v.Schema.OpenAPIV3Schema)
+		if err != nil {
+			return err
+		}
+		sch, err := newV1Schema(*props)
+		if err != nil {
+			return err
+		}
+		if x.Spec.ClaimNames != nil {
+			s.schemas[gvk(x.Spec.Group, v.Name, x.Spec.ClaimNames.Kind)] = sch
+		}
+		xr := gvk(x.Spec.Group, v.Name, x.Spec.Names.Kind)
+		s.schemas[xr] = sch
+		s.composites[xr] = true
+	}
+	return nil
+}
+
+// add adds the supplied schemas to the Schemas, overriding the schemas of
+// kinds that are already known.
+func (s *Schemas) add(o *Schemas) {
+	for gvk, sch := range o.schemas {
+		s.schemas[gvk] = sch
+	}
+	for gvk := range o.composites {
+		s.composites[gvk] = true
+	}
+	for gvk := range o.served {
+		s.served[gvk] = true
+	}
+}
+
+// schemaAt returns the schema of the field at the supplied path within the
+// supplied schema, if one exists. Nil otherwise.
+func schemaAt(s *spec.Schema, path fieldpath.Segments) *spec.Schema {
+	for _, seg := range path {
+		if s == nil {
+			return nil
+		}
+		switch seg.Type {
+		case fieldpath.SegmentField:
+			if p, ok := s.Properties[seg.Field]; ok {
+				s = &p
+				continue
+			}
+			if s.AdditionalProperties == nil {
+				return nil
+			}
+			s = s.AdditionalProperties.Schema
+		case fieldpath.SegmentIndex:
+			if s.Items == nil {
+				return nil
+			}
+			s = s.Items.Schema
+		}
+	}
+	return s
+}
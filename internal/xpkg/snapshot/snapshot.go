@@ -31,6 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"k8s.io/kube-openapi/pkg/validation/spec"
 	"k8s.io/kube-openapi/pkg/validation/validate"
 
 	apimachyaml "k8s.io/apimachinery/pkg/util/yaml"
@@ -39,12 +40,12 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
 	"github.com/goccy/go-yaml"
-	"github.com/goccy/go-yaml/token"
 	"github.com/golang/tools/lsp/protocol"
 	"github.com/golang/tools/span"
 
 	"github.com/upbound/up/internal/xpkg"
 	"github.com/upbound/up/internal/xpkg/dep/cache"
+	"github.com/upbound/up/internal/xpkg/dep/lock"
 	"github.com/upbound/up/internal/xpkg/dep/manager"
 	mxpkg "github.com/upbound/up/internal/xpkg/dep/marshaler/xpkg"
 	"github.com/upbound/up/internal/xpkg/scheme"
@@ -66,6 +67,8 @@ const (
 type DepManager interface {
 	View(context.Context, []v1beta1.Dependency) (*manager.View, error)
 	Versions(context.Context, v1beta1.Dependency) ([]string, error)
+	ObjectPath(v1beta1.Dependency, string) (string, error)
+	Defining(string) ([]cache.Entry, error)
 	Watch() <-chan cache.Event
 }
 
@@ -87,10 +90,16 @@ type Snapshot struct {
 	// packages includes the parsed packages from the defined package
 	// dependencies.
 	packages map[string]*mxpkg.ParsedPackage
+	// depErrs includes the errors of the defined package dependencies that
+	// could not be resolved, keyed by the lock key of their package.
+	depErrs map[string]error
 	// validators includes validators for both the workspace as well as
 	// the external dependencies defined in the crossplane.yaml.
 	validators map[schema.GroupVersionKind]validator.Validator
-	wsview     *workspace.View
+	// schemas includes the OpenAPI schemas of the CRDs and XRDs of both the
+	// workspace as well as the external dependencies.
+	schemas *Schemas
+	wsview  *workspace.View
 }
 
 // Factory is used to "stamp out" Snapshots while allowing
@@ -149,6 +158,7 @@ func (f *Factory) New(ctx context.Context, opts ...Option) (*Snapshot, error) {
 		objScheme:  f.objScheme,
 		metaScheme: f.metaScheme,
 		validators: make(map[schema.GroupVersionKind]validator.Validator),
+		schemas:    newSchemas(),
 	}
 
 	// use the manager instance from the Factory
@@ -212,10 +222,14 @@ func (s *Snapshot) init(ctx context.Context) error {
 				for gvk, v := range validators {
 					s.validators[gvk] = v
 				}
+				if schemas, err := SchemasForObj(o); err == nil {
+					s.schemas.add(schemas)
+				}
 			}
 		}
 
 		s.packages = extView.Packages()
+		s.depErrs = extView.Errors()
 	}
 
 	// initialize snapshot validators with workspace validators
@@ -260,10 +274,27 @@ func (s *Snapshot) Validator(gvk schema.GroupVersionKind) validator.Validator {
 	return s.validators[gvk]
 }
 
+// Schema returns the OpenAPI schema corresponding to the provided GVK within
+// the Snapshot, if one exists. Nil otherwise.
+func (s *Snapshot) Schema(gvk schema.GroupVersionKind) *spec.Schema {
+	return s.schemas.schemas[gvk]
+}
+
 // Package returns the ParsedPackage corresponding to the supplied package name
-// as defined in the crossplane.yaml, if one exists. Nil otherwise.
+// as defined in the crossplane.yaml, if one exists. Nil otherwise. Names are
+// compared by their lock key, so that the registry of packages on Docker Hub
+// may or may not be included.
 func (s *Snapshot) Package(name string) *mxpkg.ParsedPackage {
-	return s.packages[name]
+	if p, ok := s.packages[name]; ok {
+		return p
+	}
+	k := lock.Key(name)
+	for _, p := range s.packages {
+		if lock.Key(p.Name()) == k {
+			return p
+		}
+	}
+	return nil
 }
 
 // ReParseFile re-parses the file at the given path. This is only useful in
@@ -352,19 +383,50 @@ func (s *Snapshot) updateChanges(_ context.Context, uri span.URI, changes []prot
 // the corresponding validators and applying them to the workspace.
 func (s *Snapshot) loadWSValidators(ctx context.Context) error { // nolint:gocyclo
 	for _, d := range s.wsview.FileDetails() {
-		validators, err := s.validatorsFromBytes(ctx, d.Body)
+		objs, err := s.decodeObjects(d.Body)
 		if err != nil {
 			continue
 		}
-		for gvk, v := range validators {
+		for gvk, v := range s.validatorsFromObjects(ctx, objs) {
 			s.validators[gvk] = v
 		}
+		for _, o := range objs {
+			if schemas, err := SchemasForObj(o); err == nil {
+				s.schemas.add(schemas)
+			}
+		}
 	}
 	return nil
 }
 
 func (s *Snapshot) validatorsFromBytes(ctx context.Context, b []byte) (map[schema.GroupVersionKind]validator.Validator, error) {
+	objs, err := s.decodeObjects(b)
+	if err != nil {
+		return nil, err
+	}
+	return s.validatorsFromObjects(ctx, objs), nil
+}
+
+func (s *Snapshot) validatorsFromObjects(ctx context.Context, objs []runtime.Object) map[schema.GroupVersionKind]validator.Validator {
 	result := map[schema.GroupVersionKind]validator.Validator{}
+	for _, o := range objs {
+		validators, err := ValidatorsForObj(ctx, o, s)
+		if err != nil {
+			// skip YAML document if we cannot acquire validators for object
+			continue
+		}
+
+		for gvk, v := range validators {
+			result[gvk] = v
+		}
+	}
+	return result
+}
+
+// decodeObjects decodes the YAML documents of the given bytes that are known
+// to the object or meta schemes.
+func (s *Snapshot) decodeObjects(b []byte) ([]runtime.Object, error) {
+	objs := []runtime.Object{}
 
 	yr := apimachyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(b)))
 	do := json.NewSerializerWithOptions(json.DefaultMetaFactory, s.objScheme, s.objScheme, json.SerializerOptions{Yaml: true})
@@ -391,18 +453,10 @@ func (s *Snapshot) validatorsFromBytes(ctx context.Context, b []byte) (map[schem
 			}
 		}
 
-		validators, err := ValidatorsForObj(ctx, o, s)
-		if err != nil {
-			// skip YAML document if we cannot acquire validators for object
-			continue
-		}
-
-		for gvk, v := range validators {
-			result[gvk] = v
-		}
+		objs = append(objs, o)
 	}
 
-	return result, nil
+	return objs, nil
 }
 
 // ValidateAllFiles performs validations on all files in Snapshot.
@@ -441,6 +495,12 @@ func (s *Snapshot) Validate(ctx context.Context, uri span.URI) ([]protocol.Diagn
 		return nil, errors.New(errInvalidFileURI)
 	}
 
+	// objects in examples are reported separately from package objects.
+	schemaRule := validator.RuleObjectSchema
+	if workspace.IsExample(uri.Filename()) {
+		schemaRule = validator.RuleExampleSchema
+	}
+
 	for id := range details.NodeIDs {
 		n, ok := s.wsview.Nodes()[id]
 		if !ok {
@@ -454,20 +514,20 @@ func (s *Snapshot) Validate(ctx context.Context, uri span.URI) ([]protocol.Diagn
 				Errors: gvkDNEWarning(gvk, "apiVersion"),
 			}
 
-			diags = append(diags, validationDiagnostics(dneResult, n.GetAST(), n.GetGVK())...)
+			diags = append(diags, validationDiagnostics(dneResult, n.GetAST(), n.GetGVK(), schemaRule)...)
 			continue
 		}
 
-		diags = append(diags, validationDiagnostics(v.Validate(ctx, n.GetObject()), n.GetAST(), n.GetGVK())...)
+		diags = append(diags, validationDiagnostics(v.Validate(ctx, n.GetObject()), n.GetAST(), n.GetGVK(), schemaRule)...)
 	}
 	return diags, nil
 }
 
 // validationDiagnostics generates language server diagnostics from validation
-// errors.
+// errors. Schema validation errors are reported for the supplied rule.
 // TODO(@tnthornton) this function is getting pretty complex. We should work
 // towards breaking it up.
-func validationDiagnostics(res *validate.Result, n ast.Node, gvk schema.GroupVersionKind) []protocol.Diagnostic { // nolint:gocyclo
+func validationDiagnostics(res *validate.Result, n ast.Node, gvk schema.GroupVersionKind, schemaRule string) []protocol.Diagnostic { // nolint:gocyclo
 	diags := []protocol.Diagnostic{}
 	for _, err := range res.Errors {
 		var e *verror
@@ -477,12 +537,14 @@ func validationDiagnostics(res *validate.Result, n ast.Node, gvk schema.GroupVer
 				code:    et.Code(),
 				message: fmt.Sprintf("%s (%s)", et.Error(), gvk),
 				name:    et.Name,
+				rule:    schemaRule,
 			}
 		case *validator.Validation:
 			e = &verror{
 				code:    et.Code(),
 				message: et.Error(),
 				name:    et.Name,
+				rule:    et.Rule,
 			}
 		default:
 			// found an error type we weren't expecting
@@ -515,16 +577,6 @@ func validationDiagnostics(res *validate.Result, n ast.Node, gvk schema.GroupVer
 			}
 			tok := node.GetToken()
 			if tok != nil {
-				startCh, endCh := tok.Position.Column-1, 0
-
-				// end character can be unmatched if we have doublequotes
-				switch tok.Type { // nolint:exhaustive
-				case token.DoubleQuoteType:
-					endCh = tok.Position.Column + len(tok.Value) + 1
-				default:
-					endCh = tok.Position.Column + len(tok.Value) - 1
-				}
-
 				// handle different types of diagnostic notifications
 				var sev protocol.DiagnosticSeverity
 				switch c := e.code; {
@@ -540,21 +592,16 @@ func validationDiagnostics(res *validate.Result, n ast.Node, gvk schema.GroupVer
 				// and column by NOT being zero-indexed, but VSCode
 				// interprets ranges with zero-indexing. We should
 				// develop a more robust solution for this conversion.
-				diags = append(diags, protocol.Diagnostic{
-					Range: protocol.Range{
-						Start: protocol.Position{
-							Line:      uint32(tok.Position.Line - 1),
-							Character: uint32(startCh),
-						},
-						End: protocol.Position{
-							Line:      uint32(tok.Position.Line - 1),
-							Character: uint32(endCh),
-						},
-					},
+				d := protocol.Diagnostic{
+					Range:    tokenRange(tok),
 					Message:  e.Error(),
 					Severity: sev,
 					Source:   serverName,
-				})
+				}
+				if e.rule != "" {
+					d.Code = e.rule
+				}
+				diags = append(diags, d)
 			}
 		}
 	}
@@ -566,6 +613,7 @@ type verror struct {
 	code    int32
 	message string
 	name    string
+	rule    string
 }
 
 func (e *verror) Error() string {
@@ -17,6 +17,7 @@ package snapshot
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -112,7 +113,10 @@ func TestWSLoadValidators(t *testing.T) {
 	}
 }
 
-type MockDepManager struct{}
+type MockDepManager struct {
+	versions []string
+	entries  []cache.Entry
+}
 
 func NewMockDepManager() *MockDepManager { return &MockDepManager{} }
 
@@ -120,7 +124,15 @@ func (m *MockDepManager) View(context.Context, []v1beta1.Dependency) (*manager.V
 	return nil, nil
 }
 func (m *MockDepManager) Versions(context.Context, v1beta1.Dependency) ([]string, error) {
-	return nil, nil
+	return m.versions, nil
+}
+
+func (m *MockDepManager) ObjectPath(d v1beta1.Dependency, name string) (string, error) {
+	return filepath.Join("/cache", d.Package+"@"+d.Constraints, name+".yaml"), nil
+}
+
+func (m *MockDepManager) Defining(string) ([]cache.Entry, error) {
+	return m.entries, nil
 }
 
 func (m *MockDepManager) Watch() <-chan cache.Event {
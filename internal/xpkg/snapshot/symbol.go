@@ -0,0 +1,309 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+
+	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
+
+	"github.com/upbound/up/internal/xpkg/workspace"
+)
+
+const (
+	keyFunctionRef   = "functionRef"
+	keyInput         = "input"
+	keyName          = "name"
+	keyPatchSetName  = "patchSetName"
+	keyPipeline      = "pipeline"
+	keyStep          = "step"
+	patchTypeDefault = "FromCompositeFieldPath"
+	patchTypeSet     = "PatchSet"
+)
+
+// DocumentSymbols returns the outline of the file at the supplied URI. Each
+// object in the file is a symbol. The symbol of a Composition holds its patch
+// sets, pipeline steps and resources by name, which hold their patches.
+func (s *Snapshot) DocumentSymbols(_ context.Context, uri span.URI) ([]protocol.DocumentSymbol, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.wsview.FileDetails()[uri]; !ok {
+		return nil, errors.New(errInvalidFileURI)
+	}
+
+	syms := []protocol.DocumentSymbol{}
+	for _, n := range s.rootNodes() {
+		if span.URIFromPath(n.GetFileName()) != uri {
+			continue
+		}
+		var children []protocol.DocumentSymbol
+		if isComposition(n.GetGVK()) {
+			children = compositionSymbols(n.GetAST())
+		}
+		syms = append(syms, symbol(objectName(n), n.GetGVK().Kind, symbolKind(n), n.GetAST(), children, pathMetadataName, pathKind))
+	}
+	sort.Slice(syms, func(i, j int) bool {
+		return syms[i].Range.Start.Line < syms[j].Range.Start.Line
+	})
+	return syms, nil
+}
+
+// WorkspaceSymbols returns the objects of the workspace whose name or kind
+// contains the supplied query, ignoring case. Every object matches an empty
+// query.
+func (s *Snapshot) WorkspaceSymbols(_ context.Context, query string) ([]protocol.SymbolInformation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	q := strings.ToLower(query)
+
+	syms := []protocol.SymbolInformation{}
+	for _, n := range s.rootNodes() {
+		name, kind := objectName(n), n.GetGVK().Kind
+		if !strings.Contains(strings.ToLower(name), q) && !strings.Contains(strings.ToLower(kind), q) {
+			continue
+		}
+		syms = append(syms, protocol.SymbolInformation{
+			Name: name,
+			Kind: symbolKind(n),
+			Location: protocol.Location{
+				URI:   protocol.DocumentURI(span.URIFromPath(n.GetFileName())),
+				Range: astRange(n.GetAST()),
+			},
+			ContainerName: kind,
+		})
+	}
+	sort.Slice(syms, func(i, j int) bool {
+		if syms[i].Name != syms[j].Name {
+			return syms[i].Name < syms[j].Name
+		}
+		if syms[i].Location.URI != syms[j].Location.URI {
+			return syms[i].Location.URI < syms[j].Location.URI
+		}
+		return syms[i].Location.Range.Start.Line < syms[j].Location.Range.Start.Line
+	})
+	return syms, nil
+}
+
+// rootNodes returns the nodes of the objects at the root of the files of the
+// workspace, including examples.
+func (s *Snapshot) rootNodes() []workspace.Node {
+	nodes := make([]workspace.Node, 0)
+	for _, details := range s.wsview.FileDetails() {
+		for id := range details.NodeIDs {
+			n, ok := s.wsview.Nodes()[id]
+			// NOTE: examples are collected separately as nodes of the same
+			// name and kind in different files share an identifier.
+			if !ok || workspace.IsExample(n.GetFileName()) {
+				continue
+			}
+			nodes = append(nodes, n)
+		}
+	}
+	for _, ex := range s.wsview.Examples() {
+		nodes = append(nodes, ex...)
+	}
+	return nodes
+}
+
+// compositionSymbols returns the symbols of the patch sets, pipeline steps
+// and resources of the supplied Composition.
+func compositionSymbols(n ast.Node) []protocol.DocumentSymbol {
+	syms := templateSymbols(n, "$."+keySpec)
+	for i, step := range sequenceAt(n, "$."+keySpec+"."+keyPipeline) {
+		name := stringAt(step, "$."+keyStep)
+		if name == "" {
+			name = fmt.Sprintf("%s[%d]", keyPipeline, i)
+		}
+		syms = append(syms, symbol(name, stringAt(step, "$."+keyFunctionRef+"."+keyName), protocol.Function, step, templateSymbols(step, "$."+keyInput), "$."+keyStep))
+	}
+	return syms
+}
+
+// templateSymbols returns the symbols of the patch sets and resources at the
+// supplied YAML path of the supplied node. Resources are found in the spec of
+// Compositions that use resources mode, and in the input of pipeline steps
+// that use the patch and transform function.
+func templateSymbols(n ast.Node, path string) []protocol.DocumentSymbol {
+	syms := []protocol.DocumentSymbol{}
+	for i, ps := range sequenceAt(n, path+"."+keyPatchSets) {
+		name := stringAt(ps, "$."+keyName)
+		if name == "" {
+			name = fmt.Sprintf("%s[%d]", keyPatchSets, i)
+		}
+		syms = append(syms, symbol(name, "", protocol.Namespace, ps, patchSymbols(ps), "$."+keyName))
+	}
+	for i, r := range sequenceAt(n, path+"."+keyResources) {
+		name := stringAt(r, "$."+keyName)
+		if name == "" {
+			name = fmt.Sprintf("%s[%d]", keyResources, i)
+		}
+		syms = append(syms, symbol(name, stringAt(r, "$."+keyBase+"."+keyKind), protocol.Object, r, patchSymbols(r), "$."+keyName, "$."+keyBase+"."+keyKind))
+	}
+	return syms
+}
+
+// patchSymbols returns the symbols of the patches of the supplied resource or
+// patch set. Patches are named by the field paths they patch from and to.
+func patchSymbols(n ast.Node) []protocol.DocumentSymbol {
+	syms := []protocol.DocumentSymbol{}
+	for i, p := range sequenceAt(n, "$."+keyPatches) {
+		typ := stringAt(p, "$."+keyType)
+		if typ == "" {
+			typ = patchTypeDefault
+		}
+		name := patchName(p, typ)
+		if name == "" {
+			name = fmt.Sprintf("%s[%d]", keyPatches, i)
+		}
+		syms = append(syms, symbol(name, typ, protocol.Field, p, nil, "$."+keyPatchSetName, "$."+keyFromFieldPath, "$."+keyToFieldPath, "$."+keyType))
+	}
+	return syms
+}
+
+// patchName returns the name of the supplied patch of the supplied type. The
+// name of a patch that applies a patch set is the name of the patch set.
+func patchName(p ast.Node, typ string) string {
+	if typ == patchTypeSet {
+		return stringAt(p, "$."+keyPatchSetName)
+	}
+	from := stringAt(p, "$."+keyFromFieldPath)
+	if vars := sequenceAt(p, "$."+keyCombine+"."+keyVariables); len(vars) > 0 {
+		paths := make([]string, len(vars))
+		for i, v := range vars {
+			paths[i] = stringAt(v, "$."+keyFromFieldPath)
+		}
+		from = strings.Join(paths, ", ")
+	}
+	to := stringAt(p, "$."+keyToFieldPath)
+	switch {
+	case from == "" && to == "":
+		return ""
+	case to == "":
+		// NOTE: the field path that is patched from is also patched to if
+		// no other is supplied.
+		to = from
+	}
+	return from + " → " + to
+}
+
+// symbol returns the symbol of the supplied AST node, which spans the whole
+// node. The first value at the supplied YAML paths of the node is selected
+// when the symbol is picked, or the start of the node if there is none.
+func symbol(name, detail string, kind protocol.SymbolKind, n ast.Node, children []protocol.DocumentSymbol, selection ...string) protocol.DocumentSymbol {
+	rng := astRange(n)
+	sel := protocol.Range{Start: rng.Start, End: rng.Start}
+	for _, path := range selection {
+		if r := valueRange(n, path); r != (protocol.Range{}) {
+			sel = r
+			break
+		}
+	}
+	return protocol.DocumentSymbol{
+		Name:           name,
+		Detail:         detail,
+		Kind:           kind,
+		Range:          rng,
+		SelectionRange: sel,
+		Children:       children,
+	}
+}
+
+// symbolKind returns the kind of symbol of the supplied node.
+func symbolKind(n workspace.Node) protocol.SymbolKind {
+	switch {
+	case isComposition(n.GetGVK()):
+		return protocol.Class
+	case n.GetGVK().Group == pkgmetav1.Group:
+		return protocol.Package
+	}
+	if _, gks := definedKinds(n.GetObject()); len(gks) > 0 {
+		return protocol.Interface
+	}
+	return protocol.Object
+}
+
+// objectName returns the name of the supplied node, or its kind if it has
+// no name.
+func objectName(n workspace.Node) string {
+	if name := stringAt(n.GetAST(), pathMetadataName); name != "" {
+		return name
+	}
+	return n.GetGVK().Kind
+}
+
+// stringAt returns the scalar value at the supplied YAML path of the supplied
+// AST node, or an empty string if there is none.
+func stringAt(n ast.Node, path string) string {
+	v, ok := nodeAt(n, path).(ast.ScalarNode)
+	if !ok || v.GetToken() == nil {
+		return ""
+	}
+	return v.GetToken().Value
+}
+
+// sequenceAt returns the items of the sequence at the supplied YAML path of
+// the supplied AST node, or nil if there is none.
+func sequenceAt(n ast.Node, path string) []ast.Node {
+	seq, ok := nodeAt(n, path).(*ast.SequenceNode)
+	if !ok {
+		return nil
+	}
+	return seq.Values
+}
+
+// astRange returns the range spanned by the tokens of the supplied AST node.
+func astRange(n ast.Node) protocol.Range {
+	v := &rangeVisitor{}
+	ast.Walk(v, n)
+	return v.rng
+}
+
+// rangeVisitor extends its range to the tokens of the AST nodes it visits.
+type rangeVisitor struct {
+	rng protocol.Range
+	set bool
+}
+
+// Visit extends the range of the visitor to the token of the supplied node.
+func (v *rangeVisitor) Visit(n ast.Node) ast.Visitor {
+	if n == nil {
+		return v
+	}
+	if _, ok := n.(*ast.CommentGroupNode); ok || n.GetToken() == nil {
+		return v
+	}
+	r := tokenRange(n.GetToken())
+	if !v.set || before(r.Start, v.rng.Start) {
+		v.rng.Start = r.Start
+	}
+	if !v.set || before(v.rng.End, r.End) {
+		v.rng.End = r.End
+	}
+	v.set = true
+	return v
+}
+
+// before returns true if the first supplied position is before the second.
+func before(a, b protocol.Position) bool {
+	return a.Line < b.Line || (a.Line == b.Line && a.Character < b.Character)
+}
@@ -0,0 +1,295 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDocumentSymbols(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		file   string
+		body   string
+		want   []protocol.DocumentSymbol
+	}{
+		"Resources": {
+			reason: "The outline of a Composition should hold its patch sets and resources by name, which hold their patches.",
+			file:   "composition.yaml",
+			body: `apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: certificates
+spec:
+  compositeTypeRef:
+    apiVersion: example.org/v1alpha1
+    kind: XCertificate
+  patchSets:
+  - name: common
+    patches:
+    - type: PatchSet
+      patchSetName: other
+  resources:
+  - name: certificate
+    base:
+      apiVersion: acm.aws.crossplane.io/v1alpha1
+      kind: Certificate
+    patches:
+    - fromFieldPath: spec.domain
+      toFieldPath: spec.forProvider.domainName
+    - type: CombineFromComposite
+      combine:
+        variables:
+        - fromFieldPath: spec.a
+        - fromFieldPath: spec.b
+      toFieldPath: spec.c
+  - base:
+      apiVersion: s3.aws.upbound.io/v1beta1
+      kind: Bucket
+`,
+			want: []protocol.DocumentSymbol{{
+				Name:           "certificates",
+				Detail:         "Composition",
+				Kind:           protocol.Class,
+				Range:          spanRange(0, 0, 29, 18),
+				SelectionRange: rng(3, 8, 20),
+				Children: []protocol.DocumentSymbol{
+					{
+						Name:           "common",
+						Kind:           protocol.Namespace,
+						Range:          spanRange(9, 4, 12, 25),
+						SelectionRange: rng(9, 10, 16),
+						Children: []protocol.DocumentSymbol{{
+							Name:           "other",
+							Detail:         "PatchSet",
+							Kind:           protocol.Field,
+							Range:          spanRange(11, 6, 12, 25),
+							SelectionRange: rng(12, 20, 25),
+						}},
+					},
+					{
+						Name:           "certificate",
+						Detail:         "Certificate",
+						Kind:           protocol.Object,
+						Range:          spanRange(14, 4, 26, 25),
+						SelectionRange: rng(14, 10, 21),
+						Children: []protocol.DocumentSymbol{
+							{
+								Name:           "spec.domain → spec.forProvider.domainName",
+								Detail:         "FromCompositeFieldPath",
+								Kind:           protocol.Field,
+								Range:          spanRange(19, 6, 20, 46),
+								SelectionRange: rng(19, 21, 32),
+							},
+							{
+								Name:           "spec.a, spec.b → spec.c",
+								Detail:         "CombineFromComposite",
+								Kind:           protocol.Field,
+								Range:          spanRange(21, 6, 26, 25),
+								SelectionRange: rng(26, 19, 25),
+							},
+						},
+					},
+					{
+						Name:           "resources[1]",
+						Detail:         "Bucket",
+						Kind:           protocol.Object,
+						Range:          spanRange(27, 4, 29, 18),
+						SelectionRange: rng(29, 12, 18),
+						Children:       []protocol.DocumentSymbol{},
+					},
+				},
+			}},
+		},
+		"Pipeline": {
+			reason: "The outline of a Composition should hold its pipeline steps, which hold the resources of their input.",
+			file:   "composition.yaml",
+			body: `apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: buckets
+spec:
+  mode: Pipeline
+  pipeline:
+  - step: patch-and-transform
+    functionRef:
+      name: function-patch-and-transform
+    input:
+      apiVersion: pt.fn.crossplane.io/v1beta1
+      kind: Resources
+      resources:
+      - name: bucket
+        base:
+          apiVersion: s3.aws.upbound.io/v1beta1
+          kind: Bucket
+        patches:
+        - fromFieldPath: spec.region
+`,
+			want: []protocol.DocumentSymbol{{
+				Name:           "buckets",
+				Detail:         "Composition",
+				Kind:           protocol.Class,
+				Range:          spanRange(0, 0, 19, 36),
+				SelectionRange: rng(3, 8, 15),
+				Children: []protocol.DocumentSymbol{{
+					Name:           "patch-and-transform",
+					Detail:         "function-patch-and-transform",
+					Kind:           protocol.Function,
+					Range:          spanRange(7, 4, 19, 36),
+					SelectionRange: rng(7, 10, 29),
+					Children: []protocol.DocumentSymbol{{
+						Name:           "bucket",
+						Detail:         "Bucket",
+						Kind:           protocol.Object,
+						Range:          spanRange(14, 8, 19, 36),
+						SelectionRange: rng(14, 14, 20),
+						Children: []protocol.DocumentSymbol{{
+							Name:           "spec.region → spec.region",
+							Detail:         "FromCompositeFieldPath",
+							Kind:           protocol.Field,
+							Range:          rng(19, 10, 36),
+							SelectionRange: rng(19, 25, 36),
+						}},
+					}},
+				}},
+			}},
+		},
+		"Examples": {
+			reason: "Each object of a file should be a symbol, in the order of the file.",
+			file:   "examples/claims.yaml",
+			body: `apiVersion: example.org/v1alpha1
+kind: CertificateClaim
+metadata:
+  name: first
+---
+apiVersion: example.org/v1alpha1
+kind: CertificateClaim
+metadata:
+  name: second
+`,
+			want: []protocol.DocumentSymbol{
+				{
+					Name:           "first",
+					Detail:         "CertificateClaim",
+					Kind:           protocol.Object,
+					Range:          spanRange(0, 0, 3, 13),
+					SelectionRange: rng(3, 8, 13),
+				},
+				{
+					Name:           "second",
+					Detail:         "CertificateClaim",
+					Kind:           protocol.Object,
+					Range:          spanRange(5, 0, 8, 14),
+					SelectionRange: rng(8, 8, 14),
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := newTestSnapshot(t, map[string][]byte{
+				"crd.yaml": testSingleVersionCRD,
+				"xrd.yaml": testXRD,
+				tc.file:    []byte(tc.body),
+			})
+
+			got, err := s.DocumentSymbols(context.Background(), span.URIFromPath("/ws/"+tc.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nDocumentSymbols(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWorkspaceSymbols(t *testing.T) {
+	composition := protocol.SymbolInformation{
+		Name:          "certificates",
+		Kind:          protocol.Class,
+		Location:      protocol.Location{URI: "file:///ws/composition.yaml", Range: spanRange(0, 0, 14, 18)},
+		ContainerName: "Composition",
+	}
+	claim := protocol.SymbolInformation{
+		Name:          "example",
+		Kind:          protocol.Object,
+		Location:      protocol.Location{URI: "file:///ws/examples/claim.yaml", Range: spanRange(0, 0, 5, 21)},
+		ContainerName: "CertificateClaim",
+	}
+	xrd := protocol.SymbolInformation{
+		Name:          "xcertificates.example.org",
+		Kind:          protocol.Interface,
+		Location:      protocol.Location{URI: "file:///ws/xrd.yaml", Range: spanRange(0, 0, 29, 29)},
+		ContainerName: "CompositeResourceDefinition",
+	}
+
+	cases := map[string]struct {
+		reason string
+		query  string
+		want   []protocol.SymbolInformation
+	}{
+		"Name": {
+			reason: "Objects whose name contains the query should be returned, ignoring case.",
+			query:  "XCert",
+			want:   []protocol.SymbolInformation{xrd},
+		},
+		"Kind": {
+			reason: "Objects whose kind contains the query should be returned.",
+			query:  "composition",
+			want:   []protocol.SymbolInformation{composition},
+		},
+		"Empty": {
+			reason: "All objects should be returned for an empty query, sorted by name.",
+			want:   []protocol.SymbolInformation{composition, claim, xrd},
+		},
+		"NoMatch": {
+			reason: "Nothing should be returned if no object matches the query.",
+			query:  "bucket",
+			want:   []protocol.SymbolInformation{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := newTestSnapshot(t, map[string][]byte{
+				"xrd.yaml":            testXRD,
+				"composition.yaml":    []byte(testComposition),
+				"examples/claim.yaml": []byte(testClaim),
+			})
+
+			got, err := s.WorkspaceSymbols(context.Background(), tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nWorkspaceSymbols(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func spanRange(startLine, startChar, endLine, endChar uint32) protocol.Range {
+	return protocol.Range{
+		Start: protocol.Position{Line: startLine, Character: startChar},
+		End:   protocol.Position{Line: endLine, Character: endChar},
+	}
+}
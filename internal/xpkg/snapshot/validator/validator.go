@@ -34,6 +34,43 @@ const (
 	// codes.
 )
 
+// Rules identify the check that produced a Validation, so that consumers can
+// report and filter validations per check.
+const (
+	// RuleObjectSchema reports objects that do not match the schema of their
+	// CRD or XRD.
+	RuleObjectSchema = "object-schema"
+	// RuleExampleSchema reports examples that do not match the schema of
+	// their CRD or XRD.
+	RuleExampleSchema = "example-schema"
+	// RuleDefinitionNotFound reports objects for which no CRD or XRD is
+	// known.
+	RuleDefinitionNotFound = "definition-not-found"
+	// RuleXRDSchema reports invalid XRD schemas.
+	RuleXRDSchema = "xrd-schema"
+	// RuleCompositionResources reports compositions whose resources cannot
+	// be composed.
+	RuleCompositionResources = "composition-resources"
+	// RuleCompositionPatches reports composed resources that do not match
+	// the schema of their CRD once patches are applied.
+	RuleCompositionPatches = "composition-patches"
+	// RuleDeprecatedAPIVersion reports meta files using a deprecated
+	// apiVersion.
+	RuleDeprecatedAPIVersion = "deprecated-api-version"
+	// RuleDependencyType reports dependencies declared with the wrong
+	// package type.
+	RuleDependencyType = "dependency-type"
+	// RuleDependencyVersion reports dependencies for which no matching
+	// version exists locally.
+	RuleDependencyVersion = "dependency-version"
+	// RuleDependencyConflict reports dependencies with conflicting version
+	// constraints in the dependency graph.
+	RuleDependencyConflict = "dependency-conflict"
+	// RuleDependencyLocked reports dependencies that are not pinned in the
+	// lock file when resolution is frozen.
+	RuleDependencyLocked = "dependency-locked"
+)
+
 // Nop is used for no-op validator results.
 var Nop = &validate.Result{}
 
@@ -47,6 +84,7 @@ type Validation struct {
 	TypeCode int32
 	Message  string
 	Name     string
+	Rule     string
 }
 
 // Code returns the code corresponding to the MetaValidation.
@@ -269,16 +269,24 @@ func buildSchema(s runtime.RawExtension) (*extv1.JSONSchemaProps, error) {
 
 // newSchemaValidator creates an openapi schema validator for the given JSONSchemaProps validation.
 func newV1SchemaValidator(schema extv1.JSONSchemaProps) (*validate.SchemaValidator, *spec.Schema, error) { //nolint:unparam
-	// Convert CRD schema to openapi schema
+	openapiSchema, err := newV1Schema(schema)
+	if err != nil {
+		return nil, nil, err
+	}
+	return validate.NewSchemaValidator(openapiSchema, nil, "", strfmt.Default), openapiSchema, nil
+}
+
+// newV1Schema converts the given JSONSchemaProps to an openapi schema.
+func newV1Schema(schema extv1.JSONSchemaProps) (*spec.Schema, error) {
 	openapiSchema := &spec.Schema{}
 	out := new(apiextensions.JSONSchemaProps)
 	if err := extv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(&schema, out, nil); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	if err := validation.ConvertJSONSchemaPropsWithPostProcess(out, openapiSchema, validation.StripUnsupportedFormatsPostProcess); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	return validate.NewSchemaValidator(openapiSchema, nil, "", strfmt.Default), openapiSchema, nil
+	return openapiSchema, nil
 }
 
 func gvk(group, version, kind string) schema.GroupVersionKind {
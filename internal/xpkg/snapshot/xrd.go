@@ -124,6 +124,7 @@ func (v *XRDSchemaValidator) validate(ctx context.Context, xrd *xpextv1.Composit
 				TypeCode: validator.ErrorTypeCode,
 				Name:     path,
 				Message:  fmt.Sprintf("%s %s", path, fe.ErrorBody()),
+				Rule:     validator.RuleXRDSchema,
 			},
 			)
 		}
@@ -22,13 +22,15 @@ const (
 	Configuration Package = "configuration"
 	// Provider represents a provider package.
 	Provider Package = "provider"
+	// Function represents a function package.
+	Function Package = "function"
 )
 
 // IsValid is a helper function for determining if the Package
 // is a valid type of package.
 func (p Package) IsValid() bool {
 	switch p {
-	case Configuration, Provider:
+	case Configuration, Provider, Function:
 		return true
 	}
 	return false
@@ -0,0 +1,309 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package meta
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+
+	"github.com/upbound/up/internal/xpkg/dep/lock"
+)
+
+const (
+	specPath     = "$.spec"
+	specKey      = "spec"
+	dependsOnKey = "dependsOn"
+	versionKey   = "version"
+
+	errParseMetaFile         = "failed to parse meta file: %w"
+	errNoDependencies        = "meta file does not declare any dependencies"
+	errFlowStyleDependsOn    = "editing flow style dependsOn is not supported"
+	errFlowStyleSpec         = "editing flow style spec is not supported"
+	errDependencyNotFoundFmt = "dependency %s not found in meta file"
+)
+
+// packageKeys are the keys used to reference a package in a dependency.
+var packageKeys = map[string]bool{
+	"provider":      true,
+	"configuration": true,
+	"function":      true,
+}
+
+// RemoveDependency removes the dependency on the supplied package from the
+// supplied meta file contents. Unlike Upsert, the edit is made in place so
+// that comments and formatting in the rest of the file are preserved.
+func RemoveDependency(b []byte, pkg string) ([]byte, error) {
+	key, seq, err := dependsOn(b)
+	if err != nil {
+		return nil, err
+	}
+	item, _, err := findDependency(seq, pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.SplitAfter(string(b), "\n")
+	start := itemStart(lines, item)
+	end := itemEnd(lines, start)
+
+	// drop the dependsOn key entirely if we are removing its last entry.
+	if len(seq.Values) == 1 {
+		start = key.Key.GetToken().Position.Line - 1
+	}
+
+	return []byte(strings.Join(append(lines[:start:start], lines[end:]...), "")), nil
+}
+
+// SetDependencyVersion sets the version constraint of the dependency on the
+// supplied package in the supplied meta file contents. Like RemoveDependency,
+// comments and formatting are preserved.
+func SetDependencyVersion(b []byte, pkg, version string) ([]byte, error) {
+	_, seq, err := dependsOn(b)
+	if err != nil {
+		return nil, err
+	}
+	item, pkgKey, err := findDependency(seq, pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.SplitAfter(string(b), "\n")
+
+	for _, mv := range mappingValues(item) {
+		if mv.Key.GetToken().Value != versionKey {
+			continue
+		}
+		tok := mv.Value.GetToken()
+		i := tok.Position.Line - 1
+		lines[i] = replaceScalar(lines[i], tok.Position.Column-1, tok.Value, version)
+		return []byte(strings.Join(lines, "")), nil
+	}
+
+	// the dependency does not have a version yet, add one below the package.
+	kt := pkgKey.Key.GetToken()
+	i := kt.Position.Line - 1
+	nl := fmt.Sprintf("%s%s: %s\n", strings.Repeat(" ", kt.Position.Column-1), versionKey, quote(version))
+	out := append(lines[:i+1:i+1], nl)
+	return []byte(strings.Join(append(out, lines[i+1:]...), "")), nil
+}
+
+// AddDependency adds the supplied dependency to the supplied meta file
+// contents. Like RemoveDependency, comments and formatting are preserved. The
+// dependency is added as the last entry of dependsOn, which is created if it
+// does not exist yet.
+func AddDependency(b []byte, d v1beta1.Dependency) ([]byte, error) { // nolint:gocyclo
+	if len(b) > 0 && b[len(b)-1] != '\n' {
+		b = append(b[:len(b):len(b)], '\n')
+	}
+	f, err := parser.ParseBytes(b, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf(errParseMetaFile, err)
+	}
+
+	lines := strings.SplitAfter(string(b), "\n")
+	insert := func(i int, s string) []byte {
+		out := append(lines[:i:i], s)
+		return []byte(strings.Join(append(out, lines[i:]...), ""))
+	}
+
+	var spec *ast.MappingValueNode
+	if len(f.Docs) > 0 {
+		for _, mv := range mappingValues(f.Docs[0].Body) {
+			if mv.Key.GetToken().Value == specKey {
+				spec = mv
+			}
+		}
+	}
+	if spec == nil {
+		return append(b, fmt.Sprintf("%s:\n  %s:\n%s", specKey, dependsOnKey, dependencyItem(d, 2))...), nil
+	}
+	if m, ok := spec.Value.(*ast.MappingNode); ok && m.IsFlowStyle {
+		return nil, errors.New(errFlowStyleSpec)
+	}
+
+	for _, mv := range mappingValues(spec.Value) {
+		kt := mv.Key.GetToken()
+		if kt.Value != dependsOnKey {
+			continue
+		}
+		seq, ok := mv.Value.(*ast.SequenceNode)
+		if !ok {
+			// dependsOn does not have any entries yet.
+			return insert(kt.Position.Line, dependencyItem(d, kt.Position.Column-1)), nil
+		}
+		if seq.IsFlowStyle {
+			return nil, errors.New(errFlowStyleDependsOn)
+		}
+		start := itemStart(lines, seq.Values[len(seq.Values)-1])
+		return insert(itemEnd(lines, start), dependencyItem(d, indentation(lines[start]))), nil
+	}
+
+	// spec does not have a dependsOn yet, add it as the last field of spec.
+	indent := 2
+	if mvs := mappingValues(spec.Value); len(mvs) > 0 {
+		indent = mvs[0].Key.GetToken().Position.Column - 1
+	}
+	i := itemEnd(lines, spec.Key.GetToken().Position.Line-1)
+	return insert(i, fmt.Sprintf("%s%s:\n%s", strings.Repeat(" ", indent), dependsOnKey, dependencyItem(d, indent))), nil
+}
+
+// dependencyItem returns the supplied dependency as an entry of the dependsOn
+// sequence with its entry indicator at the supplied indentation.
+func dependencyItem(d v1beta1.Dependency, indent int) string {
+	pad := strings.Repeat(" ", indent)
+	item := fmt.Sprintf("%s- %s: %s\n", pad, strings.ToLower(string(d.Type)), d.Package)
+	if d.Constraints != "" {
+		item += fmt.Sprintf("%s  %s: %s\n", pad, versionKey, quote(d.Constraints))
+	}
+	return item
+}
+
+// dependsOn returns the dependsOn key and sequence of the supplied meta file
+// contents.
+func dependsOn(b []byte) (*ast.MappingValueNode, *ast.SequenceNode, error) {
+	f, err := parser.ParseBytes(b, parser.ParseComments)
+	if err != nil {
+		return nil, nil, fmt.Errorf(errParseMetaFile, err)
+	}
+	if len(f.Docs) == 0 {
+		return nil, nil, errors.New(errNoDependencies)
+	}
+
+	path, err := yaml.PathString(specPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	spec, err := path.FilterNode(f.Docs[0].Body)
+	if err != nil || spec == nil {
+		return nil, nil, errors.New(errNoDependencies)
+	}
+
+	for _, mv := range mappingValues(spec) {
+		if mv.Key.GetToken().Value != dependsOnKey {
+			continue
+		}
+		seq, ok := mv.Value.(*ast.SequenceNode)
+		if !ok {
+			return nil, nil, errors.New(errNoDependencies)
+		}
+		if seq.IsFlowStyle {
+			return nil, nil, errors.New(errFlowStyleDependsOn)
+		}
+		return mv, seq, nil
+	}
+	return nil, nil, errors.New(errNoDependencies)
+}
+
+// findDependency returns the entry in the dependsOn sequence that references
+// the supplied package, as well as the key/value pair holding the package.
+func findDependency(seq *ast.SequenceNode, pkg string) (ast.Node, *ast.MappingValueNode, error) {
+	for _, v := range seq.Values {
+		for _, mv := range mappingValues(v) {
+			if !packageKeys[mv.Key.GetToken().Value] || mv.Value == nil {
+				continue
+			}
+			if lock.Key(mv.Value.GetToken().Value) == lock.Key(pkg) {
+				return v, mv, nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf(errDependencyNotFoundFmt, pkg)
+}
+
+func mappingValues(n ast.Node) []*ast.MappingValueNode {
+	switch t := n.(type) {
+	case *ast.MappingNode:
+		return t.Values
+	case *ast.MappingValueNode:
+		return []*ast.MappingValueNode{t}
+	}
+	return nil
+}
+
+// itemStart returns the index of the line holding the sequence entry
+// indicator for the supplied item.
+func itemStart(lines []string, item ast.Node) int {
+	i := item.GetToken().Position.Line - 1
+	if mvs := mappingValues(item); len(mvs) > 0 {
+		i = mvs[0].Key.GetToken().Position.Line - 1
+	}
+	// the entry indicator may be on its own line.
+	if !strings.HasPrefix(strings.TrimSpace(lines[i]), "-") && i > 0 && strings.TrimSpace(lines[i-1]) == "-" {
+		i--
+	}
+	return i
+}
+
+// itemEnd returns the index of the first line after the sequence entry
+// starting at the supplied line. Every subsequent line that is indented
+// deeper than the entry indicator is considered part of the entry.
+func itemEnd(lines []string, start int) int {
+	indent := indentation(lines[start])
+	end := start + 1
+	for i := start + 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			continue
+		}
+		if indentation(lines[i]) <= indent {
+			break
+		}
+		end = i + 1
+	}
+	return end
+}
+
+func indentation(l string) int {
+	return len(l) - len(strings.TrimLeft(l, " "))
+}
+
+// replaceScalar replaces the scalar starting at the supplied column in the
+// supplied line, keeping its quoting style where possible.
+func replaceScalar(line string, col int, old, value string) string {
+	r := []rune(line)
+	if col >= len(r) {
+		return line
+	}
+	switch q := r[col]; q {
+	case '"', '\'':
+		end := col + 1
+		for end < len(r) && r[end] != q {
+			end++
+		}
+		return string(r[:col+1]) + value + string(r[end:])
+	default:
+		end := col + len([]rune(old))
+		if end > len(r) {
+			end = len(r)
+		}
+		return string(r[:col]) + quote(value) + string(r[end:])
+	}
+}
+
+// quote double quotes the supplied value unless it is safe to use as a plain
+// scalar.
+func quote(v string) string {
+	if v != "" && (unicode.IsLetter(rune(v[0])) || unicode.IsDigit(rune(v[0]))) && !strings.ContainsAny(v, ":#") {
+		return v
+	}
+	return fmt.Sprintf("%q", v)
+}
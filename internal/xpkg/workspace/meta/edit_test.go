@@ -0,0 +1,297 @@
+// Copyright 2024 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package meta
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+)
+
+var metaFile = `apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: platform-ref-aws
+spec:
+  crossplane:
+    version: ">=v1.14.0"
+  # providers used by the compositions
+  dependsOn:
+    - provider: xpkg.upbound.io/upbound/provider-aws-ec2
+      version: "v0.47.0" # pinned for networking
+    # database
+    - provider: xpkg.upbound.io/upbound/provider-aws-rds
+      version: v0.47.0
+    - configuration: xpkg.upbound.io/upbound/configuration-aws-network
+`
+
+func TestRemoveDependency(t *testing.T) {
+	type want struct {
+		out string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		in     string
+		pkg    string
+		want   want
+	}{
+		"RemoveMiddle": {
+			reason: "Should remove the entry and keep all comments that do not belong to it.",
+			in:     metaFile,
+			pkg:    "xpkg.upbound.io/upbound/provider-aws-rds",
+			want: want{
+				out: `apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: platform-ref-aws
+spec:
+  crossplane:
+    version: ">=v1.14.0"
+  # providers used by the compositions
+  dependsOn:
+    - provider: xpkg.upbound.io/upbound/provider-aws-ec2
+      version: "v0.47.0" # pinned for networking
+    # database
+    - configuration: xpkg.upbound.io/upbound/configuration-aws-network
+`,
+			},
+		},
+		"RemoveLast": {
+			reason: "Should remove the dependsOn key if its last entry is removed.",
+			in: `apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: platform-ref-aws
+spec:
+  dependsOn:
+  - provider: xpkg.upbound.io/upbound/provider-aws-ec2
+    version: v0.47.0
+  crossplane:
+    version: ">=v1.14.0"
+`,
+			pkg: "xpkg.upbound.io/upbound/provider-aws-ec2",
+			want: want{
+				out: `apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: platform-ref-aws
+spec:
+  crossplane:
+    version: ">=v1.14.0"
+`,
+			},
+		},
+		"NotFound": {
+			reason: "Should return an error if the dependency does not exist.",
+			in:     metaFile,
+			pkg:    "xpkg.upbound.io/upbound/provider-gcp",
+			want: want{
+				err: fmt.Errorf(errDependencyNotFoundFmt, "xpkg.upbound.io/upbound/provider-gcp"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := RemoveDependency([]byte(tc.in), tc.pkg)
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRemoveDependency(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.out, string(got)); diff != "" {
+				t.Errorf("\n%s\nRemoveDependency(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSetDependencyVersion(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		pkg     string
+		version string
+		want    string
+	}{
+		"Quoted": {
+			reason:  "Should replace a quoted version and keep trailing comments.",
+			pkg:     "xpkg.upbound.io/upbound/provider-aws-ec2",
+			version: "v0.48.0",
+			want:    `      version: "v0.48.0" # pinned for networking`,
+		},
+		"PlainToConstraint": {
+			reason:  "Should quote a constraint that is not a valid plain scalar.",
+			pkg:     "xpkg.upbound.io/upbound/provider-aws-rds",
+			version: ">=v0.48.0",
+			want:    `      version: ">=v0.48.0"`,
+		},
+		"Missing": {
+			reason:  "Should add a version if the dependency does not have one.",
+			pkg:     "xpkg.upbound.io/upbound/configuration-aws-network",
+			version: "v0.7.0",
+			want:    `      version: v0.7.0`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := SetDependencyVersion([]byte(metaFile), tc.pkg, tc.version)
+			if err != nil {
+				t.Fatalf("\n%s\nSetDependencyVersion(...): unexpected error: %v", tc.reason, err)
+			}
+
+			for _, l := range []string{tc.want, "  # providers used by the compositions", "    # database"} {
+				if !containsLine(string(got), l) {
+					t.Errorf("\n%s\nSetDependencyVersion(...): missing line %q in:\n%s", tc.reason, l, got)
+				}
+			}
+		})
+	}
+}
+
+func TestAddDependency(t *testing.T) {
+	dep := v1beta1.Dependency{
+		Package:     "xpkg.upbound.io/upbound/provider-aws-s3",
+		Type:        v1beta1.ProviderPackageType,
+		Constraints: ">=v1.1.0",
+	}
+
+	type want struct {
+		out string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		in     string
+		want   want
+	}{
+		"Append": {
+			reason: "Should add the dependency after the last entry, using the indentation of the existing entries.",
+			in:     metaFile,
+			want: want{
+				out: metaFile + `    - provider: xpkg.upbound.io/upbound/provider-aws-s3
+      version: ">=v1.1.0"
+`,
+			},
+		},
+		"NoDependsOn": {
+			reason: "Should add dependsOn as the last field of spec if it does not exist.",
+			in: `apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: platform-ref-aws
+spec:
+  crossplane:
+    version: ">=v1.14.0"
+# trailing comment
+`,
+			want: want{
+				out: `apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: platform-ref-aws
+spec:
+  crossplane:
+    version: ">=v1.14.0"
+  dependsOn:
+  - provider: xpkg.upbound.io/upbound/provider-aws-s3
+    version: ">=v1.1.0"
+# trailing comment
+`,
+			},
+		},
+		"EmptyDependsOn": {
+			reason: "Should add the first entry below an empty dependsOn.",
+			in: `apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: platform-ref-aws
+spec:
+  dependsOn:
+`,
+			want: want{
+				out: `apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: platform-ref-aws
+spec:
+  dependsOn:
+  - provider: xpkg.upbound.io/upbound/provider-aws-s3
+    version: ">=v1.1.0"
+`,
+			},
+		},
+		"NoSpec": {
+			reason: "Should add spec if it does not exist, even if the file does not end with a newline.",
+			in: `apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: platform-ref-aws`,
+			want: want{
+				out: `apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: platform-ref-aws
+spec:
+  dependsOn:
+  - provider: xpkg.upbound.io/upbound/provider-aws-s3
+    version: ">=v1.1.0"
+`,
+			},
+		},
+		"FlowStyle": {
+			reason: "Should return an error for a flow style dependsOn.",
+			in: `apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: platform-ref-aws
+spec:
+  dependsOn: []
+`,
+			want: want{
+				err: errors.New(errFlowStyleDependsOn),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := AddDependency([]byte(tc.in), dep)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAddDependency(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.out, string(got)); diff != "" {
+				t.Errorf("\n%s\nAddDependency(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func containsLine(s, line string) bool {
+	for _, l := range strings.Split(s, "\n") {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
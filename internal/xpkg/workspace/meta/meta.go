@@ -24,6 +24,7 @@ import (
 
 	v1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
 	"github.com/crossplane/crossplane/apis/pkg/meta/v1alpha1"
+	metav1beta1 "github.com/crossplane/crossplane/apis/pkg/meta/v1beta1"
 	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
 
 	"github.com/upbound/up/internal/xpkg/dep/manager"
@@ -92,6 +93,8 @@ func (m *Meta) Bytes() ([]byte, error) {
 		t = v.GetCreationTimestamp()
 	case *v1.Provider:
 		t = v.GetCreationTimestamp()
+	case *metav1beta1.Function:
+		t = v.GetCreationTimestamp()
 	default:
 		return nil, errors.New(errInvalidMetaFile)
 	}
@@ -133,6 +136,12 @@ func upsertDeps(d v1beta1.Dependency, o runtime.Object) error { // nolint:gocycl
 			}
 			deps[i].Version = d.Constraints
 			processed = true
+		} else if dep.Function != nil && *dep.Function == d.Package {
+			if processed {
+				return errors.New(errMetaContainsDupeDep)
+			}
+			deps[i].Version = d.Constraints
+			processed = true
 		}
 	}
 
@@ -142,9 +151,12 @@ func upsertDeps(d v1beta1.Dependency, o runtime.Object) error { // nolint:gocycl
 			Version: d.Constraints,
 		}
 
-		if d.Type == v1beta1.ProviderPackageType {
+		switch d.Type { //nolint:exhaustive
+		case v1beta1.ProviderPackageType:
 			dep.Provider = &d.Package
-		} else {
+		case v1beta1.FunctionPackageType:
+			dep.Function = &d.Package
+		default:
 			dep.Configuration = &d.Package
 		}
 
@@ -160,6 +172,8 @@ func upsertDeps(d v1beta1.Dependency, o runtime.Object) error { // nolint:gocycl
 		v.Spec.DependsOn = convertToV1alpha1(deps)
 	case *v1.Provider:
 		v.Spec.DependsOn = deps
+	case *metav1beta1.Function:
+		v.Spec.DependsOn = convertToV1beta1(deps)
 	}
 
 	return nil
@@ -184,6 +198,19 @@ func cleanNullTs(p runtime.Object) ([]byte, error) {
 	return sigsyaml.Marshal(m)
 }
 
+func convertToV1beta1(deps []v1.Dependency) []metav1beta1.Dependency {
+	betaDeps := make([]metav1beta1.Dependency, 0)
+	for _, d := range deps {
+		betaDeps = append(betaDeps, metav1beta1.Dependency{
+			Provider:      d.Provider,
+			Configuration: d.Configuration,
+			Function:      d.Function,
+			Version:       d.Version,
+		})
+	}
+	return betaDeps
+}
+
 func convertToV1alpha1(deps []v1.Dependency) []v1alpha1.Dependency {
 	alphaDeps := make([]v1alpha1.Dependency, 0)
 	for _, d := range deps {
@@ -27,6 +27,7 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	metav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
 	metav1alpha1 "github.com/crossplane/crossplane/apis/pkg/meta/v1alpha1"
+	metav1beta1 "github.com/crossplane/crossplane/apis/pkg/meta/v1beta1"
 	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
 
 	"github.com/upbound/up/internal/xpkg/dep"
@@ -535,6 +536,39 @@ func TestUpsertDeps(t *testing.T) {
 				err: errors.New(errMetaContainsDupeDep),
 			},
 		},
+		"InsertFunctionIntoFunction": {
+			reason: "Should return an updated deps list with the included function, for Function packages.",
+			args: args{
+				dep: dep.NewWithType(
+					"crossplane-contrib/function-auto-ready@v0.1.0",
+					string(v1beta1.FunctionPackageType),
+				),
+				pkg: &metav1beta1.Function{
+					Spec: metav1beta1.FunctionSpec{
+						MetaSpec: metav1beta1.MetaSpec{
+							DependsOn: []metav1beta1.Dependency{
+								{
+									Provider: pointer.String("crossplane/provider-aws"),
+									Version:  "v1.0.0",
+								},
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				deps: []metav1.Dependency{
+					{
+						Provider: pointer.String("crossplane/provider-aws"),
+						Version:  "v1.0.0",
+					},
+					{
+						Function: pointer.String("crossplane-contrib/function-auto-ready"),
+						Version:  "v0.1.0",
+					},
+				},
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -712,6 +746,39 @@ func TestDependsOn(t *testing.T) {
 				deps: []v1beta1.Dependency{},
 			},
 		},
+		"FunctionDependency": {
+			reason: "Should return function dependencies of Function packages.",
+			args: args{
+				metaFile: &metav1beta1.Function{
+					TypeMeta: apimetav1.TypeMeta{
+						APIVersion: "meta.pkg.crossplane.io/v1beta1",
+						Kind:       "Function",
+					},
+					ObjectMeta: apimetav1.ObjectMeta{
+						Name: "function-test",
+					},
+					Spec: metav1beta1.FunctionSpec{
+						MetaSpec: metav1beta1.MetaSpec{
+							DependsOn: []metav1beta1.Dependency{
+								{
+									Function: pointer.String("crossplane-contrib/function-auto-ready"),
+									Version:  ">=v0.1.0",
+								},
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				deps: []v1beta1.Dependency{
+					{
+						Package:     "crossplane-contrib/function-auto-ready",
+						Type:        v1beta1.FunctionPackageType,
+						Constraints: ">=v0.1.0",
+					},
+				},
+			},
+		},
 	}
 
 	for name, tc := range cases {
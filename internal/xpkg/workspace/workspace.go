@@ -42,6 +42,7 @@ import (
 	xparser "github.com/crossplane/crossplane-runtime/pkg/parser"
 	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
 	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
+	pkgmetav1beta1 "github.com/crossplane/crossplane/apis/pkg/meta/v1beta1"
 
 	"github.com/upbound/up/internal/xpkg"
 	pyaml "github.com/upbound/up/internal/xpkg/parser/yaml"
@@ -60,7 +61,7 @@ const (
 
 	errCompositionResources = "resources in Composition are malformed"
 	errInvalidFileURI       = "invalid path supplied"
-	errInvalidPackage       = "invalid package; more than one meta (configuration or provider) file supplied"
+	errInvalidPackage       = "invalid package; more than one meta (configuration, provider or function) file supplied"
 )
 
 // builds static YAML path strings ahead of usage.
@@ -170,6 +171,18 @@ func (w *Workspace) Write(m *meta.Meta) error {
 	return afero.WriteFile(w.fs, filepath.Join(w.view.metaLocation, xpkg.MetaFile), b, os.ModePerm)
 }
 
+// ReadMetaFile returns the raw contents of the workspace's meta file.
+func (w *Workspace) ReadMetaFile() ([]byte, error) {
+	return afero.ReadFile(w.fs, filepath.Join(w.view.metaLocation, xpkg.MetaFile))
+}
+
+// WriteMetaFile writes the supplied raw contents to the workspace's meta
+// file. Unlike Write, the contents are written as is, which allows callers to
+// preserve comments and formatting.
+func (w *Workspace) WriteMetaFile(b []byte) error {
+	return afero.WriteFile(w.fs, filepath.Join(w.view.metaLocation, xpkg.MetaFile), b, os.ModePerm)
+}
+
 // Parse parses the full workspace in order to hydrate the workspace's View.
 func (w *Workspace) Parse(ctx context.Context) error {
 	w.mu.Lock()
@@ -330,6 +343,16 @@ func (v *View) parseDoc(ctx context.Context, pCtx parseContext) (NodeIdentifier,
 		if err := v.parseMeta(ctx, pCtx); err != nil {
 			return NodeIdentifier{}, err
 		}
+	case pkgmetav1beta1.FunctionKind:
+		// Function is also the kind used to install functions, which is
+		// commonly found in examples, so we need to check the group as well.
+		if obj.GroupVersionKind().Group != pkgmetav1beta1.Group {
+			v.parseExample(pCtx)
+			break
+		}
+		if err := v.parseMeta(ctx, pCtx); err != nil {
+			return NodeIdentifier{}, err
+		}
 	default:
 		v.parseExample(pCtx)
 	}
@@ -362,6 +385,11 @@ func (v *View) parseComposition(ctx context.Context, pCtx parseContext) error {
 	if err != nil {
 		return err
 	}
+	if resNode == nil {
+		// NOTE: Compositions in pipeline mode have no resources of their
+		// own, their resources are composed by functions.
+		return nil
+	}
 	seq, ok := resNode.(*ast.SequenceNode)
 	if !ok {
 		// NOTE(hasheddan): if the Composition's resources field is not a
@@ -403,10 +431,15 @@ func (v *View) parseComposition(ctx context.Context, pCtx parseContext) error {
 	return nil
 }
 
+// IsExample returns true if the file at the supplied path holds examples.
+func IsExample(path string) bool {
+	return strings.Contains(filepath.Dir(path), "example")
+}
+
 func (v *View) parseExample(ctx parseContext) {
 	// NOTE(@tnthornton): we handle example claims specially so that we have
 	// them available for CompositeTemplate validation.
-	if strings.Contains(filepath.Dir(ctx.path), "example") {
+	if IsExample(ctx.path) {
 		curr, ok := v.examples[ctx.obj.GroupVersionKind()]
 		if !ok {
 			curr = make([]Node, 0)
@@ -132,6 +132,27 @@ func TestParse(t *testing.T) {
 				nodeID("vpcpostgresqlinstances.aws.database.example.org", xpextv1.CompositionGroupVersionKind): {},
 			},
 		},
+		"SuccessfulParsePipelineComposition": {
+			reason: "Should add a package node for a Composition in pipeline mode, which has no embedded resources.",
+			opts: []Option{WithFS(func() afero.Fs {
+				fs := afero.NewMemMapFs()
+				_ = afero.WriteFile(fs, "/ws/composition.yaml", []byte(`apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: pipeline
+spec:
+  mode: Pipeline
+  pipeline:
+  - step: patch-and-transform
+    functionRef:
+      name: function-patch-and-transform
+`), os.ModePerm)
+				return fs
+			}())},
+			nodes: map[NodeIdentifier]struct{}{
+				nodeID("pipeline", xpextv1.CompositionGroupVersionKind): {},
+			},
+		},
 		"SuccessfulParseMultipleSameFile": {
 			reason: "Should add a package node for every resource when multiple objects exist in single file.",
 			opts: []Option{WithFS(func() afero.Fs {
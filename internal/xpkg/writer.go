@@ -23,7 +23,8 @@ import (
 )
 
 const (
-	errAlreadyExistsFmt = "directory contains pre-existing meta file: %s"
+	errAlreadyExistsFmt     = "directory contains pre-existing meta file: %s"
+	errFileAlreadyExistsFmt = "directory contains pre-existing file: %s"
 )
 
 // Writer defines a writer that is used for creating package meta files.
@@ -97,6 +98,27 @@ func (w *Writer) NewMetaFile() error {
 	return afero.WriteFile(w.fs, targetFile, w.fileBody, StreamFileMode)
 }
 
+// NewFile creates a new file with the supplied body at the supplied path
+// relative to the configured root, creating any missing parent directories.
+func (w *Writer) NewFile(path string, body []byte) error {
+	targetFile := filepath.Join(w.root, path)
+
+	// return err if file already exists
+	exists, err := afero.Exists(w.fs, targetFile)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errors.Errorf(errFileAlreadyExistsFmt, w.relativePath(targetFile))
+	}
+
+	if err := w.fs.MkdirAll(filepath.Dir(targetFile), os.ModePerm); err != nil {
+		return err
+	}
+
+	return afero.WriteFile(w.fs, targetFile, body, StreamFileMode)
+}
+
 func (w *Writer) relativePath(path string) string {
 	if !filepath.IsAbs(path) {
 		return path
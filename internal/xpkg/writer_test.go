@@ -77,3 +77,42 @@ func TestNewMetaFile(t *testing.T) {
 		})
 	}
 }
+
+func TestNewFile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	_ = afero.WriteFile(fs, "/previous/input/input.yaml", []byte{}, StreamFileMode)
+
+	type want struct {
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		root   string
+		want   want
+	}{
+		"SuccessDirectoryDoesNotExist": {
+			reason: "We should create parent directories if they don't exist.",
+			root:   "/test",
+		},
+		"AlreadyExists": {
+			reason: "We should return an error if a file already exists at the given location.",
+			root:   "/previous",
+			want: want{
+				err: errors.Errorf(errFileAlreadyExistsFmt, "input/input.yaml"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := NewFileWriter(WithRoot(tc.root), WithFs(fs))
+			err := w.NewFile(filepath.Join("input", "input.yaml"), []byte("body"))
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nNewFile(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
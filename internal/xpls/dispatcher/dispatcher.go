@@ -24,8 +24,16 @@ import (
 )
 
 const (
-	errParseSaveParameters   = "failed to parse document save parameters"
-	errParseChangeParameters = "failed to parse document change parameters"
+	errParseSaveParameters            = "failed to parse document save parameters"
+	errParseChangeParameters          = "failed to parse document change parameters"
+	errParseCompletionParameters      = "failed to parse completion parameters"
+	errParseHoverParameters           = "failed to parse hover parameters"
+	errParseDefinitionParameters      = "failed to parse definition parameters"
+	errParseReferenceParameters       = "failed to parse reference parameters"
+	errParseCodeActionParameters      = "failed to parse code action parameters"
+	errParseDocumentSymbolParameters  = "failed to parse document symbol parameters"
+	errParseWorkspaceSymbolParameters = "failed to parse workspace symbol parameters"
+	errReply                          = "failed to reply to request"
 )
 
 // Server defines the set of LSP methods we currently support.
@@ -35,6 +43,13 @@ type Server interface {
 	DidSave(context.Context, *protocol.DidSaveTextDocumentParams)
 	DidChangeWatchedFiles(context.Context, *protocol.DidChangeWatchedFilesParams)
 	Initialize(context.Context, *jsonrpc2.Conn, jsonrpc2.ID, *protocol.InitializeParams)
+	Completion(context.Context, *protocol.CompletionParams) (*protocol.CompletionList, error)
+	Hover(context.Context, *protocol.HoverParams) (*protocol.Hover, error)
+	Definition(context.Context, *protocol.DefinitionParams) ([]protocol.Location, error)
+	References(context.Context, *protocol.ReferenceParams) ([]protocol.Location, error)
+	CodeAction(context.Context, *protocol.CodeActionParams) ([]protocol.CodeAction, error)
+	DocumentSymbol(context.Context, *protocol.DocumentSymbolParams) ([]protocol.DocumentSymbol, error)
+	Symbol(context.Context, *protocol.WorkspaceSymbolParams) ([]protocol.SymbolInformation, error)
 }
 
 // Dispatcher is responsible for routing JSONPPC request events to the
@@ -119,5 +134,93 @@ func (d *Dispatcher) Dispatch(ctx context.Context, server Server, conn *jsonrpc2
 
 		server.DidChangeWatchedFiles(ctx, &params)
 		return
+	case "textDocument/completion":
+		var params protocol.CompletionParams
+		if err := json.Unmarshal(*r.Params, &params); err != nil {
+			d.log.Debug(errParseCompletionParameters)
+			d.replyWithError(ctx, conn, r.ID, jsonrpc2.CodeInvalidParams, errParseCompletionParameters)
+			return
+		}
+		list, err := server.Completion(ctx, &params)
+		d.reply(ctx, conn, r.ID, list, err)
+		return
+	case "textDocument/hover":
+		var params protocol.HoverParams
+		if err := json.Unmarshal(*r.Params, &params); err != nil {
+			d.log.Debug(errParseHoverParameters)
+			d.replyWithError(ctx, conn, r.ID, jsonrpc2.CodeInvalidParams, errParseHoverParameters)
+			return
+		}
+		hover, err := server.Hover(ctx, &params)
+		d.reply(ctx, conn, r.ID, hover, err)
+		return
+	case "textDocument/definition":
+		var params protocol.DefinitionParams
+		if err := json.Unmarshal(*r.Params, &params); err != nil {
+			d.log.Debug(errParseDefinitionParameters)
+			d.replyWithError(ctx, conn, r.ID, jsonrpc2.CodeInvalidParams, errParseDefinitionParameters)
+			return
+		}
+		locs, err := server.Definition(ctx, &params)
+		d.reply(ctx, conn, r.ID, locs, err)
+		return
+	case "textDocument/references":
+		var params protocol.ReferenceParams
+		if err := json.Unmarshal(*r.Params, &params); err != nil {
+			d.log.Debug(errParseReferenceParameters)
+			d.replyWithError(ctx, conn, r.ID, jsonrpc2.CodeInvalidParams, errParseReferenceParameters)
+			return
+		}
+		locs, err := server.References(ctx, &params)
+		d.reply(ctx, conn, r.ID, locs, err)
+		return
+	case "textDocument/codeAction":
+		var params protocol.CodeActionParams
+		if err := json.Unmarshal(*r.Params, &params); err != nil {
+			d.log.Debug(errParseCodeActionParameters)
+			d.replyWithError(ctx, conn, r.ID, jsonrpc2.CodeInvalidParams, errParseCodeActionParameters)
+			return
+		}
+		actions, err := server.CodeAction(ctx, &params)
+		d.reply(ctx, conn, r.ID, actions, err)
+		return
+	case "textDocument/documentSymbol":
+		var params protocol.DocumentSymbolParams
+		if err := json.Unmarshal(*r.Params, &params); err != nil {
+			d.log.Debug(errParseDocumentSymbolParameters)
+			d.replyWithError(ctx, conn, r.ID, jsonrpc2.CodeInvalidParams, errParseDocumentSymbolParameters)
+			return
+		}
+		syms, err := server.DocumentSymbol(ctx, &params)
+		d.reply(ctx, conn, r.ID, syms, err)
+		return
+	case "workspace/symbol":
+		var params protocol.WorkspaceSymbolParams
+		if err := json.Unmarshal(*r.Params, &params); err != nil {
+			d.log.Debug(errParseWorkspaceSymbolParameters)
+			d.replyWithError(ctx, conn, r.ID, jsonrpc2.CodeInvalidParams, errParseWorkspaceSymbolParameters)
+			return
+		}
+		syms, err := server.Symbol(ctx, &params)
+		d.reply(ctx, conn, r.ID, syms, err)
+		return
+	}
+}
+
+// reply replies to the request with the supplied ID with the supplied result,
+// or with the supplied error if it is not nil.
+func (d *Dispatcher) reply(ctx context.Context, conn *jsonrpc2.Conn, id jsonrpc2.ID, result any, err error) {
+	if err != nil {
+		d.replyWithError(ctx, conn, id, jsonrpc2.CodeInternalError, err.Error())
+		return
+	}
+	if err := conn.Reply(ctx, id, result); err != nil {
+		d.log.Debug(errReply, "error", err)
+	}
+}
+
+func (d *Dispatcher) replyWithError(ctx context.Context, conn *jsonrpc2.Conn, id jsonrpc2.ID, code int64, msg string) {
+	if err := conn.ReplyWithError(ctx, id, &jsonrpc2.Error{Code: code, Message: msg}); err != nil {
+		d.log.Debug(errReply, "error", err)
 	}
 }
@@ -30,6 +30,8 @@ type Handler struct {
 	log        logging.Logger
 	dispatcher *dispatcher.Dispatcher
 	server     *server.Server
+	frozen     bool
+	offline    bool
 }
 
 // New constructs a new LSP handler,
@@ -38,7 +40,15 @@ func New(opts ...Option) (*Handler, error) {
 		log: logging.NewNopLogger(),
 	}
 
-	server, err := server.New(server.WithLogger(h.log))
+	for _, o := range opts {
+		o(h)
+	}
+
+	server, err := server.New(
+		server.WithLogger(h.log),
+		server.WithFrozen(h.frozen),
+		server.WithOffline(h.offline),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -47,10 +57,6 @@ func New(opts ...Option) (*Handler, error) {
 
 	h.dispatcher = dispatcher.New(dispatcher.WithLogger(h.log))
 
-	for _, o := range opts {
-		o(h)
-	}
-
 	return h, nil
 }
 
@@ -64,6 +70,21 @@ func WithLogger(l logging.Logger) Option {
 	}
 }
 
+// WithFrozen configures the handler to only resolve dependencies that are
+// pinned in the workspace's lock file.
+func WithFrozen(f bool) Option {
+	return func(h *Handler) {
+		h.frozen = f
+	}
+}
+
+// WithOffline configures the handler to not access the network.
+func WithOffline(o bool) Option {
+	return func(h *Handler) {
+		h.offline = o
+	}
+}
+
 // Handle handles LSP requests. It panics if we cannot initialize the workspace.
 func (h *Handler) Handle(ctx context.Context, conn *jsonrpc2.Conn, r *jsonrpc2.Request) { // nolint:gocyclo
 	h.dispatcher.Dispatch(ctx, h.server, conn, r)
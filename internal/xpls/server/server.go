@@ -17,6 +17,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -25,10 +26,13 @@ import (
 	"github.com/golang/tools/span"
 	"github.com/sourcegraph/go-lsp"
 	"github.com/sourcegraph/jsonrpc2"
+	"github.com/spf13/afero"
 
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 
 	"github.com/upbound/up/internal/version"
+	"github.com/upbound/up/internal/xpkg"
+	"github.com/upbound/up/internal/xpkg/dep/lock"
 	"github.com/upbound/up/internal/xpkg/dep/manager"
 	"github.com/upbound/up/internal/xpkg/snapshot"
 )
@@ -36,6 +40,10 @@ import (
 var (
 	// kind describes how text synchronization works.
 	kind = lsp.TDSKIncremental
+	// completionTriggers are the characters that trigger completion in
+	// addition to those that start a word, e.g. to complete the segments of
+	// field paths.
+	completionTriggers = []string{"."}
 )
 
 const (
@@ -51,16 +59,20 @@ const (
 	errValidateMeta       = "failed to validate crossplane.yaml file in workspace"
 	errShowMessage        = "failed to show message"
 	errValidateNodes      = "failed to validate nodes in workspace"
+	errReadLock           = "failed to read crossplane.lock in workspace"
 )
 
 // Server services incoming LSP requests.
 type Server struct {
 	conn *jsonrpc2.Conn
 
-	i   *version.Informer
-	log logging.Logger
-	m   *manager.Manager
-	mu  sync.RWMutex
+	i        *version.Informer
+	log      logging.Logger
+	m        *manager.Manager
+	mu       sync.RWMutex
+	frozen   bool
+	offline  bool
+	interval time.Duration
 
 	root span.URI
 
@@ -78,11 +90,16 @@ func New(opts ...Option) (*Server, error) {
 	if err != nil {
 		return nil, err
 	}
+	s.interval = interval
+
+	for _, o := range opts {
+		o(s)
+	}
 
 	// TODO(@tnthornton) supply cache root from Config here.
 	m, err := manager.New(
 		manager.WithLogger(s.log),
-		manager.WithWatchInterval(&interval),
+		manager.WithWatchInterval(&s.interval),
 	)
 	if err != nil {
 		return nil, err
@@ -106,6 +123,22 @@ func WithLogger(l logging.Logger) Option {
 	}
 }
 
+// WithFrozen configures the Server to only resolve dependencies that are
+// pinned in the workspace's crossplane.lock file.
+func WithFrozen(f bool) Option {
+	return func(s *Server) {
+		s.frozen = f
+	}
+}
+
+// WithOffline configures the Server to not access the network, e.g. to check
+// for updates. Dependencies are always read from the package cache.
+func WithOffline(o bool) Option {
+	return func(s *Server) {
+		s.offline = o
+	}
+}
+
 // Initialize handles calls to Initialize.
 func (s *Server) Initialize(ctx context.Context, conn *jsonrpc2.Conn, id jsonrpc2.ID, params *protocol.InitializeParams) {
 
@@ -117,6 +150,26 @@ func (s *Server) Initialize(ctx context.Context, conn *jsonrpc2.Conn, id jsonrpc
 	s.conn = conn
 	s.root = params.RootURI.SpanURI()
 
+	if s.frozen {
+		// replace the dependency manager with one that only resolves
+		// dependencies pinned in the workspace's lock file.
+		l, err := lock.Read(afero.NewOsFs(), filepath.Join(s.root.Filename(), xpkg.LockFile))
+		if err != nil {
+			s.log.Debug(errReadLock, "error", err)
+			l = lock.New()
+		}
+		m, err := manager.New(
+			manager.WithLogger(s.log),
+			manager.WithWatchInterval(&s.interval),
+			manager.WithLock(l),
+			manager.WithFrozen(),
+		)
+		if err != nil {
+			panic(err)
+		}
+		s.m = m
+	}
+
 	factory, err := snapshot.NewFactory(
 		s.root.Filename(),
 		snapshot.WithLogger(s.log),
@@ -143,6 +196,15 @@ func (s *Server) Initialize(ctx context.Context, conn *jsonrpc2.Conn, id jsonrpc
 			TextDocumentSync: &lsp.TextDocumentSyncOptionsOrKind{
 				Kind: &kind,
 			},
+			CompletionProvider: &lsp.CompletionOptions{
+				TriggerCharacters: completionTriggers,
+			},
+			HoverProvider:           true,
+			DefinitionProvider:      true,
+			ReferencesProvider:      true,
+			CodeActionProvider:      true,
+			DocumentSymbolProvider:  true,
+			WorkspaceSymbolProvider: true,
 		},
 	}
 
@@ -154,7 +216,9 @@ func (s *Server) Initialize(ctx context.Context, conn *jsonrpc2.Conn, id jsonrpc
 
 	s.registerWatchFilesCapability(context.Background()) //nolint:contextcheck // TODO(epk) thread through top level context
 	s.checkMetaFile(context.Background())                //nolint:contextcheck // TODO(epk) thread through top level context
-	s.checkForUpdates(context.Background())              //nolint:contextcheck // TODO(epk) thread through top level context
+	if !s.offline {
+		s.checkForUpdates(context.Background()) //nolint:contextcheck // TODO(epk) thread through top level context
+	}
 }
 
 // DidChange handles calls to DidChange.
@@ -260,6 +324,55 @@ func (s *Server) DidChangeWatchedFiles(ctx context.Context, params *protocol.Did
 	}
 }
 
+// Completion handles calls to Completion.
+func (s *Server) Completion(ctx context.Context, params *protocol.CompletionParams) (*protocol.CompletionList, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.snap.Complete(ctx, params.TextDocument.URI.SpanURI(), params.Position)
+}
+
+// Hover handles calls to Hover.
+func (s *Server) Hover(ctx context.Context, params *protocol.HoverParams) (*protocol.Hover, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.snap.Hover(ctx, params.TextDocument.URI.SpanURI(), params.Position)
+}
+
+// Definition handles calls to Definition.
+func (s *Server) Definition(ctx context.Context, params *protocol.DefinitionParams) ([]protocol.Location, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.snap.Definition(ctx, params.TextDocument.URI.SpanURI(), params.Position)
+}
+
+// References handles calls to References.
+func (s *Server) References(ctx context.Context, params *protocol.ReferenceParams) ([]protocol.Location, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.snap.References(ctx, params.TextDocument.URI.SpanURI(), params.Position, params.Context.IncludeDeclaration)
+}
+
+// CodeAction handles calls to CodeAction.
+func (s *Server) CodeAction(ctx context.Context, params *protocol.CodeActionParams) ([]protocol.CodeAction, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.snap.CodeActions(ctx, params.TextDocument.URI.SpanURI(), params.Context.Diagnostics)
+}
+
+// DocumentSymbol handles calls to DocumentSymbol.
+func (s *Server) DocumentSymbol(ctx context.Context, params *protocol.DocumentSymbolParams) ([]protocol.DocumentSymbol, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.snap.DocumentSymbols(ctx, params.TextDocument.URI.SpanURI())
+}
+
+// Symbol handles calls to Symbol.
+func (s *Server) Symbol(ctx context.Context, params *protocol.WorkspaceSymbolParams) ([]protocol.SymbolInformation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.snap.WorkspaceSymbols(ctx, params.Query)
+}
+
 func (s *Server) publishDiagnostics(ctx context.Context, params *protocol.PublishDiagnosticsParams) {
 	if err := s.conn.Notify(ctx, "textDocument/publishDiagnostics", params); err != nil {
 		s.log.Debug(errPublishDiagnostics, "error", err)